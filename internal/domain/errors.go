@@ -26,7 +26,76 @@ var (
 	ErrOrderNotFound    = errors.New("ordem não encontrada")
 	ErrExceedsLimit     = errors.New("ordem excede limite do perfil")
 	ErrInvalidOrderSide = errors.New("lado da ordem inválido")
+	ErrOrderNotOpen     = errors.New("ordem não está aberta")
+
+	// Client order ID errors
+	ErrInvalidClientOrderID  = errors.New("client_order_id inválido")
+	ErrClientOrderIDConflict = errors.New("client_order_id já usado com outros parâmetros")
 
 	// Matching errors
 	ErrNoMatch = errors.New("nenhuma correspondência encontrada")
+
+	// Snapshot errors
+	ErrSnapshotNotFound = errors.New("snapshot não encontrado")
 )
+
+// CodeInternal é o código de qualquer erro sem mapeamento
+const CodeInternal = "INTERNAL_ERROR"
+
+// errorCode associa um erro ao seu código estável
+type errorCode struct {
+	err  error
+	code string
+}
+
+// errorCodes é a tabela única de códigos estáveis dos erros de domínio. REST,
+// API do engine e gateway FIX acrescentam apenas o mapeamento do transporte.
+var errorCodes = []errorCode{
+	{ErrInvalidOrder, "INVALID_ORDER"},
+	{ErrInvalidOrderSide, "INVALID_ORDER_SIDE"},
+	{ErrInvalidQuantity, "INVALID_QUANTITY"},
+	{ErrInvalidPrice, "INVALID_PRICE"},
+	{ErrInvalidSymbol, "INVALID_SYMBOL"},
+	{ErrInvalidUser, "INVALID_USER"},
+	{ErrInvalidClientOrderID, "INVALID_CLIENT_ORDER_ID"},
+	{ErrUserNotFound, "USER_NOT_FOUND"},
+	{ErrOrderNotFound, "ORDER_NOT_FOUND"},
+	{ErrSnapshotNotFound, "SNAPSHOT_NOT_FOUND"},
+	{ErrOrderNotOpen, "ORDER_NOT_OPEN"},
+	{ErrClientOrderIDConflict, "CLIENT_ORDER_ID_CONFLICT"},
+	{ErrPriceTooLow, "PRICE_TOO_LOW"},
+	{ErrInsufficientBalance, "INSUFFICIENT_BALANCE"},
+	{ErrInsufficientPosition, "INSUFFICIENT_POSITION"},
+	{ErrExceedsLimit, "EXCEEDS_PROFILE_LIMIT"},
+	{ErrNoMatch, "NO_MATCH"},
+	{ErrMarketClosed, "MARKET_CLOSED"},
+}
+
+// ErrorCode retorna o código estável de err, ou CodeInternal se não houver
+func ErrorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return CodeInternal
+}
+
+// ErrorForCode retorna o erro de domínio associado a um código estável
+func ErrorForCode(code string) (error, bool) {
+	for _, c := range errorCodes {
+		if c.code == code {
+			return c.err, true
+		}
+	}
+	return nil, false
+}
+
+// ErrorCodes retorna todos os códigos estáveis de erros de domínio
+func ErrorCodes() []string {
+	codes := make([]string, len(errorCodes))
+	for i, c := range errorCodes {
+		codes[i] = c.code
+	}
+	return codes
+}
@@ -1,6 +1,10 @@
 package domain
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,8 +24,13 @@ const (
 	FILLED   OrderStatus = "FILLED"
 	REJECTED OrderStatus = "REJECTED"
 	PARTIAL  OrderStatus = "PARTIAL"
+
+	CANCELLED OrderStatus = "CANCELLED"
 )
 
+// MaxClientOrderIDLength é o tamanho máximo do client_order_id
+const MaxClientOrderIDLength = 64
+
 // Order representa uma ordem de compra ou venda
 type Order struct {
 	ID        string      `json:"id"`
@@ -34,6 +43,10 @@ type Order struct {
 	CreatedAt time.Time   `json:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at"`
 
+	// ClientOrderID é o identificador atribuído pelo cliente, único por
+	// usuário; reenvios com o mesmo valor não criam outra ordem
+	ClientOrderID string `json:"client_order_id,omitempty"`
+
 	// Campos para matching
 	RemainingQuantity int `json:"remaining_quantity,omitempty"`
 }
@@ -42,7 +55,7 @@ type Order struct {
 func NewOrder(userID, symbol string, side OrderSide, quantity int, price float64) *Order {
 	now := time.Now().UTC()
 	return &Order{
-		ID:                generateOrderID(),
+		ID:                generateOrderID(now),
 		UserID:            userID,
 		Symbol:            symbol,
 		Side:              side,
@@ -65,17 +78,119 @@ func (o *Order) GetValue() float64 {
 	return float64(o.Quantity) * o.Price
 }
 
-// generateOrderID gera um ID único para a ordem
-func generateOrderID() string {
-	return "order-" + time.Now().Format("20060102150405") + "-" + randomString(6)
+// Fill registra a execução de parte da ordem
+func (o *Order) Fill(quantity int) {
+	o.FillAt(quantity, time.Now().UTC())
+}
+
+// FillAt registra a execução de parte da ordem no instante informado
+func (o *Order) FillAt(quantity int, at time.Time) {
+	o.RemainingQuantity -= quantity
+	if o.RemainingQuantity == 0 {
+		o.Status = FILLED
+	} else {
+		o.Status = PARTIAL
+	}
+	o.UpdatedAt = at
+}
+
+// FilledQuantity retorna a quantidade já executada
+func (o *Order) FilledQuantity() int {
+	return o.Quantity - o.RemainingQuantity
 }
 
-// randomString gera uma string aleatória
-func randomString(length int) string {
-	const chars = "abcdefghijklmnopqrstuvwxyz0123456789"
-	result := make([]byte, length)
-	for i := range result {
-		result[i] = chars[time.Now().UnixNano()%int64(len(chars))]
+// Validate verifica os campos básicos da ordem
+func (o *Order) Validate() error {
+	switch {
+	case o.UserID == "":
+		return ErrInvalidUser
+	case o.Symbol == "":
+		return ErrInvalidSymbol
+	case o.Side != BUY && o.Side != SELL:
+		return ErrInvalidOrderSide
+	case o.Quantity <= 0:
+		return ErrInvalidQuantity
+	case o.Price <= 0:
+		return ErrInvalidPrice
+	case !validClientOrderID(o.ClientOrderID):
+		return ErrInvalidClientOrderID
 	}
-	return string(result)
+	return nil
+}
+
+// validClientOrderID aceita vazio ou até MaxClientOrderIDLength caracteres
+// ASCII visíveis
+func validClientOrderID(id string) bool {
+	if len(id) > MaxClientOrderIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// SameParams informa se as ordens têm símbolo, lado, quantidade e preço iguais
+func (o *Order) SameParams(other *Order) bool {
+	return o.Symbol == other.Symbol && o.Side == other.Side && o.Quantity == other.Quantity && o.Price == other.Price
+}
+
+// Clone retorna uma cópia da ordem
+func (o *Order) Clone() *Order {
+	clone := *o
+	return &clone
+}
+
+// Sequências usadas na geração de IDs
+var (
+	orderSequence atomic.Uint64
+	tradeSequence atomic.Uint64
+)
+
+// IDSequences é o estado dos geradores de ID de ordens e negociações
+type IDSequences struct {
+	Order uint64 `json:"order"`
+	Trade uint64 `json:"trade"`
+}
+
+// Sequences retorna o estado atual dos geradores de ID
+func Sequences() IDSequences {
+	return IDSequences{Order: orderSequence.Load(), Trade: tradeSequence.Load()}
+}
+
+// RestoreSequences redefine os geradores de ID (usado ao restaurar o estado)
+func RestoreSequences(sequences IDSequences) {
+	orderSequence.Store(sequences.Order)
+	tradeSequence.Store(sequences.Trade)
+}
+
+// ObserveOrderID avança o gerador de ordens para além de um ID já emitido,
+// evitando reutilizar sequências após restaurar ordens
+func ObserveOrderID(id string) {
+	i := strings.LastIndex(id, "-")
+	if i < 0 {
+		return
+	}
+	value, err := strconv.ParseUint(id[i+1:], 10, 64)
+	if err != nil {
+		return
+	}
+	for {
+		current := orderSequence.Load()
+		if current >= value || orderSequence.CompareAndSwap(current, value) {
+			return
+		}
+	}
+}
+
+// generateOrderID gera um ID único para a ordem
+func generateOrderID(at time.Time) string {
+	return nextID("order", &orderSequence, at)
+}
+
+// nextID gera um ID com timestamp e sequência monotônica
+func nextID(prefix string, sequence *atomic.Uint64, at time.Time) string {
+	return fmt.Sprintf("%s-%s-%06d", prefix, at.UTC().Format("20060102150405"), sequence.Add(1))
 }
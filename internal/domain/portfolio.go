@@ -90,6 +90,13 @@ func (p *Portfolio) ExecuteSell(symbol string, quantity int, price float64) erro
 	return nil
 }
 
+// Touch registra o instante da última alteração do portfolio
+func (p *Portfolio) Touch(at time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.UpdatedAt = at
+}
+
 // GetTotalValue calcula o valor total do portfolio (cash + posições)
 func (p *Portfolio) GetTotalValue(stockPrices map[string]float64) float64 {
 	p.mutex.RLock()
@@ -105,3 +112,21 @@ func (p *Portfolio) GetTotalValue(stockPrices map[string]float64) float64 {
 
 	return total
 }
+
+// Clone retorna uma cópia independente do portfolio (thread-safe)
+func (p *Portfolio) Clone() *Portfolio {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	positions := make(map[string]int, len(p.Positions))
+	for symbol, quantity := range p.Positions {
+		positions[symbol] = quantity
+	}
+
+	return &Portfolio{
+		UserID:    p.UserID,
+		Cash:      p.Cash,
+		Positions: positions,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
@@ -22,23 +22,28 @@ type Trade struct {
 
 // NewTrade cria uma nova negociação
 func NewTrade(buyOrder, sellOrder *Order, quantity int, price float64) *Trade {
+	return NewTradeAt(buyOrder, sellOrder, quantity, price, time.Now().UTC())
+}
+
+// NewTradeAt cria uma negociação executada no instante informado
+func NewTradeAt(buyOrder, sellOrder *Order, quantity int, price float64, executedAt time.Time) *Trade {
 	value := float64(quantity) * price
 
 	return &Trade{
-		ID:          generateTradeID(),
+		ID:          generateTradeID(executedAt),
 		BuyerID:     buyOrder.UserID,
 		SellerID:    sellOrder.UserID,
 		Symbol:      buyOrder.Symbol,
 		Quantity:    quantity,
 		Price:       price,
 		Value:       value,
-		ExecutedAt:  time.Now().UTC(),
+		ExecutedAt:  executedAt,
 		BuyOrderID:  buyOrder.ID,
 		SellOrderID: sellOrder.ID,
 	}
 }
 
 // generateTradeID gera um ID único para a negociação
-func generateTradeID() string {
-	return "trade-" + time.Now().Format("20060102150405") + "-" + randomString(6)
+func generateTradeID(at time.Time) string {
+	return nextID("trade", &tradeSequence, at)
 }
@@ -0,0 +1,429 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/engine/portfolio"
+	"trading/internal/services/engine/repository"
+	"trading/internal/services/engine/snapshot"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/shared/stats"
+	"trading/internal/services/shared/tracing"
+)
+
+// DefaultTimeout é o tempo máximo de cada chamada ao engine
+const DefaultTimeout = 5 * time.Second
+
+// Intervalos de reconexão do stream de eventos
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 5 * time.Second
+)
+
+// Client acessa um engine remoto. Implementa as mesmas operações de
+// matching.Service, orderbook.Manager e portfolio.Service usadas pela camada web.
+type Client struct {
+	baseURL    string
+	token      string
+	adminToken string
+	http       *http.Client
+	stream     *http.Client
+}
+
+// NewClient cria um cliente para o engine em addr (host:porta ou URL)
+func NewClient(addr string, timeout time.Duration) *Client {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(addr, "/") + rootPath,
+		http:    &http.Client{Timeout: timeout},
+		stream:  &http.Client{},
+	}
+}
+
+// SetToken define o token de serviço enviado ao engine em X-Engine-Token
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// SetAdminToken define o ADMIN_TOKEN enviado nas chamadas de snapshot
+func (c *Client) SetAdminToken(token string) {
+	c.adminToken = token
+}
+
+// ProcessOrder envia a ordem ao engine. Se o engine não responder a ordem
+// volta rejeitada com ErrUnavailable.
+func (c *Client) ProcessOrder(ctx context.Context, order *domain.Order) *matching.MatchResult {
+	var response MatchResponse
+	if err := c.doContext(ctx, http.MethodPost, "/orders", orderRequest(order), &response); err != nil {
+		return matching.Reject(order, err)
+	}
+	return matchResult(response)
+}
+
+// Recall consulta o resultado de um client_order_id já enviado ao engine
+func (c *Client) Recall(ctx context.Context, order *domain.Order) (*matching.MatchResult, bool) {
+	if order.ClientOrderID == "" {
+		return nil, false
+	}
+	var response MatchResponse
+	if err := c.doContext(ctx, http.MethodPost, "/orders/recall", orderRequest(order), &response); err != nil {
+		return nil, false
+	}
+	return matchResult(response), true
+}
+
+// CancelOrder cancela uma ordem aberta
+func (c *Client) CancelOrder(ctx context.Context, orderID string) (*domain.Order, error) {
+	var order domain.Order
+	if err := c.doContext(ctx, http.MethodDelete, "/orders/"+url.PathEscape(orderID), nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// AmendOrder altera quantidade e preço de uma ordem aberta
+func (c *Client) AmendOrder(ctx context.Context, orderID string, quantity int, price float64) (*matching.MatchResult, error) {
+	var response MatchResponse
+	body := AmendRequest{Quantity: quantity, Price: price}
+	if err := c.doContext(ctx, http.MethodPatch, "/orders/"+url.PathEscape(orderID), body, &response); err != nil {
+		return nil, err
+	}
+	return matchResult(response), nil
+}
+
+// GetOrder retorna o último estado conhecido de uma ordem
+func (c *Client) GetOrder(orderID string) (*domain.Order, error) {
+	var order domain.Order
+	if err := c.do(http.MethodGet, "/orders/"+url.PathEscape(orderID), nil, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ListOrders consulta o histórico de ordens
+func (c *Client) ListOrders(filter repository.OrderFilter) ([]*domain.Order, error) {
+	query := url.Values{}
+	setQuery(query, "user_id", filter.UserID)
+	setQuery(query, "symbol", filter.Symbol)
+	setQuery(query, "status", string(filter.Status))
+	setQuery(query, "client_order_id", filter.ClientOrderID)
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	var orders []*domain.Order
+	if err := c.do(http.MethodGet, "/orders?"+query.Encode(), nil, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ListTrades consulta o histórico de negociações
+func (c *Client) ListTrades(filter repository.TradeFilter) ([]*domain.Trade, error) {
+	query := url.Values{}
+	setQuery(query, "symbol", filter.Symbol)
+	setQuery(query, "user_id", filter.UserID)
+	setQuery(query, "after", filter.After)
+	if !filter.From.IsZero() {
+		query.Set("from", filter.From.UTC().Format(time.RFC3339Nano))
+	}
+	if !filter.To.IsZero() {
+		query.Set("to", filter.To.UTC().Format(time.RFC3339Nano))
+	}
+	if filter.MinValue > 0 {
+		query.Set("min_value", strconv.FormatFloat(filter.MinValue, 'f', -1, 64))
+	}
+	if filter.Descending {
+		query.Set("sort", "desc")
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	var trades []*domain.Trade
+	if err := c.do(http.MethodGet, "/trades?"+query.Encode(), nil, &trades); err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// GetTrades retorna as negociações executadas (vazio se o engine não responder)
+func (c *Client) GetTrades() []*domain.Trade {
+	trades := []*domain.Trade{}
+	if err := c.do(http.MethodGet, "/trades", nil, &trades); err != nil {
+		slog.Error("erro ao consultar negociações no engine", "error", err)
+	}
+	return trades
+}
+
+// Stats retorna os contadores do engine (zerados se o engine não responder).
+// Rejeições sem código estável voltam como stats.ErrOther.
+func (c *Client) Stats() matching.Stats {
+	var response StatsResponse
+	if err := c.do(http.MethodGet, "/stats", nil, &response); err != nil {
+		slog.Error("erro ao consultar estatísticas do engine", "error", err)
+	}
+
+	result := response.Stats
+	result.Rejected = make(map[error]uint64, len(response.Rejected))
+	for code, count := range response.Rejected {
+		reason, ok := domain.ErrorForCode(code)
+		if !ok {
+			reason = stats.ErrOther
+		}
+		result.Rejected[reason] += count
+	}
+	return result
+}
+
+// GetOrderBook retorna o livro completo (vazio se o engine não responder)
+func (c *Client) GetOrderBook(symbol string) *orderbook.OrderBook {
+	book := &orderbook.OrderBook{Symbol: symbol, Bids: []*domain.Order{}, Asks: []*domain.Order{}}
+	if err := c.do(http.MethodGet, "/books/"+url.PathEscape(symbol), nil, book); err != nil {
+		slog.Error("erro ao consultar livro no engine", "symbol", symbol, "error", err)
+	}
+	return book
+}
+
+// GetDepth retorna o livro agregado (vazio se o engine não responder)
+func (c *Client) GetDepth(symbol string, depth int) *orderbook.Depth {
+	result := &orderbook.Depth{
+		Symbol:    symbol,
+		Bids:      []orderbook.PriceLevel{},
+		Asks:      []orderbook.PriceLevel{},
+		Timestamp: time.Now().UTC(),
+	}
+	path := fmt.Sprintf("/books/%s/depth?depth=%d", url.PathEscape(symbol), depth)
+	if err := c.do(http.MethodGet, path, nil, result); err != nil {
+		slog.Error("erro ao consultar profundidade no engine", "symbol", symbol, "error", err)
+	}
+	return result
+}
+
+// FindOrder retorna uma ordem em repouso no livro, ou nil
+func (c *Client) FindOrder(orderID string) *domain.Order {
+	var order domain.Order
+	if err := c.do(http.MethodGet, "/resting/"+url.PathEscape(orderID), nil, &order); err != nil {
+		if err != domain.ErrOrderNotFound {
+			slog.Error("erro ao consultar ordem no engine", "order_id", orderID, "error", err)
+		}
+		return nil
+	}
+	return &order
+}
+
+// GetUser retorna os dados de um usuário
+func (c *Client) GetUser(userID string) (portfolio.User, error) {
+	var user portfolio.User
+	err := c.do(http.MethodGet, "/users/"+url.PathEscape(userID), nil, &user)
+	return user, err
+}
+
+// GetPortfolio retorna o portfolio de um usuário
+func (c *Client) GetPortfolio(userID string) (*domain.Portfolio, error) {
+	var p domain.Portfolio
+	if err := c.do(http.MethodGet, "/portfolios/"+url.PathEscape(userID), nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ValidateOrder aplica no engine as validações de saldo, posição e perfil
+func (c *Client) ValidateOrder(ctx context.Context, order *domain.Order) error {
+	return c.doContext(ctx, http.MethodPost, "/validate", orderRequest(order), nil)
+}
+
+// Take grava um snapshot no engine
+func (c *Client) Take() (snapshot.Info, error) {
+	var info snapshot.Info
+	err := c.doAdmin(http.MethodPost, "/snapshots", &info)
+	return info, err
+}
+
+// List lista os snapshots do engine
+func (c *Client) List() ([]snapshot.Info, error) {
+	var infos []snapshot.Info
+	err := c.doAdmin(http.MethodGet, "/snapshots", &infos)
+	return infos, err
+}
+
+// Restore recarrega o engine a partir de um snapshot
+func (c *Client) Restore(seq uint64) (snapshot.Info, int, error) {
+	var response RestoreResponse
+	err := c.doAdmin(http.MethodPost, fmt.Sprintf("/snapshots/%d/restore", seq), &response)
+	return response.Snapshot, response.Replayed, err
+}
+
+// Subscribe recebe o stream de eventos do engine e os publica em publisher,
+// reconectando até o contexto ser cancelado. Eventos emitidos enquanto a
+// conexão estava caída são perdidos.
+func (c *Client) Subscribe(ctx context.Context, publisher events.Publisher) {
+	delay := minReconnectDelay
+	for {
+		received, err := c.consume(ctx, publisher)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			delay = minReconnectDelay
+		}
+		slog.Warn("stream de eventos do engine encerrado, reconectando", "error", err, "delay", delay.String())
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// consume lê uma conexão do stream de eventos até ela terminar
+func (c *Client) consume(ctx context.Context, publisher events.Publisher) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/events", nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set(HeaderServiceToken, c.token)
+
+	resp, err := c.stream.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	received := false
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		event, err := decodeEvent(scanner.Bytes())
+		if err != nil {
+			slog.Error("evento inválido do engine", "error", err)
+			continue
+		}
+		received = true
+		publisher.Publish(event)
+	}
+	if err := scanner.Err(); err != nil {
+		return received, err
+	}
+	return received, fmt.Errorf("conexão encerrada pelo engine")
+}
+
+// do executa uma chamada à API e decodifica a resposta em out (se não nil)
+func (c *Client) do(method, path string, body, out interface{}) error {
+	return c.doContext(context.Background(), method, path, body, out)
+}
+
+// doAdmin executa uma chamada administrativa, com o ADMIN_TOKEN
+func (c *Client) doAdmin(method, path string, out interface{}) error {
+	return c.send(context.Background(), method, path, nil, out, map[string]string{HeaderAdminToken: c.adminToken})
+}
+
+// doContext executa a chamada repassando ao engine o request ID e o trace do contexto
+func (c *Client) doContext(ctx context.Context, method, path string, body, out interface{}) error {
+	return c.send(ctx, method, path, body, out, nil)
+}
+
+// send executa a chamada com os headers adicionais e decodifica a resposta
+func (c *Client) send(ctx context.Context, method, path string, body, out interface{}, headers map[string]string) error {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, &payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(HeaderServiceToken, c.token)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.HeaderRequestID, id)
+	}
+	if parent := tracing.TraceParent(ctx); parent != "" {
+		req.Header.Set(tracing.HeaderTraceParent, parent)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var e errorBody
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+			return fmt.Errorf("%w: status %d", ErrUnavailable, resp.StatusCode)
+		}
+		return decodeError(e.Code, e.Message)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: resposta inválida: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// orderRequest extrai os campos da ordem enviados ao engine
+func orderRequest(order *domain.Order) OrderRequest {
+	return OrderRequest{
+		UserID:        order.UserID,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		Quantity:      order.Quantity,
+		Price:         order.Price,
+		ClientOrderID: order.ClientOrderID,
+	}
+}
+
+// matchResult restaura o erro de domínio de uma rejeição
+func matchResult(response MatchResponse) *matching.MatchResult {
+	result := response.MatchResult
+	if result == nil {
+		result = &matching.MatchResult{}
+	}
+	if response.ErrorCode != "" {
+		result.Err = decodeError(response.ErrorCode, result.Reason)
+	}
+	return result
+}
+
+// setQuery adiciona o parâmetro apenas se não for vazio
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/api/enginepb"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+)
+
+// Conversões entre os tipos de domínio e as mensagens gRPC
+
+var sidesToProto = map[domain.OrderSide]enginepb.Side{
+	domain.BUY:  enginepb.Side_SIDE_BUY,
+	domain.SELL: enginepb.Side_SIDE_SELL,
+}
+
+var statusesToProto = map[domain.OrderStatus]enginepb.OrderStatus{
+	domain.PENDING:   enginepb.OrderStatus_ORDER_STATUS_PENDING,
+	domain.PARTIAL:   enginepb.OrderStatus_ORDER_STATUS_PARTIAL,
+	domain.FILLED:    enginepb.OrderStatus_ORDER_STATUS_FILLED,
+	domain.CANCELLED: enginepb.OrderStatus_ORDER_STATUS_CANCELLED,
+	domain.REJECTED:  enginepb.OrderStatus_ORDER_STATUS_REJECTED,
+}
+
+// sideFromProto retorna "" para lados desconhecidos (rejeitado por Validate)
+func sideFromProto(side enginepb.Side) domain.OrderSide {
+	for domainSide, protoSide := range sidesToProto {
+		if protoSide == side {
+			return domainSide
+		}
+	}
+	return ""
+}
+
+func statusFromProto(status enginepb.OrderStatus) domain.OrderStatus {
+	for domainStatus, protoStatus := range statusesToProto {
+		if protoStatus == status {
+			return domainStatus
+		}
+	}
+	return ""
+}
+
+func timestampFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime().UTC()
+}
+
+func orderToProto(order *domain.Order) *enginepb.Order {
+	if order == nil {
+		return nil
+	}
+	return &enginepb.Order{
+		Id:                order.ID,
+		UserId:            order.UserID,
+		Symbol:            order.Symbol,
+		Side:              sidesToProto[order.Side],
+		Quantity:          int64(order.Quantity),
+		Price:             order.Price,
+		Status:            statusesToProto[order.Status],
+		RemainingQuantity: int64(order.RemainingQuantity),
+		CreatedAt:         timestamppb.New(order.CreatedAt),
+		UpdatedAt:         timestamppb.New(order.UpdatedAt),
+		ClientOrderId:     order.ClientOrderID,
+	}
+}
+
+func orderFromProto(order *enginepb.Order) *domain.Order {
+	if order == nil {
+		return nil
+	}
+	return &domain.Order{
+		ID:                order.Id,
+		UserID:            order.UserId,
+		Symbol:            order.Symbol,
+		Side:              sideFromProto(order.Side),
+		Quantity:          int(order.Quantity),
+		Price:             order.Price,
+		Status:            statusFromProto(order.Status),
+		RemainingQuantity: int(order.RemainingQuantity),
+		CreatedAt:         timestampFromProto(order.CreatedAt),
+		UpdatedAt:         timestampFromProto(order.UpdatedAt),
+		ClientOrderID:     order.ClientOrderId,
+	}
+}
+
+func tradeToProto(trade *domain.Trade) *enginepb.Trade {
+	return &enginepb.Trade{
+		Id:          trade.ID,
+		Symbol:      trade.Symbol,
+		BuyerId:     trade.BuyerID,
+		SellerId:    trade.SellerID,
+		BuyOrderId:  trade.BuyOrderID,
+		SellOrderId: trade.SellOrderID,
+		Quantity:    int64(trade.Quantity),
+		Price:       trade.Price,
+		Value:       trade.Value,
+		ExecutedAt:  timestamppb.New(trade.ExecutedAt),
+	}
+}
+
+func tradeFromProto(trade *enginepb.Trade) *domain.Trade {
+	return &domain.Trade{
+		ID:          trade.Id,
+		Symbol:      trade.Symbol,
+		BuyerID:     trade.BuyerId,
+		SellerID:    trade.SellerId,
+		BuyOrderID:  trade.BuyOrderId,
+		SellOrderID: trade.SellOrderId,
+		Quantity:    int(trade.Quantity),
+		Price:       trade.Price,
+		Value:       trade.Value,
+		ExecutedAt:  timestampFromProto(trade.ExecutedAt),
+	}
+}
+
+func publicTradeToProto(trade *domain.Trade) *enginepb.PublicTrade {
+	return &enginepb.PublicTrade{
+		Id:         trade.ID,
+		Quantity:   int64(trade.Quantity),
+		Price:      trade.Price,
+		ExecutedAt: timestamppb.New(trade.ExecutedAt),
+	}
+}
+
+func submitResponseToProto(result *matching.MatchResult) *enginepb.SubmitOrderResponse {
+	response := &enginepb.SubmitOrderResponse{
+		Order:    orderToProto(result.Order),
+		Status:   result.Status,
+		Message:  result.Message,
+		Rejected: result.Rejected,
+		Replayed: result.Replayed,
+	}
+	for _, trade := range result.Trades {
+		response.Trades = append(response.Trades, tradeToProto(trade))
+	}
+	if result.Err != nil {
+		response.ReasonCode, _ = encodeError(result.Err)
+	}
+	return response
+}
+
+func submitResponseFromProto(response *enginepb.SubmitOrderResponse) *matching.MatchResult {
+	result := &matching.MatchResult{
+		Order:    orderFromProto(response.Order),
+		Trades:   make([]*domain.Trade, 0, len(response.Trades)),
+		Status:   response.Status,
+		Message:  response.Message,
+		Rejected: response.Rejected,
+		Replayed: response.Replayed,
+	}
+	for _, trade := range response.Trades {
+		result.Trades = append(result.Trades, tradeFromProto(trade))
+	}
+	if response.ReasonCode != "" {
+		result.Err = decodeError(response.ReasonCode, response.Message)
+		result.Reason = result.Err.Error()
+	}
+	return result
+}
+
+func levelsToProto(levels []orderbook.PriceLevel) []*enginepb.PriceLevel {
+	result := make([]*enginepb.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		result = append(result, &enginepb.PriceLevel{
+			Price:    level.Price,
+			Quantity: int64(level.Quantity),
+			Orders:   int32(level.Orders),
+		})
+	}
+	return result
+}
+
+func levelsFromProto(levels []*enginepb.PriceLevel) []orderbook.PriceLevel {
+	result := make([]orderbook.PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		result = append(result, orderbook.PriceLevel{
+			Price:    level.Price,
+			Quantity: int(level.Quantity),
+			Orders:   int(level.Orders),
+		})
+	}
+	return result
+}
+
+func depthToProto(depth *orderbook.Depth) *enginepb.OrderBook {
+	return &enginepb.OrderBook{
+		Symbol:    depth.Symbol,
+		Bids:      levelsToProto(depth.Bids),
+		Asks:      levelsToProto(depth.Asks),
+		BestBid:   depth.BestBid,
+		BestAsk:   depth.BestAsk,
+		Spread:    depth.Spread,
+		MidPrice:  depth.MidPrice,
+		Timestamp: timestamppb.New(depth.Timestamp),
+	}
+}
+
+func depthFromProto(book *enginepb.OrderBook) *orderbook.Depth {
+	return &orderbook.Depth{
+		Symbol:    book.Symbol,
+		Bids:      levelsFromProto(book.Bids),
+		Asks:      levelsFromProto(book.Asks),
+		BestBid:   book.BestBid,
+		BestAsk:   book.BestAsk,
+		Spread:    book.Spread,
+		MidPrice:  book.MidPrice,
+		Timestamp: timestampFromProto(book.Timestamp),
+	}
+}
+
+func portfolioToProto(portfolio *domain.Portfolio) *enginepb.Portfolio {
+	positions := make(map[string]int64, len(portfolio.Positions))
+	for symbol, quantity := range portfolio.Positions {
+		positions[symbol] = int64(quantity)
+	}
+	return &enginepb.Portfolio{
+		UserId:    portfolio.UserID,
+		Cash:      portfolio.Cash,
+		Positions: positions,
+		UpdatedAt: timestamppb.New(portfolio.UpdatedAt),
+	}
+}
+
+func portfolioFromProto(portfolio *enginepb.Portfolio) *domain.Portfolio {
+	positions := make(map[string]int, len(portfolio.Positions))
+	for symbol, quantity := range portfolio.Positions {
+		positions[symbol] = int(quantity)
+	}
+	return &domain.Portfolio{
+		UserID:    portfolio.UserId,
+		Cash:      portfolio.Cash,
+		Positions: positions,
+		UpdatedAt: timestampFromProto(portfolio.UpdatedAt),
+	}
+}
@@ -0,0 +1,5 @@
+// Package enginepb contém as mensagens e o serviço gRPC do engine, gerados a
+// partir de engine.proto
+package enginepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative engine.proto
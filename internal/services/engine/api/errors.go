@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"trading/internal/domain"
+)
+
+// ErrUnavailable indica que o engine não respondeu (rede, timeout ou falha interna)
+var ErrUnavailable = errors.New("engine indisponível")
+
+// errorBody é o corpo das respostas de erro da API do engine
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// errorDomain identifica os erros do engine em errdetails.ErrorInfo
+const errorDomain = "engine.trading"
+
+// wireError associa um código estável de domínio aos status HTTP e gRPC
+type wireError struct {
+	code   string
+	status int
+	grpc   codes.Code
+}
+
+// wireErrors são os erros que atravessam a rede preservando a identidade: o
+// código vem de domain.ErrorCode e o cliente devolve a mesma variável de erro
+var wireErrors = []wireError{
+	{"INVALID_ORDER", http.StatusBadRequest, codes.InvalidArgument},
+	{"INVALID_ORDER_SIDE", http.StatusBadRequest, codes.InvalidArgument},
+	{"INVALID_QUANTITY", http.StatusBadRequest, codes.InvalidArgument},
+	{"INVALID_PRICE", http.StatusBadRequest, codes.InvalidArgument},
+	{"INVALID_SYMBOL", http.StatusBadRequest, codes.InvalidArgument},
+	{"INVALID_USER", http.StatusBadRequest, codes.InvalidArgument},
+	{"USER_NOT_FOUND", http.StatusNotFound, codes.NotFound},
+	{"ORDER_NOT_FOUND", http.StatusNotFound, codes.NotFound},
+	{"SNAPSHOT_NOT_FOUND", http.StatusNotFound, codes.NotFound},
+	{"ORDER_NOT_OPEN", http.StatusConflict, codes.FailedPrecondition},
+	{"INVALID_CLIENT_ORDER_ID", http.StatusBadRequest, codes.InvalidArgument},
+	{"CLIENT_ORDER_ID_CONFLICT", http.StatusConflict, codes.AlreadyExists},
+	{"PRICE_TOO_LOW", http.StatusUnprocessableEntity, codes.FailedPrecondition},
+	{"INSUFFICIENT_BALANCE", http.StatusUnprocessableEntity, codes.FailedPrecondition},
+	{"INSUFFICIENT_POSITION", http.StatusUnprocessableEntity, codes.FailedPrecondition},
+	{"EXCEEDS_PROFILE_LIMIT", http.StatusUnprocessableEntity, codes.FailedPrecondition},
+	{"NO_MATCH", http.StatusUnprocessableEntity, codes.FailedPrecondition},
+	{"MARKET_CLOSED", http.StatusServiceUnavailable, codes.FailedPrecondition},
+}
+
+// codeInternal é usado para erros sem código estável, o mesmo da API REST
+const codeInternal = domain.CodeInternal
+
+// CodeUnavailable é o código com que os transportes expõem ErrUnavailable
+const CodeUnavailable = "ENGINE_UNAVAILABLE"
+
+// codeUnauthorized responde chamadas sem o token de serviço; no cliente vira
+// ErrUnavailable, pois é falha de configuração entre os serviços
+const codeUnauthorized = "UNAUTHORIZED"
+
+// codeForbidden responde chamadas de snapshot sem o ADMIN_TOKEN
+const codeForbidden = "FORBIDDEN"
+
+// lookupWire retorna o mapeamento de transporte de um erro de domínio
+func lookupWire(err error) wireError {
+	code := domain.ErrorCode(err)
+	for _, w := range wireErrors {
+		if w.code == code {
+			return w
+		}
+	}
+	return wireError{code: codeInternal, status: http.StatusInternalServerError, grpc: codes.Internal}
+}
+
+// encodeError retorna o código e o status de um erro
+func encodeError(err error) (string, int) {
+	w := lookupWire(err)
+	return w.code, w.status
+}
+
+// ErrorCode retorna o código estável de um erro (INTERNAL_ERROR se não houver)
+func ErrorCode(err error) string {
+	code, _ := encodeError(err)
+	return code
+}
+
+// decodeError reconstrói o erro a partir do código recebido
+func decodeError(code, message string) error {
+	if err, ok := domain.ErrorForCode(code); ok {
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrUnavailable, message)
+}
+
+// statusError converte um erro de domínio em status gRPC, com o código
+// estável em errdetails.ErrorInfo
+func statusError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return status.FromContextError(err).Err()
+	}
+
+	w := lookupWire(err)
+	grpcCode, code := w.grpc, w.code
+
+	st, detailErr := status.New(grpcCode, err.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason: code,
+		Domain: errorDomain,
+	})
+	if detailErr != nil {
+		return status.Error(grpcCode, err.Error())
+	}
+	return st.Err()
+}
+
+// errorFromStatus reconstrói o erro de domínio de um status gRPC. Falhas de
+// transporte e prazos estourados viram ErrUnavailable.
+func errorFromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			return fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
+		return err
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == errorDomain {
+			return decodeError(info.Reason, st.Message())
+		}
+	}
+	return fmt.Errorf("%w: %s: %s", ErrUnavailable, st.Code(), st.Message())
+}
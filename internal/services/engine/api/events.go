@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"trading/internal/services/shared/events"
+)
+
+// envelope é uma linha do stream de eventos (NDJSON)
+type envelope struct {
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data"`
+	Reason string          `json:"reason,omitempty"` // código do erro de OrderRejected
+}
+
+// encodeEvent serializa um evento do barramento em uma linha do stream
+func encodeEvent(event events.Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	env := envelope{Type: event.Type(), Data: data}
+	if rejected, ok := event.(events.OrderRejected); ok && rejected.Reason != nil {
+		env.Reason, _ = encodeError(rejected.Reason)
+	}
+
+	line, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// decodeEvent reconstrói o evento tipado a partir de uma linha do stream
+func decodeEvent(line []byte) (events.Event, error) {
+	var env envelope
+	if err := json.Unmarshal(line, &env); err != nil {
+		return nil, err
+	}
+
+	var event events.Event
+	var err error
+	switch env.Type {
+	case events.TypeOrderAccepted:
+		event, err = unmarshalEvent[events.OrderAccepted](env.Data)
+	case events.TypeOrderRejected:
+		var rejected events.OrderRejected
+		rejected, err = unmarshalEvent[events.OrderRejected](env.Data)
+		if env.Reason != "" {
+			rejected.Reason = decodeError(env.Reason, env.Reason)
+		}
+		event = rejected
+	case events.TypeTradeExecuted:
+		event, err = unmarshalEvent[events.TradeExecuted](env.Data)
+	case events.TypeOrderCancelled:
+		event, err = unmarshalEvent[events.OrderCancelled](env.Data)
+	case events.TypePortfolioUpdated:
+		event, err = unmarshalEvent[events.PortfolioUpdated](env.Data)
+	case events.TypeBookChanged:
+		event, err = unmarshalEvent[events.BookChanged](env.Data)
+	default:
+		return nil, fmt.Errorf("tipo de evento desconhecido: %s", env.Type)
+	}
+	return event, err
+}
+
+// unmarshalEvent decodifica o corpo de um evento no tipo concreto
+func unmarshalEvent[T events.Event](data json.RawMessage) (T, error) {
+	var event T
+	err := json.Unmarshal(data, &event)
+	return event, err
+}
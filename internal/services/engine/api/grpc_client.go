@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/api/enginepb"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/shared/tracing"
+)
+
+// GRPCClient usa o canal gRPC para envio e cancelamento de ordens, livro e
+// portfolio; as demais operações seguem pela API HTTP do Client embutido.
+// Cada chamada tem o prazo de timeout.
+type GRPCClient struct {
+	*Client
+
+	rpc     enginepb.EngineClient
+	timeout time.Duration
+}
+
+// NewGRPCClient cria o cliente sobre uma conexão gRPC já aberta
+func NewGRPCClient(conn grpc.ClientConnInterface, fallback *Client, timeout time.Duration) *GRPCClient {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &GRPCClient{
+		Client:  fallback,
+		rpc:     enginepb.NewEngineClient(conn),
+		timeout: timeout,
+	}
+}
+
+// ProcessOrder envia a ordem ao engine. Se o engine não responder no prazo a
+// ordem volta rejeitada com ErrUnavailable.
+func (c *GRPCClient) ProcessOrder(ctx context.Context, order *domain.Order) *matching.MatchResult {
+	ctx, cancel := context.WithTimeout(outgoingContext(ctx), c.timeout)
+	defer cancel()
+
+	response, err := c.rpc.SubmitOrder(ctx, &enginepb.SubmitOrderRequest{
+		UserId:        order.UserID,
+		Symbol:        order.Symbol,
+		Side:          sidesToProto[order.Side],
+		Quantity:      int64(order.Quantity),
+		Price:         order.Price,
+		ClientOrderId: order.ClientOrderID,
+	})
+	if err != nil {
+		return matching.Reject(order, errorFromStatus(err))
+	}
+	return submitResponseFromProto(response)
+}
+
+// CancelOrder cancela uma ordem aberta
+func (c *GRPCClient) CancelOrder(ctx context.Context, orderID string) (*domain.Order, error) {
+	ctx, cancel := context.WithTimeout(outgoingContext(ctx), c.timeout)
+	defer cancel()
+
+	response, err := c.rpc.CancelOrder(ctx, &enginepb.CancelOrderRequest{OrderId: orderID})
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	return orderFromProto(response.Order), nil
+}
+
+// GetDepth retorna o livro agregado (vazio se o engine não responder)
+func (c *GRPCClient) GetDepth(symbol string, depth int) *orderbook.Depth {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	book, err := c.rpc.GetOrderBook(ctx, &enginepb.GetOrderBookRequest{Symbol: symbol, Depth: int32(depth)})
+	if err != nil {
+		slog.Error("erro ao consultar profundidade no engine", "symbol", symbol, "error", errorFromStatus(err))
+		return &orderbook.Depth{
+			Symbol:    symbol,
+			Bids:      []orderbook.PriceLevel{},
+			Asks:      []orderbook.PriceLevel{},
+			Timestamp: time.Now().UTC(),
+		}
+	}
+	return depthFromProto(book)
+}
+
+// GetPortfolio retorna o portfolio de um usuário
+func (c *GRPCClient) GetPortfolio(userID string) (*domain.Portfolio, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	p, err := c.rpc.GetPortfolio(ctx, &enginepb.GetPortfolioRequest{UserId: userID})
+	if err != nil {
+		return nil, errorFromStatus(err)
+	}
+	return portfolioFromProto(p), nil
+}
+
+// SubscribeMarketData recebe o market data dos símbolos até o contexto ser
+// cancelado ou o stream terminar. Sem prazo: a chamada dura o que a inscrição durar.
+func (c *GRPCClient) SubscribeMarketData(ctx context.Context, symbols []string, depth int, fn func(*enginepb.MarketDataUpdate)) error {
+	stream, err := c.rpc.SubscribeMarketData(ctx, &enginepb.SubscribeMarketDataRequest{Symbols: symbols, Depth: int32(depth)})
+	if err != nil {
+		return errorFromStatus(err)
+	}
+
+	for {
+		update, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errorFromStatus(err)
+		}
+		fn(update)
+	}
+}
+
+// serviceToken envia o token de serviço nos metadados de cada chamada
+type serviceToken string
+
+// GetRequestMetadata anexa o token a cada chamada
+func (t serviceToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{metadataServiceToken: string(t)}, nil
+}
+
+// RequireTransportSecurity permite o token sem TLS: o canal é interno
+func (t serviceToken) RequireTransportSecurity() bool {
+	return false
+}
+
+// TokenCredentials envia o token de serviço exigido por TokenInterceptors em
+// todas as chamadas da conexão
+func TokenCredentials(token string) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(serviceToken(token))
+}
+
+// outgoingContext envia o request ID e o traceparent do contexto nos
+// metadados gRPC
+func outgoingContext(ctx context.Context) context.Context {
+	if id := logging.RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, metadataRequestID, id)
+	}
+	if parent := tracing.TraceParent(ctx); parent != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, tracing.HeaderTraceParent, parent)
+	}
+	return ctx
+}
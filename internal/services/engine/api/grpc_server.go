@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/api/enginepb"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/engine/portfolio"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/shared/tracing"
+)
+
+// metadataRequestID é a chave do request ID nos metadados gRPC
+const metadataRequestID = "x-request-id"
+
+// metadataServiceToken é a chave do token de serviço nos metadados gRPC
+const metadataServiceToken = "x-engine-token"
+
+// defaultMarketDataDepth é a profundidade padrão das atualizações de livro
+const defaultMarketDataDepth = 10
+
+// GRPCServer implementa o serviço gRPC Engine sobre os serviços do engine
+type GRPCServer struct {
+	enginepb.UnimplementedEngineServer
+
+	matcher    *matching.Service
+	books      *orderbook.Manager
+	portfolios *portfolio.Service
+
+	subscribers map[*marketDataSubscriber]struct{}
+	mutex       sync.Mutex
+}
+
+// marketDataSubscriber é uma chamada SubscribeMarketData em andamento
+type marketDataSubscriber struct {
+	symbols map[string]bool
+	events  chan events.Event
+}
+
+// NewGRPCServer cria o serviço gRPC do engine
+func NewGRPCServer(matcher *matching.Service, books *orderbook.Manager, portfolios *portfolio.Service) *GRPCServer {
+	return &GRPCServer{
+		matcher:     matcher,
+		books:       books,
+		portfolios:  portfolios,
+		subscribers: make(map[*marketDataSubscriber]struct{}),
+	}
+}
+
+// Register registra o serviço em um servidor gRPC
+func (s *GRPCServer) Register(server *grpc.Server) {
+	enginepb.RegisterEngineServer(server, s)
+}
+
+// TokenInterceptors exigem o token de serviço em toda chamada, unária ou de
+// stream, recusando as demais com Unauthenticated; sem token, toda chamada é
+// recusada. Uso: grpc.NewServer(api.TokenInterceptors(token)...).
+func TokenInterceptors(token string) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := checkServiceToken(ctx, token, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := checkServiceToken(stream.Context(), token, info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+// checkServiceToken confere o token de serviço recebido nos metadados
+func checkServiceToken(ctx context.Context, token, method string) error {
+	values := metadata.ValueFromIncomingContext(ctx, metadataServiceToken)
+	if token == "" || len(values) != 1 || subtle.ConstantTimeCompare([]byte(values[0]), []byte(token)) != 1 {
+		slog.WarnContext(incomingContext(ctx), "token de serviço inválido", "method", method)
+		return status.Error(codes.Unauthenticated, "token de serviço inválido")
+	}
+	return nil
+}
+
+// SubmitOrder cria a ordem no engine e executa o matching
+func (s *GRPCServer) SubmitOrder(ctx context.Context, req *enginepb.SubmitOrderRequest) (*enginepb.SubmitOrderResponse, error) {
+	// O cliente já desistiu: não executa uma ordem que ninguém vai receber
+	if err := ctx.Err(); err != nil {
+		return nil, statusError(err)
+	}
+
+	order := domain.NewOrder(req.UserId, strings.ToUpper(req.Symbol), sideFromProto(req.Side), int(req.Quantity), req.Price)
+	order.ClientOrderID = req.ClientOrderId
+	if err := order.Validate(); err != nil {
+		return nil, statusError(err)
+	}
+
+	return submitResponseToProto(s.matcher.ProcessOrder(incomingContext(ctx), order)), nil
+}
+
+// CancelOrder cancela uma ordem aberta
+func (s *GRPCServer) CancelOrder(ctx context.Context, req *enginepb.CancelOrderRequest) (*enginepb.CancelOrderResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, statusError(err)
+	}
+
+	order, err := s.matcher.CancelOrder(incomingContext(ctx), req.OrderId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &enginepb.CancelOrderResponse{Order: orderToProto(order)}, nil
+}
+
+// GetOrderBook retorna o livro agregado por nível de preço
+func (s *GRPCServer) GetOrderBook(ctx context.Context, req *enginepb.GetOrderBookRequest) (*enginepb.OrderBook, error) {
+	if req.Depth < 0 {
+		return nil, status.Error(codes.InvalidArgument, "depth não pode ser negativo")
+	}
+	return depthToProto(s.books.GetDepth(strings.ToUpper(req.Symbol), int(req.Depth))), nil
+}
+
+// GetPortfolio retorna caixa e posições de um usuário
+func (s *GRPCServer) GetPortfolio(ctx context.Context, req *enginepb.GetPortfolioRequest) (*enginepb.Portfolio, error) {
+	p, err := s.portfolios.GetPortfolio(req.UserId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return portfolioToProto(p.Clone()), nil
+}
+
+// SubscribeMarketData envia o livro atual de cada símbolo e depois as
+// alterações de livro e negociações. Um inscrito que não acompanha o ritmo é
+// encerrado com ResourceExhausted e deve se inscrever novamente.
+func (s *GRPCServer) SubscribeMarketData(req *enginepb.SubscribeMarketDataRequest, stream enginepb.Engine_SubscribeMarketDataServer) error {
+	if len(req.Symbols) == 0 {
+		return status.Error(codes.InvalidArgument, "informe ao menos um símbolo")
+	}
+	if req.Depth < 0 {
+		return status.Error(codes.InvalidArgument, "depth não pode ser negativo")
+	}
+	depth := int(req.Depth)
+	if depth == 0 {
+		depth = defaultMarketDataDepth
+	}
+
+	sub := &marketDataSubscriber{
+		symbols: make(map[string]bool, len(req.Symbols)),
+		events:  make(chan events.Event, streamBuffer),
+	}
+	for _, symbol := range req.Symbols {
+		sub.symbols[strings.ToUpper(symbol)] = true
+	}
+
+	// Inscreve antes do snapshot para não perder alterações intermediárias
+	s.mutex.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mutex.Unlock()
+	defer s.unsubscribe(sub)
+
+	seqs := make(map[string]uint64, len(sub.symbols))
+	send := func(update *enginepb.MarketDataUpdate) error {
+		seqs[update.Symbol]++
+		update.Seq = seqs[update.Symbol]
+		return stream.Send(update)
+	}
+
+	for _, symbol := range req.Symbols {
+		symbol = strings.ToUpper(symbol)
+		if seqs[symbol] > 0 {
+			continue
+		}
+		book := depthToProto(s.books.GetDepth(symbol, depth))
+		if err := send(&enginepb.MarketDataUpdate{Symbol: symbol, Payload: &enginepb.MarketDataUpdate_Book{Book: book}}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-sub.events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "inscrito não acompanhou o ritmo do market data")
+			}
+
+			var update *enginepb.MarketDataUpdate
+			switch e := event.(type) {
+			case events.BookChanged:
+				book := depthToProto(s.books.GetDepth(e.Symbol, depth))
+				update = &enginepb.MarketDataUpdate{Symbol: e.Symbol, Payload: &enginepb.MarketDataUpdate_Book{Book: book}}
+			case events.TradeExecuted:
+				update = &enginepb.MarketDataUpdate{Symbol: e.Trade.Symbol, Payload: &enginepb.MarketDataUpdate_Trade{Trade: publicTradeToProto(e.Trade)}}
+			}
+			if err := send(update); err != nil {
+				return err
+			}
+
+		case <-stream.Context().Done():
+			return statusError(stream.Context().Err())
+		}
+	}
+}
+
+// HandleEvent repassa alterações de livro e negociações aos inscritos do símbolo
+func (s *GRPCServer) HandleEvent(event events.Event) {
+	switch event.(type) {
+	case events.BookChanged, events.TradeExecuted:
+	default:
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for sub := range s.subscribers {
+		if !sub.symbols[event.Key()] {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			delete(s.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// unsubscribe remove o inscrito, se ainda não tiver sido removido
+func (s *GRPCServer) unsubscribe(sub *marketDataSubscriber) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.subscribers[sub]; exists {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// incomingContext leva o request ID e o traceparent recebidos nos metadados
+// ao contexto
+func incomingContext(ctx context.Context) context.Context {
+	if values := metadata.ValueFromIncomingContext(ctx, metadataRequestID); len(values) > 0 && values[0] != "" {
+		ctx = logging.WithRequestID(ctx, values[0])
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, tracing.HeaderTraceParent); len(values) > 0 {
+		ctx = tracing.WithTraceParent(ctx, values[0])
+	}
+	return ctx
+}
@@ -0,0 +1,540 @@
+package api
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/engine/portfolio"
+	"trading/internal/services/engine/repository"
+	"trading/internal/services/engine/snapshot"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/shared/metrics"
+	"trading/internal/services/shared/tracing"
+)
+
+// Prefixo das rotas da API do engine
+const rootPath = "/engine"
+
+// HeaderServiceToken leva o token de serviço que o web service apresenta ao
+// engine
+const HeaderServiceToken = "X-Engine-Token"
+
+// HeaderAdminToken leva o ADMIN_TOKEN exigido, além do token de serviço, nas
+// rotas de snapshot
+const HeaderAdminToken = "X-Admin-Token"
+
+// streamBuffer é quantos eventos cada inscrito do stream pode acumular antes
+// de ser desconectado
+const streamBuffer = 1024
+
+// OrderRequest representa uma nova ordem enviada ao engine. O ID é sempre
+// gerado pelo engine.
+type OrderRequest struct {
+	UserID        string           `json:"user_id"`
+	Symbol        string           `json:"symbol"`
+	Side          domain.OrderSide `json:"side"`
+	Quantity      int              `json:"quantity"`
+	Price         float64          `json:"price"`
+	ClientOrderID string           `json:"client_order_id,omitempty"`
+}
+
+// AmendRequest representa a alteração de uma ordem aberta
+type AmendRequest struct {
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+// MatchResponse é o MatchResult com o código do erro de rejeição
+type MatchResponse struct {
+	*matching.MatchResult
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// StatsResponse é o matching.Stats com as rejeições indexadas pelo código do erro
+type StatsResponse struct {
+	matching.Stats
+	Rejected map[string]uint64 `json:"rejected"`
+}
+
+// RestoreResponse é a resposta da restauração de um snapshot
+type RestoreResponse struct {
+	Snapshot snapshot.Info `json:"snapshot"`
+	Replayed int           `json:"replayed_commands"`
+}
+
+// Server expõe matching, livros e portfolios do engine via HTTP/JSON
+type Server struct {
+	matcher    *matching.Service
+	books      *orderbook.Manager
+	portfolios *portfolio.Service
+	snapshots  *snapshot.Manager
+	http       *metrics.HTTPMetrics
+	token      string
+	adminToken string
+
+	subscribers map[chan []byte]struct{}
+	mutex       sync.Mutex
+}
+
+// NewServer cria a API sobre os serviços do engine; snapshots pode ser nil
+func NewServer(matcher *matching.Service, books *orderbook.Manager, portfolios *portfolio.Service, snapshots *snapshot.Manager) *Server {
+	return &Server{
+		matcher:     matcher,
+		books:       books,
+		portfolios:  portfolios,
+		snapshots:   snapshots,
+		subscribers: make(map[chan []byte]struct{}),
+	}
+}
+
+// SetToken define o token de serviço exigido em todas as rotas; sem token,
+// toda requisição é recusada
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+// SetAdminToken define o ADMIN_TOKEN exigido para gravar, listar e restaurar
+// snapshots; sem ele, essas rotas respondem 403
+func (s *Server) SetAdminToken(token string) {
+	s.adminToken = token
+}
+
+// SetHTTPMetrics passa a medir as requisições por rota; deve ser chamado antes de Container
+func (s *Server) SetHTTPMetrics(m *metrics.HTTPMetrics) {
+	s.http = m
+}
+
+// Container cria o container HTTP com as rotas da API
+func (s *Server) Container() *restful.Container {
+	container := restful.NewContainer()
+	container.Router(restful.CurlyRouter{})
+	container.Add(s.webService())
+	return container
+}
+
+// webService registra as rotas da API
+func (s *Server) webService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(rootPath).
+		Consumes(restful.MIME_JSON).
+		Produces(restful.MIME_JSON).
+		Doc("Trading Engine API")
+
+	ws.Filter(s.tokenFilter)
+	ws.Filter(requestFilter)
+	if s.http != nil {
+		ws.Filter(s.metricsFilter)
+	}
+
+	ws.Route(ws.GET("/health").To(s.health))
+	ws.Route(ws.GET("/stats").To(s.stats))
+
+	ws.Route(ws.POST("/orders").To(s.submitOrder))
+	ws.Route(ws.POST("/orders/recall").To(s.recallOrder))
+	ws.Route(ws.GET("/orders").To(s.listOrders))
+	ws.Route(ws.GET("/orders/{order_id}").To(s.getOrder))
+	ws.Route(ws.PATCH("/orders/{order_id}").To(s.amendOrder))
+	ws.Route(ws.DELETE("/orders/{order_id}").To(s.cancelOrder))
+	ws.Route(ws.GET("/resting/{order_id}").To(s.findOrder))
+	ws.Route(ws.GET("/trades").To(s.listTrades))
+
+	ws.Route(ws.GET("/books/{symbol}").To(s.getOrderBook))
+	ws.Route(ws.GET("/books/{symbol}/depth").To(s.getDepth))
+
+	ws.Route(ws.GET("/users/{user_id}").To(s.getUser))
+	ws.Route(ws.GET("/portfolios/{user_id}").To(s.getPortfolio))
+	ws.Route(ws.POST("/validate").To(s.validateOrder))
+
+	ws.Route(ws.GET("/events").To(s.streamEvents).
+		Produces("application/x-ndjson").
+		ContentEncodingEnabled(false))
+
+	if s.snapshots != nil {
+		ws.Route(ws.GET("/snapshots").To(s.listSnapshots).Filter(s.adminFilter))
+		ws.Route(ws.POST("/snapshots").To(s.takeSnapshot).Filter(s.adminFilter))
+		ws.Route(ws.POST("/snapshots/{seq}/restore").To(s.restoreSnapshot).Filter(s.adminFilter))
+	}
+
+	return ws
+}
+
+// tokenFilter recusa com 401 as requisições sem o token de serviço. O engine
+// confia no user_id recebido: só o web service, que autentica os usuários,
+// pode chamá-lo.
+func (s *Server) tokenFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	token := req.HeaderParameter(HeaderServiceToken)
+	if s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		slog.WarnContext(req.Request.Context(), "token de serviço inválido", "method", req.Request.Method,
+			"path", req.Request.URL.Path, "remote_addr", req.Request.RemoteAddr)
+		writeJSON(resp, http.StatusUnauthorized, errorBody{Code: codeUnauthorized, Message: "token de serviço inválido"})
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// adminFilter recusa com 403 as requisições sem o ADMIN_TOKEN: restaurar um
+// snapshot substitui todo o estado do engine
+func (s *Server) adminFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	token := req.HeaderParameter(HeaderAdminToken)
+	if s.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+		slog.WarnContext(req.Request.Context(), "acesso negado", "log", "audit", "method", req.Request.Method,
+			"path", req.Request.URL.Path, "permission", "admin", "remote_addr", req.Request.RemoteAddr)
+		writeJSON(resp, http.StatusForbidden, errorBody{Code: codeForbidden, Message: "requer o ADMIN_TOKEN"})
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// requestFilter leva o X-Request-ID e o traceparent recebidos do web service
+// ao contexto da requisição, correlacionando os logs e spans do engine
+func requestFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	ctx := req.Request.Context()
+	if id := req.HeaderParameter(logging.HeaderRequestID); id != "" {
+		ctx = logging.WithRequestID(ctx, id)
+	}
+	if parent := req.HeaderParameter(tracing.HeaderTraceParent); parent != "" {
+		ctx = tracing.WithTraceParent(ctx, parent)
+	}
+	req.Request = req.Request.WithContext(ctx)
+	chain.ProcessFilter(req, resp)
+}
+
+// metricsFilter mede cada requisição por rota
+func (s *Server) metricsFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	start := time.Now()
+	chain.ProcessFilter(req, resp)
+	s.http.Observe(req.Request.Method, req.SelectedRoutePath(), resp.StatusCode(), time.Since(start))
+}
+
+// health responde se o engine está no ar
+func (s *Server) health(req *restful.Request, resp *restful.Response) {
+	writeJSON(resp, http.StatusOK, map[string]interface{}{
+		"status":      "ok",
+		"timestamp":   time.Now().UTC(),
+		"subscribers": s.Subscribers(),
+	})
+}
+
+// stats responde os contadores do engine
+func (s *Server) stats(req *restful.Request, resp *restful.Response) {
+	response := StatsResponse{Stats: s.matcher.Stats(), Rejected: map[string]uint64{}}
+	for reason, count := range response.Stats.Rejected {
+		code, _ := encodeError(reason)
+		response.Rejected[code] += count
+	}
+	writeJSON(resp, http.StatusOK, response)
+}
+
+// Subscribers retorna quantos streams de eventos estão abertos
+func (s *Server) Subscribers() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.subscribers)
+}
+
+// submitOrder cria a ordem no engine e executa o matching
+func (s *Server) submitOrder(req *restful.Request, resp *restful.Response) {
+	var body OrderRequest
+	if err := req.ReadEntity(&body); err != nil {
+		writeError(resp, domain.ErrInvalidOrder)
+		return
+	}
+
+	order := newOrder(body)
+	if err := order.Validate(); err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	writeJSON(resp, http.StatusOK, matchResponse(s.matcher.ProcessOrder(req.Request.Context(), order)))
+}
+
+// recallOrder devolve o resultado de um client_order_id já enviado, ou 404
+func (s *Server) recallOrder(req *restful.Request, resp *restful.Response) {
+	var body OrderRequest
+	if err := req.ReadEntity(&body); err != nil {
+		writeError(resp, domain.ErrInvalidOrder)
+		return
+	}
+
+	result, ok := s.matcher.Recall(req.Request.Context(), newOrder(body))
+	if !ok {
+		writeError(resp, domain.ErrOrderNotFound)
+		return
+	}
+	writeJSON(resp, http.StatusOK, matchResponse(result))
+}
+
+// newOrder cria a ordem descrita na requisição
+func newOrder(body OrderRequest) *domain.Order {
+	order := domain.NewOrder(body.UserID, body.Symbol, body.Side, body.Quantity, body.Price)
+	order.ClientOrderID = body.ClientOrderID
+	return order
+}
+
+// amendOrder altera quantidade e preço de uma ordem aberta
+func (s *Server) amendOrder(req *restful.Request, resp *restful.Response) {
+	var body AmendRequest
+	if err := req.ReadEntity(&body); err != nil {
+		writeError(resp, domain.ErrInvalidOrder)
+		return
+	}
+
+	result, err := s.matcher.AmendOrder(req.Request.Context(), req.PathParameter("order_id"), body.Quantity, body.Price)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	writeJSON(resp, http.StatusOK, matchResponse(result))
+}
+
+// cancelOrder cancela uma ordem aberta
+func (s *Server) cancelOrder(req *restful.Request, resp *restful.Response) {
+	order, err := s.matcher.CancelOrder(req.Request.Context(), req.PathParameter("order_id"))
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	writeJSON(resp, http.StatusOK, order)
+}
+
+// getOrder retorna o último estado conhecido de uma ordem
+func (s *Server) getOrder(req *restful.Request, resp *restful.Response) {
+	order, err := s.matcher.GetOrder(req.PathParameter("order_id"))
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	writeJSON(resp, http.StatusOK, order)
+}
+
+// listOrders consulta o histórico de ordens
+func (s *Server) listOrders(req *restful.Request, resp *restful.Response) {
+	limit, _ := strconv.Atoi(req.QueryParameter("limit"))
+	orders, err := s.matcher.ListOrders(repository.OrderFilter{
+		UserID:        req.QueryParameter("user_id"),
+		Symbol:        req.QueryParameter("symbol"),
+		Status:        domain.OrderStatus(req.QueryParameter("status")),
+		ClientOrderID: req.QueryParameter("client_order_id"),
+		Limit:         limit,
+	})
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	writeJSON(resp, http.StatusOK, orders)
+}
+
+// findOrder retorna uma ordem em repouso no livro
+func (s *Server) findOrder(req *restful.Request, resp *restful.Response) {
+	order := s.books.FindOrder(req.PathParameter("order_id"))
+	if order == nil {
+		writeError(resp, domain.ErrOrderNotFound)
+		return
+	}
+	writeJSON(resp, http.StatusOK, order)
+}
+
+// listTrades consulta o histórico de negociações
+func (s *Server) listTrades(req *restful.Request, resp *restful.Response) {
+	limit, _ := strconv.Atoi(req.QueryParameter("limit"))
+	minValue, _ := strconv.ParseFloat(req.QueryParameter("min_value"), 64)
+	from, _ := time.Parse(time.RFC3339Nano, req.QueryParameter("from"))
+	to, _ := time.Parse(time.RFC3339Nano, req.QueryParameter("to"))
+	trades, err := s.matcher.ListTrades(repository.TradeFilter{
+		Symbol:     req.QueryParameter("symbol"),
+		UserID:     req.QueryParameter("user_id"),
+		From:       from,
+		To:         to,
+		MinValue:   minValue,
+		After:      req.QueryParameter("after"),
+		Descending: req.QueryParameter("sort") == "desc",
+		Limit:      limit,
+	})
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	writeJSON(resp, http.StatusOK, trades)
+}
+
+// getOrderBook retorna o livro completo de um símbolo
+func (s *Server) getOrderBook(req *restful.Request, resp *restful.Response) {
+	writeJSON(resp, http.StatusOK, s.books.GetOrderBook(strings.ToUpper(req.PathParameter("symbol"))))
+}
+
+// getDepth retorna o livro agregado por nível de preço
+func (s *Server) getDepth(req *restful.Request, resp *restful.Response) {
+	depth, _ := strconv.Atoi(req.QueryParameter("depth"))
+	writeJSON(resp, http.StatusOK, s.books.GetDepth(strings.ToUpper(req.PathParameter("symbol")), depth))
+}
+
+// getUser retorna os dados de um usuário
+func (s *Server) getUser(req *restful.Request, resp *restful.Response) {
+	user, err := s.portfolios.GetUser(req.PathParameter("user_id"))
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	writeJSON(resp, http.StatusOK, user)
+}
+
+// getPortfolio retorna o portfolio de um usuário
+func (s *Server) getPortfolio(req *restful.Request, resp *restful.Response) {
+	portfolio, err := s.portfolios.GetPortfolio(req.PathParameter("user_id"))
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	writeJSON(resp, http.StatusOK, portfolio.Clone())
+}
+
+// validateOrder aplica as validações de saldo, posição e perfil
+func (s *Server) validateOrder(req *restful.Request, resp *restful.Response) {
+	var body OrderRequest
+	if err := req.ReadEntity(&body); err != nil {
+		writeError(resp, domain.ErrInvalidOrder)
+		return
+	}
+
+	// Apenas validação: não consome um ID do gerador de ordens
+	order := &domain.Order{
+		UserID:            body.UserID,
+		Symbol:            body.Symbol,
+		Side:              body.Side,
+		Quantity:          body.Quantity,
+		Price:             body.Price,
+		RemainingQuantity: body.Quantity,
+	}
+	if err := s.portfolios.ValidateOrder(req.Request.Context(), order); err != nil {
+		writeError(resp, err)
+		return
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// listSnapshots lista os snapshots disponíveis
+func (s *Server) listSnapshots(req *restful.Request, resp *restful.Response) {
+	infos, err := s.snapshots.List()
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	writeJSON(resp, http.StatusOK, infos)
+}
+
+// takeSnapshot grava um snapshot imediatamente
+func (s *Server) takeSnapshot(req *restful.Request, resp *restful.Response) {
+	info, err := s.snapshots.Take()
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	writeJSON(resp, http.StatusCreated, info)
+}
+
+// restoreSnapshot recarrega o engine a partir de um snapshot
+func (s *Server) restoreSnapshot(req *restful.Request, resp *restful.Response) {
+	seq, err := strconv.ParseUint(req.PathParameter("seq"), 10, 64)
+	if err != nil {
+		writeError(resp, snapshot.ErrNotFound)
+		return
+	}
+
+	info, replayed, err := s.snapshots.Restore(seq)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	writeJSON(resp, http.StatusOK, RestoreResponse{Snapshot: info, Replayed: replayed})
+}
+
+// HandleEvent repassa um evento do barramento a todos os streams abertos.
+// Um inscrito que não acompanha o ritmo é desconectado e deve reconectar.
+func (s *Server) HandleEvent(event events.Event) {
+	line, err := encodeEvent(event)
+	if err != nil {
+		slog.Error("erro ao serializar evento", "event", event.Type(), "error", err)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- line:
+		default:
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// streamEvents envia os eventos do engine como JSON delimitado por linha
+func (s *Server) streamEvents(req *restful.Request, resp *restful.Response) {
+	ch := make(chan []byte, streamBuffer)
+	s.mutex.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		if _, exists := s.subscribers[ch]; exists {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+		s.mutex.Unlock()
+	}()
+
+	resp.Header().Set("Content-Type", "application/x-ndjson")
+	resp.WriteHeader(http.StatusOK)
+	resp.Flush()
+
+	for {
+		select {
+		case line, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, err := resp.Write(line); err != nil {
+				return
+			}
+			resp.Flush()
+		case <-req.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// matchResponse anexa ao resultado o código do erro de rejeição
+func matchResponse(result *matching.MatchResult) MatchResponse {
+	response := MatchResponse{MatchResult: result}
+	if result.Err != nil {
+		response.ErrorCode, _ = encodeError(result.Err)
+	}
+	return response
+}
+
+// writeJSON escreve uma resposta JSON com o status informado
+func writeJSON(resp *restful.Response, status int, value interface{}) {
+	_ = resp.WriteHeaderAndJson(status, value, restful.MIME_JSON)
+}
+
+// writeError escreve o erro com seu código estável
+func writeError(resp *restful.Response, err error) {
+	code, status := encodeError(err)
+	writeJSON(resp, status, errorBody{Code: code, Message: err.Error()})
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc"
+
+	"trading/internal/services/engine"
+	"trading/internal/services/engine/api"
+	"trading/internal/services/engine/feed"
+	"trading/internal/services/fix"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/shared/metrics"
+	"trading/internal/services/shared/tracing"
+	"trading/internal/services/shared/validators"
+)
+
+func main() {
+	logging.Setup()
+	slog.Info("iniciando engine service")
+
+	// Spans via OTLP ou arquivo, se configurados
+	shutdownTracing, err := tracing.Setup("trading-engine")
+	if err != nil {
+		logging.Fatal("erro ao configurar tracing", err)
+	}
+	defer shutdownTracing()
+
+	cfg, err := engine.ConfigFromEnv()
+	if err != nil {
+		logging.Fatal("configuração inválida", err)
+	}
+
+	// Carrega livros, portfolios e matching, recuperando o estado do disco
+	core, err := engine.Open(cfg)
+	if err != nil {
+		logging.Fatal("erro ao iniciar engine", err)
+	}
+	defer core.Close()
+
+	// A API do engine aceita o user_id informado: só o web service, com o
+	// token de serviço, pode chamá-la, e por padrão apenas pela interface local
+	token := os.Getenv("ENGINE_TOKEN")
+	if token == "" {
+		logging.Fatal("token de serviço ausente", errors.New("defina ENGINE_TOKEN (o mesmo valor no web service)"))
+	}
+	bind := getEnv("ENGINE_BIND", "127.0.0.1")
+
+	// Eventos do engine seguem para os web services conectados ao stream
+	server := api.NewServer(core.Matcher, core.Books, core.Portfolios, core.Snapshots)
+	server.SetToken(token)
+	server.SetAdminToken(os.Getenv("ADMIN_TOKEN"))
+	bus := events.NewBus()
+	defer bus.Close()
+	core.SetPublisher(bus)
+	bus.Subscribe("engine-api", server.HandleEvent)
+
+	// API gRPC para o web service: ordens, livro, portfolio e market data
+	grpcService := api.NewGRPCServer(core.Matcher, core.Books, core.Portfolios)
+	bus.Subscribe("engine-grpc", grpcService.HandleEvent)
+
+	grpcPort := getEnv("ENGINE_GRPC_PORT", "9091")
+	listener, err := net.Listen("tcp", net.JoinHostPort(bind, grpcPort))
+	if err != nil {
+		logging.Fatal("erro ao abrir porta gRPC", err)
+	}
+	grpcServer := grpc.NewServer(api.TokenInterceptors(token)...)
+	grpcService.Register(grpcServer)
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			logging.Fatal("erro no servidor gRPC", err)
+		}
+	}()
+	defer grpcServer.GracefulStop()
+
+	// Gateway FIX 4.4 para clientes institucionais, com as mesmas validações da
+	// API REST. Só sobe com FIX_CLIENTS: cada cliente autentica com senha e
+	// opera apenas as contas listadas.
+	validator, err := validators.NewBusinessValidator(filepath.Join(cfg.DataDir, "stocks.json"))
+	if err != nil {
+		logging.Fatal("erro ao carregar ações", err)
+	}
+	fixConfig, err := fix.ConfigFromEnv()
+	if err != nil {
+		logging.Fatal("FIX_CLIENTS inválido", err)
+	}
+	fixPort := getEnv("FIX_PORT", "9876")
+	if len(fixConfig.Clients) > 0 {
+		gateway := fix.NewAcceptor(fixConfig, core.Matcher, core.Portfolios, validator)
+		bus.Subscribe("engine-fix", gateway.HandleEvent)
+
+		fixListener, err := net.Listen("tcp", net.JoinHostPort(bind, fixPort))
+		if err != nil {
+			logging.Fatal("erro ao abrir porta FIX", err)
+		}
+		go func() {
+			if err := gateway.Serve(fixListener); err != nil {
+				logging.Fatal("erro no gateway FIX", err)
+			}
+		}()
+		defer gateway.Close()
+	} else {
+		fixPort = ""
+		slog.Info("gateway FIX desativado: defina FIX_CLIENTS para habilitá-lo")
+	}
+
+	// Feed binário de market data por UDP, com snapshot e retransmissão por TCP
+	feedAddr := getEnv("FEED_ADDR", "127.0.0.1:9200")
+	publisher, err := feed.NewPublisher(feed.Config{Addr: feedAddr}, core.Books)
+	if err != nil {
+		logging.Fatal("FEED_ADDR inválido", err)
+	}
+	defer publisher.Close()
+	bus.Subscribe("engine-feed", publisher.HandleEvent)
+	go publisher.Run(context.Background())
+
+	feedPort := getEnv("FEED_SNAPSHOT_PORT", "9201")
+	feedListener, err := net.Listen("tcp", ":"+feedPort)
+	if err != nil {
+		logging.Fatal("erro ao abrir porta de snapshot do feed", err)
+	}
+	feedServer := feed.NewServer(publisher)
+	go func() {
+		if err := feedServer.Serve(feedListener); err != nil {
+			logging.Fatal("erro no serviço de snapshot do feed", err)
+		}
+	}()
+	defer feedServer.Close()
+
+	go core.Run(context.Background())
+
+	// Métricas Prometheus em /metrics
+	registry := metrics.NewRegistry()
+	httpMetrics := metrics.NewHTTPMetrics()
+	server.SetHTTPMetrics(httpMetrics)
+	registry.Register(httpMetrics, metrics.RuntimeCollector(),
+		metrics.EngineCollector(core.Matcher, core.Books, api.ErrorCode),
+		metrics.RejectionCollector("trading_validation_rejections_total", "Ordens FIX rejeitadas pelas regras de ações e mercado.", validator.Rejections, api.ErrorCode))
+	container := server.Container()
+	container.Handle("/metrics", registry)
+
+	// Porta do servidor
+	port := getEnv("ENGINE_PORT", "9090")
+	slog.Info("engine service rodando",
+		"bind", bind,
+		"port", port,
+		"health", "http://localhost:"+port+"/engine/health",
+		"metrics", "http://localhost:"+port+"/metrics",
+		"grpc_port", grpcPort,
+		"fix_port", fixPort,
+		"feed_addr", feedAddr,
+		"feed_snapshot_port", feedPort)
+
+	if err := http.ListenAndServe(net.JoinHostPort(bind, port), container); err != nil {
+		logging.Fatal("erro ao iniciar engine service", err)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"trading/internal/services/engine/journal"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/engine/portfolio"
+	"trading/internal/services/engine/repository"
+	"trading/internal/services/engine/snapshot"
+	"trading/internal/services/shared/events"
+)
+
+// SnapshotsKept é quantos snapshots são mantidos em SnapshotDir
+const SnapshotsKept = 5
+
+// Config reúne as opções de inicialização do engine; caminhos vazios
+// desabilitam o recurso correspondente
+type Config struct {
+	DataDir          string
+	DatabasePath     string
+	JournalPath      string
+	SnapshotDir      string
+	SnapshotInterval time.Duration
+}
+
+// ConfigFromEnv lê a configuração de DATA_DIR, DATABASE_PATH, JOURNAL_PATH,
+// SNAPSHOT_DIR e SNAPSHOT_INTERVAL
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		DataDir:      getEnv("DATA_DIR", "data"),
+		DatabasePath: os.Getenv("DATABASE_PATH"),
+		JournalPath:  os.Getenv("JOURNAL_PATH"),
+		SnapshotDir:  os.Getenv("SNAPSHOT_DIR"),
+	}
+
+	interval, err := time.ParseDuration(getEnv("SNAPSHOT_INTERVAL", "5m"))
+	if err != nil {
+		return cfg, fmt.Errorf("SNAPSHOT_INTERVAL inválido: %w", err)
+	}
+	cfg.SnapshotInterval = interval
+
+	return cfg, nil
+}
+
+// Engine agrupa os serviços do engine já recuperados do disco
+type Engine struct {
+	Books      *orderbook.Manager
+	Portfolios *portfolio.Service
+	Matcher    *matching.Service
+	Snapshots  *snapshot.Manager // nil sem SnapshotDir
+
+	interval time.Duration
+	closers  []func() error
+}
+
+// Open carrega usuários, abre banco, journal e snapshots e reconstrói o
+// estado do engine: snapshot + final do journal, só o journal, ou os
+// portfolios e ordens abertas gravados no banco, nessa ordem de preferência
+func Open(cfg Config) (*Engine, error) {
+	portfolios, err := portfolio.NewService(filepath.Join(cfg.DataDir, "users.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	books := orderbook.NewManager()
+	e := &Engine{
+		Books:      books,
+		Portfolios: portfolios,
+		Matcher:    matching.NewService(books, portfolios),
+		interval:   cfg.SnapshotInterval,
+	}
+
+	if err := e.recover(cfg); err != nil {
+		e.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+// recover abre o armazenamento configurado e reconstrói o estado
+func (e *Engine) recover(cfg Config) error {
+	if cfg.DatabasePath != "" {
+		store, err := repository.OpenSQLite(cfg.DatabasePath)
+		if err != nil {
+			return fmt.Errorf("abrindo banco de dados: %w", err)
+		}
+		e.closers = append(e.closers, store.Close)
+		e.Matcher.SetRepositories(store, store)
+		e.Portfolios.SetRepository(store)
+	}
+
+	var commands *journal.Journal
+	if cfg.JournalPath != "" {
+		var err error
+		commands, err = journal.Open(cfg.JournalPath)
+		if err != nil {
+			return fmt.Errorf("abrindo journal: %w", err)
+		}
+		e.closers = append(e.closers, commands.Close)
+	}
+
+	switch {
+	case cfg.SnapshotDir != "":
+		store, err := snapshot.NewStore(cfg.SnapshotDir, SnapshotsKept)
+		if err != nil {
+			return fmt.Errorf("abrindo snapshots: %w", err)
+		}
+		e.Snapshots = snapshot.NewManager(e.Matcher, store, commands)
+
+		info, applied, err := e.Snapshots.Recover()
+		if err != nil {
+			return fmt.Errorf("restaurando snapshot: %w", err)
+		}
+		slog.Info("snapshot carregado", "seq", info.Seq, "replayed", applied)
+
+	case commands != nil:
+		applied, err := e.Matcher.Replay(commands, 0)
+		if err != nil {
+			return fmt.Errorf("replay do journal: %w", err)
+		}
+		slog.Info("journal reaplicado", "replayed", applied)
+
+	case cfg.DatabasePath != "":
+		// Sem journal, o banco é a única fonte do estado: portfolios e as
+		// ordens abertas, que voltam aos livros com suas reservas
+		loaded, err := e.Portfolios.Load()
+		if err != nil {
+			return fmt.Errorf("carregando portfolios: %w", err)
+		}
+		resting, err := e.Matcher.RestoreOpenOrders()
+		if err != nil {
+			return fmt.Errorf("carregando ordens abertas: %w", err)
+		}
+		slog.Info("estado carregado do banco de dados", "portfolios", loaded, "open_orders", resting)
+	}
+
+	if commands != nil {
+		e.Matcher.SetJournal(commands)
+	}
+	return nil
+}
+
+// SetPublisher define onde matching e portfolio publicam seus eventos
+func (e *Engine) SetPublisher(publisher events.Publisher) {
+	e.Matcher.SetPublisher(publisher)
+	e.Portfolios.SetPublisher(publisher)
+}
+
+// Run grava snapshots periódicos até o contexto ser cancelado
+func (e *Engine) Run(ctx context.Context) {
+	if e.Snapshots != nil {
+		e.Snapshots.Run(ctx, e.interval)
+	}
+}
+
+// Close fecha journal e banco de dados, na ordem inversa da abertura
+func (e *Engine) Close() error {
+	var errs []error
+	for i := len(e.closers) - 1; i >= 0; i-- {
+		errs = append(errs, e.closers[i]())
+	}
+	e.closers = nil
+	return errors.Join(errs...)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
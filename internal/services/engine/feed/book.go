@@ -0,0 +1,92 @@
+package feed
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrGap indica que a mensagem pula sequências: as anteriores devem ser
+// recuperadas (Retransmit ou Snapshot) antes de aplicá-la
+var ErrGap = errors.New("lacuna na sequência do feed")
+
+// Book é o livro de um símbolo reconstruído por um consumidor do feed
+type Book struct {
+	Symbol string
+	Seq    uint64
+
+	bids map[int64]Level
+	asks map[int64]Level
+}
+
+// NewBook cria um livro vazio, antes de qualquer sequência
+func NewBook(symbol string) *Book {
+	return &Book{
+		Symbol: symbol,
+		bids:   make(map[int64]Level),
+		asks:   make(map[int64]Level),
+	}
+}
+
+// Apply aplica a mensagem ao livro. Mensagens já aplicadas são ignoradas; uma
+// mensagem além da próxima sequência (ou um heartbeat adiante do livro)
+// retorna ErrGap sem alterar o livro.
+func (b *Book) Apply(m *Message) error {
+	if m.Symbol != b.Symbol {
+		return fmt.Errorf("mensagem de %s aplicada ao livro de %s", m.Symbol, b.Symbol)
+	}
+
+	switch m.Type {
+	case TypeSnapshot:
+		if m.Seq < b.Seq {
+			return nil
+		}
+		b.bids = make(map[int64]Level, len(m.Bids))
+		b.asks = make(map[int64]Level, len(m.Asks))
+		for _, level := range m.Bids {
+			b.bids[toTicks(level.Price)] = level
+		}
+		for _, level := range m.Asks {
+			b.asks[toTicks(level.Price)] = level
+		}
+		b.Seq = m.Seq
+		return nil
+
+	case TypeHeartbeat:
+		if m.Seq > b.Seq {
+			return fmt.Errorf("%w: %s em %d, heartbeat em %d", ErrGap, b.Symbol, b.Seq, m.Seq)
+		}
+		return nil
+
+	case TypeBookUpdate, TypeTrade:
+		switch {
+		case m.Seq <= b.Seq:
+			return nil
+		case m.Seq > b.Seq+1:
+			return fmt.Errorf("%w: %s esperava %d, recebido %d", ErrGap, b.Symbol, b.Seq+1, m.Seq)
+		}
+		if m.Type == TypeBookUpdate {
+			levels := b.bids
+			if m.Side == Ask {
+				levels = b.asks
+			}
+			if m.Level.Quantity == 0 {
+				delete(levels, toTicks(m.Level.Price))
+			} else {
+				levels[toTicks(m.Level.Price)] = m.Level
+			}
+		}
+		b.Seq = m.Seq
+		return nil
+	}
+	return nil
+}
+
+// Bids retorna os níveis de compra em preço decrescente
+func (b *Book) Bids() []Level {
+	return sortedLevels(b.bids, true)
+}
+
+// Asks retorna os níveis de venda em preço crescente
+func (b *Book) Asks() []Level {
+	return sortedLevels(b.asks, false)
+}
@@ -0,0 +1,87 @@
+package feed
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ErrOutOfWindow indica que parte do intervalo pedido já saiu da janela de
+// retransmissão; o consumidor deve se recuperar por snapshot
+var ErrOutOfWindow = errors.New("sequência fora da janela de retransmissão")
+
+// Client é o cliente de referência do serviço de snapshot/retransmissão
+type Client struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+}
+
+// Dial conecta ao serviço de snapshot/retransmissão
+func Dial(addr string, timeout time.Duration) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, reader: bufio.NewReader(conn), timeout: timeout}, nil
+}
+
+// Close encerra a conexão
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Snapshot pede os níveis atuais do símbolo; o feed deve ser aplicado a partir
+// da sequência Seq+1 do snapshot
+func (c *Client) Snapshot(symbol string) (*Message, error) {
+	if err := c.send(request{kind: requestSnapshot, symbol: symbol}); err != nil {
+		return nil, err
+	}
+	m, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	if m.Type != TypeSnapshot {
+		return nil, fmt.Errorf("%w: resposta %c a um pedido de snapshot", ErrMalformed, m.Type)
+	}
+	return m, nil
+}
+
+// Retransmit pede as mensagens de from a to (inclusive) do símbolo
+func (c *Client) Retransmit(symbol string, from, to uint64) ([]*Message, error) {
+	if err := c.send(request{kind: requestRetransmit, symbol: symbol, from: from, to: to}); err != nil {
+		return nil, err
+	}
+
+	var messages []*Message
+	for {
+		m, err := c.read()
+		if err != nil {
+			return nil, err
+		}
+		switch m.Type {
+		case TypeEnd:
+			return messages, nil
+		case TypeReject:
+			if m.Reason == RejectOutOfWindow {
+				return nil, fmt.Errorf("%w: mais antiga disponível %d", ErrOutOfWindow, m.Seq)
+			}
+			return nil, fmt.Errorf("%w: pedido recusado (%d)", ErrMalformed, m.Reason)
+		default:
+			messages = append(messages, m)
+		}
+	}
+}
+
+func (c *Client) send(req request) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	_, err := c.conn.Write(encodeRequest(req))
+	return err
+}
+
+func (c *Client) read() (*Message, error) {
+	c.conn.SetReadDeadline(time.Now().Add(c.timeout))
+	return ReadMessage(c.reader)
+}
@@ -0,0 +1,317 @@
+package feed
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Formato binário do feed (little-endian). Toda mensagem começa com o mesmo
+// cabeçalho de 28 bytes:
+//
+//	0   uint16  tamanho total da mensagem, cabeçalho incluso
+//	2   uint8   tipo (U, T, H, S, E, X)
+//	3   uint8   versão do protocolo
+//	4   uint64  sequência do símbolo
+//	12  int64   timestamp em nanossegundos Unix
+//	20  [8]byte símbolo, completado com zeros
+//
+// Corpos:
+//
+//	U (BookUpdate)  int64 preço, uint32 quantidade, uint32 ordens, uint8 lado
+//	T (Trade)       int64 preço, uint32 quantidade
+//	H (Heartbeat)   vazio; a sequência é a última publicada no símbolo
+//	S (Snapshot)    uint16 bids, uint16 asks e os níveis (int64 preço, uint32
+//	                quantidade, uint32 ordens); a sequência é a da última
+//	                mensagem refletida no snapshot
+//	E (End)         vazio; fim de uma retransmissão
+//	X (Reject)      uint8 motivo; a sequência é a mais antiga disponível
+//
+// Preços são inteiros com PriceScale casas implícitas. Quantidade zero em um
+// BookUpdate remove o nível.
+const (
+	Version    = 1
+	PriceScale = 10000
+	HeaderSize = 28
+
+	symbolSize    = 8
+	levelSize     = 16
+	maxMessageLen = math.MaxUint16
+)
+
+// MessageType identifica o tipo da mensagem
+type MessageType byte
+
+// Tipos de mensagem
+const (
+	TypeBookUpdate MessageType = 'U'
+	TypeTrade      MessageType = 'T'
+	TypeHeartbeat  MessageType = 'H'
+	TypeSnapshot   MessageType = 'S'
+	TypeEnd        MessageType = 'E'
+	TypeReject     MessageType = 'X'
+)
+
+// Side é o lado de um nível de preço
+type Side byte
+
+// Lados do livro
+const (
+	Bid Side = 'B'
+	Ask Side = 'A'
+)
+
+// RejectReason explica a recusa de um pedido ao serviço de snapshot
+type RejectReason byte
+
+// Motivos de recusa
+const (
+	RejectOutOfWindow    RejectReason = 1
+	RejectInvalidRequest RejectReason = 2
+)
+
+// Erros do protocolo
+var (
+	ErrMalformed  = errors.New("mensagem do feed mal formada")
+	ErrSymbolSize = errors.New("símbolo excede 8 bytes")
+	ErrTooLarge   = errors.New("snapshot excede o tamanho máximo da mensagem")
+)
+
+// Level é um nível de preço agregado
+type Level struct {
+	Price    float64 `json:"price"`
+	Quantity int     `json:"quantity"`
+	Orders   int     `json:"orders"`
+}
+
+// Message é uma mensagem decodificada do feed; os campos usados dependem de Type
+type Message struct {
+	Type      MessageType
+	Seq       uint64
+	Timestamp time.Time
+	Symbol    string
+
+	// BookUpdate e Trade (Orders não é usado em Trade)
+	Side  Side
+	Level Level
+
+	// Snapshot
+	Bids []Level
+	Asks []Level
+
+	// Reject
+	Reason RejectReason
+}
+
+// Encode serializa a mensagem no formato binário
+func Encode(m *Message) ([]byte, error) {
+	if len(m.Symbol) > symbolSize {
+		return nil, ErrSymbolSize
+	}
+
+	size := HeaderSize
+	switch m.Type {
+	case TypeBookUpdate:
+		size += 17
+	case TypeTrade:
+		size += 12
+	case TypeSnapshot:
+		size += 4 + levelSize*(len(m.Bids)+len(m.Asks))
+	case TypeReject:
+		size++
+	case TypeHeartbeat, TypeEnd:
+	default:
+		return nil, fmt.Errorf("%w: tipo %q", ErrMalformed, m.Type)
+	}
+	if size > maxMessageLen {
+		return nil, ErrTooLarge
+	}
+
+	buf := make([]byte, size)
+	binary.LittleEndian.PutUint16(buf[0:], uint16(size))
+	buf[2] = byte(m.Type)
+	buf[3] = Version
+	binary.LittleEndian.PutUint64(buf[4:], m.Seq)
+	binary.LittleEndian.PutUint64(buf[12:], uint64(m.Timestamp.UnixNano()))
+	copy(buf[20:HeaderSize], m.Symbol)
+
+	body := buf[HeaderSize:]
+	switch m.Type {
+	case TypeBookUpdate:
+		putLevel(body, m.Level)
+		body[16] = byte(m.Side)
+	case TypeTrade:
+		binary.LittleEndian.PutUint64(body, uint64(toTicks(m.Level.Price)))
+		binary.LittleEndian.PutUint32(body[8:], uint32(m.Level.Quantity))
+	case TypeSnapshot:
+		binary.LittleEndian.PutUint16(body, uint16(len(m.Bids)))
+		binary.LittleEndian.PutUint16(body[2:], uint16(len(m.Asks)))
+		offset := 4
+		for _, level := range append(append([]Level{}, m.Bids...), m.Asks...) {
+			putLevel(body[offset:], level)
+			offset += levelSize
+		}
+	case TypeReject:
+		body[0] = byte(m.Reason)
+	}
+	return buf, nil
+}
+
+// Decode interpreta uma mensagem completa (um datagrama UDP ou um quadro TCP)
+func Decode(data []byte) (*Message, error) {
+	if len(data) < HeaderSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrMalformed, len(data))
+	}
+	if size := int(binary.LittleEndian.Uint16(data)); size != len(data) {
+		return nil, fmt.Errorf("%w: tamanho %d, recebidos %d bytes", ErrMalformed, size, len(data))
+	}
+	if data[3] != Version {
+		return nil, fmt.Errorf("%w: versão %d", ErrMalformed, data[3])
+	}
+
+	m := &Message{
+		Type:      MessageType(data[2]),
+		Seq:       binary.LittleEndian.Uint64(data[4:]),
+		Timestamp: time.Unix(0, int64(binary.LittleEndian.Uint64(data[12:]))).UTC(),
+		Symbol:    strings.TrimRight(string(data[20:HeaderSize]), "\x00"),
+	}
+
+	body := data[HeaderSize:]
+	switch m.Type {
+	case TypeBookUpdate:
+		if len(body) != 17 {
+			return nil, fmt.Errorf("%w: BookUpdate com %d bytes", ErrMalformed, len(body))
+		}
+		m.Level = readLevel(body)
+		m.Side = Side(body[16])
+		if m.Side != Bid && m.Side != Ask {
+			return nil, fmt.Errorf("%w: lado %q", ErrMalformed, m.Side)
+		}
+	case TypeTrade:
+		if len(body) != 12 {
+			return nil, fmt.Errorf("%w: Trade com %d bytes", ErrMalformed, len(body))
+		}
+		m.Level = Level{
+			Price:    fromTicks(int64(binary.LittleEndian.Uint64(body))),
+			Quantity: int(binary.LittleEndian.Uint32(body[8:])),
+		}
+	case TypeSnapshot:
+		if len(body) < 4 {
+			return nil, fmt.Errorf("%w: Snapshot sem contagem de níveis", ErrMalformed)
+		}
+		bids := int(binary.LittleEndian.Uint16(body))
+		asks := int(binary.LittleEndian.Uint16(body[2:]))
+		if len(body) != 4+levelSize*(bids+asks) {
+			return nil, fmt.Errorf("%w: Snapshot com %d bytes para %d níveis", ErrMalformed, len(body), bids+asks)
+		}
+		m.Bids = make([]Level, bids)
+		m.Asks = make([]Level, asks)
+		for i := range m.Bids {
+			m.Bids[i] = readLevel(body[4+levelSize*i:])
+		}
+		for i := range m.Asks {
+			m.Asks[i] = readLevel(body[4+levelSize*(bids+i):])
+		}
+	case TypeReject:
+		if len(body) != 1 {
+			return nil, fmt.Errorf("%w: Reject com %d bytes", ErrMalformed, len(body))
+		}
+		m.Reason = RejectReason(body[0])
+	case TypeHeartbeat, TypeEnd:
+		if len(body) != 0 {
+			return nil, fmt.Errorf("%w: %c com corpo", ErrMalformed, m.Type)
+		}
+	default:
+		return nil, fmt.Errorf("%w: tipo %q", ErrMalformed, m.Type)
+	}
+	return m, nil
+}
+
+// ReadMessage lê a próxima mensagem de um fluxo TCP, usando o tamanho do cabeçalho
+func ReadMessage(r io.Reader) (*Message, error) {
+	var size [2]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	length := int(binary.LittleEndian.Uint16(size[:]))
+	if length < HeaderSize {
+		return nil, fmt.Errorf("%w: tamanho %d", ErrMalformed, length)
+	}
+
+	data := make([]byte, length)
+	copy(data, size[:])
+	if _, err := io.ReadFull(r, data[2:]); err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+// Pedidos ao serviço de snapshot/retransmissão (25 bytes):
+//
+//	0   uint8   tipo (S = snapshot, R = retransmissão)
+//	1   [8]byte símbolo
+//	9   uint64  primeira sequência (retransmissão)
+//	17  uint64  última sequência (retransmissão)
+const requestSize = 25
+
+// Tipos de pedido
+const (
+	requestSnapshot   = 'S'
+	requestRetransmit = 'R'
+)
+
+// request é um pedido ao serviço de snapshot/retransmissão
+type request struct {
+	kind     byte
+	symbol   string
+	from, to uint64
+}
+
+func encodeRequest(req request) []byte {
+	buf := make([]byte, requestSize)
+	buf[0] = req.kind
+	copy(buf[1:9], req.symbol)
+	binary.LittleEndian.PutUint64(buf[9:], req.from)
+	binary.LittleEndian.PutUint64(buf[17:], req.to)
+	return buf
+}
+
+func readRequest(r io.Reader) (request, error) {
+	buf := make([]byte, requestSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return request{}, err
+	}
+	return request{
+		kind:   buf[0],
+		symbol: strings.TrimRight(string(buf[1:9]), "\x00"),
+		from:   binary.LittleEndian.Uint64(buf[9:]),
+		to:     binary.LittleEndian.Uint64(buf[17:]),
+	}, nil
+}
+
+func putLevel(buf []byte, level Level) {
+	binary.LittleEndian.PutUint64(buf, uint64(toTicks(level.Price)))
+	binary.LittleEndian.PutUint32(buf[8:], uint32(level.Quantity))
+	binary.LittleEndian.PutUint32(buf[12:], uint32(level.Orders))
+}
+
+func readLevel(buf []byte) Level {
+	return Level{
+		Price:    fromTicks(int64(binary.LittleEndian.Uint64(buf))),
+		Quantity: int(binary.LittleEndian.Uint32(buf[8:])),
+		Orders:   int(binary.LittleEndian.Uint32(buf[12:])),
+	}
+}
+
+// toTicks converte o preço para inteiro com PriceScale casas implícitas
+func toTicks(price float64) int64 {
+	return int64(math.Round(price * PriceScale))
+}
+
+func fromTicks(ticks int64) float64 {
+	return float64(ticks) / PriceScale
+}
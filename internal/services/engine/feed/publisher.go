@@ -0,0 +1,273 @@
+package feed
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/shared/events"
+)
+
+// Valores padrão do feed
+const (
+	DefaultDepth             = 10
+	DefaultWindow            = 4096
+	DefaultHeartbeatInterval = time.Second
+)
+
+// Config configura o feed binário
+type Config struct {
+	// Addr é o destino UDP dos datagramas (unicast ou grupo multicast)
+	Addr string
+
+	// Depth é o número de níveis publicados por lado
+	Depth int
+
+	// Window é o número de mensagens guardadas por símbolo para retransmissão
+	Window int
+
+	// HeartbeatInterval é o intervalo dos heartbeats por símbolo
+	HeartbeatInterval time.Duration
+}
+
+// Publisher publica, por UDP, as alterações de nível do livro e as negociações
+// de cada símbolo, com sequência própria por símbolo. Cada datagrama carrega
+// uma mensagem.
+type Publisher struct {
+	cfg   Config
+	books *orderbook.Manager
+
+	conn net.PacketConn
+	dest net.Addr
+
+	mutex   sync.Mutex
+	symbols map[string]*symbolFeed
+}
+
+// symbolFeed é o estado publicado de um símbolo: o livro como o consumidor o
+// vê após a última sequência e as mensagens recentes para retransmissão
+type symbolFeed struct {
+	seq     uint64
+	bids    map[int64]Level
+	asks    map[int64]Level
+	history [][]byte
+}
+
+// NewPublisher abre o socket UDP do feed
+func NewPublisher(cfg Config, books *orderbook.Manager) (*Publisher, error) {
+	if cfg.Depth <= 0 {
+		cfg.Depth = DefaultDepth
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultWindow
+	}
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+
+	dest, err := net.ResolveUDPAddr("udp", cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	// Socket não conectado: sem consumidor, o envio não falha com ECONNREFUSED
+	conn, err := net.ListenPacket("udp", ":0")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Publisher{
+		cfg:     cfg,
+		books:   books,
+		conn:    conn,
+		dest:    dest,
+		symbols: make(map[string]*symbolFeed),
+	}, nil
+}
+
+// HandleEvent publica alterações de livro e negociações
+func (p *Publisher) HandleEvent(event events.Event) {
+	switch e := event.(type) {
+	case events.BookChanged:
+		p.publishBook(e.Symbol, e.Timestamp)
+	case events.TradeExecuted:
+		p.publish(&Message{
+			Type:      TypeTrade,
+			Timestamp: e.Trade.ExecutedAt,
+			Symbol:    e.Trade.Symbol,
+			Level:     Level{Price: e.Trade.Price, Quantity: e.Trade.Quantity},
+		})
+	}
+}
+
+// Run envia heartbeats com a última sequência de cada símbolo, permitindo ao
+// consumidor detectar perdas mesmo sem novas alterações
+func (p *Publisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.mutex.Lock()
+			for symbol, feed := range p.symbols {
+				p.sendLocked(&Message{Type: TypeHeartbeat, Seq: feed.seq, Timestamp: now, Symbol: symbol})
+			}
+			p.mutex.Unlock()
+		}
+	}
+}
+
+// Close fecha o socket do feed
+func (p *Publisher) Close() error {
+	return p.conn.Close()
+}
+
+// Snapshot retorna os níveis publicados do símbolo e a sequência a partir da
+// qual o consumidor deve aplicar o feed
+func (p *Publisher) Snapshot(symbol string) *Message {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	snapshot := &Message{Type: TypeSnapshot, Timestamp: time.Now().UTC(), Symbol: symbol}
+	if feed, exists := p.symbols[symbol]; exists {
+		snapshot.Seq = feed.seq
+		snapshot.Bids = sortedLevels(feed.bids, true)
+		snapshot.Asks = sortedLevels(feed.asks, false)
+	}
+	return snapshot
+}
+
+// Retransmit retorna as mensagens de from a to (inclusive) já codificadas. Se
+// parte do intervalo saiu da janela, retorna ok=false e a sequência mais antiga
+// disponível.
+func (p *Publisher) Retransmit(symbol string, from, to uint64) (packets [][]byte, oldest uint64, ok bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	feed, exists := p.symbols[symbol]
+	if !exists {
+		return nil, 1, false
+	}
+
+	oldest = 1
+	if window := uint64(len(feed.history)); feed.seq > window {
+		oldest = feed.seq - window + 1
+	}
+	if to > feed.seq {
+		to = feed.seq
+	}
+	if from < oldest || from == 0 {
+		return nil, oldest, false
+	}
+
+	for seq := from; seq <= to; seq++ {
+		packets = append(packets, feed.history[seq%uint64(len(feed.history))])
+	}
+	return packets, oldest, true
+}
+
+// publishBook compara o livro atual com o último publicado e envia um
+// BookUpdate por nível alterado, removido ou novo
+func (p *Publisher) publishBook(symbol string, at time.Time) {
+	depth := p.books.GetDepth(symbol, p.cfg.Depth)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	feed := p.feedLocked(symbol)
+	for _, side := range []struct {
+		side      Side
+		published map[int64]Level
+		current   []orderbook.PriceLevel
+		desc      bool
+	}{
+		{Bid, feed.bids, depth.Bids, true},
+		{Ask, feed.asks, depth.Asks, false},
+	} {
+		current := make(map[int64]Level, len(side.current))
+		for _, level := range side.current {
+			current[toTicks(level.Price)] = Level{Price: level.Price, Quantity: level.Quantity, Orders: level.Orders}
+		}
+
+		// Níveis removidos primeiro, depois novos e alterados, em ordem de preço
+		for _, level := range sortedLevels(side.published, side.desc) {
+			if _, exists := current[toTicks(level.Price)]; !exists {
+				delete(side.published, toTicks(level.Price))
+				p.publishLocked(feed, &Message{Type: TypeBookUpdate, Timestamp: at, Symbol: symbol, Side: side.side, Level: Level{Price: level.Price}})
+			}
+		}
+		for _, level := range sortedLevels(current, side.desc) {
+			ticks := toTicks(level.Price)
+			if side.published[ticks] != level {
+				side.published[ticks] = level
+				p.publishLocked(feed, &Message{Type: TypeBookUpdate, Timestamp: at, Symbol: symbol, Side: side.side, Level: level})
+			}
+		}
+	}
+}
+
+// publish numera e envia uma mensagem do símbolo
+func (p *Publisher) publish(m *Message) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.publishLocked(p.feedLocked(m.Symbol), m)
+}
+
+func (p *Publisher) publishLocked(feed *symbolFeed, m *Message) {
+	m.Seq = feed.seq + 1
+	packet := p.sendLocked(m)
+	if packet == nil {
+		return
+	}
+	feed.seq = m.Seq
+	feed.history[m.Seq%uint64(len(feed.history))] = packet
+}
+
+// sendLocked codifica e envia o datagrama; falhas de envio não interrompem o
+// feed, pois o consumidor recupera pela retransmissão
+func (p *Publisher) sendLocked(m *Message) []byte {
+	packet, err := Encode(m)
+	if err != nil {
+		slog.Warn("feed: mensagem descartada", "type", string(m.Type), "symbol", m.Symbol, "error", err)
+		return nil
+	}
+	if _, err := p.conn.WriteTo(packet, p.dest); err != nil {
+		slog.Warn("feed: erro enviando mensagem", "type", string(m.Type), "seq", m.Seq, "symbol", m.Symbol, "error", err)
+	}
+	return packet
+}
+
+func (p *Publisher) feedLocked(symbol string) *symbolFeed {
+	feed, exists := p.symbols[symbol]
+	if !exists {
+		feed = &symbolFeed{
+			bids:    make(map[int64]Level),
+			asks:    make(map[int64]Level),
+			history: make([][]byte, p.cfg.Window),
+		}
+		p.symbols[symbol] = feed
+	}
+	return feed
+}
+
+// sortedLevels ordena os níveis por preço
+func sortedLevels(levels map[int64]Level, desc bool) []Level {
+	result := make([]Level, 0, len(levels))
+	for _, level := range levels {
+		result = append(result, level)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if desc {
+			return result[i].Price > result[j].Price
+		}
+		return result[i].Price < result[j].Price
+	})
+	return result
+}
@@ -0,0 +1,146 @@
+package feed
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// idleTimeout encerra conexões de recuperação sem pedidos
+const idleTimeout = time.Minute
+
+// Server atende, por TCP, pedidos de snapshot e de retransmissão de um
+// intervalo de sequências de um símbolo
+type Server struct {
+	publisher *Publisher
+
+	mutex     sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// NewServer cria o serviço de snapshot/retransmissão do feed
+func NewServer(publisher *Publisher) *Server {
+	return &Server{
+		publisher: publisher,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve aceita conexões do listener até Close
+func (s *Server) Serve(listener net.Listener) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return listener.Close()
+	}
+	s.listeners[listener] = struct{}{}
+	s.mutex.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			closed := s.closed
+			s.mutex.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// Close encerra listeners e conexões abertas
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	for listener := range s.listeners {
+		listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+// handle responde aos pedidos da conexão, um de cada vez
+func (s *Server) handle(conn net.Conn) {
+	s.mutex.Lock()
+	s.conns[conn] = struct{}{}
+	s.mutex.Unlock()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		conn.SetReadDeadline(time.Now().Add(idleTimeout))
+		req, err := readRequest(reader)
+		if err != nil {
+			return
+		}
+
+		if err := s.respond(writer, req); err != nil {
+			slog.Warn("feed: erro respondendo", "addr", conn.RemoteAddr().String(), "error", err)
+			return
+		}
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// respond escreve o snapshot, ou as mensagens retransmitidas seguidas de End
+func (s *Server) respond(writer *bufio.Writer, req request) error {
+	now := time.Now().UTC()
+
+	switch req.kind {
+	case requestSnapshot:
+		packet, err := Encode(s.publisher.Snapshot(req.symbol))
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(packet)
+		return err
+
+	case requestRetransmit:
+		if req.from == 0 || req.to < req.from {
+			return writeMessage(writer, &Message{Type: TypeReject, Timestamp: now, Symbol: req.symbol, Reason: RejectInvalidRequest})
+		}
+		packets, oldest, ok := s.publisher.Retransmit(req.symbol, req.from, req.to)
+		if !ok {
+			return writeMessage(writer, &Message{Type: TypeReject, Seq: oldest, Timestamp: now, Symbol: req.symbol, Reason: RejectOutOfWindow})
+		}
+		for _, packet := range packets {
+			if _, err := writer.Write(packet); err != nil {
+				return err
+			}
+		}
+		return writeMessage(writer, &Message{Type: TypeEnd, Seq: req.from + uint64(len(packets)) - 1, Timestamp: now, Symbol: req.symbol})
+
+	default:
+		return writeMessage(writer, &Message{Type: TypeReject, Timestamp: now, Symbol: req.symbol, Reason: RejectInvalidRequest})
+	}
+}
+
+func writeMessage(writer *bufio.Writer, m *Message) error {
+	packet, err := Encode(m)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(packet)
+	return err
+}
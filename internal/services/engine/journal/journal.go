@@ -0,0 +1,240 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"trading/internal/domain"
+)
+
+// CommandType identifica o comando registrado no journal
+type CommandType string
+
+const (
+	CommandNew    CommandType = "new"
+	CommandCancel CommandType = "cancel"
+	CommandAmend  CommandType = "amend"
+)
+
+// Erros de leitura do journal
+var (
+	ErrCorrupt  = errors.New("registro do journal corrompido")
+	ErrSequence = errors.New("sequência do journal fora de ordem")
+)
+
+// ErrFailed indica que uma gravação falhou e o arquivo não pôde voltar ao
+// último registro válido; o journal recusa novos comandos
+var ErrFailed = errors.New("journal inutilizável após falha de gravação")
+
+// crcTable é a tabela Castagnoli usada nos checksums
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Command é um comando recebido pelo engine, na ordem em que foi aplicado
+type Command struct {
+	Seq       uint64      `json:"seq"`
+	Type      CommandType `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+
+	// Order é a ordem recebida (new)
+	Order *domain.Order `json:"order,omitempty"`
+
+	// OrderID, Quantity e Price identificam e alteram uma ordem (cancel/amend)
+	OrderID  string  `json:"order_id,omitempty"`
+	Quantity int     `json:"quantity,omitempty"`
+	Price    float64 `json:"price,omitempty"`
+}
+
+// record é a linha gravada no arquivo: o comando e o CRC32 dos seus bytes
+type record struct {
+	Checksum uint32          `json:"crc32"`
+	Command  json.RawMessage `json:"command"`
+}
+
+// Journal é um arquivo append-only de comandos sequenciados
+type Journal struct {
+	path   string
+	file   *os.File
+	seq    uint64
+	size   int64
+	failed error
+	mutex  sync.Mutex
+}
+
+// Open abre (ou cria) o journal, valida os registros existentes e posiciona a
+// escrita no final. Um último registro incompleto (queda durante a escrita) é
+// descartado; qualquer outro registro inválido impede a abertura.
+func Open(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("abrindo journal %s: %w", path, err)
+	}
+
+	seq, valid, err := scan(file, nil)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("lendo journal %s: %w", path, err)
+	}
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("truncando journal %s: %w", path, err)
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &Journal{path: path, file: file, seq: seq, size: valid}, nil
+}
+
+// Append atribui a próxima sequência ao comando e o grava de forma durável
+// antes de retornar. Se a gravação falhar, o arquivo volta ao último registro
+// válido e a sequência não avança.
+func (j *Journal) Append(cmd Command) (Command, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.failed != nil {
+		return cmd, fmt.Errorf("%w: %v", ErrFailed, j.failed)
+	}
+
+	cmd.Seq = j.seq + 1
+	line, err := encode(cmd)
+	if err != nil {
+		return cmd, err
+	}
+
+	if _, err := j.file.Write(line); err != nil {
+		return cmd, j.rollback(fmt.Errorf("gravando journal: %w", err))
+	}
+	if err := j.file.Sync(); err != nil {
+		return cmd, j.rollback(fmt.Errorf("sincronizando journal: %w", err))
+	}
+
+	j.seq = cmd.Seq
+	j.size += int64(len(line))
+	return cmd, nil
+}
+
+// rollback descarta um registro parcialmente gravado, voltando o arquivo ao
+// fim do último registro válido. Se nem isso for possível, o journal passa a
+// recusar gravações: outro registro após o parcial impediria a reabertura.
+func (j *Journal) rollback(cause error) error {
+	if err := j.file.Truncate(j.size); err != nil {
+		j.failed = cause
+		return fmt.Errorf("%w: %v (truncando: %v)", ErrFailed, cause, err)
+	}
+	if _, err := j.file.Seek(j.size, io.SeekStart); err != nil {
+		j.failed = cause
+		return fmt.Errorf("%w: %v (posicionando: %v)", ErrFailed, cause, err)
+	}
+	return cause
+}
+
+// Replay entrega, em ordem, os comandos com sequência maior que after
+func (j *Journal) Replay(after uint64, apply func(cmd Command) error) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	file, err := os.Open(j.path)
+	if err != nil {
+		return fmt.Errorf("abrindo journal %s: %w", j.path, err)
+	}
+	defer file.Close()
+
+	_, _, err = scan(file, func(cmd Command) error {
+		if cmd.Seq <= after {
+			return nil
+		}
+		return apply(cmd)
+	})
+	return err
+}
+
+// Seq retorna a sequência do último comando gravado
+func (j *Journal) Seq() uint64 {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.seq
+}
+
+// Close fecha o arquivo do journal
+func (j *Journal) Close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.file.Close()
+}
+
+// encode serializa o comando como uma linha JSON com checksum
+func encode(cmd Command) ([]byte, error) {
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("serializando comando: %w", err)
+	}
+
+	line, err := json.Marshal(record{Checksum: crc32.Checksum(payload, crcTable), Command: payload})
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// scan lê os registros do início do arquivo verificando checksum e sequência.
+// Retorna a última sequência e o tamanho da parte válida do arquivo.
+func scan(r io.Reader, apply func(cmd Command) error) (uint64, int64, error) {
+	reader := bufio.NewReader(r)
+
+	var seq uint64
+	var valid int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Sem '\n' final: registro incompleto, descartado
+			return seq, valid, nil
+		}
+		if err != nil {
+			return seq, valid, err
+		}
+
+		cmd, err := decode(bytes.TrimSuffix(line, []byte{'\n'}))
+		if err != nil {
+			return seq, valid, fmt.Errorf("registro após seq %d: %w", seq, err)
+		}
+		if cmd.Seq != seq+1 {
+			return seq, valid, fmt.Errorf("%w: esperado %d, obtido %d", ErrSequence, seq+1, cmd.Seq)
+		}
+
+		if apply != nil {
+			if err := apply(cmd); err != nil {
+				return seq, valid, fmt.Errorf("aplicando seq %d: %w", cmd.Seq, err)
+			}
+		}
+
+		seq = cmd.Seq
+		valid += int64(len(line))
+	}
+}
+
+// decode valida o checksum e decodifica o comando de uma linha
+func decode(line []byte) (Command, error) {
+	var rec record
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return Command{}, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if crc32.Checksum(rec.Command, crcTable) != rec.Checksum {
+		return Command{}, fmt.Errorf("%w: checksum não confere", ErrCorrupt)
+	}
+
+	var cmd Command
+	if err := json.Unmarshal(rec.Command, &cmd); err != nil {
+		return Command{}, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return cmd, nil
+}
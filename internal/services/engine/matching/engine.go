@@ -1,12 +1,72 @@
 package matching
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
 	"trading/internal/domain"
+	"trading/internal/services/engine/journal"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/engine/portfolio"
+	"trading/internal/services/engine/repository"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/tracing"
+)
+
+// Status possíveis de um MatchResult
+const (
+	StatusFilled   = "filled"
+	StatusPartial  = "partial"
+	StatusPending  = "pending"
+	StatusRejected = "rejected"
 )
 
 // Service implementa o motor de correspondência
 type Service struct {
-	// TODO: Implementar campos necessários
+	books      *orderbook.Manager
+	portfolios *portfolio.Service
+
+	symbolLocks map[string]*sync.Mutex
+	locksMutex  sync.Mutex
+
+	orders repository.OrderRepository
+	trades repository.TradeRepository
+
+	publisher events.Publisher
+
+	journal   Journal
+	sequencer sync.Mutex
+
+	// pause é adquirido em leitura por cada comando e em escrita por
+	// snapshots e restaurações, que precisam do engine parado
+	pause     sync.RWMutex
+	restoring bool
+
+	counters *counters
+
+	// clientOrders torna idempotente o reenvio de um client_order_id
+	clientOrders *clientOrders
+}
+
+// Journal registra os comandos recebidos antes de serem aplicados
+type Journal interface {
+	Append(cmd journal.Command) (journal.Command, error)
+	Seq() uint64
+}
+
+// State é uma fotografia consistente do engine, marcada com a sequência do
+// último comando do journal já aplicado. O histórico de negociações não faz
+// parte dela: fica no repositório de negociações.
+type State struct {
+	Seq        uint64                 `json:"seq"`
+	CreatedAt  time.Time              `json:"created_at"`
+	Sequences  domain.IDSequences     `json:"sequences"`
+	Books      []*orderbook.OrderBook `json:"books"`
+	Portfolios []*domain.Portfolio    `json:"portfolios"`
 }
 
 // MatchResult representa o resultado de uma operação de matching
@@ -17,28 +77,590 @@ type MatchResult struct {
 	Message  string          `json:"message"`
 	Rejected bool            `json:"rejected,omitempty"`
 	Reason   string          `json:"reason,omitempty"`
+
+	// Replayed indica o resultado original devolvido a um client_order_id
+	// repetido
+	Replayed bool `json:"replayed,omitempty"`
+
+	// Err guarda o erro de domínio que causou a rejeição
+	Err error `json:"-"`
 }
 
 // NewService cria um novo serviço de matching
-func NewService() *Service {
+func NewService(books *orderbook.Manager, portfolios *portfolio.Service) *Service {
+	store := repository.NewMemoryStore()
 	return &Service{
-		// TODO: Inicializar campos
+		books:        books,
+		portfolios:   portfolios,
+		orders:       store,
+		trades:       store,
+		symbolLocks:  make(map[string]*sync.Mutex),
+		counters:     newCounters(),
+		clientOrders: newClientOrders(),
 	}
 }
 
-// ProcessOrder processa uma ordem através do matching engine
-func (s *Service) ProcessOrder(order *domain.Order) *MatchResult {
-	// TODO: Implementar lógica de matching
-	// 1. Validar ordem
-	// 2. Buscar correspondências no order book
-	// 3. Executar trades
-	// 4. Atualizar portfolios
-	// 5. Retornar resultado
+// SetIdempotencyWindow define por quanto tempo um client_order_id repetido
+// devolve o resultado original (padrão: DefaultIdempotencyWindow)
+func (s *Service) SetIdempotencyWindow(window time.Duration) {
+	s.clientOrders.setWindow(window)
+}
+
+// SetRepositories troca os repositórios de ordens e negociações (padrão: memória)
+func (s *Service) SetRepositories(orders repository.OrderRepository, trades repository.TradeRepository) {
+	s.orders = orders
+	s.trades = trades
+}
+
+// ProcessOrder processa uma ordem através do matching engine. O contexto
+// carrega o request ID usado nos logs do engine e do portfolio. Uma ordem com
+// client_order_id já enviado dentro da janela não é processada de novo:
+// recebe o resultado original ou, com outros parâmetros, é rejeitada com
+// domain.ErrClientOrderIDConflict.
+func (s *Service) ProcessOrder(ctx context.Context, order *domain.Order) *MatchResult {
+	ctx, span := tracing.Start(ctx, "matching.ProcessOrder", tracing.OrderAttrs(order)...)
+	defer span.End()
+
+	if order.ClientOrderID != "" {
+		previous, entry := s.clientOrders.find(order, true)
+		if previous != nil {
+			s.logReplay(ctx, order, previous)
+			span.SetAttributes(slog.Bool("order.replayed", previous.Replayed))
+			span.RecordError(previous.Err)
+			return previous
+		}
+		defer s.clientOrders.release(entry)
+	}
+
+	started := time.Now()
+	var result *MatchResult
+	cmd := journal.Command{Type: journal.CommandNew, Order: order.Clone()}
+	err := s.submit(cmd, func(at time.Time) {
+		result = s.processOrder(ctx, order, at)
+		s.clientOrders.record(order, result, at)
+	})
+	if err != nil {
+		result = Reject(order, err)
+	}
+	elapsed := time.Since(started)
+	s.counters.observe(result, elapsed)
+
+	attrs := []any{"order_id", result.Order.ID, "user_id", result.Order.UserID, "symbol", result.Order.Symbol,
+		"side", result.Order.Side, "quantity", result.Order.Quantity, "price", result.Order.Price,
+		"status", result.Status, "trades", len(result.Trades), "duration_ms", float64(elapsed) / float64(time.Millisecond)}
+	if result.Rejected {
+		attrs = append(attrs, "reason", result.Reason)
+	}
+	slog.InfoContext(ctx, "ordem processada", attrs...)
+
+	span.SetAttributes(slog.String("order.status", result.Status), slog.Int("order.trades", len(result.Trades)))
+	span.RecordError(result.Err)
+	return result
+}
+
+// Recall retorna o resultado de uma ordem já enviada com o mesmo
+// client_order_id dentro da janela (ou a rejeição por conflito de
+// parâmetros), sem processar nada. ok é false para um client_order_id novo.
+func (s *Service) Recall(ctx context.Context, order *domain.Order) (*MatchResult, bool) {
+	if order.ClientOrderID == "" {
+		return nil, false
+	}
+	previous, _ := s.clientOrders.find(order, false)
+	if previous == nil {
+		return nil, false
+	}
+	s.logReplay(ctx, order, previous)
+	return previous, true
+}
+
+// logReplay registra o reenvio de um client_order_id
+func (s *Service) logReplay(ctx context.Context, order *domain.Order, result *MatchResult) {
+	if result.Replayed {
+		slog.InfoContext(ctx, "ordem repetida", "client_order_id", order.ClientOrderID, "user_id", order.UserID,
+			"order_id", result.Order.ID, "status", result.Status)
+		return
+	}
+	slog.InfoContext(ctx, "client_order_id em conflito", "client_order_id", order.ClientOrderID, "user_id", order.UserID)
+}
+
+// CancelOrder remove uma ordem aberta do livro e libera sua reserva
+func (s *Service) CancelOrder(ctx context.Context, orderID string) (*domain.Order, error) {
+	var cancelled *domain.Order
+	var err error
+	cmd := journal.Command{Type: journal.CommandCancel, OrderID: orderID}
+	if journalErr := s.submit(cmd, func(at time.Time) { cancelled, err = s.cancelOrder(orderID, at) }); journalErr != nil {
+		return nil, journalErr
+	}
+	if err != nil {
+		slog.InfoContext(ctx, "cancelamento recusado", "order_id", orderID, "error", err)
+	} else {
+		slog.InfoContext(ctx, "ordem cancelada", "order_id", orderID, "user_id", cancelled.UserID, "symbol", cancelled.Symbol)
+	}
+	return cancelled, err
+}
+
+// AmendOrder altera quantidade e preço de uma ordem aberta. A ordem mantém o
+// ID e a quantidade já executada, mas perde a prioridade no livro; se o novo
+// preço cruzar o livro, ela é executada imediatamente.
+func (s *Service) AmendOrder(ctx context.Context, orderID string, quantity int, price float64) (*MatchResult, error) {
+	ctx, span := tracing.Start(ctx, "matching.AmendOrder", slog.String("order.id", orderID),
+		slog.Int("order.quantity", quantity), slog.Float64("order.price", price))
+	defer span.End()
+
+	var result *MatchResult
+	var err error
+	cmd := journal.Command{Type: journal.CommandAmend, OrderID: orderID, Quantity: quantity, Price: price}
+	if journalErr := s.submit(cmd, func(at time.Time) { result, err = s.amendOrder(ctx, orderID, quantity, price, at) }); journalErr != nil {
+		return nil, journalErr
+	}
+	if err != nil {
+		span.RecordError(err)
+		slog.InfoContext(ctx, "alteração recusada", "order_id", orderID, "quantity", quantity, "price", price, "error", err)
+		return nil, err
+	}
+	s.counters.observeTrades(result)
+	slog.InfoContext(ctx, "ordem alterada", "order_id", orderID, "quantity", quantity, "price", price,
+		"status", result.Status, "trades", len(result.Trades))
+	return result, nil
+}
+
+// SetJournal passa a registrar cada comando no journal antes de aplicá-lo
+func (s *Service) SetJournal(j Journal) {
+	s.journal = j
+}
+
+// Replay reaplica, em ordem, os comandos do journal com sequência maior que
+// after, reconstruindo livros, reservas, portfolios e negociações
+func (s *Service) Replay(source *journal.Journal, after uint64) (int, error) {
+	s.pause.Lock()
+	defer s.pause.Unlock()
+
+	return s.replayLocked(source, after)
+}
+
+// Capture tira uma fotografia consistente do engine. Os comandos ficam
+// suspensos enquanto os livros, portfolios e geradores de ID são copiados.
+func (s *Service) Capture() *State {
+	s.pause.Lock()
+	defer s.pause.Unlock()
+
+	state := &State{
+		CreatedAt:  time.Now().UTC(),
+		Sequences:  domain.Sequences(),
+		Books:      s.books.Snapshot(),
+		Portfolios: s.portfolios.Snapshot(),
+	}
+	if s.journal != nil {
+		state.Seq = s.journal.Seq()
+	}
+	return state
+}
+
+// Restore carrega o estado de um snapshot (nil parte do estado inicial) e
+// reaplica os comandos do journal posteriores a ele, quando informado. Só
+// livros, portfolios e geradores de ID são restaurados; as negociações
+// anteriores ao snapshot continuam no repositório de negociações. Os
+// eventos dos comandos reaplicados não são publicados; ao final, cada livro
+// afetado publica BookChanged para que os consumidores se atualizem.
+func (s *Service) Restore(state *State, tail *journal.Journal) (int, error) {
+	s.pause.Lock()
+	defer s.pause.Unlock()
+
+	s.clientOrders.reset()
+	symbols := map[string]bool{}
+	for _, book := range s.books.Snapshot() {
+		symbols[book.Symbol] = true
+	}
+
+	after := uint64(0)
+	if state != nil {
+		after = state.Seq
+		domain.RestoreSequences(state.Sequences)
+		s.books.Restore(state.Books)
+
+		var resting []*domain.Order
+		for _, book := range state.Books {
+			symbols[book.Symbol] = true
+			resting = append(append(resting, book.Bids...), book.Asks...)
+		}
+		s.portfolios.Restore(state.Portfolios, resting)
+
+		s.saveOrders(resting...)
+	}
+
+	applied := 0
+	if tail != nil {
+		s.restoring = true
+		s.portfolios.Mute(true)
+		var err error
+		applied, err = s.replayLocked(tail, after)
+		s.portfolios.Mute(false)
+		s.restoring = false
+		if err != nil {
+			return applied, err
+		}
+	}
+
+	for _, book := range s.books.Snapshot() {
+		symbols[book.Symbol] = true
+	}
+	now := time.Now().UTC()
+	for symbol := range symbols {
+		s.publish(events.BookChanged{Symbol: symbol, Timestamp: now})
+	}
+
+	return applied, nil
+}
+
+// RestoreOpenOrders recoloca nos livros as ordens PENDING e PARTIAL gravadas
+// no repositório de ordens, na ordem de chegada, e recria suas reservas. É a
+// recuperação quando o banco é a única fonte do estado (sem journal nem
+// snapshot); os portfolios devem ter sido carregados antes.
+func (s *Service) RestoreOpenOrders() (int, error) {
+	s.pause.Lock()
+	defer s.pause.Unlock()
+
+	var resting []*domain.Order
+	for _, status := range []domain.OrderStatus{domain.PENDING, domain.PARTIAL} {
+		orders, err := s.orders.ListOrders(repository.OrderFilter{Status: status})
+		if err != nil {
+			return 0, err
+		}
+		resting = append(resting, orders...)
+	}
+	sort.SliceStable(resting, func(i, j int) bool {
+		return resting[i].CreatedAt.Before(resting[j].CreatedAt)
+	})
+
+	symbols := map[string]bool{}
+	for _, order := range resting {
+		domain.ObserveOrderID(order.ID)
+		s.books.AddOrder(order)
+		symbols[order.Symbol] = true
+	}
+	s.portfolios.RestoreReservations(resting)
+
+	now := time.Now().UTC()
+	for symbol := range symbols {
+		s.publish(events.BookChanged{Symbol: symbol, Timestamp: now})
+	}
+	return len(resting), nil
+}
+
+// replayLocked aplica os comandos do journal; o chamador deve ter o lock de pausa
+func (s *Service) replayLocked(source *journal.Journal, after uint64) (int, error) {
+	applied := 0
+	err := source.Replay(after, func(cmd journal.Command) error {
+		applied++
+		return s.apply(cmd)
+	})
+	return applied, err
+}
+
+// submit registra o comando no journal, se habilitado, e o aplica com o
+// timestamp gravado. Com journal os comandos são aplicados em série, na ordem
+// da sequência, para que o replay reproduza exatamente o mesmo estado.
+func (s *Service) submit(cmd journal.Command, apply func(at time.Time)) error {
+	if s.journal == nil {
+		s.pause.RLock()
+		defer s.pause.RUnlock()
+
+		apply(time.Now().UTC())
+		return nil
+	}
+
+	s.sequencer.Lock()
+	defer s.sequencer.Unlock()
+	s.pause.RLock()
+	defer s.pause.RUnlock()
+
+	cmd.Timestamp = time.Now().UTC()
+	cmd, err := s.journal.Append(cmd)
+	if err != nil {
+		return err
+	}
+
+	apply(cmd.Timestamp)
+	return nil
+}
+
+// apply executa um comando lido do journal. Erros de negócio (ex.: cancelar
+// uma ordem já executada) se repetem no replay e não interrompem a leitura.
+func (s *Service) apply(cmd journal.Command) error {
+	switch cmd.Type {
+	case journal.CommandNew:
+		if cmd.Order == nil {
+			return domain.ErrInvalidOrder
+		}
+		domain.ObserveOrderID(cmd.Order.ID)
+		result := s.processOrder(context.Background(), cmd.Order, cmd.Timestamp)
+		s.clientOrders.record(cmd.Order, result, cmd.Timestamp)
+	case journal.CommandCancel:
+		_, _ = s.cancelOrder(cmd.OrderID, cmd.Timestamp)
+	case journal.CommandAmend:
+		_, _ = s.amendOrder(context.Background(), cmd.OrderID, cmd.Quantity, cmd.Price, cmd.Timestamp)
+	default:
+		return fmt.Errorf("comando desconhecido %q", cmd.Type)
+	}
+	return nil
+}
+
+// processOrder reserva saldo/posição, executa contra o livro e coloca o restante em repouso
+func (s *Service) processOrder(ctx context.Context, order *domain.Order, at time.Time) *MatchResult {
+	lock := s.symbolLock(order.Symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Compromete saldo/posição antes de tocar no livro
+	if err := s.portfolios.ReserveOrder(ctx, order); err != nil {
+		result := Reject(order, err)
+		s.saveOrders(result.Order)
+		s.publish(events.OrderRejected{Order: result.Order, Reason: err, Timestamp: at})
+		return result
+	}
+	s.publish(events.OrderAccepted{Order: order.Clone(), Timestamp: at})
+
+	trades := s.match(ctx, order, at)
+	if !order.IsComplete() {
+		s.books.AddOrder(order)
+	}
+
+	s.saveOrders(order)
+	s.recordTrades(trades)
+	s.publish(events.BookChanged{Symbol: order.Symbol, Timestamp: at})
+
+	return newResult(order, trades)
+}
+
+// cancelOrder remove a ordem do livro e libera a reserva
+func (s *Service) cancelOrder(orderID string, at time.Time) (*domain.Order, error) {
+	resting := s.books.FindOrder(orderID)
+	if resting == nil {
+		return nil, s.closedOrderError(orderID)
+	}
+
+	lock := s.symbolLock(resting.Symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// A ordem pode ter sido executada antes de o lock ser adquirido
+	order := s.books.RemoveOrder(orderID)
+	if order == nil {
+		return nil, s.closedOrderError(orderID)
+	}
+
+	s.portfolios.ReleaseOrder(order)
+	order.Status = domain.CANCELLED
+	order.UpdatedAt = at
+
+	cancelled := order.Clone()
+	s.saveOrders(cancelled)
+	s.publish(events.OrderCancelled{Order: cancelled, Timestamp: at})
+	s.publish(events.BookChanged{Symbol: order.Symbol, Timestamp: at})
+
+	return cancelled, nil
+}
+
+// amendOrder troca a reserva da ordem pela dos novos parâmetros e a reenvia ao livro
+func (s *Service) amendOrder(ctx context.Context, orderID string, quantity int, price float64, at time.Time) (*MatchResult, error) {
+	resting := s.books.FindOrder(orderID)
+	if resting == nil {
+		return nil, s.closedOrderError(orderID)
+	}
+
+	lock := s.symbolLock(resting.Symbol)
+	lock.Lock()
+	defer lock.Unlock()
+
+	current := s.books.FindOrder(orderID)
+	switch {
+	case current == nil:
+		return nil, s.closedOrderError(orderID)
+	case price <= 0:
+		return nil, domain.ErrInvalidPrice
+	case quantity <= current.FilledQuantity():
+		// A nova quantidade precisa deixar algo a executar
+		return nil, domain.ErrInvalidQuantity
+	}
+
+	amended := current.Clone()
+	amended.Quantity = quantity
+	amended.Price = price
+	amended.RemainingQuantity = quantity - current.FilledQuantity()
+	amended.UpdatedAt = at
+
+	// Troca atômica: recusada, a ordem continua no livro com a reserva original
+	if err := s.portfolios.ReplaceReservation(ctx, current, amended); err != nil {
+		return nil, err
+	}
+
+	s.books.RemoveOrder(orderID)
+	s.publish(events.OrderAccepted{Order: amended.Clone(), Timestamp: at})
+
+	trades := s.match(ctx, amended, at)
+	if !amended.IsComplete() {
+		s.books.AddOrder(amended)
+	}
+
+	s.saveOrders(amended)
+	s.recordTrades(trades)
+	s.publish(events.BookChanged{Symbol: amended.Symbol, Timestamp: at})
+
+	return newResult(amended, trades), nil
+}
+
+// match executa a ordem contra o lado oposto do livro enquanto houver preço
+// compatível; o chamador deve ter o lock do símbolo
+func (s *Service) match(ctx context.Context, order *domain.Order, at time.Time) []*domain.Trade {
+	trades := []*domain.Trade{}
+	for !order.IsComplete() {
+		match := s.books.FindBestMatch(order)
+		if match == nil {
+			break
+		}
+
+		quantity := min(order.RemainingQuantity, match.RemainingQuantity)
+		buyOrder, sellOrder := order, match
+		if order.Side == domain.SELL {
+			buyOrder, sellOrder = match, order
+		}
+
+		// Executa ao preço da ordem que já estava no livro
+		trade := domain.NewTradeAt(buyOrder, sellOrder, quantity, match.Price, at)
+		if err := s.portfolios.ExecuteTrade(ctx, trade); err != nil {
+			// Não deveria ocorrer com as reservas; interrompe sem corromper o livro
+			slog.ErrorContext(ctx, "erro ao liquidar negociação", "order_id", order.ID, "match_id", match.ID, "error", err)
+			break
+		}
+
+		order.FillAt(quantity, at)
+		s.books.FillOrder(match, quantity, at)
+		s.saveOrders(match)
+		trades = append(trades, trade)
+		slog.DebugContext(ctx, "negociação executada", "trade_id", trade.ID, "symbol", trade.Symbol,
+			"quantity", trade.Quantity, "price", trade.Price, "buy_order_id", trade.BuyOrderID, "sell_order_id", trade.SellOrderID)
+
+		executed := events.TradeExecuted{Trade: trade, BuyOrder: order.Clone(), SellOrder: match.Clone()}
+		if order.Side == domain.SELL {
+			executed.BuyOrder, executed.SellOrder = executed.SellOrder, executed.BuyOrder
+		}
+		s.publish(executed)
+	}
+	return trades
+}
+
+// newResult monta o MatchResult de uma ordem aceita
+func newResult(order *domain.Order, trades []*domain.Trade) *MatchResult {
+	result := &MatchResult{
+		Order:  order.Clone(),
+		Trades: trades,
+	}
+
+	switch {
+	case order.IsComplete():
+		result.Status = StatusFilled
+		result.Message = "Ordem executada totalmente"
+	case len(trades) > 0:
+		result.Status = StatusPartial
+		result.Message = "Ordem executada parcialmente, restante adicionado ao livro"
+	default:
+		result.Status = StatusPending
+		result.Message = "Ordem adicionada ao livro"
+	}
+
+	return result
+}
+
+// Reject monta o resultado de uma ordem rejeitada
+func Reject(order *domain.Order, err error) *MatchResult {
+	order.Status = domain.REJECTED
 
 	return &MatchResult{
-		Order:   order,
-		Trades:  []*domain.Trade{},
-		Status:  "pending",
-		Message: "TODO: Implementar matching engine",
+		Order:    order.Clone(),
+		Trades:   []*domain.Trade{},
+		Status:   StatusRejected,
+		Message:  "Ordem rejeitada",
+		Rejected: true,
+		Reason:   err.Error(),
+		Err:      err,
+	}
+}
+
+// GetTrades retorna as negociações executadas
+func (s *Service) GetTrades() []*domain.Trade {
+	trades, err := s.trades.ListTrades(repository.TradeFilter{})
+	if err != nil {
+		slog.Error("erro ao consultar negociações", "error", err)
+	}
+	return trades
+}
+
+// ListTrades consulta o histórico de negociações
+func (s *Service) ListTrades(filter repository.TradeFilter) ([]*domain.Trade, error) {
+	return s.trades.ListTrades(filter)
+}
+
+// GetOrder retorna o último estado conhecido de uma ordem
+func (s *Service) GetOrder(orderID string) (*domain.Order, error) {
+	return s.orders.GetOrder(orderID)
+}
+
+// ListOrders consulta o histórico de ordens
+func (s *Service) ListOrders(filter repository.OrderFilter) ([]*domain.Order, error) {
+	return s.orders.ListOrders(filter)
+}
+
+// SetPublisher define onde os eventos do engine são publicados
+func (s *Service) SetPublisher(publisher events.Publisher) {
+	s.publisher = publisher
+}
+
+// publish envia um evento ao barramento. É chamado com o lock do símbolo
+// adquirido, o que garante a ordem dos eventos por símbolo.
+func (s *Service) publish(event events.Event) {
+	if s.publisher != nil && !s.restoring {
+		s.publisher.Publish(event)
+	}
+}
+
+// recordTrades grava as negociações no repositório. Falhas não desfazem a
+// execução em memória: são registradas e contadas em Stats.PersistenceErrors.
+func (s *Service) recordTrades(trades []*domain.Trade) {
+	for _, trade := range trades {
+		if err := s.trades.SaveTrade(trade); err != nil {
+			s.counters.persistence.Add(1)
+			slog.Error("erro ao gravar negociação", "trade_id", trade.ID, "error", err)
+		}
+	}
+}
+
+// saveOrders grava o estado atual das ordens no repositório
+func (s *Service) saveOrders(orders ...*domain.Order) {
+	for _, order := range orders {
+		if err := s.orders.SaveOrder(order); err != nil {
+			s.counters.persistence.Add(1)
+			slog.Error("erro ao gravar ordem", "order_id", order.ID, "error", err)
+		}
+	}
+}
+
+// closedOrderError diferencia uma ordem inexistente de uma já encerrada
+func (s *Service) closedOrderError(orderID string) error {
+	if _, err := s.orders.GetOrder(orderID); err == nil {
+		return domain.ErrOrderNotOpen
+	}
+	return domain.ErrOrderNotFound
+}
+
+// symbolLock retorna o lock exclusivo de um símbolo
+func (s *Service) symbolLock(symbol string) *sync.Mutex {
+	s.locksMutex.Lock()
+	defer s.locksMutex.Unlock()
+
+	lock, exists := s.symbolLocks[symbol]
+	if !exists {
+		lock = &sync.Mutex{}
+		s.symbolLocks[symbol] = lock
 	}
+	return lock
 }
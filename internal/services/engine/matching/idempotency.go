@@ -0,0 +1,160 @@
+package matching
+
+import (
+	"sync"
+	"time"
+
+	"trading/internal/domain"
+)
+
+// DefaultIdempotencyWindow é por quanto tempo um client_order_id repetido
+// devolve o resultado da ordem original
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// clientOrderKey identifica uma ordem pelo client_order_id, único por usuário
+type clientOrderKey struct {
+	userID        string
+	clientOrderID string
+}
+
+// clientOrder é a primeira submissão de um client_order_id. Enquanto o
+// resultado não existe (pending), reenvios concorrentes aguardam done.
+type clientOrder struct {
+	order   *domain.Order
+	result  *MatchResult
+	at      time.Time
+	pending bool
+	done    chan struct{}
+}
+
+// clientOrders guarda os resultados por client_order_id dentro da janela.
+// O conteúdo é reconstruído pelo replay do journal.
+type clientOrders struct {
+	window    time.Duration
+	entries   map[clientOrderKey]*clientOrder
+	lastSweep time.Time
+	mutex     sync.Mutex
+}
+
+// newClientOrders cria o registro vazio com a janela padrão
+func newClientOrders() *clientOrders {
+	return &clientOrders{
+		window:  DefaultIdempotencyWindow,
+		entries: make(map[clientOrderKey]*clientOrder),
+	}
+}
+
+func keyOf(order *domain.Order) clientOrderKey {
+	return clientOrderKey{userID: order.UserID, clientOrderID: order.ClientOrderID}
+}
+
+// find retorna o resultado de uma submissão anterior do client_order_id,
+// aguardando a que estiver em andamento. Com claim, uma chave nova fica
+// reservada para a ordem e a entrada é retornada para release.
+func (c *clientOrders) find(order *domain.Order, claim bool) (*MatchResult, *clientOrder) {
+	key := keyOf(order)
+	for {
+		c.mutex.Lock()
+		now := time.Now()
+		c.sweepLocked(now)
+
+		entry, exists := c.entries[key]
+		if exists && !entry.pending && now.Sub(entry.at) > c.window {
+			delete(c.entries, key)
+			exists = false
+		}
+		switch {
+		case exists && entry.pending:
+			c.mutex.Unlock()
+			<-entry.done
+			continue
+		case exists:
+			c.mutex.Unlock()
+			return replay(entry, order), nil
+		case !claim:
+			c.mutex.Unlock()
+			return nil, nil
+		}
+
+		entry = &clientOrder{order: order.Clone(), pending: true, done: make(chan struct{})}
+		c.entries[key] = entry
+		c.mutex.Unlock()
+		return nil, entry
+	}
+}
+
+// record guarda o resultado da ordem processada no instante at, ao vivo ou
+// no replay do journal
+func (c *clientOrders) record(order *domain.Order, result *MatchResult, at time.Time) {
+	if order.ClientOrderID == "" {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, exists := c.entries[keyOf(order)]
+	if !exists {
+		entry = &clientOrder{order: order.Clone(), done: make(chan struct{})}
+		close(entry.done)
+		c.entries[keyOf(order)] = entry
+	}
+	entry.result = result
+	entry.at = at
+}
+
+// release libera os reenvios que aguardam a entrada. Sem resultado (o
+// comando não chegou ao journal), a chave volta a ficar livre.
+func (c *clientOrders) release(entry *clientOrder) {
+	c.mutex.Lock()
+	entry.pending = false
+	key := keyOf(entry.order)
+	if entry.result == nil && c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+	c.mutex.Unlock()
+	close(entry.done)
+}
+
+// setWindow troca a janela; vale também para as entradas já guardadas
+func (c *clientOrders) setWindow(window time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.window = window
+}
+
+// reset descarta todos os resultados (o estado do engine foi substituído)
+func (c *clientOrders) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key, entry := range c.entries {
+		if !entry.pending {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// sweepLocked descarta, no máximo uma vez por minuto, as entradas fora da
+// janela; o chamador deve ter o lock
+func (c *clientOrders) sweepLocked(now time.Time) {
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = now
+	for key, entry := range c.entries {
+		if !entry.pending && now.Sub(entry.at) > c.window {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// replay devolve uma cópia do resultado original, ou a rejeição por conflito
+// se a ordem repetida tiver outros parâmetros
+func replay(entry *clientOrder, order *domain.Order) *MatchResult {
+	if !entry.order.SameParams(order) {
+		return Reject(order, domain.ErrClientOrderIDConflict)
+	}
+	result := *entry.result
+	result.Order = entry.result.Order.Clone()
+	result.Replayed = true
+	return &result
+}
@@ -0,0 +1,87 @@
+package matching
+
+import (
+	"sync/atomic"
+	"time"
+
+	"trading/internal/services/engine/portfolio"
+	"trading/internal/services/shared/stats"
+)
+
+// Stats resume a atividade do engine desde a partida. Received e Rejected
+// incluem as ordens barradas por portfolio.ValidateOrder antes do matching.
+type Stats struct {
+	Received    uint64                  `json:"received"`
+	Accepted    uint64                  `json:"accepted"`
+	Rejected    map[error]uint64        `json:"-"`
+	Trades      map[string]stats.Total  `json:"trades"`
+	Latency     stats.HistogramSnapshot `json:"latency"`
+	Resting     map[string]int          `json:"resting"`
+	ActiveUsers int                     `json:"active_users"`
+	// PersistenceErrors conta as gravações de ordens, negociações e
+	// portfolios que falharam no repositório
+	PersistenceErrors uint64 `json:"persistence_errors"`
+}
+
+// counters acumula, sem locks, os comandos recebidos ao vivo (o replay do
+// journal não é contado)
+type counters struct {
+	received atomic.Uint64
+	accepted atomic.Uint64
+	rejected *stats.ErrorCounter
+	trades   stats.KeyedTotals
+	latency  *stats.Histogram
+	users    stats.Set
+	// persistence conta as gravações recusadas pelo repositório
+	persistence atomic.Uint64
+}
+
+// newCounters cria os contadores zerados
+func newCounters() *counters {
+	return &counters{
+		rejected: stats.NewErrorCounter(portfolio.RejectionReasons...),
+		latency:  stats.NewHistogram(stats.LatencyBuckets),
+	}
+}
+
+// observe registra uma ordem nova e o tempo gasto para processá-la
+func (c *counters) observe(result *MatchResult, elapsed time.Duration) {
+	c.received.Add(1)
+	c.latency.Observe(elapsed)
+	c.users.Add(result.Order.UserID)
+	if result.Rejected {
+		c.rejected.Inc(result.Err)
+		return
+	}
+	c.accepted.Add(1)
+	c.observeTrades(result)
+}
+
+// observeTrades soma as negociações do resultado por símbolo
+func (c *counters) observeTrades(result *MatchResult) {
+	for _, trade := range result.Trades {
+		c.trades.Add(trade.Symbol, trade.Value)
+	}
+}
+
+// Stats retorna os contadores do engine e as ordens em repouso em cada livro
+func (s *Service) Stats() Stats {
+	rejected := s.counters.rejected.Snapshot()
+	received := s.counters.received.Load()
+	for reason, count := range s.portfolios.Rejections() {
+		rejected[reason] += count
+		received += count
+	}
+
+	return Stats{
+		Received:    received,
+		Accepted:    s.counters.accepted.Load(),
+		Rejected:    rejected,
+		Trades:      s.counters.trades.Snapshot(),
+		Latency:     s.counters.latency.Snapshot(),
+		Resting:     s.books.RestingOrders(),
+		ActiveUsers: s.counters.users.Len(),
+
+		PersistenceErrors: s.counters.persistence.Load() + s.portfolios.PersistenceErrors(),
+	}
+}
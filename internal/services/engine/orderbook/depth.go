@@ -0,0 +1,136 @@
+package orderbook
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"trading/internal/domain"
+)
+
+// PriceLevel representa a quantidade agregada em um nível de preço
+type PriceLevel struct {
+	Price    float64 `json:"price"`
+	Quantity int     `json:"quantity"`
+	Orders   int     `json:"orders"`
+}
+
+// Depth representa a visão agregada (L2) de um livro de ofertas
+type Depth struct {
+	Symbol    string       `json:"symbol"`
+	Bids      []PriceLevel `json:"bids"` // Preço decrescente
+	Asks      []PriceLevel `json:"asks"` // Preço crescente
+	BestBid   *float64     `json:"best_bid"`
+	BestAsk   *float64     `json:"best_ask"`
+	Spread    *float64     `json:"spread"`
+	MidPrice  *float64     `json:"mid_price"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// GetDepth retorna o livro agregado por nível de preço, limitado a depth
+// níveis por lado (depth <= 0 retorna todos os níveis)
+func (s *Manager) GetDepth(symbol string, depth int) *Depth {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := &Depth{
+		Symbol:    symbol,
+		Bids:      []PriceLevel{},
+		Asks:      []PriceLevel{},
+		Timestamp: time.Now().UTC(),
+	}
+
+	book, exists := s.books[symbol]
+	if !exists {
+		return result
+	}
+
+	result.Bids = aggregate(book.Bids, depth)
+	result.Asks = aggregate(book.Asks, depth)
+
+	if len(result.Bids) > 0 {
+		result.BestBid = &result.Bids[0].Price
+	}
+	if len(result.Asks) > 0 {
+		result.BestAsk = &result.Asks[0].Price
+	}
+	if result.BestBid != nil && result.BestAsk != nil {
+		spread := roundPrice(*result.BestAsk - *result.BestBid)
+		mid := roundPrice((*result.BestAsk + *result.BestBid) / 2)
+		result.Spread = &spread
+		result.MidPrice = &mid
+	}
+
+	return result
+}
+
+// aggregate agrupa ordens já ordenadas em níveis de preço
+func aggregate(orders []*domain.Order, depth int) []PriceLevel {
+	levels := []PriceLevel{}
+
+	for _, order := range orders {
+		last := len(levels) - 1
+		if last >= 0 && levels[last].Price == order.Price {
+			levels[last].Quantity += order.RemainingQuantity
+			levels[last].Orders++
+			continue
+		}
+
+		if depth > 0 && len(levels) == depth {
+			break
+		}
+		levels = append(levels, PriceLevel{
+			Price:    order.Price,
+			Quantity: order.RemainingQuantity,
+			Orders:   1,
+		})
+	}
+
+	return levels
+}
+
+// SideSummary resume um lado do livro
+type SideSummary struct {
+	Orders   int `json:"orders"`
+	Levels   int `json:"levels"`
+	Quantity int `json:"quantity"`
+}
+
+// BookSummary resume a profundidade de um livro
+type BookSummary struct {
+	Symbol string      `json:"symbol"`
+	Bids   SideSummary `json:"bids"`
+	Asks   SideSummary `json:"asks"`
+}
+
+// Summaries retorna o resumo de cada livro, ordenado por símbolo
+func (s *Manager) Summaries() []BookSummary {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	summaries := make([]BookSummary, 0, len(s.books))
+	for symbol, book := range s.books {
+		summaries = append(summaries, BookSummary{Symbol: symbol, Bids: summarize(book.Bids), Asks: summarize(book.Asks)})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Symbol < summaries[j].Symbol
+	})
+	return summaries
+}
+
+// summarize conta ordens, níveis de preço e quantidade de um lado já ordenado
+func summarize(orders []*domain.Order) SideSummary {
+	summary := SideSummary{Orders: len(orders)}
+	for i, order := range orders {
+		if i == 0 || order.Price != orders[i-1].Price {
+			summary.Levels++
+		}
+		summary.Quantity += order.RemainingQuantity
+	}
+	return summary
+}
+
+// roundPrice arredonda valores derivados de preço para evitar ruído de ponto flutuante
+func roundPrice(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
@@ -1,6 +1,10 @@
 package orderbook
 
 import (
+	"sort"
+	"sync"
+	"time"
+
 	"trading/internal/domain"
 )
 
@@ -13,53 +17,212 @@ type OrderBook struct {
 
 // Manager gerencia livros de ofertas
 type Manager struct {
-	// TODO: Implementar campos necessários
+	books map[string]*OrderBook
+	index map[string]*domain.Order // orderID -> ordem em repouso
+	mutex sync.RWMutex
 }
 
 // NewManager cria um novo manager de order book
 func NewManager() *Manager {
 	return &Manager{
-		// TODO: Inicializar campos
+		books: make(map[string]*OrderBook),
+		index: make(map[string]*domain.Order),
 	}
 }
 
-// GetOrderBook retorna o livro de ofertas de um símbolo
+// FindOrder retorna uma cópia de uma ordem em repouso (nil se não estiver no livro)
+func (s *Manager) FindOrder(orderID string) *domain.Order {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	order, exists := s.index[orderID]
+	if !exists {
+		return nil
+	}
+	return order.Clone()
+}
+
+// GetOrderBook retorna uma cópia do livro de ofertas de um símbolo
 func (s *Manager) GetOrderBook(symbol string) *OrderBook {
-	// TODO: Implementar lógica do order book
-	// 1. Buscar ou criar livro para o símbolo
-	// 2. Organizar bids por preço decrescente
-	// 3. Organizar asks por preço crescente
-	// 4. Retornar livro atualizado
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 
-	return &OrderBook{
+	book := &OrderBook{
 		Symbol: symbol,
 		Bids:   []*domain.Order{},
 		Asks:   []*domain.Order{},
 	}
+
+	if current, exists := s.books[symbol]; exists {
+		for _, order := range current.Bids {
+			book.Bids = append(book.Bids, order.Clone())
+		}
+		for _, order := range current.Asks {
+			book.Asks = append(book.Asks, order.Clone())
+		}
+	}
+
+	return book
+}
+
+// Snapshot retorna cópias de todos os livros, ordenadas por símbolo
+func (s *Manager) Snapshot() []*OrderBook {
+	s.mutex.RLock()
+	symbols := make([]string, 0, len(s.books))
+	for symbol := range s.books {
+		symbols = append(symbols, symbol)
+	}
+	s.mutex.RUnlock()
+
+	sort.Strings(symbols)
+	books := make([]*OrderBook, 0, len(symbols))
+	for _, symbol := range symbols {
+		books = append(books, s.GetOrderBook(symbol))
+	}
+	return books
 }
 
-// AddOrder adiciona uma ordem ao livro
+// RestingOrders retorna quantas ordens estão em repouso em cada livro
+func (s *Manager) RestingOrders() map[string]int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	counts := make(map[string]int, len(s.books))
+	for symbol, book := range s.books {
+		counts[symbol] = len(book.Bids) + len(book.Asks)
+	}
+	return counts
+}
+
+// Restore substitui todos os livros pelos informados
+func (s *Manager) Restore(books []*OrderBook) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.books = make(map[string]*OrderBook, len(books))
+	s.index = make(map[string]*domain.Order)
+	for _, saved := range books {
+		book := &OrderBook{Symbol: saved.Symbol}
+		for _, order := range saved.Bids {
+			book.Bids = append(book.Bids, order.Clone())
+		}
+		for _, order := range saved.Asks {
+			book.Asks = append(book.Asks, order.Clone())
+		}
+		for _, order := range append(append([]*domain.Order{}, book.Bids...), book.Asks...) {
+			s.index[order.ID] = order
+		}
+		s.books[book.Symbol] = book
+	}
+}
+
+// AddOrder adiciona uma ordem ao livro respeitando price-time priority
 func (s *Manager) AddOrder(order *domain.Order) {
-	// TODO: Implementar adição de ordem
-	// 1. Obter livro do símbolo
-	// 2. Adicionar ordem na lista correta (bids ou asks)
-	// 3. Ordenar lista por price-time priority
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	book := s.book(order.Symbol)
+	s.index[order.ID] = order
+
+	if order.Side == domain.BUY {
+		// Bids: maior preço primeiro; mesmo preço mantém ordem de chegada
+		i := sort.Search(len(book.Bids), func(i int) bool {
+			return book.Bids[i].Price < order.Price
+		})
+		book.Bids = insertAt(book.Bids, i, order)
+		return
+	}
+
+	// Asks: menor preço primeiro; mesmo preço mantém ordem de chegada
+	i := sort.Search(len(book.Asks), func(i int) bool {
+		return book.Asks[i].Price > order.Price
+	})
+	book.Asks = insertAt(book.Asks, i, order)
 }
 
-// RemoveOrder remove uma ordem do livro
-func (s *Manager) RemoveOrder(symbol, orderID string) {
-	// TODO: Implementar remoção de ordem
-	// 1. Encontrar ordem no livro
-	// 2. Remover da lista correspondente
+// RemoveOrder remove uma ordem do livro, retornando-a (nil se não estiver no livro)
+func (s *Manager) RemoveOrder(orderID string) *domain.Order {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	order, exists := s.index[orderID]
+	if !exists {
+		return nil
+	}
+
+	s.removeLocked(order)
+	return order
 }
 
 // FindBestMatch encontra a melhor correspondência para uma ordem
 func (s *Manager) FindBestMatch(order *domain.Order) *domain.Order {
-	// TODO: Implementar busca de correspondência
-	// 1. Para ordem BUY: buscar menor preço de venda (ask)
-	// 2. Para ordem SELL: buscar maior preço de compra (bid)
-	// 3. Verificar se preços são compatíveis
-	// 4. Retornar ordem correspondente ou nil
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	book, exists := s.books[order.Symbol]
+	if !exists {
+		return nil
+	}
+
+	if order.Side == domain.BUY {
+		if len(book.Asks) > 0 && book.Asks[0].Price <= order.Price {
+			return book.Asks[0]
+		}
+		return nil
+	}
 
+	if len(book.Bids) > 0 && book.Bids[0].Price >= order.Price {
+		return book.Bids[0]
+	}
 	return nil
 }
+
+// FillOrder registra a execução de uma quantidade de uma ordem do livro,
+// removendo-a quando totalmente executada
+func (s *Manager) FillOrder(order *domain.Order, quantity int, at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	order.FillAt(quantity, at)
+
+	if order.IsComplete() {
+		s.removeLocked(order)
+	}
+}
+
+// removeLocked tira a ordem do livro e do índice; o chamador deve ter o lock
+func (s *Manager) removeLocked(order *domain.Order) {
+	delete(s.index, order.ID)
+	if book, exists := s.books[order.Symbol]; exists {
+		book.Bids = removeByID(book.Bids, order.ID)
+		book.Asks = removeByID(book.Asks, order.ID)
+	}
+}
+
+// book retorna (ou cria) o livro de um símbolo; o chamador deve ter o lock
+func (s *Manager) book(symbol string) *OrderBook {
+	book, exists := s.books[symbol]
+	if !exists {
+		book = &OrderBook{Symbol: symbol}
+		s.books[symbol] = book
+	}
+	return book
+}
+
+// insertAt insere uma ordem na posição i
+func insertAt(orders []*domain.Order, i int, order *domain.Order) []*domain.Order {
+	orders = append(orders, nil)
+	copy(orders[i+1:], orders[i:])
+	orders[i] = order
+	return orders
+}
+
+// removeByID remove uma ordem pelo ID, preservando a ordenação
+func removeByID(orders []*domain.Order, orderID string) []*domain.Order {
+	for i, order := range orders {
+		if order.ID == orderID {
+			return append(orders[:i], orders[i+1:]...)
+		}
+	}
+	return orders
+}
@@ -1,12 +1,52 @@
 package portfolio
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+
 	"trading/internal/domain"
+	"trading/internal/services/engine/repository"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/stats"
+	"trading/internal/services/shared/tracing"
 )
 
 // Service gerencia portfolios dos usuários
 type Service struct {
-	// TODO: Implementar campos necessários
+	users        map[string]User
+	portfolios   map[string]*domain.Portfolio
+	reservations map[string]map[string]*reservation // userID -> orderID -> reserva
+	publisher    events.Publisher
+	repository   repository.PortfolioRepository
+	muted        bool
+	mutex        sync.RWMutex
+
+	// rejections conta as ordens barradas por ValidateOrder antes do matching
+	rejections *stats.ErrorCounter
+	// persistence conta as gravações recusadas pelo repositório
+	persistence atomic.Uint64
+}
+
+// RejectionReasons são os erros com que a validação de saldo, posição e
+// perfil rejeita ordens
+var RejectionReasons = []error{
+	domain.ErrUserNotFound, domain.ErrInvalidUser, domain.ErrInsufficientBalance,
+	domain.ErrInsufficientPosition, domain.ErrInvalidOrderSide, domain.ErrExceedsLimit,
+}
+
+// reservation representa saldo ou posição comprometidos por uma ordem aberta
+type reservation struct {
+	orderID   string
+	symbol    string
+	side      domain.OrderSide
+	price     float64
+	remaining int
 }
 
 // User representa dados de usuário
@@ -22,53 +62,447 @@ type User struct {
 	InitialPositions map[string]int `json:"initial_positions,omitempty"`
 }
 
-// NewService cria um novo serviço de portfolio
-func NewService() *Service {
+// usersFile representa o formato de data/users.json
+type usersFile struct {
+	Users []User `json:"users"`
+}
+
+// NewService cria um novo serviço de portfolio a partir do arquivo de usuários
+func NewService(usersPath string) (*Service, error) {
+	data, err := os.ReadFile(usersPath)
+	if err != nil {
+		return nil, fmt.Errorf("lendo %s: %w", usersPath, err)
+	}
+
+	var file usersFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decodificando %s: %w", usersPath, err)
+	}
+
 	service := &Service{
-		// TODO: Inicializar campos
+		users:        make(map[string]User, len(file.Users)),
+		portfolios:   make(map[string]*domain.Portfolio),
+		reservations: make(map[string]map[string]*reservation),
+		rejections:   stats.NewErrorCounter(RejectionReasons...),
+	}
+
+	for _, user := range file.Users {
+		service.users[user.ID] = user
 	}
 
-	// TODO: Carregar dados de usuários do arquivo JSON
-	return service
+	return service, nil
 }
 
-// GetPortfolio retorna o portfolio de um usuário
+// GetPortfolio retorna o portfolio de um usuário, criando-o no primeiro acesso
 func (s *Service) GetPortfolio(userID string) (*domain.Portfolio, error) {
-	// TODO: Implementar lógica de portfolio
-	// 1. Verificar se usuário existe
-	// 2. Carregar ou criar portfolio
-	// 3. Aplicar posições iniciais se necessário
-	// 4. Retornar portfolio
+	s.mutex.RLock()
+	portfolio, exists := s.portfolios[userID]
+	s.mutex.RUnlock()
+	if exists {
+		return portfolio, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.portfolioLocked(userID)
+}
+
+// portfolioLocked busca ou cria o portfolio; o chamador deve ter o lock de escrita
+func (s *Service) portfolioLocked(userID string) (*domain.Portfolio, error) {
+	if portfolio, exists := s.portfolios[userID]; exists {
+		return portfolio, nil
+	}
+
+	user, exists := s.users[userID]
+	if !exists {
+		return nil, domain.ErrUserNotFound
+	}
 
-	return nil, domain.ErrUserNotFound
+	portfolio := domain.NewPortfolio(user.ID, user.Cash)
+	for symbol, quantity := range user.InitialPositions {
+		portfolio.Positions[symbol] = quantity
+	}
+	s.portfolios[userID] = portfolio
+
+	return portfolio, nil
 }
 
 // ValidateOrder valida se o usuário pode fazer a ordem
-func (s *Service) ValidateOrder(order *domain.Order) error {
-	// TODO: Implementar validações
-	// 1. Verificar se usuário existe
-	// 2. Validar saldo para compras
-	// 3. Validar posição para vendas
-	// 4. Verificar limites do perfil
+func (s *Service) ValidateOrder(ctx context.Context, order *domain.Order) error {
+	_, span := tracing.Start(ctx, "portfolio.ValidateOrder", tracing.OrderAttrs(order)...)
+	defer span.End()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.validateLocked(order)
+	if err != nil {
+		span.RecordError(err)
+		s.rejections.Inc(err)
+		slog.InfoContext(ctx, "ordem rejeitada pelo portfolio", "user_id", order.UserID, "symbol", order.Symbol,
+			"side", order.Side, "quantity", order.Quantity, "price", order.Price, "reason", err)
+	}
+	return err
+}
+
+// PersistenceErrors retorna quantas gravações de portfolio falharam
+func (s *Service) PersistenceErrors() uint64 {
+	return s.persistence.Load()
+}
+
+// Rejections retorna quantas ordens ValidateOrder rejeitou, por motivo
+func (s *Service) Rejections() map[error]uint64 {
+	return s.rejections.Snapshot()
+}
+
+// ReserveOrder valida a ordem e compromete o saldo (compra) ou a posição
+// (venda) necessários até que ela seja executada ou liberada
+func (s *Service) ReserveOrder(ctx context.Context, order *domain.Order) error {
+	_, span := tracing.Start(ctx, "portfolio.ReserveOrder", tracing.OrderAttrs(order)...)
+	defer span.End()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.validateLocked(order); err != nil {
+		span.RecordError(err)
+		slog.DebugContext(ctx, "reserva recusada", "order_id", order.ID, "user_id", order.UserID, "reason", err)
+		return err
+	}
+
+	s.reserveLocked(order)
+	slog.DebugContext(ctx, "reserva criada", "order_id", order.ID, "user_id", order.UserID, "symbol", order.Symbol,
+		"side", order.Side, "quantity", order.RemainingQuantity, "price", order.Price)
 
 	return nil
 }
 
+// ReplaceReservation troca a reserva de uma ordem aberta pela dos novos
+// parâmetros numa única etapa: a nova é validada sem contar a antiga e, se
+// recusada, a reserva original permanece intacta
+func (s *Service) ReplaceReservation(ctx context.Context, current, amended *domain.Order) error {
+	_, span := tracing.Start(ctx, "portfolio.ReplaceReservation", tracing.OrderAttrs(amended)...)
+	defer span.End()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, exists := s.reservations[current.UserID][current.ID]
+	s.releaseLocked(current)
+	if err := s.validateLocked(amended); err != nil {
+		if exists {
+			s.putLocked(current.UserID, previous)
+		}
+		span.RecordError(err)
+		slog.DebugContext(ctx, "alteração de reserva recusada", "order_id", amended.ID, "user_id", amended.UserID, "reason", err)
+		return err
+	}
+
+	s.reserveLocked(amended)
+	slog.DebugContext(ctx, "reserva alterada", "order_id", amended.ID, "user_id", amended.UserID, "symbol", amended.Symbol,
+		"side", amended.Side, "quantity", amended.RemainingQuantity, "price", amended.Price)
+
+	return nil
+}
+
+// reserveLocked registra a reserva do restante de uma ordem
+func (s *Service) reserveLocked(order *domain.Order) {
+	s.putLocked(order.UserID, &reservation{
+		orderID:   order.ID,
+		symbol:    order.Symbol,
+		side:      order.Side,
+		price:     order.Price,
+		remaining: order.RemainingQuantity,
+	})
+}
+
+// putLocked guarda uma reserva do usuário
+func (s *Service) putLocked(userID string, r *reservation) {
+	if s.reservations[userID] == nil {
+		s.reservations[userID] = make(map[string]*reservation)
+	}
+	s.reservations[userID][r.orderID] = r
+}
+
+// Snapshot retorna cópias de todos os portfolios já carregados, ordenadas por usuário
+func (s *Service) Snapshot() []*domain.Portfolio {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	portfolios := make([]*domain.Portfolio, 0, len(s.portfolios))
+	for _, portfolio := range s.portfolios {
+		portfolios = append(portfolios, portfolio.Clone())
+	}
+	sort.Slice(portfolios, func(i, j int) bool {
+		return portfolios[i].UserID < portfolios[j].UserID
+	})
+	return portfolios
+}
+
+// Restore substitui os portfolios e recria as reservas a partir das ordens em
+// repouso no livro (cada ordem aberta reserva exatamente o seu restante)
+func (s *Service) Restore(portfolios []*domain.Portfolio, resting []*domain.Order) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.portfolios = make(map[string]*domain.Portfolio, len(portfolios))
+	for _, portfolio := range portfolios {
+		s.portfolios[portfolio.UserID] = portfolio.Clone()
+		s.saveLocked(portfolio)
+	}
+
+	s.reservations = make(map[string]map[string]*reservation)
+	for _, order := range resting {
+		s.reserveLocked(order)
+	}
+}
+
+// RestoreReservations recria as reservas das ordens abertas, mantendo os
+// portfolios já carregados (usado quando o banco é a única fonte do estado)
+func (s *Service) RestoreReservations(resting []*domain.Order) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.reservations = make(map[string]map[string]*reservation)
+	for _, order := range resting {
+		s.reserveLocked(order)
+	}
+}
+
+// ReleaseOrder libera o que ainda estiver reservado para uma ordem
+func (s *Service) ReleaseOrder(order *domain.Order) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.releaseLocked(order)
+}
+
+// releaseLocked remove a reserva de uma ordem, se houver
+func (s *Service) releaseLocked(order *domain.Order) {
+	if reservations, exists := s.reservations[order.UserID]; exists {
+		delete(reservations, order.ID)
+		if len(reservations) == 0 {
+			delete(s.reservations, order.UserID)
+		}
+	}
+}
+
 // ExecuteTrade executa uma negociação atualizando os portfolios
-func (s *Service) ExecuteTrade(trade *domain.Trade) error {
-	// TODO: Implementar execução de trade
-	// 1. Obter portfolios do comprador e vendedor
-	// 2. Atualizar saldo e posições atomicamente
-	// 3. Garantir consistência dos dados
+func (s *Service) ExecuteTrade(ctx context.Context, trade *domain.Trade) error {
+	_, span := tracing.Start(ctx, "portfolio.ExecuteTrade",
+		slog.String("trade.id", trade.ID), slog.String("order.symbol", trade.Symbol),
+		slog.String("trade.buy_order_id", trade.BuyOrderID), slog.String("trade.sell_order_id", trade.SellOrderID),
+		slog.Int("trade.quantity", trade.Quantity), slog.Float64("trade.price", trade.Price))
+	defer span.End()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.executeTradeLocked(ctx, trade)
+	span.RecordError(err)
+	return err
+}
+
+// executeTradeLocked liquida a negociação; o mutex deve estar adquirido
+func (s *Service) executeTradeLocked(ctx context.Context, trade *domain.Trade) error {
+	buyer, err := s.portfolioLocked(trade.BuyerID)
+	if err != nil {
+		return err
+	}
+	seller, err := s.portfolioLocked(trade.SellerID)
+	if err != nil {
+		return err
+	}
+
+	// Valida os dois lados antes de alterar qualquer portfolio
+	if !buyer.HasSufficientCash(trade.Value) {
+		return domain.ErrInsufficientBalance
+	}
+	if !seller.HasSufficientPosition(trade.Symbol, trade.Quantity) {
+		return domain.ErrInsufficientPosition
+	}
+
+	if err := buyer.ExecuteBuy(trade.Symbol, trade.Quantity, trade.Price); err != nil {
+		return err
+	}
+	if err := seller.ExecuteSell(trade.Symbol, trade.Quantity, trade.Price); err != nil {
+		// Desfaz a compra para manter a operação atômica
+		_ = buyer.ExecuteSell(trade.Symbol, trade.Quantity, trade.Price)
+		return err
+	}
+
+	buyer.Touch(trade.ExecutedAt)
+	seller.Touch(trade.ExecutedAt)
+
+	s.consumeLocked(trade.BuyerID, trade.BuyOrderID, trade.Quantity)
+	s.consumeLocked(trade.SellerID, trade.SellOrderID, trade.Quantity)
+
+	s.saveLocked(buyer)
+	s.saveLocked(seller)
+
+	s.notifyLocked(buyer, trade, -trade.Value, trade.Quantity)
+	s.notifyLocked(seller, trade, trade.Value, -trade.Quantity)
+	slog.DebugContext(ctx, "negociação liquidada", "trade_id", trade.ID, "buyer_id", trade.BuyerID,
+		"seller_id", trade.SellerID, "symbol", trade.Symbol, "value", trade.Value)
 
 	return nil
 }
 
+// SetRepository define onde os portfolios alterados são gravados
+func (s *Service) SetRepository(repo repository.PortfolioRepository) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.repository = repo
+}
+
+// Load carrega os portfolios gravados no repositório, substituindo os em memória
+func (s *Service) Load() (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.repository == nil {
+		return 0, nil
+	}
+
+	portfolios, err := s.repository.ListPortfolios()
+	if err != nil {
+		return 0, err
+	}
+	for _, portfolio := range portfolios {
+		s.portfolios[portfolio.UserID] = portfolio
+	}
+	return len(portfolios), nil
+}
+
+// saveLocked grava o portfolio no repositório, se houver. Falhas são
+// registradas e contadas em PersistenceErrors.
+func (s *Service) saveLocked(portfolio *domain.Portfolio) {
+	if s.repository == nil {
+		return
+	}
+	if err := s.repository.SavePortfolio(portfolio); err != nil {
+		s.persistence.Add(1)
+		slog.Error("erro ao gravar portfolio", "user_id", portfolio.UserID, "error", err)
+	}
+}
+
+// SetPublisher define onde os eventos PortfolioUpdated são publicados
+func (s *Service) SetPublisher(publisher events.Publisher) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.publisher = publisher
+}
+
+// Mute suspende (ou retoma) a publicação de eventos, usado durante o replay
+func (s *Service) Mute(muted bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.muted = muted
+}
+
+// notifyLocked publica a alteração de um portfolio. A publicação acontece com
+// o lock adquirido para manter a ordem em relação às negociações.
+func (s *Service) notifyLocked(portfolio *domain.Portfolio, trade *domain.Trade, cashDelta float64, positionDelta int) {
+	if s.publisher == nil || s.muted {
+		return
+	}
+
+	s.publisher.Publish(events.PortfolioUpdated{
+		UserID:        portfolio.UserID,
+		TradeID:       trade.ID,
+		Symbol:        trade.Symbol,
+		CashDelta:     cashDelta,
+		Cash:          portfolio.GetCash(),
+		PositionDelta: positionDelta,
+		Position:      portfolio.GetPosition(trade.Symbol),
+		UpdatedAt:     trade.ExecutedAt,
+	})
+}
+
+// validateLocked aplica as validações de usuário, saldo, posição e limite
+func (s *Service) validateLocked(order *domain.Order) error {
+	user, exists := s.users[order.UserID]
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	if user.Status != "active" {
+		return domain.ErrInvalidUser
+	}
+
+	portfolio, err := s.portfolioLocked(order.UserID)
+	if err != nil {
+		return err
+	}
+
+	reservedCash, reservedShares := s.reservedLocked(order.UserID, order.Symbol)
+	value := float64(order.RemainingQuantity) * order.Price
+
+	switch order.Side {
+	case domain.BUY:
+		if portfolio.GetCash()-reservedCash < value {
+			return domain.ErrInsufficientBalance
+		}
+	case domain.SELL:
+		if portfolio.GetPosition(order.Symbol)-reservedShares < order.RemainingQuantity {
+			return domain.ErrInsufficientPosition
+		}
+	default:
+		return domain.ErrInvalidOrderSide
+	}
+
+	// Limite por ordem do perfil (0 = sem limite)
+	if user.MaxOrderValue > 0 && value > user.MaxOrderValue {
+		return domain.ErrExceedsLimit
+	}
+
+	return nil
+}
+
+// reservedLocked soma o saldo reservado e a posição reservada no símbolo
+func (s *Service) reservedLocked(userID, symbol string) (float64, int) {
+	var cash float64
+	var shares int
+
+	for _, r := range s.reservations[userID] {
+		switch {
+		case r.side == domain.BUY:
+			cash += float64(r.remaining) * r.price
+		case r.symbol == symbol:
+			shares += r.remaining
+		}
+	}
+
+	return cash, shares
+}
+
+// consumeLocked abate a quantidade executada da reserva de uma ordem
+func (s *Service) consumeLocked(userID, orderID string, quantity int) {
+	r, exists := s.reservations[userID][orderID]
+	if !exists {
+		return
+	}
+
+	r.remaining -= quantity
+	if r.remaining <= 0 {
+		delete(s.reservations[userID], orderID)
+		if len(s.reservations[userID]) == 0 {
+			delete(s.reservations, userID)
+		}
+	}
+}
+
 // GetUser retorna dados do usuário
 func (s *Service) GetUser(userID string) (User, error) {
-	// TODO: Implementar busca de usuário
-	// 1. Buscar usuário no dataset carregado
-	// 2. Retornar dados ou erro se não encontrado
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 
-	return User{}, domain.ErrUserNotFound
+	user, exists := s.users[userID]
+	if !exists {
+		return User{}, domain.ErrUserNotFound
+	}
+	return user, nil
 }
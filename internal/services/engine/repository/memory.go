@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"trading/internal/domain"
+)
+
+// MemoryStore implementa os três repositórios em memória (usado em testes e
+// quando não há banco configurado)
+type MemoryStore struct {
+	orders     map[string]*domain.Order
+	orderIDs   []string
+	trades     map[string]*domain.Trade
+	tradeIDs   []string
+	portfolios map[string]*domain.Portfolio
+	mutex      sync.RWMutex
+}
+
+// NewMemoryStore cria um repositório em memória vazio
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		orders:     make(map[string]*domain.Order),
+		trades:     make(map[string]*domain.Trade),
+		portfolios: make(map[string]*domain.Portfolio),
+	}
+}
+
+// SaveOrder grava uma cópia da ordem
+func (m *MemoryStore) SaveOrder(order *domain.Order) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.orders[order.ID]; !exists {
+		m.orderIDs = append(m.orderIDs, order.ID)
+	}
+	m.orders[order.ID] = order.Clone()
+	return nil
+}
+
+// GetOrder retorna uma cópia da ordem
+func (m *MemoryStore) GetOrder(orderID string) (*domain.Order, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	order, exists := m.orders[orderID]
+	if !exists {
+		return nil, domain.ErrOrderNotFound
+	}
+	return order.Clone(), nil
+}
+
+// ListOrders retorna cópias das ordens que atendem ao filtro, da mais recente
+// para a mais antiga
+func (m *MemoryStore) ListOrders(filter OrderFilter) ([]*domain.Order, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	orders := []*domain.Order{}
+	for i := len(m.orderIDs) - 1; i >= 0; i-- {
+		if order := m.orders[m.orderIDs[i]]; filter.matchOrder(order) {
+			orders = append(orders, order.Clone())
+			if filter.Limit > 0 && len(orders) == filter.Limit {
+				break
+			}
+		}
+	}
+	return orders, nil
+}
+
+// SaveTrade grava uma cópia da negociação
+func (m *MemoryStore) SaveTrade(trade *domain.Trade) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.trades[trade.ID]; !exists {
+		m.tradeIDs = append(m.tradeIDs, trade.ID)
+	}
+	saved := *trade
+	m.trades[trade.ID] = &saved
+	return nil
+}
+
+// ListTrades retorna cópias das negociações que atendem ao filtro
+func (m *MemoryStore) ListTrades(filter TradeFilter) ([]*domain.Trade, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	ids := m.tradeIDs
+	if filter.Descending {
+		ids = make([]string, len(m.tradeIDs))
+		for i, id := range m.tradeIDs {
+			ids[len(ids)-1-i] = id
+		}
+	}
+	if filter.After != "" {
+		position := -1
+		for i, id := range ids {
+			if id == filter.After {
+				position = i
+				break
+			}
+		}
+		if position < 0 {
+			return []*domain.Trade{}, nil
+		}
+		ids = ids[position+1:]
+	}
+
+	trades := []*domain.Trade{}
+	for _, id := range ids {
+		if trade := m.trades[id]; filter.matchTrade(trade) {
+			saved := *trade
+			trades = append(trades, &saved)
+			if filter.Limit > 0 && len(trades) == filter.Limit {
+				break
+			}
+		}
+	}
+	return trades, nil
+}
+
+// SavePortfolio grava uma cópia do portfolio
+func (m *MemoryStore) SavePortfolio(portfolio *domain.Portfolio) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.portfolios[portfolio.UserID] = portfolio.Clone()
+	return nil
+}
+
+// GetPortfolio retorna uma cópia do portfolio (nil se não gravado)
+func (m *MemoryStore) GetPortfolio(userID string) (*domain.Portfolio, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	portfolio, exists := m.portfolios[userID]
+	if !exists {
+		return nil, nil
+	}
+	return portfolio.Clone(), nil
+}
+
+// ListPortfolios retorna cópias de todos os portfolios
+func (m *MemoryStore) ListPortfolios() ([]*domain.Portfolio, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	portfolios := make([]*domain.Portfolio, 0, len(m.portfolios))
+	for _, portfolio := range m.portfolios {
+		portfolios = append(portfolios, portfolio.Clone())
+	}
+	sort.Slice(portfolios, func(i, j int) bool {
+		return portfolios[i].UserID < portfolios[j].UserID
+	})
+	return portfolios, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+// migrations são aplicadas em ordem; cada posição é uma versão do schema.
+// Nunca altere uma migração já publicada: adicione uma nova ao final.
+var migrations = []string{
+	// 1 - ordens, negociações e portfolios
+	`CREATE TABLE orders (
+		id                 TEXT PRIMARY KEY,
+		user_id            TEXT NOT NULL,
+		symbol             TEXT NOT NULL,
+		side               TEXT NOT NULL,
+		quantity           INTEGER NOT NULL,
+		price              REAL NOT NULL,
+		status             TEXT NOT NULL,
+		remaining_quantity INTEGER NOT NULL,
+		created_at         TEXT NOT NULL,
+		updated_at         TEXT NOT NULL
+	);
+	CREATE INDEX idx_orders_user ON orders (user_id);
+	CREATE INDEX idx_orders_symbol ON orders (symbol);
+
+	CREATE TABLE trades (
+		id            TEXT PRIMARY KEY,
+		buyer_id      TEXT NOT NULL,
+		seller_id     TEXT NOT NULL,
+		symbol        TEXT NOT NULL,
+		quantity      INTEGER NOT NULL,
+		price         REAL NOT NULL,
+		value         REAL NOT NULL,
+		executed_at   TEXT NOT NULL,
+		buy_order_id  TEXT NOT NULL,
+		sell_order_id TEXT NOT NULL
+	);
+	CREATE INDEX idx_trades_symbol ON trades (symbol);
+	CREATE INDEX idx_trades_buyer ON trades (buyer_id);
+	CREATE INDEX idx_trades_seller ON trades (seller_id);
+	CREATE INDEX idx_trades_executed_at ON trades (executed_at);
+
+	CREATE TABLE portfolios (
+		user_id    TEXT PRIMARY KEY,
+		cash       REAL NOT NULL,
+		updated_at TEXT NOT NULL
+	);
+
+	CREATE TABLE positions (
+		user_id  TEXT NOT NULL REFERENCES portfolios (user_id) ON DELETE CASCADE,
+		symbol   TEXT NOT NULL,
+		quantity INTEGER NOT NULL,
+		PRIMARY KEY (user_id, symbol)
+	);`,
+
+	// 2 - client_order_id das ordens
+	`ALTER TABLE orders ADD COLUMN client_order_id TEXT NOT NULL DEFAULT '';
+	CREATE INDEX idx_orders_client_order_id ON orders (user_id, client_order_id);`,
+}
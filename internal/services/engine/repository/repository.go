@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"trading/internal/domain"
+)
+
+// OrderFilter restringe a consulta de ordens; campos vazios não filtram
+type OrderFilter struct {
+	UserID        string
+	Symbol        string
+	Status        domain.OrderStatus
+	ClientOrderID string
+	Limit         int
+}
+
+// TradeFilter restringe a consulta de negociações; campos vazios não filtram.
+// UserID casa tanto com o comprador quanto com o vendedor. After é o cursor
+// de paginação: só entram negociações posteriores à de ID After na ordem
+// pedida (um ID desconhecido não retorna nada).
+type TradeFilter struct {
+	Symbol     string
+	UserID     string
+	From       time.Time
+	To         time.Time
+	MinValue   float64
+	After      string
+	Descending bool
+	Limit      int
+}
+
+// OrderRepository guarda o último estado de cada ordem
+type OrderRepository interface {
+	// SaveOrder grava (ou substitui) o estado da ordem
+	SaveOrder(order *domain.Order) error
+	// GetOrder retorna domain.ErrOrderNotFound se a ordem não existir
+	GetOrder(orderID string) (*domain.Order, error)
+	// ListOrders retorna as ordens da gravada pela primeira vez mais
+	// recentemente para a mais antiga, para que Limit traga as últimas
+	ListOrders(filter OrderFilter) ([]*domain.Order, error)
+}
+
+// TradeRepository guarda as negociações executadas
+type TradeRepository interface {
+	// SaveTrade grava a negociação; gravar o mesmo ID novamente a substitui
+	SaveTrade(trade *domain.Trade) error
+	// ListTrades retorna as negociações na ordem de execução (ou na inversa,
+	// com Descending)
+	ListTrades(filter TradeFilter) ([]*domain.Trade, error)
+}
+
+// PortfolioRepository guarda o último estado de cada portfolio
+type PortfolioRepository interface {
+	// SavePortfolio grava (ou substitui) caixa e posições do usuário
+	SavePortfolio(portfolio *domain.Portfolio) error
+	// GetPortfolio retorna nil se o portfolio nunca foi gravado
+	GetPortfolio(userID string) (*domain.Portfolio, error)
+	// ListPortfolios retorna todos os portfolios, ordenados por usuário
+	ListPortfolios() ([]*domain.Portfolio, error)
+}
+
+// matchOrder verifica se a ordem atende ao filtro
+func (f OrderFilter) matchOrder(order *domain.Order) bool {
+	return (f.UserID == "" || order.UserID == f.UserID) &&
+		(f.Symbol == "" || order.Symbol == f.Symbol) &&
+		(f.Status == "" || order.Status == f.Status) &&
+		(f.ClientOrderID == "" || order.ClientOrderID == f.ClientOrderID)
+}
+
+// matchTrade verifica se a negociação atende ao filtro
+func (f TradeFilter) matchTrade(trade *domain.Trade) bool {
+	return (f.Symbol == "" || trade.Symbol == f.Symbol) &&
+		(f.UserID == "" || trade.BuyerID == f.UserID || trade.SellerID == f.UserID) &&
+		(f.From.IsZero() || !trade.ExecutedAt.Before(f.From)) &&
+		(f.To.IsZero() || trade.ExecutedAt.Before(f.To)) &&
+		trade.Value >= f.MinValue
+}
@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"trading/internal/domain"
+)
+
+// SQLiteStore implementa os três repositórios sobre um arquivo SQLite
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite abre (ou cria) o banco e aplica as migrações pendentes
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("abrindo %s: %w", path, err)
+	}
+	// SQLite aceita um escritor por vez; uma conexão evita SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	store := &SQLiteStore{db: db}
+	if err := store.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Close fecha o banco
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// SchemaVersion retorna a versão atual do schema
+func (s *SQLiteStore) SchemaVersion() (int, error) {
+	var version int
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+// migrate aplica, cada uma em sua transação, as migrações ainda não registradas
+func (s *SQLiteStore) migrate() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("criando schema_migrations: %w", err)
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	for i, migration := range migrations[min(current, len(migrations)):] {
+		version := current + i + 1
+
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migration); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migração %d: %w", version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+			version, formatTime(time.Now())); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("registrando migração %d: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveOrder grava (ou substitui) o estado da ordem
+func (s *SQLiteStore) SaveOrder(order *domain.Order) error {
+	_, err := s.db.Exec(`INSERT INTO orders
+		(id, user_id, symbol, side, quantity, price, status, remaining_quantity, created_at, updated_at, client_order_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			quantity = excluded.quantity,
+			price = excluded.price,
+			status = excluded.status,
+			remaining_quantity = excluded.remaining_quantity,
+			updated_at = excluded.updated_at`,
+		order.ID, order.UserID, order.Symbol, string(order.Side), order.Quantity, order.Price,
+		string(order.Status), order.RemainingQuantity, formatTime(order.CreatedAt), formatTime(order.UpdatedAt), order.ClientOrderID)
+	if err != nil {
+		return fmt.Errorf("gravando ordem %s: %w", order.ID, err)
+	}
+	return nil
+}
+
+// GetOrder busca uma ordem pelo ID
+func (s *SQLiteStore) GetOrder(orderID string) (*domain.Order, error) {
+	orders, err := s.queryOrders(`WHERE id = ?`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, domain.ErrOrderNotFound
+	}
+	return orders[0], nil
+}
+
+// ListOrders retorna as ordens que atendem ao filtro, da mais recente para a
+// mais antiga
+func (s *SQLiteStore) ListOrders(filter OrderFilter) ([]*domain.Order, error) {
+	var where []string
+	var args []interface{}
+	if filter.UserID != "" {
+		where, args = append(where, "user_id = ?"), append(args, filter.UserID)
+	}
+	if filter.Symbol != "" {
+		where, args = append(where, "symbol = ?"), append(args, filter.Symbol)
+	}
+	if filter.Status != "" {
+		where, args = append(where, "status = ?"), append(args, string(filter.Status))
+	}
+	if filter.ClientOrderID != "" {
+		where, args = append(where, "client_order_id = ?"), append(args, filter.ClientOrderID)
+	}
+
+	return s.queryOrders(whereClause(where)+" ORDER BY rowid DESC"+limitClause(filter.Limit), args...)
+}
+
+// SaveTrade grava a negociação
+func (s *SQLiteStore) SaveTrade(trade *domain.Trade) error {
+	_, err := s.db.Exec(`INSERT INTO trades
+		(id, buyer_id, seller_id, symbol, quantity, price, value, executed_at, buy_order_id, sell_order_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			buyer_id = excluded.buyer_id,
+			seller_id = excluded.seller_id,
+			symbol = excluded.symbol,
+			quantity = excluded.quantity,
+			price = excluded.price,
+			value = excluded.value,
+			executed_at = excluded.executed_at,
+			buy_order_id = excluded.buy_order_id,
+			sell_order_id = excluded.sell_order_id`,
+		trade.ID, trade.BuyerID, trade.SellerID, trade.Symbol, trade.Quantity, trade.Price, trade.Value,
+		formatTime(trade.ExecutedAt), trade.BuyOrderID, trade.SellOrderID)
+	if err != nil {
+		return fmt.Errorf("gravando negociação %s: %w", trade.ID, err)
+	}
+	return nil
+}
+
+// ListTrades retorna as negociações que atendem ao filtro
+func (s *SQLiteStore) ListTrades(filter TradeFilter) ([]*domain.Trade, error) {
+	var where []string
+	var args []interface{}
+	if filter.Symbol != "" {
+		where, args = append(where, "symbol = ?"), append(args, filter.Symbol)
+	}
+	if filter.UserID != "" {
+		where, args = append(where, "(buyer_id = ? OR seller_id = ?)"), append(args, filter.UserID, filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		where, args = append(where, "executed_at >= ?"), append(args, formatTime(filter.From))
+	}
+	if !filter.To.IsZero() {
+		where, args = append(where, "executed_at < ?"), append(args, formatTime(filter.To))
+	}
+	if filter.MinValue > 0 {
+		where, args = append(where, "value >= ?"), append(args, filter.MinValue)
+	}
+	order := " ORDER BY rowid"
+	if filter.Descending {
+		order += " DESC"
+	}
+	if filter.After != "" {
+		// Sem a negociação do cursor a subconsulta é NULL e nada é retornado
+		comparison := ">"
+		if filter.Descending {
+			comparison = "<"
+		}
+		where, args = append(where, "rowid "+comparison+" (SELECT rowid FROM trades WHERE id = ?)"), append(args, filter.After)
+	}
+
+	rows, err := s.db.Query(`SELECT id, buyer_id, seller_id, symbol, quantity, price, value, executed_at,
+		buy_order_id, sell_order_id FROM trades`+whereClause(where)+order+limitClause(filter.Limit), args...)
+	if err != nil {
+		return nil, fmt.Errorf("consultando negociações: %w", err)
+	}
+	defer rows.Close()
+
+	trades := []*domain.Trade{}
+	for rows.Next() {
+		var trade domain.Trade
+		var executedAt string
+		if err := rows.Scan(&trade.ID, &trade.BuyerID, &trade.SellerID, &trade.Symbol, &trade.Quantity,
+			&trade.Price, &trade.Value, &executedAt, &trade.BuyOrderID, &trade.SellOrderID); err != nil {
+			return nil, err
+		}
+		if trade.ExecutedAt, err = parseTime(executedAt); err != nil {
+			return nil, err
+		}
+		trades = append(trades, &trade)
+	}
+	return trades, rows.Err()
+}
+
+// SavePortfolio grava caixa e posições do usuário em uma transação
+func (s *SQLiteStore) SavePortfolio(portfolio *domain.Portfolio) error {
+	snapshot := portfolio.Clone()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO portfolios (user_id, cash, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET cash = excluded.cash, updated_at = excluded.updated_at`,
+		snapshot.UserID, snapshot.Cash, formatTime(snapshot.UpdatedAt)); err != nil {
+		return fmt.Errorf("gravando portfolio %s: %w", snapshot.UserID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM positions WHERE user_id = ?`, snapshot.UserID); err != nil {
+		return err
+	}
+	for symbol, quantity := range snapshot.Positions {
+		if _, err := tx.Exec(`INSERT INTO positions (user_id, symbol, quantity) VALUES (?, ?, ?)`,
+			snapshot.UserID, symbol, quantity); err != nil {
+			return fmt.Errorf("gravando posição %s/%s: %w", snapshot.UserID, symbol, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPortfolio busca o portfolio de um usuário (nil se nunca gravado)
+func (s *SQLiteStore) GetPortfolio(userID string) (*domain.Portfolio, error) {
+	portfolios, err := s.queryPortfolios(`WHERE user_id = ?`, userID)
+	if err != nil || len(portfolios) == 0 {
+		return nil, err
+	}
+	return portfolios[0], nil
+}
+
+// ListPortfolios retorna todos os portfolios gravados
+func (s *SQLiteStore) ListPortfolios() ([]*domain.Portfolio, error) {
+	return s.queryPortfolios("")
+}
+
+// queryOrders executa um SELECT de ordens com o complemento informado
+func (s *SQLiteStore) queryOrders(clause string, args ...interface{}) ([]*domain.Order, error) {
+	rows, err := s.db.Query(`SELECT id, user_id, symbol, side, quantity, price, status, remaining_quantity,
+		created_at, updated_at, client_order_id FROM orders `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("consultando ordens: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []*domain.Order{}
+	for rows.Next() {
+		var order domain.Order
+		var side, status, createdAt, updatedAt string
+		if err := rows.Scan(&order.ID, &order.UserID, &order.Symbol, &side, &order.Quantity, &order.Price,
+			&status, &order.RemainingQuantity, &createdAt, &updatedAt, &order.ClientOrderID); err != nil {
+			return nil, err
+		}
+		order.Side = domain.OrderSide(side)
+		order.Status = domain.OrderStatus(status)
+		if order.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		if order.UpdatedAt, err = parseTime(updatedAt); err != nil {
+			return nil, err
+		}
+		orders = append(orders, &order)
+	}
+	return orders, rows.Err()
+}
+
+// queryPortfolios carrega portfolios e suas posições
+func (s *SQLiteStore) queryPortfolios(clause string, args ...interface{}) ([]*domain.Portfolio, error) {
+	rows, err := s.db.Query(`SELECT user_id, cash, updated_at FROM portfolios `+clause+` ORDER BY user_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("consultando portfolios: %w", err)
+	}
+
+	portfolios := []*domain.Portfolio{}
+	for rows.Next() {
+		var userID, updatedAt string
+		var cash float64
+		if err := rows.Scan(&userID, &cash, &updatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		portfolio := domain.NewPortfolio(userID, cash)
+		if portfolio.UpdatedAt, err = parseTime(updatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		portfolios = append(portfolios, portfolio)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Com uma única conexão, as posições são lidas após fechar o cursor anterior
+	for _, portfolio := range portfolios {
+		if err := s.loadPositions(portfolio); err != nil {
+			return nil, err
+		}
+	}
+	return portfolios, nil
+}
+
+// loadPositions preenche as posições de um portfolio
+func (s *SQLiteStore) loadPositions(portfolio *domain.Portfolio) error {
+	rows, err := s.db.Query(`SELECT symbol, quantity FROM positions WHERE user_id = ?`, portfolio.UserID)
+	if err != nil {
+		return fmt.Errorf("consultando posições: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var symbol string
+		var quantity int
+		if err := rows.Scan(&symbol, &quantity); err != nil {
+			return err
+		}
+		portfolio.Positions[symbol] = quantity
+	}
+	return rows.Err()
+}
+
+// whereClause monta o WHERE a partir das condições
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// limitClause monta o LIMIT (0 = sem limite)
+func limitClause(limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" LIMIT %d", limit)
+}
+
+// timeLayout tem largura fixa para que a comparação de texto no SQLite
+// respeite a ordem cronológica
+const timeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// formatTime serializa o instante em UTC
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// parseTime lê um instante gravado por formatTime
+func parseTime(value string) (time.Time, error) {
+	t, err := time.Parse(timeLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("data inválida %q: %w", value, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,119 @@
+package snapshot
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"trading/internal/services/engine/journal"
+	"trading/internal/services/engine/matching"
+)
+
+// Manager coordena snapshots do engine com o journal de comandos
+type Manager struct {
+	engine  *matching.Service
+	store   *Store
+	journal *journal.Journal
+
+	lastSeq uint64
+	taken   bool
+	mutex   sync.Mutex
+}
+
+// NewManager cria o gerenciador; commands pode ser nil quando o journal está desabilitado
+func NewManager(engine *matching.Service, store *Store, commands *journal.Journal) *Manager {
+	return &Manager{engine: engine, store: store, journal: commands}
+}
+
+// Take grava um snapshot consistente do estado atual
+func (m *Manager) Take() (Info, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	info, err := m.store.Save(m.engine.Capture())
+	if err != nil {
+		return Info{}, err
+	}
+
+	m.lastSeq, m.taken = info.Seq, true
+	return info, nil
+}
+
+// List retorna os snapshots disponíveis, do mais recente para o mais antigo
+func (m *Manager) List() ([]Info, error) {
+	return m.store.List()
+}
+
+// Restore recarrega o engine a partir do snapshot seq e reaplica os comandos
+// do journal gravados depois dele, retornando o snapshot usado e quantos
+// comandos foram reaplicados
+func (m *Manager) Restore(seq uint64) (Info, int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state, err := m.store.Load(seq)
+	if err != nil {
+		return Info{}, 0, err
+	}
+	return m.restoreLocked(state)
+}
+
+// Recover carrega o snapshot mais recente (se houver) e o final do journal.
+// É usado na inicialização, antes de o engine receber ordens.
+func (m *Manager) Recover() (Info, int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state, err := m.store.Latest()
+	if err != nil {
+		return Info{}, 0, err
+	}
+	return m.restoreLocked(state)
+}
+
+// Run grava snapshots periodicamente até o contexto ser cancelado. Com
+// journal, o snapshot é pulado se nenhum comando foi aplicado desde o último.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if m.unchanged() {
+				continue
+			}
+			if info, err := m.Take(); err != nil {
+				slog.Error("erro ao gravar snapshot", "error", err)
+			} else {
+				slog.Info("snapshot gravado", "file", info.File, "seq", info.Seq)
+			}
+		}
+	}
+}
+
+// restoreLocked aplica o estado e o final do journal; o chamador deve ter o lock
+func (m *Manager) restoreLocked(state *matching.State) (Info, int, error) {
+	applied, err := m.engine.Restore(state, m.journal)
+	if err != nil {
+		return Info{}, applied, err
+	}
+
+	info := Info{}
+	if state != nil {
+		info = Info{Seq: state.Seq, CreatedAt: state.CreatedAt, File: fileName(state.Seq)}
+		m.lastSeq, m.taken = state.Seq, true
+	}
+	return info, applied, nil
+}
+
+// unchanged indica se o journal não avançou desde o último snapshot
+func (m *Manager) unchanged() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.journal != nil && m.taken && m.journal.Seq() == m.lastSeq
+}
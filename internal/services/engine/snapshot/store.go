@@ -0,0 +1,202 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/matching"
+)
+
+// Erros do armazenamento de snapshots
+var (
+	ErrNotFound = domain.ErrSnapshotNotFound
+	ErrCorrupt  = errors.New("snapshot corrompido")
+)
+
+// Prefixo e extensão dos arquivos de snapshot
+const (
+	filePrefix = "snapshot-"
+	fileSuffix = ".json"
+)
+
+// crcTable é a tabela Castagnoli usada nos checksums
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Info descreve um snapshot gravado
+type Info struct {
+	Seq       uint64    `json:"seq"`
+	CreatedAt time.Time `json:"created_at"`
+	File      string    `json:"file"`
+	Size      int64     `json:"size"`
+}
+
+// file é o conteúdo gravado em disco: o estado e o CRC32 dos seus bytes
+type file struct {
+	Checksum uint32          `json:"crc32"`
+	State    json.RawMessage `json:"state"`
+}
+
+// Store grava snapshots em um diretório, um arquivo por sequência do journal
+type Store struct {
+	dir  string
+	keep int
+}
+
+// NewStore cria o diretório se necessário; keep é quantos snapshots manter
+// (keep <= 0 mantém todos)
+func NewStore(dir string, keep int) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("criando %s: %w", dir, err)
+	}
+	return &Store{dir: dir, keep: keep}, nil
+}
+
+// Save grava o estado de forma atômica (arquivo temporário + rename) e remove
+// os snapshots mais antigos além do limite
+func (s *Store) Save(state *matching.State) (Info, error) {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return Info{}, fmt.Errorf("serializando snapshot: %w", err)
+	}
+	data, err := json.Marshal(file{Checksum: crc32.Checksum(payload, crcTable), State: payload})
+	if err != nil {
+		return Info{}, err
+	}
+
+	name := fileName(state.Seq)
+	path := filepath.Join(s.dir, name)
+
+	tmp, err := os.CreateTemp(s.dir, name+".tmp-*")
+	if err != nil {
+		return Info{}, fmt.Errorf("criando snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return Info{}, fmt.Errorf("gravando snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return Info{}, fmt.Errorf("sincronizando snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return Info{}, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Info{}, fmt.Errorf("publicando snapshot: %w", err)
+	}
+
+	if err := s.prune(); err != nil {
+		return Info{}, err
+	}
+
+	return Info{Seq: state.Seq, CreatedAt: state.CreatedAt, File: name, Size: int64(len(data))}, nil
+}
+
+// List retorna os snapshots disponíveis, do mais recente para o mais antigo
+func (s *Store) List() ([]Info, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("listando %s: %w", s.dir, err)
+	}
+
+	infos := []Info{}
+	for _, entry := range entries {
+		seq, ok := parseName(entry.Name())
+		if !ok {
+			continue
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, Info{
+			Seq:       seq,
+			CreatedAt: fileInfo.ModTime().UTC(),
+			File:      entry.Name(),
+			Size:      fileInfo.Size(),
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Seq > infos[j].Seq
+	})
+	return infos, nil
+}
+
+// Load lê e valida o snapshot de uma sequência
+func (s *Store) Load(seq uint64) (*matching.State, error) {
+	path := filepath.Join(s.dir, fileName(seq))
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lendo snapshot: %w", err)
+	}
+
+	var saved file
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if crc32.Checksum(saved.State, crcTable) != saved.Checksum {
+		return nil, fmt.Errorf("%w: checksum não confere", ErrCorrupt)
+	}
+
+	var state matching.State
+	if err := json.Unmarshal(saved.State, &state); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return &state, nil
+}
+
+// Latest carrega o snapshot mais recente (nil se não houver nenhum)
+func (s *Store) Latest() (*matching.State, error) {
+	infos, err := s.List()
+	if err != nil || len(infos) == 0 {
+		return nil, err
+	}
+	return s.Load(infos[0].Seq)
+}
+
+// prune remove os snapshots mais antigos além do limite configurado
+func (s *Store) prune() error {
+	if s.keep <= 0 {
+		return nil
+	}
+
+	infos, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, info := range infos[min(s.keep, len(infos)):] {
+		if err := os.Remove(filepath.Join(s.dir, info.File)); err != nil {
+			return fmt.Errorf("removendo snapshot antigo: %w", err)
+		}
+	}
+	return nil
+}
+
+// fileName retorna o nome do arquivo de snapshot de uma sequência
+func fileName(seq uint64) string {
+	return fmt.Sprintf("%s%020d%s", filePrefix, seq, fileSuffix)
+}
+
+// parseName extrai a sequência do nome de um arquivo de snapshot
+func parseName(name string) (uint64, bool) {
+	if !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix), 10, 64)
+	return seq, err == nil
+}
@@ -0,0 +1,487 @@
+package fix
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/portfolio"
+)
+
+// DefaultCompID é o SenderCompID padrão do gateway
+const DefaultCompID = "TRADING"
+
+// defaultLogonTimeout é o prazo padrão para o cliente enviar o Logon
+const defaultLogonTimeout = 10 * time.Second
+
+// monitorInterval é o intervalo de verificação de heartbeats
+const monitorInterval = 250 * time.Millisecond
+
+// OrderService executa as operações de ordem no matching engine
+type OrderService interface {
+	ProcessOrder(ctx context.Context, order *domain.Order) *matching.MatchResult
+	CancelOrder(ctx context.Context, orderID string) (*domain.Order, error)
+	AmendOrder(ctx context.Context, orderID string, quantity int, price float64) (*matching.MatchResult, error)
+	GetOrder(orderID string) (*domain.Order, error)
+}
+
+// PortfolioProvider fornece usuários e as regras de saldo, posição e perfil
+type PortfolioProvider interface {
+	GetUser(userID string) (portfolio.User, error)
+	ValidateOrder(ctx context.Context, order *domain.Order) error
+}
+
+// OrderValidator aplica as regras de ações e horário de mercado
+type OrderValidator interface {
+	ValidateOrder(order *domain.Order) error
+}
+
+// Config configura o acceptor FIX
+type Config struct {
+	// CompID é o SenderCompID do gateway (TargetCompID dos clientes)
+	CompID string
+
+	// Clients associa o CompID de cada cliente à sua senha e às contas que
+	// ele pode operar. Sem clientes configurados, nenhum Logon é aceito.
+	Clients map[string]Client
+
+	// LogonTimeout é o prazo para a primeira mensagem da conexão
+	LogonTimeout time.Duration
+}
+
+// Client é um cliente FIX autorizado: a senha exigida em Password (554) no
+// Logon e as contas (user_id) que ele pode operar
+type Client struct {
+	Password string
+	Accounts []string
+}
+
+// authenticates compara a senha do Logon em tempo constante
+func (c Client) authenticates(password string) bool {
+	return c.Password != "" && subtle.ConstantTimeCompare([]byte(password), []byte(c.Password)) == 1
+}
+
+// ConfigFromEnv lê FIX_COMP_ID e FIX_CLIENTS
+func ConfigFromEnv() (Config, error) {
+	clients, err := ParseClients(os.Getenv("FIX_CLIENTS"))
+	if err != nil {
+		return Config{}, err
+	}
+	return Config{CompID: os.Getenv("FIX_COMP_ID"), Clients: clients}, nil
+}
+
+// ParseClients lê a lista de clientes no formato
+// "CLIENTE1:senha1=conta1|conta2,CLIENTE2:senha2=conta3". Senha e ao menos
+// uma conta são obrigatórias.
+func ParseClients(value string) (map[string]Client, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	clients := make(map[string]Client)
+	for _, entry := range strings.Split(value, ",") {
+		credentials, accounts, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		compID, password, _ := strings.Cut(credentials, ":")
+		if compID == "" {
+			return nil, fmt.Errorf("cliente FIX sem CompID em %q", entry)
+		}
+		if password == "" {
+			return nil, fmt.Errorf("cliente FIX %s sem senha", compID)
+		}
+
+		client := Client{Password: password}
+		for _, account := range strings.Split(accounts, "|") {
+			if account = strings.TrimSpace(account); account != "" {
+				client.Accounts = append(client.Accounts, account)
+			}
+		}
+		if len(client.Accounts) == 0 {
+			return nil, fmt.Errorf("cliente FIX %s sem contas", compID)
+		}
+		clients[compID] = client
+	}
+	return clients, nil
+}
+
+// Acceptor é o gateway FIX 4.4 de entrada de ordens. Cada cliente tem uma
+// sessão com sequências próprias; os ExecutionReports de aceite, execução e
+// cancelamento são gerados a partir dos eventos do engine (HandleEvent).
+type Acceptor struct {
+	cfg        Config
+	matcher    OrderService
+	portfolios PortfolioProvider
+	validator  OrderValidator
+
+	mutex     sync.Mutex
+	sessions  map[string]*session
+	orders    map[string]*trackedOrder
+	listeners map[net.Listener]struct{}
+	closed    bool
+
+	startedAt time.Time
+	execSeq   atomic.Uint64
+}
+
+// NewAcceptor cria o gateway FIX sobre o matching engine
+func NewAcceptor(cfg Config, matcher OrderService, portfolios PortfolioProvider, validator OrderValidator) *Acceptor {
+	if cfg.CompID == "" {
+		cfg.CompID = DefaultCompID
+	}
+	if cfg.LogonTimeout <= 0 {
+		cfg.LogonTimeout = defaultLogonTimeout
+	}
+	return &Acceptor{
+		cfg:        cfg,
+		matcher:    matcher,
+		portfolios: portfolios,
+		validator:  validator,
+		sessions:   make(map[string]*session),
+		orders:     make(map[string]*trackedOrder),
+		listeners:  make(map[net.Listener]struct{}),
+		startedAt:  time.Now().UTC(),
+	}
+}
+
+// ListenAndServe aceita conexões FIX no endereço informado
+func (a *Acceptor) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return a.Serve(listener)
+}
+
+// Serve aceita conexões do listener até Close
+func (a *Acceptor) Serve(listener net.Listener) error {
+	a.mutex.Lock()
+	if a.closed {
+		a.mutex.Unlock()
+		listener.Close()
+		return nil
+	}
+	a.listeners[listener] = struct{}{}
+	a.mutex.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			a.mutex.Lock()
+			closed := a.closed
+			a.mutex.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		go a.handle(conn)
+	}
+}
+
+// Close encerra os listeners e envia Logout às sessões conectadas
+func (a *Acceptor) Close() error {
+	a.mutex.Lock()
+	a.closed = true
+	for listener := range a.listeners {
+		listener.Close()
+	}
+	sessions := make([]*session, 0, len(a.sessions))
+	for _, sess := range a.sessions {
+		sessions = append(sessions, sess)
+	}
+	a.mutex.Unlock()
+
+	for _, sess := range sessions {
+		sess.mutex.Lock()
+		if sess.conn != nil {
+			sess.sendLocked(NewMessage(MsgLogout).Set(TagText, "gateway encerrado"))
+			if sess.conn != nil {
+				sess.conn.Close()
+			}
+			sess.conn = nil
+		}
+		sess.mutex.Unlock()
+	}
+	return nil
+}
+
+// handle conduz uma conexão: Logon, mensagens e desconexão
+func (a *Acceptor) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	conn.SetReadDeadline(time.Now().Add(a.cfg.LogonTimeout))
+	logon, err := ReadMessage(reader)
+	if err != nil {
+		slog.Warn("FIX: conexão sem Logon", "addr", conn.RemoteAddr().String(), "error", err)
+		return
+	}
+	sess, err := a.logon(conn, logon)
+	if err != nil {
+		slog.Warn("FIX: Logon recusado", "addr", conn.RemoteAddr().String(), "error", err)
+		return
+	}
+	defer a.detach(sess, conn)
+	conn.SetReadDeadline(time.Time{})
+
+	done := make(chan struct{})
+	defer close(done)
+	go a.monitor(sess, conn, done)
+
+	for {
+		msg, err := ReadMessage(reader)
+		if errors.Is(err, ErrGarbled) {
+			// Mensagem corrompida é descartada; a lacuna será pedida via ResendRequest
+			slog.Warn("FIX: mensagem corrompida", "session", sess.clientID, "error", err)
+			continue
+		}
+		if err != nil {
+			return
+		}
+		if !a.receive(sess, msg) {
+			return
+		}
+	}
+}
+
+// logon valida o Logon e associa a conexão à sessão do cliente
+func (a *Acceptor) logon(conn net.Conn, msg *Message) (*session, error) {
+	if msg.Type() != MsgLogon {
+		return nil, fmt.Errorf("primeira mensagem deve ser Logon, recebido %s", msg.Type())
+	}
+
+	clientID := msg.Get(TagSenderCompID)
+	if msg.Get(TagTargetCompID) != a.cfg.CompID || clientID == "" {
+		writeLogout(conn, clientID, a.cfg.CompID, "CompID inválido")
+		return nil, fmt.Errorf("CompID inválido: %s -> %s", clientID, msg.Get(TagTargetCompID))
+	}
+	client, known := a.cfg.Clients[clientID]
+	if !known || !client.authenticates(msg.Get(TagPassword)) {
+		writeLogout(conn, clientID, a.cfg.CompID, "cliente não autorizado")
+		return nil, fmt.Errorf("cliente %s não autorizado", clientID)
+	}
+	heartBtInt, err := msg.Int(TagHeartBtInt)
+	if err != nil || heartBtInt < 0 {
+		writeLogout(conn, clientID, a.cfg.CompID, "HeartBtInt inválido")
+		return nil, fmt.Errorf("HeartBtInt inválido: %q", msg.Get(TagHeartBtInt))
+	}
+
+	a.mutex.Lock()
+	sess, exists := a.sessions[clientID]
+	if !exists {
+		sess = newSession(clientID, a.cfg.CompID, client.Accounts)
+		a.sessions[clientID] = sess
+	}
+	a.mutex.Unlock()
+
+	sess.mutex.Lock()
+	defer sess.mutex.Unlock()
+
+	if sess.conn != nil {
+		writeLogout(conn, clientID, a.cfg.CompID, "sessão já conectada")
+		return nil, fmt.Errorf("sessão %s já conectada", clientID)
+	}
+
+	reset := msg.Flag(TagResetSeqNumFlag)
+	if reset {
+		sess.resetLocked()
+	}
+
+	sess.conn = conn
+	sess.heartBtInt = time.Duration(heartBtInt) * time.Second
+	sess.lastReceived = time.Now()
+	sess.testReqSent = false
+
+	seq := msg.SeqNum()
+	if seq < sess.inSeq {
+		sess.sendLocked(NewMessage(MsgLogout).Set(TagText, fmt.Sprintf("MsgSeqNum too low, expecting %d but received %d", sess.inSeq, seq)))
+		sess.conn = nil
+		return nil, fmt.Errorf("sessão %s: MsgSeqNum %d menor que o esperado %d", clientID, seq, sess.inSeq)
+	}
+
+	response := NewMessage(MsgLogon).Set(TagEncryptMethod, "0").SetInt(TagHeartBtInt, heartBtInt)
+	if reset {
+		response.Set(TagResetSeqNumFlag, "Y")
+	}
+	sess.sendLocked(response)
+
+	if seq > sess.inSeq {
+		sess.requestResendLocked(seq)
+	} else {
+		sess.inSeq++
+	}
+
+	slog.Info("FIX: sessão conectada", "session", clientID, "addr", conn.RemoteAddr().String())
+	return sess, nil
+}
+
+// detach desassocia a conexão encerrada da sessão
+func (a *Acceptor) detach(sess *session, conn net.Conn) {
+	sess.mutex.Lock()
+	defer sess.mutex.Unlock()
+
+	if sess.conn == conn {
+		sess.conn = nil
+	}
+	slog.Info("FIX: sessão desconectada", "session", sess.clientID)
+}
+
+// receive aplica as regras de sessão e despacha as mensagens de aplicação.
+// Retorna false quando a conexão deve ser encerrada.
+func (a *Acceptor) receive(sess *session, msg *Message) bool {
+	sess.mutex.Lock()
+	dispatch, keep := a.receiveLocked(sess, msg)
+	sess.mutex.Unlock()
+
+	// Ordens são processadas sem o lock da sessão, que também é usado pelos
+	// relatórios gerados a partir dos eventos do engine
+	if dispatch {
+		a.handleApplication(sess, msg)
+	}
+	return keep
+}
+
+func (a *Acceptor) receiveLocked(sess *session, msg *Message) (dispatch, keep bool) {
+	sess.lastReceived = time.Now()
+	sess.testReqSent = false
+
+	if msg.Get(TagSenderCompID) != sess.clientID || msg.Get(TagTargetCompID) != sess.gatewayID {
+		sess.sendLocked(sessionReject(msg, TagSenderCompID, sessionRejectCompIDProblem, "CompID inválido"))
+		sess.sendLocked(NewMessage(MsgLogout).Set(TagText, "CompID inválido"))
+		return false, false
+	}
+
+	seq := msg.SeqNum()
+	if seq <= 0 {
+		sess.sendLocked(NewMessage(MsgLogout).Set(TagText, "MsgSeqNum ausente"))
+		return false, false
+	}
+
+	// SequenceReset-Reset ignora a sequência da própria mensagem
+	if msg.Type() == MsgSequenceReset && !msg.Flag(TagGapFillFlag) {
+		sess.applySeqResetLocked(msg)
+		return false, true
+	}
+
+	switch {
+	case seq > sess.inSeq:
+		switch msg.Type() {
+		case MsgLogout:
+			sess.sendLocked(NewMessage(MsgLogout))
+			return false, false
+		case MsgResendRequest:
+			sess.resendLocked(resendRange(msg))
+		}
+		sess.requestResendLocked(seq)
+		return false, true
+
+	case seq < sess.inSeq:
+		if msg.Flag(TagPossDupFlag) {
+			// Duplicata de mensagem já processada
+			return false, true
+		}
+		sess.sendLocked(NewMessage(MsgLogout).Set(TagText, fmt.Sprintf("MsgSeqNum too low, expecting %d but received %d", sess.inSeq, seq)))
+		return false, false
+	}
+
+	sess.inSeq++
+
+	switch msg.Type() {
+	case MsgHeartbeat, MsgReject:
+	case MsgTestRequest:
+		sess.sendLocked(NewMessage(MsgHeartbeat).Set(TagTestReqID, msg.Get(TagTestReqID)))
+	case MsgResendRequest:
+		sess.resendLocked(resendRange(msg))
+	case MsgSequenceReset:
+		sess.applySeqResetLocked(msg)
+	case MsgLogout:
+		sess.sendLocked(NewMessage(MsgLogout))
+		return false, false
+	case MsgLogon:
+		sess.sendLocked(sessionReject(msg, TagMsgType, sessionRejectIncorrectValue, "sessão já autenticada"))
+	case MsgNewOrderSingle, MsgOrderCancelRequest, MsgOrderCancelReplace:
+		return true, true
+	default:
+		sess.sendLocked(NewMessage(MsgBusinessMessageReject).
+			SetInt(TagRefSeqNum, seq).
+			Set(TagRefMsgType, msg.Type()).
+			Set(TagBusinessRejectRsn, businessRejectUnsupported).
+			Set(TagText, "tipo de mensagem não suportado"))
+	}
+	return false, true
+}
+
+// monitor envia heartbeats, testa clientes silenciosos e derruba a conexão
+// quando o cliente não responde
+func (a *Acceptor) monitor(sess *session, conn net.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			sess.mutex.Lock()
+			if sess.conn != conn {
+				sess.mutex.Unlock()
+				return
+			}
+			interval := sess.heartBtInt
+			if interval > 0 {
+				grace := interval / 5
+				idle := now.Sub(sess.lastReceived)
+				switch {
+				case sess.testReqSent && idle > 2*interval+grace:
+					slog.Warn("FIX: sem resposta ao TestRequest, desconectando", "session", sess.clientID)
+					conn.Close()
+				case !sess.testReqSent && idle > interval+grace:
+					sess.testReqSent = true
+					sess.sendLocked(NewMessage(MsgTestRequest).Set(TagTestReqID, fmt.Sprintf("TEST-%d", now.UnixNano())))
+				case now.Sub(sess.lastSent) >= interval:
+					sess.sendLocked(NewMessage(MsgHeartbeat))
+				}
+			}
+			sess.mutex.Unlock()
+		}
+	}
+}
+
+// resendRange lê BeginSeqNo e EndSeqNo de um ResendRequest
+func resendRange(msg *Message) (int, int) {
+	begin, _ := msg.Int(TagBeginSeqNo)
+	end, _ := msg.Int(TagEndSeqNo)
+	return begin, end
+}
+
+// sessionReject monta um Reject (35=3) referente à mensagem recebida
+func sessionReject(msg *Message, tag int, reason, text string) *Message {
+	return NewMessage(MsgReject).
+		SetInt(TagRefSeqNum, msg.SeqNum()).
+		SetInt(TagRefTagID, tag).
+		Set(TagRefMsgType, msg.Type()).
+		Set(TagSessionRejectReason, reason).
+		Set(TagText, text)
+}
+
+// writeLogout responde a um Logon recusado, fora de qualquer sessão
+func writeLogout(conn net.Conn, clientID, compID, text string) {
+	msg := NewMessage(MsgLogout).
+		Set(TagSenderCompID, compID).
+		Set(TagTargetCompID, clientID).
+		SetInt(TagMsgSeqNum, 1).
+		SetTime(TagSendingTime, time.Now()).
+		Set(TagText, text)
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	conn.Write(msg.Bytes())
+}
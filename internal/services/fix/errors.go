@@ -0,0 +1,107 @@
+package fix
+
+import (
+	"errors"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/api"
+)
+
+// Erros próprios do gateway
+var (
+	errAccountNotAllowed = errors.New("conta não autorizada para esta sessão")
+	errDuplicateClOrdID  = errors.New("ClOrdID já utilizado nesta sessão")
+	errUnsupportedType   = errors.New("apenas ordens limitadas (OrdType=2) são aceitas")
+)
+
+// Valores de OrdRejReason (103) e CxlRejReason (102)
+const (
+	ordRejBrokerOption   = "0"
+	ordRejUnknownSymbol  = "1"
+	ordRejExchangeClosed = "2"
+	ordRejExceedsLimit   = "3"
+	ordRejUnknownOrder   = "5"
+	ordRejDuplicateOrder = "6"
+	ordRejUnsupported    = "11"
+	ordRejIncorrectQty   = "13"
+	ordRejUnknownAccount = "15"
+	ordRejOther          = "99"
+
+	cxlRejTooLate      = "0"
+	cxlRejUnknownOrder = "1"
+	cxlRejDuplicate    = "6"
+	cxlRejOther        = "99"
+)
+
+// gatewayErrors são os erros próprios do gateway e do transporte com o
+// engine; os demais códigos vêm de domain.ErrorCode
+var gatewayErrors = []struct {
+	err  error
+	code string
+}{
+	{api.ErrUnavailable, api.CodeUnavailable},
+	{errAccountNotAllowed, "ACCOUNT_NOT_ALLOWED"},
+	{errDuplicateClOrdID, "DUPLICATE_CL_ORD_ID"},
+	{errUnsupportedType, "UNSUPPORTED_ORDER_TYPE"},
+}
+
+// rejectReason associa um código estável, enviado em Text (58), aos motivos
+// FIX de rejeição de ordem e de cancelamento
+type rejectReason struct {
+	code   string
+	ordRej string
+	cxlRej string
+}
+
+// rejectReasons traduz os códigos da API REST para OrdRejReason e CxlRejReason
+var rejectReasons = []rejectReason{
+	{"INVALID_ORDER", ordRejOther, cxlRejOther},
+	{"INVALID_ORDER_SIDE", ordRejOther, cxlRejOther},
+	{"INVALID_QUANTITY", ordRejIncorrectQty, cxlRejOther},
+	{"INVALID_PRICE", ordRejOther, cxlRejOther},
+	{"INVALID_SYMBOL", ordRejUnknownSymbol, cxlRejOther},
+	{"INVALID_USER", ordRejUnknownAccount, cxlRejOther},
+	{"INVALID_CLIENT_ORDER_ID", ordRejOther, cxlRejOther},
+	{"USER_NOT_FOUND", ordRejUnknownAccount, cxlRejOther},
+	{"ORDER_NOT_FOUND", ordRejUnknownOrder, cxlRejUnknownOrder},
+	{"SNAPSHOT_NOT_FOUND", ordRejOther, cxlRejOther},
+	{"ORDER_NOT_OPEN", ordRejOther, cxlRejTooLate},
+	{"CLIENT_ORDER_ID_CONFLICT", ordRejDuplicateOrder, cxlRejDuplicate},
+	{"PRICE_TOO_LOW", ordRejOther, cxlRejOther},
+	{"INSUFFICIENT_BALANCE", ordRejOther, cxlRejOther},
+	{"INSUFFICIENT_POSITION", ordRejOther, cxlRejOther},
+	{"EXCEEDS_PROFILE_LIMIT", ordRejExceedsLimit, cxlRejOther},
+	{"NO_MATCH", ordRejOther, cxlRejOther},
+	{"MARKET_CLOSED", ordRejExchangeClosed, cxlRejOther},
+	{api.CodeUnavailable, ordRejBrokerOption, cxlRejOther},
+	{"ACCOUNT_NOT_ALLOWED", ordRejUnknownAccount, cxlRejOther},
+	{"DUPLICATE_CL_ORD_ID", ordRejDuplicateOrder, cxlRejDuplicate},
+	{"UNSUPPORTED_ORDER_TYPE", ordRejUnsupported, cxlRejOther},
+}
+
+// errorCode retorna o código estável de um erro do gateway ou de domínio
+func errorCode(err error) string {
+	for _, g := range gatewayErrors {
+		if errors.Is(err, g.err) {
+			return g.code
+		}
+	}
+	return domain.ErrorCode(err)
+}
+
+// lookupReason retorna o motivo do erro; códigos sem motivo FIX próprio, e
+// INTERNAL_ERROR para erros desconhecidos, usam Other (99)
+func lookupReason(err error) rejectReason {
+	code := errorCode(err)
+	for _, reason := range rejectReasons {
+		if reason.code == code {
+			return reason
+		}
+	}
+	return rejectReason{code: code, ordRej: ordRejOther, cxlRej: cxlRejOther}
+}
+
+// rejectText monta o Text (58) com o código estável e a mensagem do erro
+func rejectText(err error) string {
+	return lookupReason(err).code + ": " + err.Error()
+}
@@ -0,0 +1,323 @@
+package fix
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BeginString é a versão do protocolo aceita pelo gateway
+const BeginString = "FIX.4.4"
+
+// SOH separa os campos de uma mensagem FIX
+const SOH = '\x01'
+
+// maxBodyLength limita o tamanho de uma mensagem recebida
+const maxBodyLength = 64 * 1024
+
+// sendingTimeLayout é o formato UTCTimestamp com milissegundos
+const sendingTimeLayout = "20060102-15:04:05.000"
+
+// ErrGarbled indica uma mensagem mal formada (tamanho, checksum ou campos);
+// pela especificação ela é descartada sem afetar a sequência
+var ErrGarbled = errors.New("mensagem FIX mal formada")
+
+// Tags usadas pelo gateway
+const (
+	TagAccount             = 1
+	TagAvgPx               = 6
+	TagBeginSeqNo          = 7
+	TagBeginString         = 8
+	TagBodyLength          = 9
+	TagCheckSum            = 10
+	TagClOrdID             = 11
+	TagCumQty              = 14
+	TagEndSeqNo            = 16
+	TagExecID              = 17
+	TagLastPx              = 31
+	TagLastQty             = 32
+	TagMsgSeqNum           = 34
+	TagMsgType             = 35
+	TagNewSeqNo            = 36
+	TagOrderID             = 37
+	TagOrderQty            = 38
+	TagOrdStatus           = 39
+	TagOrdType             = 40
+	TagOrigClOrdID         = 41
+	TagPossDupFlag         = 43
+	TagPrice               = 44
+	TagRefSeqNum           = 45
+	TagSenderCompID        = 49
+	TagSendingTime         = 52
+	TagSide                = 54
+	TagSymbol              = 55
+	TagTargetCompID        = 56
+	TagText                = 58
+	TagTransactTime        = 60
+	TagPossResend          = 97
+	TagEncryptMethod       = 98
+	TagCxlRejReason        = 102
+	TagOrdRejReason        = 103
+	TagHeartBtInt          = 108
+	TagTestReqID           = 112
+	TagOrigSendingTime     = 122
+	TagGapFillFlag         = 123
+	TagResetSeqNumFlag     = 141
+	TagExecType            = 150
+	TagLeavesQty           = 151
+	TagRefTagID            = 371
+	TagRefMsgType          = 372
+	TagSessionRejectReason = 373
+	TagBusinessRejectRefID = 379
+	TagBusinessRejectRsn   = 380
+	TagCxlRejResponseTo    = 434
+	TagPassword            = 554
+	TagTrdMatchID          = 880
+)
+
+// Tipos de mensagem (MsgType)
+const (
+	MsgHeartbeat             = "0"
+	MsgTestRequest           = "1"
+	MsgResendRequest         = "2"
+	MsgReject                = "3"
+	MsgSequenceReset         = "4"
+	MsgLogout                = "5"
+	MsgExecutionReport       = "8"
+	MsgOrderCancelReject     = "9"
+	MsgLogon                 = "A"
+	MsgNewOrderSingle        = "D"
+	MsgOrderCancelRequest    = "F"
+	MsgOrderCancelReplace    = "G"
+	MsgBusinessMessageReject = "j"
+)
+
+// Motivos de Reject (373) e BusinessMessageReject (380)
+const (
+	sessionRejectRequiredTag    = "1"
+	sessionRejectIncorrectValue = "5"
+	sessionRejectCompIDProblem  = "9"
+	businessRejectUnsupported   = "3"
+)
+
+// headerTags são escritas logo após BodyLength, nesta ordem
+var headerTags = []int{TagMsgType, TagSenderCompID, TagTargetCompID, TagMsgSeqNum, TagPossDupFlag, TagPossResend, TagSendingTime, TagOrigSendingTime}
+
+// Field é um par tag=valor
+type Field struct {
+	Tag   int
+	Value string
+}
+
+// Message é uma mensagem FIX com os campos na ordem em que foram definidos.
+// BeginString, BodyLength e CheckSum são calculados na codificação.
+type Message struct {
+	Fields []Field
+}
+
+// NewMessage cria uma mensagem do tipo informado
+func NewMessage(msgType string) *Message {
+	return &Message{Fields: []Field{{TagMsgType, msgType}}}
+}
+
+// Type retorna o MsgType
+func (m *Message) Type() string {
+	return m.Get(TagMsgType)
+}
+
+// Get retorna o valor da tag ou "" se ausente
+func (m *Message) Get(tag int) string {
+	for _, field := range m.Fields {
+		if field.Tag == tag {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// Has informa se a tag está presente
+func (m *Message) Has(tag int) bool {
+	for _, field := range m.Fields {
+		if field.Tag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Set define o valor da tag, substituindo o anterior
+func (m *Message) Set(tag int, value string) *Message {
+	for i := range m.Fields {
+		if m.Fields[i].Tag == tag {
+			m.Fields[i].Value = value
+			return m
+		}
+	}
+	m.Fields = append(m.Fields, Field{tag, value})
+	return m
+}
+
+// SetInt define um valor inteiro
+func (m *Message) SetInt(tag int, value int) *Message {
+	return m.Set(tag, strconv.Itoa(value))
+}
+
+// SetFloat define um valor decimal sem zeros à direita
+func (m *Message) SetFloat(tag int, value float64) *Message {
+	return m.Set(tag, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// SetTime define um UTCTimestamp
+func (m *Message) SetTime(tag int, value time.Time) *Message {
+	return m.Set(tag, value.UTC().Format(sendingTimeLayout))
+}
+
+// Int retorna o valor inteiro da tag
+func (m *Message) Int(tag int) (int, error) {
+	return strconv.Atoi(m.Get(tag))
+}
+
+// Float retorna o valor decimal da tag
+func (m *Message) Float(tag int) (float64, error) {
+	return strconv.ParseFloat(m.Get(tag), 64)
+}
+
+// SeqNum retorna o MsgSeqNum (0 se ausente ou inválido)
+func (m *Message) SeqNum() int {
+	seq, _ := m.Int(TagMsgSeqNum)
+	return seq
+}
+
+// Flag informa se a tag booleana vale Y
+func (m *Message) Flag(tag int) bool {
+	return m.Get(tag) == "Y"
+}
+
+// Clone retorna uma cópia independente da mensagem
+func (m *Message) Clone() *Message {
+	return &Message{Fields: append([]Field(nil), m.Fields...)}
+}
+
+// Bytes codifica a mensagem com BeginString, BodyLength e CheckSum. Campos
+// vazios são omitidos, pois FIX não admite valores vazios.
+func (m *Message) Bytes() []byte {
+	var body bytes.Buffer
+	for _, tag := range headerTags {
+		if value := m.Get(tag); value != "" {
+			writeField(&body, tag, value)
+		}
+	}
+	for _, field := range m.Fields {
+		if field.Value == "" {
+			continue
+		}
+		if !isHeaderTag(field.Tag) && field.Tag != TagBeginString && field.Tag != TagBodyLength && field.Tag != TagCheckSum {
+			writeField(&body, field.Tag, field.Value)
+		}
+	}
+
+	var out bytes.Buffer
+	writeField(&out, TagBeginString, BeginString)
+	writeField(&out, TagBodyLength, strconv.Itoa(body.Len()))
+	out.Write(body.Bytes())
+	writeField(&out, TagCheckSum, fmt.Sprintf("%03d", checksum(out.Bytes())))
+	return out.Bytes()
+}
+
+// String mostra a mensagem com | no lugar de SOH, para logs
+func (m *Message) String() string {
+	return strings.ReplaceAll(string(m.Bytes()), string(SOH), "|")
+}
+
+// ReadMessage lê a próxima mensagem do fluxo, conferindo BeginString,
+// BodyLength e CheckSum. Erros de rede são devolvidos como estão; mensagens
+// inválidas resultam em ErrGarbled.
+func ReadMessage(r *bufio.Reader) (*Message, error) {
+	begin, err := r.ReadString(SOH)
+	if err != nil {
+		return nil, err
+	}
+	if begin != "8="+BeginString+string(SOH) {
+		return nil, fmt.Errorf("%w: BeginString %q", ErrGarbled, strings.TrimSuffix(begin, string(SOH)))
+	}
+
+	lengthField, err := r.ReadString(SOH)
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(lengthField, "9="), string(SOH)))
+	if !strings.HasPrefix(lengthField, "9=") || err != nil || length <= 0 || length > maxBodyLength {
+		return nil, fmt.Errorf("%w: BodyLength %q", ErrGarbled, strings.TrimSuffix(lengthField, string(SOH)))
+	}
+
+	// Corpo mais "10=nnn<SOH>"
+	raw := make([]byte, length+7)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		return nil, err
+	}
+	body, trailer := raw[:length], raw[length:]
+	if !bytes.HasPrefix(trailer, []byte("10=")) || trailer[6] != SOH {
+		return nil, fmt.Errorf("%w: CheckSum ausente", ErrGarbled)
+	}
+
+	sum := checksum([]byte(begin)) + checksum([]byte(lengthField)) + checksum(body)
+	if fmt.Sprintf("%03d", sum%256) != string(trailer[3:6]) {
+		return nil, fmt.Errorf("%w: CheckSum %s", ErrGarbled, trailer[3:6])
+	}
+
+	msg, err := parseFields(body)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Fields[0].Tag != TagMsgType {
+		return nil, fmt.Errorf("%w: MsgType deve ser o primeiro campo", ErrGarbled)
+	}
+	return msg, nil
+}
+
+// parseFields decodifica uma sequência de campos tag=valor<SOH>
+func parseFields(data []byte) (*Message, error) {
+	if len(data) == 0 || data[len(data)-1] != SOH {
+		return nil, fmt.Errorf("%w: campo sem terminador", ErrGarbled)
+	}
+
+	msg := &Message{}
+	for _, raw := range bytes.Split(data[:len(data)-1], []byte{SOH}) {
+		tag, value, found := bytes.Cut(raw, []byte{'='})
+		number, err := strconv.Atoi(string(tag))
+		if !found || err != nil || number <= 0 {
+			return nil, fmt.Errorf("%w: campo %q", ErrGarbled, raw)
+		}
+		msg.Fields = append(msg.Fields, Field{number, string(value)})
+	}
+	return msg, nil
+}
+
+func writeField(buf *bytes.Buffer, tag int, value string) {
+	buf.WriteString(strconv.Itoa(tag))
+	buf.WriteByte('=')
+	buf.WriteString(value)
+	buf.WriteByte(SOH)
+}
+
+func isHeaderTag(tag int) bool {
+	for _, header := range headerTags {
+		if header == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func checksum(data []byte) int {
+	sum := 0
+	for _, b := range data {
+		sum += int(b)
+	}
+	return sum % 256
+}
@@ -0,0 +1,464 @@
+package fix
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"trading/internal/domain"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/shared/tracing"
+)
+
+// Valores de ExecType (150), OrdStatus (39), Side (54), OrdType (40) e
+// CxlRejResponseTo (434)
+const (
+	execTypeNew      = "0"
+	execTypeCanceled = "4"
+	execTypeReplaced = "5"
+	execTypeRejected = "8"
+	execTypeTrade    = "F"
+
+	sideBuy  = "1"
+	sideSell = "2"
+
+	ordTypeLimit = "2"
+
+	cxlRejToCancel  = "1"
+	cxlRejToReplace = "2"
+)
+
+var ordStatuses = map[domain.OrderStatus]string{
+	domain.PENDING:   "0",
+	domain.PARTIAL:   "1",
+	domain.FILLED:    "2",
+	domain.CANCELLED: "4",
+	domain.REJECTED:  "8",
+}
+
+var sides = map[string]domain.OrderSide{
+	sideBuy:  domain.BUY,
+	sideSell: domain.SELL,
+}
+
+// trackedOrder é uma ordem aberta enviada por uma sessão FIX
+type trackedOrder struct {
+	session *session
+	clOrdID string
+
+	// pendingClOrdID é o ClOrdID do cancelamento ou da alteração em andamento
+	pendingClOrdID string
+
+	accepted bool
+	notional float64
+}
+
+// handleApplication processa NewOrderSingle, OrderCancelRequest e
+// OrderCancelReplaceRequest. Cada mensagem recebe um request ID próprio,
+// que acompanha os logs do matching e do portfolio.
+func (a *Acceptor) handleApplication(sess *session, msg *Message) {
+	ctx := logging.WithRequestID(context.Background(), logging.NewRequestID())
+	switch msg.Type() {
+	case MsgNewOrderSingle:
+		a.newOrder(ctx, sess, msg)
+	case MsgOrderCancelRequest:
+		a.cancelOrder(ctx, sess, msg)
+	case MsgOrderCancelReplace:
+		a.replaceOrder(ctx, sess, msg)
+	}
+}
+
+// newOrder valida a ordem com o mesmo pipeline da API REST e a envia ao
+// matching. Aceite e execuções são reportados pelos eventos do engine;
+// rejeições, aqui.
+func (a *Acceptor) newOrder(ctx context.Context, sess *session, msg *Message) {
+	if !requireTags(sess, msg, TagClOrdID, TagAccount, TagSymbol, TagSide, TagOrderQty, TagOrdType) {
+		return
+	}
+
+	clOrdID := msg.Get(TagClOrdID)
+	order := domain.NewOrder(msg.Get(TagAccount), strings.ToUpper(msg.Get(TagSymbol)), sides[msg.Get(TagSide)], quantityFrom(msg), priceFrom(msg))
+	order.ClientOrderID = clOrdID
+
+	ctx, span := tracing.Start(ctx, "fix.NewOrderSingle", tracing.OrderAttrs(order)...)
+	defer span.End()
+
+	if err := a.checkOrder(ctx, sess, msg, order); err != nil {
+		span.RecordError(err)
+		order.Status = domain.REJECTED
+		sess.send(a.rejectReport(order, clOrdID, err))
+		return
+	}
+
+	a.mutex.Lock()
+	a.orders[order.ID] = &trackedOrder{session: sess, clOrdID: clOrdID}
+	a.mutex.Unlock()
+
+	result := a.matcher.ProcessOrder(ctx, order)
+	span.RecordError(result.Err)
+	switch {
+	case result.Rejected:
+		a.untrack(order.ID)
+		sess.send(a.rejectReport(result.Order, clOrdID, result.Err))
+	case result.Replayed:
+		// O ClOrdID já chegou ao engine (antes de um reinício do gateway ou
+		// via REST): a sessão passa a acompanhar a ordem original
+		a.untrack(order.ID)
+		a.adopt(sess, clOrdID, result.Order.ID)
+		order.Status = domain.REJECTED
+		sess.send(a.rejectReport(order, clOrdID, errDuplicateClOrdID))
+	}
+}
+
+// adopt associa o ClOrdID à ordem original no engine, para que cancelamentos
+// e execuções futuras cheguem à sessão
+func (a *Acceptor) adopt(sess *session, clOrdID, orderID string) {
+	sess.mutex.Lock()
+	sess.clOrdIDs[clOrdID] = orderID
+	sess.mutex.Unlock()
+
+	order, err := a.matcher.GetOrder(orderID)
+	if err != nil || order.IsComplete() {
+		return
+	}
+	a.mutex.Lock()
+	if _, tracked := a.orders[orderID]; !tracked {
+		a.orders[orderID] = &trackedOrder{session: sess, clOrdID: clOrdID, accepted: true}
+	}
+	a.mutex.Unlock()
+}
+
+// checkOrder aplica as validações e reserva o ClOrdID na sessão
+func (a *Acceptor) checkOrder(ctx context.Context, sess *session, msg *Message, order *domain.Order) error {
+	if !sess.claim(msg.Get(TagClOrdID), order.ID) {
+		return errDuplicateClOrdID
+	}
+
+	switch {
+	case msg.Get(TagOrdType) != ordTypeLimit:
+		return errUnsupportedType
+	case !sess.allows(order.UserID):
+		return errAccountNotAllowed
+	}
+	if err := order.Validate(); err != nil {
+		return err
+	}
+
+	// 1. Usuário existe
+	if _, err := a.portfolios.GetUser(order.UserID); err != nil {
+		return err
+	}
+	// 2. Símbolo, preço mínimo e horário de mercado
+	if err := a.validateRules(ctx, order); err != nil {
+		return err
+	}
+	// 3. Saldo/posição e limites do perfil
+	return a.portfolios.ValidateOrder(ctx, order)
+}
+
+// cancelOrder cancela a ordem identificada por OrigClOrdID
+func (a *Acceptor) cancelOrder(ctx context.Context, sess *session, msg *Message) {
+	if !requireTags(sess, msg, TagClOrdID, TagOrigClOrdID) {
+		return
+	}
+
+	orderID, err := a.prepareCancel(sess, msg)
+	if err == nil {
+		_, err = a.matcher.CancelOrder(ctx, orderID)
+	}
+	if err != nil {
+		a.clearPending(orderID)
+		sess.send(a.cancelReject(msg, orderID, cxlRejToCancel, err))
+	}
+}
+
+// replaceOrder altera quantidade e preço da ordem identificada por OrigClOrdID
+func (a *Acceptor) replaceOrder(ctx context.Context, sess *session, msg *Message) {
+	if !requireTags(sess, msg, TagClOrdID, TagOrigClOrdID, TagOrderQty, TagPrice, TagOrdType) {
+		return
+	}
+
+	orderID, err := a.prepareCancel(sess, msg)
+	if err == nil {
+		err = a.amend(ctx, orderID, msg)
+	}
+	if err != nil {
+		a.clearPending(orderID)
+		sess.send(a.cancelReject(msg, orderID, cxlRejToReplace, err))
+	}
+}
+
+// amend valida os novos parâmetros como a API REST e altera a ordem
+func (a *Acceptor) amend(ctx context.Context, orderID string, msg *Message) error {
+	if msg.Get(TagOrdType) != ordTypeLimit {
+		return errUnsupportedType
+	}
+
+	current, err := a.matcher.GetOrder(orderID)
+	if err != nil {
+		return err
+	}
+	amended := current.Clone()
+	amended.Quantity = quantityFrom(msg)
+	amended.Price = priceFrom(msg)
+	if err := amended.Validate(); err != nil {
+		return err
+	}
+	if err := a.validateRules(ctx, amended); err != nil {
+		return err
+	}
+
+	_, err = a.matcher.AmendOrder(ctx, orderID, amended.Quantity, amended.Price)
+	return err
+}
+
+// validateRules aplica as regras de ações e mercado medindo-as em um span
+func (a *Acceptor) validateRules(ctx context.Context, order *domain.Order) error {
+	_, span := tracing.Start(ctx, "BusinessValidator.ValidateOrder", tracing.OrderAttrs(order)...)
+	defer span.End()
+
+	err := a.validator.ValidateOrder(order)
+	span.RecordError(err)
+	return err
+}
+
+// prepareCancel localiza a ordem original, reserva o novo ClOrdID e marca o
+// pedido como pendente para o relatório gerado pelo evento
+func (a *Acceptor) prepareCancel(sess *session, msg *Message) (string, error) {
+	sess.mutex.Lock()
+	orderID, known := sess.clOrdIDs[msg.Get(TagOrigClOrdID)]
+	_, duplicate := sess.clOrdIDs[msg.Get(TagClOrdID)]
+	if known && !duplicate {
+		sess.clOrdIDs[msg.Get(TagClOrdID)] = orderID
+	}
+	sess.mutex.Unlock()
+
+	switch {
+	case duplicate:
+		return "", errDuplicateClOrdID
+	case !known:
+		return "", domain.ErrOrderNotFound
+	}
+
+	a.mutex.Lock()
+	if tracked, exists := a.orders[orderID]; exists {
+		tracked.pendingClOrdID = msg.Get(TagClOrdID)
+	}
+	a.mutex.Unlock()
+	return orderID, nil
+}
+
+func (a *Acceptor) clearPending(orderID string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if tracked, exists := a.orders[orderID]; exists {
+		tracked.pendingClOrdID = ""
+	}
+}
+
+func (a *Acceptor) untrack(orderID string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	delete(a.orders, orderID)
+}
+
+// HandleEvent gera os ExecutionReports das ordens FIX a partir dos eventos do
+// engine: aceite, alteração, execução (inclusive passiva) e cancelamento
+func (a *Acceptor) HandleEvent(event events.Event) {
+	switch e := event.(type) {
+	case events.OrderAccepted:
+		a.reportAccepted(e.Order)
+	case events.TradeExecuted:
+		a.reportFill(e.BuyOrder, e.Trade)
+		a.reportFill(e.SellOrder, e.Trade)
+	case events.OrderCancelled:
+		a.reportCancelled(e.Order)
+	}
+}
+
+// reportAccepted envia New para ordens novas e Replaced para ordens já
+// aceitas (alteradas via FIX ou REST)
+func (a *Acceptor) reportAccepted(order *domain.Order) {
+	a.mutex.Lock()
+	tracked, exists := a.orders[order.ID]
+	if !exists {
+		a.mutex.Unlock()
+		return
+	}
+
+	var report *Message
+	if !tracked.accepted {
+		tracked.accepted = true
+		report = a.executionReport(order, tracked, execTypeNew)
+		report.Set(TagClOrdID, tracked.clOrdID)
+	} else {
+		report = a.executionReport(order, tracked, execTypeReplaced)
+		origClOrdID := tracked.clOrdID
+		if tracked.pendingClOrdID != "" {
+			tracked.clOrdID, tracked.pendingClOrdID = tracked.pendingClOrdID, ""
+		}
+		report.Set(TagClOrdID, tracked.clOrdID).Set(TagOrigClOrdID, origClOrdID)
+	}
+	sess := tracked.session
+	a.mutex.Unlock()
+
+	sess.send(report)
+}
+
+// reportFill envia a execução de um dos lados da negociação
+func (a *Acceptor) reportFill(order *domain.Order, trade *domain.Trade) {
+	a.mutex.Lock()
+	tracked, exists := a.orders[order.ID]
+	if !exists {
+		a.mutex.Unlock()
+		return
+	}
+
+	tracked.notional += float64(trade.Quantity) * trade.Price
+	report := a.executionReport(order, tracked, execTypeTrade).
+		Set(TagClOrdID, tracked.clOrdID).
+		SetInt(TagLastQty, trade.Quantity).
+		SetFloat(TagLastPx, trade.Price).
+		Set(TagTrdMatchID, trade.ID)
+	if order.IsComplete() {
+		delete(a.orders, order.ID)
+	}
+	sess := tracked.session
+	a.mutex.Unlock()
+
+	sess.send(report)
+}
+
+// reportCancelled envia o cancelamento, pedido via FIX ou não
+func (a *Acceptor) reportCancelled(order *domain.Order) {
+	a.mutex.Lock()
+	tracked, exists := a.orders[order.ID]
+	if !exists {
+		a.mutex.Unlock()
+		return
+	}
+	delete(a.orders, order.ID)
+
+	report := a.executionReport(order, tracked, execTypeCanceled).Set(TagClOrdID, tracked.clOrdID)
+	if tracked.pendingClOrdID != "" {
+		report.Set(TagClOrdID, tracked.pendingClOrdID).Set(TagOrigClOrdID, tracked.clOrdID)
+	}
+	sess := tracked.session
+	a.mutex.Unlock()
+
+	sess.send(report)
+}
+
+// executionReport monta um ExecutionReport com o estado atual da ordem
+func (a *Acceptor) executionReport(order *domain.Order, tracked *trackedOrder, execType string) *Message {
+	leaves := order.RemainingQuantity
+	if order.Status == domain.CANCELLED || order.Status == domain.REJECTED {
+		leaves = 0
+	}
+	avgPx := 0.0
+	if filled := order.FilledQuantity(); tracked != nil && filled > 0 {
+		avgPx = math.Round(tracked.notional/float64(filled)*1e6) / 1e6
+	}
+
+	return NewMessage(MsgExecutionReport).
+		Set(TagOrderID, order.ID).
+		Set(TagExecID, a.nextExecID()).
+		Set(TagExecType, execType).
+		Set(TagOrdStatus, ordStatuses[order.Status]).
+		Set(TagAccount, order.UserID).
+		Set(TagSymbol, order.Symbol).
+		Set(TagSide, sideCode(order.Side)).
+		SetInt(TagOrderQty, order.Quantity).
+		Set(TagOrdType, ordTypeLimit).
+		SetFloat(TagPrice, order.Price).
+		SetInt(TagLeavesQty, leaves).
+		SetInt(TagCumQty, order.FilledQuantity()).
+		SetFloat(TagAvgPx, avgPx).
+		SetTime(TagTransactTime, order.UpdatedAt)
+}
+
+// rejectReport monta o ExecutionReport de uma ordem rejeitada
+func (a *Acceptor) rejectReport(order *domain.Order, clOrdID string, err error) *Message {
+	return a.executionReport(order, nil, execTypeRejected).
+		Set(TagClOrdID, clOrdID).
+		Set(TagOrdRejReason, lookupReason(err).ordRej).
+		Set(TagText, rejectText(err))
+}
+
+// cancelReject monta o OrderCancelReject de um cancelamento ou alteração recusado
+func (a *Acceptor) cancelReject(msg *Message, orderID, responseTo string, err error) *Message {
+	status := ordStatuses[domain.REJECTED]
+	if orderID == "" {
+		orderID = "NONE"
+	} else if order, lookupErr := a.matcher.GetOrder(orderID); lookupErr == nil {
+		status = ordStatuses[order.Status]
+	}
+
+	return NewMessage(MsgOrderCancelReject).
+		Set(TagOrderID, orderID).
+		Set(TagClOrdID, msg.Get(TagClOrdID)).
+		Set(TagOrigClOrdID, msg.Get(TagOrigClOrdID)).
+		Set(TagOrdStatus, status).
+		Set(TagCxlRejResponseTo, responseTo).
+		Set(TagCxlRejReason, lookupReason(err).cxlRej).
+		Set(TagText, rejectText(err))
+}
+
+// nextExecID gera um ExecID único entre reinícios do gateway
+func (a *Acceptor) nextExecID() string {
+	return fmt.Sprintf("EXEC-%d-%d", a.startedAt.Unix(), a.execSeq.Add(1))
+}
+
+// claim associa o ClOrdID à ordem, se ainda não usado na sessão
+func (s *session) claim(clOrdID, orderID string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, used := s.clOrdIDs[clOrdID]; used {
+		return false
+	}
+	s.clOrdIDs[clOrdID] = orderID
+	return true
+}
+
+// requireTags responde com Reject se faltar alguma das tags obrigatórias
+func requireTags(sess *session, msg *Message, tags ...int) bool {
+	for _, tag := range tags {
+		if msg.Get(tag) == "" {
+			sess.send(sessionReject(msg, tag, sessionRejectRequiredTag, fmt.Sprintf("tag %d obrigatória", tag)))
+			return false
+		}
+	}
+	return true
+}
+
+// quantityFrom lê OrderQty; valores fracionados viram 0 (quantidade inválida)
+func quantityFrom(msg *Message) int {
+	quantity, err := msg.Float(TagOrderQty)
+	if err != nil || quantity != math.Trunc(quantity) || quantity > math.MaxInt32 {
+		return 0
+	}
+	return int(quantity)
+}
+
+// priceFrom lê Price; valores inválidos viram 0 (preço inválido)
+func priceFrom(msg *Message) float64 {
+	price, err := msg.Float(TagPrice)
+	if err != nil || math.IsNaN(price) || math.IsInf(price, 0) {
+		return 0
+	}
+	return price
+}
+
+func sideCode(side domain.OrderSide) string {
+	for code, domainSide := range sides {
+		if domainSide == side {
+			return code
+		}
+	}
+	return ""
+}
@@ -0,0 +1,187 @@
+package fix
+
+import (
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// maxStoredMessages limita as mensagens enviadas guardadas para reenvio; as
+// mais antigas são preenchidas com SequenceReset-GapFill
+const maxStoredMessages = 10000
+
+// writeTimeout limita a espera por um cliente que não lê a conexão
+const writeTimeout = 5 * time.Second
+
+// session é o estado de uma sessão FIX, identificada pelo CompID do cliente.
+// Sequências e mensagens enviadas sobrevivem às reconexões: relatórios gerados
+// com o cliente desconectado são entregues via ResendRequest.
+type session struct {
+	clientID  string
+	gatewayID string
+	accounts  map[string]bool
+
+	mutex  sync.Mutex
+	conn   net.Conn
+	inSeq  int
+	outSeq int
+	sent   map[int]*Message
+
+	// resendUntil é a última sequência pedida no ResendRequest pendente
+	resendUntil int
+
+	heartBtInt   time.Duration
+	lastSent     time.Time
+	lastReceived time.Time
+	testReqSent  bool
+
+	// clOrdIDs associa os ClOrdID recebidos ao ID da ordem no engine
+	clOrdIDs map[string]string
+}
+
+// newSession cria uma sessão com as sequências iniciando em 1
+func newSession(clientID, gatewayID string, accounts []string) *session {
+	s := &session{
+		clientID:  clientID,
+		gatewayID: gatewayID,
+		inSeq:     1,
+		outSeq:    1,
+		sent:      make(map[int]*Message),
+		clOrdIDs:  make(map[string]string),
+		accounts:  make(map[string]bool, len(accounts)),
+	}
+	for _, account := range accounts {
+		s.accounts[account] = true
+	}
+	return s
+}
+
+// allows informa se a sessão pode operar a conta
+func (s *session) allows(account string) bool {
+	return s.accounts[account]
+}
+
+// resetLocked reinicia as sequências (ResetSeqNumFlag=Y)
+func (s *session) resetLocked() {
+	s.inSeq = 1
+	s.outSeq = 1
+	s.resendUntil = 0
+	s.sent = make(map[int]*Message)
+}
+
+// send numera, guarda e escreve a mensagem. Sem conexão, a mensagem fica
+// guardada para ser reenviada quando o cliente pedir.
+func (s *session) send(msg *Message) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sendLocked(msg)
+}
+
+func (s *session) sendLocked(msg *Message) {
+	seq := s.outSeq
+	s.outSeq++
+
+	msg.Set(TagSenderCompID, s.gatewayID)
+	msg.Set(TagTargetCompID, s.clientID)
+	msg.SetInt(TagMsgSeqNum, seq)
+	msg.SetTime(TagSendingTime, time.Now())
+
+	s.sent[seq] = msg
+	delete(s.sent, seq-maxStoredMessages)
+
+	s.writeLocked(msg)
+}
+
+// writeLocked escreve na conexão atual; em caso de erro a conexão é fechada
+func (s *session) writeLocked(msg *Message) {
+	if s.conn == nil {
+		return
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := s.conn.Write(msg.Bytes()); err != nil {
+		slog.Warn("FIX: erro ao enviar mensagem", "session", s.clientID, "msg_type", msg.Type(), "error", err)
+		s.conn.Close()
+		s.conn = nil
+		return
+	}
+	s.lastSent = time.Now()
+}
+
+// requestResendLocked pede ao cliente as mensagens a partir da esperada, uma
+// vez por lacuna
+func (s *session) requestResendLocked(received int) {
+	if s.resendUntil >= s.inSeq {
+		return
+	}
+	s.resendUntil = received
+	s.sendLocked(NewMessage(MsgResendRequest).SetInt(TagBeginSeqNo, s.inSeq).SetInt(TagEndSeqNo, 0))
+}
+
+// applySeqResetLocked avança a sequência esperada (GapFill ou Reset)
+func (s *session) applySeqResetLocked(msg *Message) {
+	newSeq, err := msg.Int(TagNewSeqNo)
+	if err != nil || newSeq < s.inSeq {
+		s.sendLocked(sessionReject(msg, TagNewSeqNo, sessionRejectIncorrectValue, "NewSeqNo inválido"))
+		return
+	}
+	s.inSeq = newSeq
+}
+
+// resendLocked reenvia as mensagens de begin a end (0 = até a última). Mensagens
+// de aplicação vão com PossDupFlag=Y; mensagens de sessão e lacunas viram
+// SequenceReset-GapFill.
+func (s *session) resendLocked(begin, end int) {
+	last := s.outSeq - 1
+	if end == 0 || end > last {
+		end = last
+	}
+	if begin < 1 {
+		begin = 1
+	}
+
+	gapStart := 0
+	flushGap := func(next int) {
+		if gapStart == 0 {
+			return
+		}
+		reset := NewMessage(MsgSequenceReset).
+			Set(TagSenderCompID, s.gatewayID).
+			Set(TagTargetCompID, s.clientID).
+			SetInt(TagMsgSeqNum, gapStart).
+			Set(TagPossDupFlag, "Y").
+			SetTime(TagSendingTime, time.Now()).
+			Set(TagGapFillFlag, "Y").
+			SetInt(TagNewSeqNo, next)
+		s.writeLocked(reset)
+		gapStart = 0
+	}
+
+	for seq := begin; seq <= end; seq++ {
+		msg, stored := s.sent[seq]
+		if !stored || isAdminMessage(msg.Type()) {
+			if gapStart == 0 {
+				gapStart = seq
+			}
+			continue
+		}
+		flushGap(seq)
+
+		dup := msg.Clone()
+		dup.Set(TagPossDupFlag, "Y")
+		dup.Set(TagOrigSendingTime, msg.Get(TagSendingTime))
+		dup.SetTime(TagSendingTime, time.Now())
+		s.writeLocked(dup)
+	}
+	flushGap(end + 1)
+}
+
+// isAdminMessage informa se o tipo é de sessão (não é reenviado)
+func isAdminMessage(msgType string) bool {
+	switch msgType {
+	case MsgHeartbeat, MsgTestRequest, MsgResendRequest, MsgReject, MsgSequenceReset, MsgLogout, MsgLogon:
+		return true
+	}
+	return false
+}
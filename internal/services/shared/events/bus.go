@@ -0,0 +1,217 @@
+package events
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Publisher publica eventos no barramento
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Handler processa um evento entregue pelo barramento
+type Handler func(event Event)
+
+// Valores padrão das inscrições
+const (
+	defaultBufferSize = 1024
+	defaultPartitions = 1
+)
+
+// Option configura uma inscrição
+type Option func(*Subscription)
+
+// WithBuffer define o tamanho da fila de cada partição
+func WithBuffer(size int) Option {
+	return func(s *Subscription) { s.bufferSize = size }
+}
+
+// WithPartitions distribui os símbolos entre n goroutines, mantendo a ordem
+// por símbolo
+func WithPartitions(n int) Option {
+	return func(s *Subscription) { s.partitions = n }
+}
+
+// Bus é um barramento publish/subscribe em memória
+type Bus struct {
+	subscriptions []*Subscription
+	mutex         sync.RWMutex
+	closed        bool
+	published     atomic.Uint64
+}
+
+// Subscription é um inscrito do barramento com filas particionadas por chave.
+// Com a fila cheia o publicador aguarda espaço: nenhum evento é perdido.
+type Subscription struct {
+	name       string
+	handler    Handler
+	bufferSize int
+	partitions int
+
+	queues []chan Event
+	wg     sync.WaitGroup
+
+	delivered    atomic.Uint64
+	blocked      atomic.Uint64
+	blockedNanos atomic.Int64
+	maxDepth     atomic.Int64
+}
+
+// SubscriptionStats são as métricas de backpressure de um inscrito
+type SubscriptionStats struct {
+	Name          string  `json:"name"`
+	QueueDepth    int     `json:"queue_depth"`
+	MaxQueueDepth int64   `json:"max_queue_depth"`
+	QueueCapacity int     `json:"queue_capacity"`
+	Delivered     uint64  `json:"delivered"`
+	Blocked       uint64  `json:"blocked"`
+	BlockedMillis float64 `json:"blocked_ms"`
+}
+
+// Stats são as métricas do barramento
+type Stats struct {
+	Published     uint64              `json:"published"`
+	Subscriptions []SubscriptionStats `json:"subscriptions"`
+}
+
+// NewBus cria um barramento vazio
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registra um inscrito que recebe todos os eventos publicados
+func (b *Bus) Subscribe(name string, handler Handler, options ...Option) *Subscription {
+	sub := &Subscription{
+		name:       name,
+		handler:    handler,
+		bufferSize: defaultBufferSize,
+		partitions: defaultPartitions,
+	}
+	for _, option := range options {
+		option(sub)
+	}
+
+	sub.queues = make([]chan Event, sub.partitions)
+	for i := range sub.queues {
+		sub.queues[i] = make(chan Event, sub.bufferSize)
+		sub.wg.Add(1)
+		go sub.run(sub.queues[i])
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.subscriptions = append(b.subscriptions, sub)
+	return sub
+}
+
+// On registra um inscrito apenas para eventos do tipo T
+func On[T Event](b *Bus, name string, handler func(event T), options ...Option) *Subscription {
+	return b.Subscribe(name, func(event Event) {
+		if typed, ok := event.(T); ok {
+			handler(typed)
+		}
+	}, options...)
+}
+
+// Publish entrega o evento à fila de cada inscrito. Eventos com a mesma chave
+// caem sempre na mesma partição, preservando a ordem por símbolo.
+func (b *Bus) Publish(event Event) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	b.published.Add(1)
+	partition := hashKey(event.Key())
+	for _, sub := range b.subscriptions {
+		sub.enqueue(sub.queues[partition%uint32(len(sub.queues))], event)
+	}
+}
+
+// Close para de aceitar eventos e aguarda os inscritos esvaziarem as filas
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return
+	}
+	b.closed = true
+	subscriptions := b.subscriptions
+	b.mutex.Unlock()
+
+	for _, sub := range subscriptions {
+		for _, queue := range sub.queues {
+			close(queue)
+		}
+		sub.wg.Wait()
+	}
+}
+
+// Stats retorna as métricas do barramento e de cada inscrito
+func (b *Bus) Stats() Stats {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	stats := Stats{Published: b.published.Load()}
+	for _, sub := range b.subscriptions {
+		stats.Subscriptions = append(stats.Subscriptions, sub.stats())
+	}
+	return stats
+}
+
+// enqueue coloca o evento na fila, aguardando espaço se ela estiver cheia
+func (s *Subscription) enqueue(queue chan Event, event Event) {
+	select {
+	case queue <- event:
+	default:
+		start := time.Now()
+		queue <- event
+		s.blocked.Add(1)
+		s.blockedNanos.Add(int64(time.Since(start)))
+	}
+
+	if depth := int64(len(queue)); depth > s.maxDepth.Load() {
+		s.maxDepth.Store(depth)
+	}
+}
+
+// run consome uma partição até a fila ser fechada
+func (s *Subscription) run(queue chan Event) {
+	defer s.wg.Done()
+
+	for event := range queue {
+		s.handler(event)
+		s.delivered.Add(1)
+	}
+}
+
+// stats retorna as métricas do inscrito
+func (s *Subscription) stats() SubscriptionStats {
+	depth := 0
+	for _, queue := range s.queues {
+		depth += len(queue)
+	}
+
+	return SubscriptionStats{
+		Name:          s.name,
+		QueueDepth:    depth,
+		MaxQueueDepth: s.maxDepth.Load(),
+		QueueCapacity: s.bufferSize * s.partitions,
+		Delivered:     s.delivered.Load(),
+		Blocked:       s.blocked.Load(),
+		BlockedMillis: float64(s.blockedNanos.Load()) / float64(time.Millisecond),
+	}
+}
+
+// hashKey distribui chaves entre partições
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"time"
+
+	"trading/internal/domain"
+)
+
+// Tipos de evento publicados no barramento
+const (
+	TypeOrderAccepted    = "OrderAccepted"
+	TypeOrderRejected    = "OrderRejected"
+	TypeTradeExecuted    = "TradeExecuted"
+	TypeOrderCancelled   = "OrderCancelled"
+	TypePortfolioUpdated = "PortfolioUpdated"
+	TypeBookChanged      = "BookChanged"
+)
+
+// Event é implementado por todos os eventos do barramento. Eventos com a
+// mesma chave (o símbolo) são entregues na ordem em que foram publicados.
+type Event interface {
+	Type() string
+	Key() string
+}
+
+// OrderAccepted é publicado quando a ordem passa pelas validações e entra no
+// matching, antes de qualquer execução
+type OrderAccepted struct {
+	Order     *domain.Order `json:"order"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// OrderRejected é publicado quando o engine rejeita a ordem
+type OrderRejected struct {
+	Order     *domain.Order `json:"order"`
+	Reason    error         `json:"-"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// TradeExecuted é publicado a cada negociação, com o estado das duas ordens
+// após a execução
+type TradeExecuted struct {
+	Trade     *domain.Trade `json:"trade"`
+	BuyOrder  *domain.Order `json:"buy_order"`
+	SellOrder *domain.Order `json:"sell_order"`
+}
+
+// OrderCancelled é publicado quando uma ordem aberta é cancelada
+type OrderCancelled struct {
+	Order     *domain.Order `json:"order"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// PortfolioUpdated descreve a variação de caixa e posição de um usuário após
+// um trade
+type PortfolioUpdated struct {
+	UserID        string    `json:"user_id"`
+	TradeID       string    `json:"trade_id"`
+	Symbol        string    `json:"symbol"`
+	CashDelta     float64   `json:"cash_delta"`
+	Cash          float64   `json:"cash"`
+	PositionDelta int       `json:"position_delta"`
+	Position      int       `json:"position"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// BookChanged é publicado quando o livro de um símbolo é alterado
+type BookChanged struct {
+	Symbol    string    `json:"symbol"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (e OrderAccepted) Type() string    { return TypeOrderAccepted }
+func (e OrderRejected) Type() string    { return TypeOrderRejected }
+func (e TradeExecuted) Type() string    { return TypeTradeExecuted }
+func (e OrderCancelled) Type() string   { return TypeOrderCancelled }
+func (e PortfolioUpdated) Type() string { return TypePortfolioUpdated }
+func (e BookChanged) Type() string      { return TypeBookChanged }
+
+func (e OrderAccepted) Key() string    { return e.Order.Symbol }
+func (e OrderRejected) Key() string    { return e.Order.Symbol }
+func (e TradeExecuted) Key() string    { return e.Trade.Symbol }
+func (e OrderCancelled) Key() string   { return e.Order.Symbol }
+func (e PortfolioUpdated) Key() string { return e.Symbol }
+func (e BookChanged) Key() string      { return e.Symbol }
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// HeaderRequestID é o header que correlaciona uma requisição entre web,
+// engine e logs
+const HeaderRequestID = "X-Request-ID"
+
+// KeyRequestID é o atributo com o request ID em cada linha de log
+const KeyRequestID = "request_id"
+
+// requestIDKey guarda o request ID no contexto
+type requestIDKey struct{}
+
+// WithRequestID retorna um contexto que carrega o request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID retorna o request ID do contexto (vazio se ausente)
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID gera um identificador aleatório de requisição
+func NewRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "req-unknown"
+	}
+	return "req-" + hex.EncodeToString(buf)
+}
+
+// contextHandler acrescenta a cada registro o request ID do contexto
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle inclui request_id quando o contexto o carrega
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String(KeyRequestID, id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs mantém o request ID nos loggers derivados
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup mantém o request ID nos loggers derivados
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// New cria um logger JSON no nível informado que inclui o request ID do contexto
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// ParseLevel interpreta debug, info, warn ou error (vazio = info)
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	err := level.UnmarshalText([]byte(strings.ToUpper(name)))
+	return level, err
+}
+
+// Setup instala o logger JSON na saída padrão como padrão do processo, no
+// nível de LOG_LEVEL. As chamadas ao pacote log passam pelo mesmo handler.
+func Setup() {
+	level, err := ParseLevel(os.Getenv("LOG_LEVEL"))
+	slog.SetDefault(New(os.Stdout, level))
+	if err != nil {
+		slog.Warn("LOG_LEVEL inválido, usando info", "value", os.Getenv("LOG_LEVEL"))
+	}
+}
+
+// Fatal registra o erro e encerra o processo
+func Fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
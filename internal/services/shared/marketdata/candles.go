@@ -0,0 +1,231 @@
+package marketdata
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/shared/events"
+)
+
+// DefaultCandleCapacity é a quantidade de barras guardadas por símbolo e intervalo
+const DefaultCandleCapacity = 1000
+
+// ErrInvalidInterval indica um intervalo de barra não suportado
+var ErrInvalidInterval = errors.New("intervalo de barra inválido")
+
+// Interval é a duração de uma barra
+type Interval struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Intervals são os intervalos agregados; 1d corresponde ao pregão inteiro
+var Intervals = []Interval{
+	{"1s", time.Second},
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"1h", time.Hour},
+	{"1d", 24 * time.Hour},
+}
+
+// ParseInterval retorna o intervalo pelo nome (1s, 1m, 5m, 1h ou 1d)
+func ParseInterval(name string) (Interval, error) {
+	for _, interval := range Intervals {
+		if interval.Name == name {
+			return interval, nil
+		}
+	}
+	return Interval{}, ErrInvalidInterval
+}
+
+// SessionCalendar informa o pregão regular que contém um instante
+type SessionCalendar interface {
+	Session(t time.Time) (open, close time.Time, ok bool)
+}
+
+// Candle é uma barra OHLCV. Start e End delimitam o período [Start, End);
+// a última barra do pregão termina no fechamento, mesmo que mais curta.
+type Candle struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume int       `json:"volume"`
+	VWAP   float64   `json:"vwap"`
+	Trades int       `json:"trades"`
+	Closed bool      `json:"closed"`
+
+	notional float64
+}
+
+// add incorpora a negociação à barra
+func (c *Candle) add(trade *domain.Trade) {
+	if c.Trades == 0 {
+		c.Open, c.High, c.Low = trade.Price, trade.Price, trade.Price
+	}
+	c.High = max(c.High, trade.Price)
+	c.Low = min(c.Low, trade.Price)
+	c.Close = trade.Price
+	c.Volume += trade.Quantity
+	c.notional += trade.Value
+	c.VWAP = c.notional / float64(c.Volume)
+	c.Trades++
+}
+
+// ring guarda as barras mais recentes de uma série, da mais antiga à mais nova
+type ring struct {
+	bars []Candle
+	head int
+	size int
+}
+
+// at retorna a i-ésima barra a partir da mais antiga
+func (r *ring) at(i int) *Candle {
+	return &r.bars[(r.head+i)%len(r.bars)]
+}
+
+// push acrescenta uma barra, descartando a mais antiga se o anel estiver cheio
+func (r *ring) push(candle Candle) *Candle {
+	if r.size < len(r.bars) {
+		r.size++
+	} else {
+		r.head = (r.head + 1) % len(r.bars)
+	}
+	bar := r.at(r.size - 1)
+	*bar = candle
+	return bar
+}
+
+// last retorna a barra mais nova (nil se vazio)
+func (r *ring) last() *Candle {
+	if r.size == 0 {
+		return nil
+	}
+	return r.at(r.size - 1)
+}
+
+// find procura, da mais nova para a mais antiga, a barra iniciada em start
+func (r *ring) find(start time.Time) *Candle {
+	for i := r.size - 1; i >= 0; i-- {
+		if bar := r.at(i); bar.Start.Equal(start) {
+			return bar
+		}
+	}
+	return nil
+}
+
+// seriesKey identifica a série de barras de um símbolo em um intervalo
+type seriesKey struct {
+	symbol   string
+	interval string
+}
+
+// CandleStore agrega as negociações executadas em barras OHLCV por símbolo e
+// intervalo, alinhadas à abertura do pregão
+type CandleStore struct {
+	calendar SessionCalendar
+	capacity int
+	now      func() time.Time
+
+	series map[seriesKey]*ring
+	mutex  sync.RWMutex
+}
+
+// NewCandleStore cria o agregador; capacity <= 0 usa DefaultCandleCapacity
+func NewCandleStore(calendar SessionCalendar, capacity int) *CandleStore {
+	if capacity <= 0 {
+		capacity = DefaultCandleCapacity
+	}
+	return &CandleStore{
+		calendar: calendar,
+		capacity: capacity,
+		now:      time.Now,
+		series:   make(map[seriesKey]*ring),
+	}
+}
+
+// SetClock substitui o relógio usado para marcar barras encerradas (útil em testes)
+func (s *CandleStore) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// HandleEvent agrega as negociações publicadas no barramento
+func (s *CandleStore) HandleEvent(event events.Event) {
+	if e, ok := event.(events.TradeExecuted); ok {
+		s.Add(e.Trade)
+	}
+}
+
+// Add agrega uma negociação às barras de todos os intervalos
+func (s *CandleStore) Add(trade *domain.Trade) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, interval := range Intervals {
+		start, end := s.bounds(trade.ExecutedAt, interval.Duration)
+
+		key := seriesKey{trade.Symbol, interval.Name}
+		r, exists := s.series[key]
+		if !exists {
+			r = &ring{bars: make([]Candle, s.capacity)}
+			s.series[key] = r
+		}
+
+		// Negociações fora de ordem só atualizam barras ainda guardadas
+		var bar *Candle
+		if last := r.last(); last == nil || last.Start.Before(start) {
+			bar = r.push(Candle{Start: start, End: end})
+		} else {
+			bar = r.find(start)
+		}
+		if bar != nil {
+			bar.add(trade)
+		}
+	}
+}
+
+// Candles retorna as barras do símbolo com início em [from, to), da mais
+// antiga à mais nova; instantes zero não limitam
+func (s *CandleStore) Candles(symbol string, interval Interval, from, to time.Time) []Candle {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	candles := []Candle{}
+	r, exists := s.series[seriesKey{symbol, interval.Name}]
+	if !exists {
+		return candles
+	}
+
+	now := s.now()
+	for i := 0; i < r.size; i++ {
+		bar := *r.at(i)
+		if (!from.IsZero() && bar.Start.Before(from)) || (!to.IsZero() && !bar.Start.Before(to)) {
+			continue
+		}
+		bar.Closed = i < r.size-1 || !now.Before(bar.End)
+		candles = append(candles, bar)
+	}
+	return candles
+}
+
+// bounds calcula o período da barra que contém t. Dentro do pregão as barras
+// começam na abertura e a última é encerrada no fechamento; fora dele são
+// alinhadas ao relógio UTC.
+func (s *CandleStore) bounds(t time.Time, length time.Duration) (time.Time, time.Time) {
+	if open, close, ok := s.calendar.Session(t); ok {
+		elapsed := t.Sub(open)
+		start := open.Add(elapsed - elapsed%length)
+		end := start.Add(length)
+		if end.After(close) {
+			end = close
+		}
+		return start.UTC(), end.UTC()
+	}
+
+	start := t.UTC().Truncate(length)
+	return start, start.Add(length)
+}
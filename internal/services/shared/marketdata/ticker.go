@@ -0,0 +1,201 @@
+package marketdata
+
+import (
+	"sync"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/shared/events"
+)
+
+// DepthSource fornece o livro agregado de um símbolo
+type DepthSource interface {
+	GetDepth(symbol string, depth int) *orderbook.Depth
+}
+
+// Ticker resume o mercado de um símbolo: último negócio, variação sobre o
+// fechamento anterior e estatísticas do pregão. Campos nulos ainda não têm
+// valor (sem negócios ou lado do livro vazio).
+type Ticker struct {
+	Symbol        string     `json:"symbol"`
+	LastPrice     *float64   `json:"last_price"`
+	LastQuantity  int        `json:"last_quantity"`
+	LastTradeAt   *time.Time `json:"last_trade_at"`
+	PreviousClose *float64   `json:"previous_close"`
+	Change        *float64   `json:"change"`
+	ChangePercent *float64   `json:"change_percent"`
+	High          *float64   `json:"high"`
+	Low           *float64   `json:"low"`
+	Volume        int        `json:"volume"`
+	VWAP          *float64   `json:"vwap"`
+	Trades        int        `json:"trades"`
+	BestBid       *float64   `json:"best_bid"`
+	BidSize       int        `json:"bid_size"`
+	BestAsk       *float64   `json:"best_ask"`
+	AskSize       int        `json:"ask_size"`
+	Timestamp     time.Time  `json:"timestamp"`
+}
+
+// tickerState acumula os negócios de um símbolo. day é o início do pregão
+// a que se referem as estatísticas do dia.
+type tickerState struct {
+	day          time.Time
+	hasLast      bool
+	last         float64
+	lastQuantity int
+	lastAt       time.Time
+	hasClose     bool
+	close        float64
+	high         float64
+	low          float64
+	volume       int
+	notional     float64
+	trades       int
+}
+
+// rollover encerra o pregão anterior: o último preço vira o fechamento
+// anterior e as estatísticas do dia recomeçam
+func (st *tickerState) rollover(day time.Time) {
+	if st.hasLast {
+		st.hasClose, st.close = true, st.last
+	}
+	st.day = day
+	st.high, st.low, st.volume, st.notional, st.trades = 0, 0, 0, 0, 0
+}
+
+// TickerService mantém o ticker de cada ação negociável a partir das
+// negociações executadas; o melhor bid/ask é lido do livro na consulta
+type TickerService struct {
+	calendar SessionCalendar
+	books    DepthSource
+	now      func() time.Time
+
+	states map[string]*tickerState
+	mutex  sync.RWMutex
+}
+
+// NewTickerService cria o serviço para os símbolos informados
+func NewTickerService(symbols []string, calendar SessionCalendar, books DepthSource) *TickerService {
+	states := make(map[string]*tickerState, len(symbols))
+	for _, symbol := range symbols {
+		states[symbol] = &tickerState{}
+	}
+	return &TickerService{
+		calendar: calendar,
+		books:    books,
+		now:      time.Now,
+		states:   states,
+	}
+}
+
+// SetClock substitui o relógio usado para detectar a virada de pregão (útil em testes)
+func (s *TickerService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// HandleEvent atualiza os tickers com as negociações publicadas no barramento
+func (s *TickerService) HandleEvent(event events.Event) {
+	if e, ok := event.(events.TradeExecuted); ok {
+		s.Add(e.Trade)
+	}
+}
+
+// Add registra uma negociação; negociações de símbolos desconhecidos são ignoradas
+func (s *TickerService) Add(trade *domain.Trade) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	st, exists := s.states[trade.Symbol]
+	if !exists {
+		return
+	}
+	if day := s.dayOf(trade.ExecutedAt); day.After(st.day) {
+		st.rollover(day)
+	}
+
+	if !st.hasLast || !trade.ExecutedAt.Before(st.lastAt) {
+		st.hasLast = true
+		st.last, st.lastQuantity, st.lastAt = trade.Price, trade.Quantity, trade.ExecutedAt
+	}
+	if st.trades == 0 {
+		st.high, st.low = trade.Price, trade.Price
+	}
+	st.high = max(st.high, trade.Price)
+	st.low = min(st.low, trade.Price)
+	st.volume += trade.Quantity
+	st.notional += trade.Value
+	st.trades++
+}
+
+// Ticker retorna o ticker do símbolo (false se o símbolo não for negociável)
+func (s *TickerService) Ticker(symbol string) (Ticker, bool) {
+	s.mutex.RLock()
+	st, exists := s.states[symbol]
+	var state tickerState
+	if exists {
+		state = *st
+	}
+	s.mutex.RUnlock()
+	if !exists {
+		return Ticker{}, false
+	}
+
+	// Sem negócios no pregão atual as estatísticas do anterior não valem mais
+	now := s.now()
+	if open, _, ok := s.calendar.Session(now); ok && open.After(state.day) {
+		state.rollover(open)
+	}
+
+	ticker := Ticker{Symbol: symbol, Volume: state.volume, Trades: state.trades, Timestamp: now.UTC()}
+	if state.hasLast {
+		last, at := state.last, state.lastAt
+		ticker.LastPrice, ticker.LastQuantity, ticker.LastTradeAt = &last, state.lastQuantity, &at
+	}
+	if state.hasClose {
+		previous := state.close
+		ticker.PreviousClose = &previous
+		if state.hasLast && previous > 0 {
+			change := state.last - previous
+			percent := change / previous * 100
+			ticker.Change, ticker.ChangePercent = &change, &percent
+		}
+	}
+	if state.trades > 0 {
+		high, low, vwap := state.high, state.low, state.notional/float64(state.volume)
+		ticker.High, ticker.Low, ticker.VWAP = &high, &low, &vwap
+	}
+
+	if depth := s.books.GetDepth(symbol, 1); depth != nil {
+		if len(depth.Bids) > 0 {
+			ticker.BestBid, ticker.BidSize = &depth.Bids[0].Price, depth.Bids[0].Quantity
+		}
+		if len(depth.Asks) > 0 {
+			ticker.BestAsk, ticker.AskSize = &depth.Asks[0].Price, depth.Asks[0].Quantity
+		}
+	}
+	return ticker, true
+}
+
+// Prices retorna o último preço negociado de cada símbolo que já teve
+// negócios, no formato esperado por domain.Portfolio.GetTotalValue
+func (s *TickerService) Prices() map[string]float64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	prices := make(map[string]float64, len(s.states))
+	for symbol, st := range s.states {
+		if st.hasLast {
+			prices[symbol] = st.last
+		}
+	}
+	return prices
+}
+
+// dayOf retorna o início do pregão que contém t; fora do pregão, o dia UTC
+func (s *TickerService) dayOf(t time.Time) time.Time {
+	if open, _, ok := s.calendar.Session(t); ok {
+		return open
+	}
+	return t.UTC().Truncate(24 * time.Hour)
+}
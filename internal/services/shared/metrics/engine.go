@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"sort"
+
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+)
+
+// EngineStats fornece os contadores do matching engine
+type EngineStats interface {
+	Stats() matching.Stats
+}
+
+// BookSummaries fornece a profundidade de cada livro
+type BookSummaries interface {
+	Summaries() []orderbook.BookSummary
+}
+
+// ErrorCoder traduz um erro de domínio em seu código estável (ex.: INSUFFICIENT_BALANCE)
+type ErrorCoder func(err error) string
+
+// EngineCollector expõe ordens, rejeições, negociações, latência do matching
+// e profundidade dos livros
+func EngineCollector(engine EngineStats, books BookSummaries, code ErrorCoder) Collector {
+	return CollectorFunc(func() []Family {
+		s := engine.Stats()
+
+		families := []Family{
+			{Name: "trading_orders_received_total", Help: "Ordens novas recebidas pelo engine.", Type: TypeCounter,
+				Samples: []Sample{{Name: "trading_orders_received_total", Value: float64(s.Received)}}},
+			{Name: "trading_orders_accepted_total", Help: "Ordens novas aceitas pelo engine.", Type: TypeCounter,
+				Samples: []Sample{{Name: "trading_orders_accepted_total", Value: float64(s.Accepted)}}},
+			rejections("trading_orders_rejected_total", "Ordens rejeitadas pelo engine por erro de domínio.", s.Rejected, code),
+			{Name: "trading_match_latency_seconds", Help: "Tempo de processamento de ordens novas no engine.", Type: TypeHistogram,
+				Samples: HistogramSamples("trading_match_latency_seconds", nil, s.Latency)},
+			{Name: "trading_active_users", Help: "Usuários distintos que enviaram ordens ao engine.", Type: TypeGauge,
+				Samples: []Sample{{Name: "trading_active_users", Value: float64(s.ActiveUsers)}}},
+			{Name: "trading_persistence_errors_total", Help: "Gravações de ordens, negociações e portfolios que falharam no repositório.", Type: TypeCounter,
+				Samples: []Sample{{Name: "trading_persistence_errors_total", Value: float64(s.PersistenceErrors)}}},
+		}
+
+		trades := Family{Name: "trading_trades_total", Help: "Negociações executadas por símbolo.", Type: TypeCounter}
+		notional := Family{Name: "trading_trade_notional_total", Help: "Valor financeiro negociado por símbolo.", Type: TypeCounter}
+		symbols := make([]string, 0, len(s.Trades))
+		for symbol := range s.Trades {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+		for _, symbol := range symbols {
+			labels := []Label{{"symbol", symbol}}
+			trades.Samples = append(trades.Samples, Sample{Name: trades.Name, Labels: labels, Value: float64(s.Trades[symbol].Count)})
+			notional.Samples = append(notional.Samples, Sample{Name: notional.Name, Labels: labels, Value: s.Trades[symbol].Sum})
+		}
+
+		orders := Family{Name: "trading_book_orders", Help: "Ordens em repouso por símbolo e lado.", Type: TypeGauge}
+		levels := Family{Name: "trading_book_levels", Help: "Níveis de preço do livro por símbolo e lado.", Type: TypeGauge}
+		quantity := Family{Name: "trading_book_quantity", Help: "Quantidade em repouso por símbolo e lado.", Type: TypeGauge}
+		for _, book := range books.Summaries() {
+			for _, side := range []struct {
+				name    string
+				summary orderbook.SideSummary
+			}{{"bid", book.Bids}, {"ask", book.Asks}} {
+				labels := []Label{{"symbol", book.Symbol}, {"side", side.name}}
+				orders.Samples = append(orders.Samples, Sample{Name: orders.Name, Labels: labels, Value: float64(side.summary.Orders)})
+				levels.Samples = append(levels.Samples, Sample{Name: levels.Name, Labels: labels, Value: float64(side.summary.Levels)})
+				quantity.Samples = append(quantity.Samples, Sample{Name: quantity.Name, Labels: labels, Value: float64(side.summary.Quantity)})
+			}
+		}
+
+		return append(families, trades, notional, orders, levels, quantity)
+	})
+}
+
+// RejectionCollector expõe como contador as rejeições por motivo de um validador
+func RejectionCollector(name, help string, source func() map[error]uint64, code ErrorCoder) Collector {
+	return CollectorFunc(func() []Family {
+		return []Family{rejections(name, help, source(), code)}
+	})
+}
+
+// rejections monta o contador de rejeições com o label reason, somando erros
+// que compartilham o mesmo código
+func rejections(name, help string, counts map[error]uint64, code ErrorCoder) Family {
+	byCode := map[string]uint64{}
+	for err, count := range counts {
+		byCode[code(err)] += count
+	}
+	codes := make([]string, 0, len(byCode))
+	for reason := range byCode {
+		codes = append(codes, reason)
+	}
+	sort.Strings(codes)
+
+	family := Family{Name: name, Help: help, Type: TypeCounter}
+	for _, reason := range codes {
+		family.Samples = append(family.Samples, Sample{Name: name, Labels: []Label{{"reason", reason}}, Value: float64(byCode[reason])})
+	}
+	return family
+}
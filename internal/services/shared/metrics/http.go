@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"trading/internal/services/shared/stats"
+)
+
+// HTTPMetrics conta as requisições e mede sua duração por método, rota e status
+type HTTPMetrics struct {
+	requests *CounterVec
+	duration *HistogramVec
+}
+
+// NewHTTPMetrics cria as métricas HTTP zeradas
+func NewHTTPMetrics() *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: NewCounterVec("http_requests_total", "Requisições HTTP por método, rota e status.", "method", "route", "code"),
+		duration: NewHistogramVec("http_request_duration_seconds", "Duração das requisições HTTP por método e rota.", stats.LatencyBuckets, "method", "route"),
+	}
+}
+
+// Observe registra uma requisição. route é o modelo da rota (ex.:
+// /api/orders/{order_id}), para que IDs não multipliquem as séries.
+func (m *HTTPMetrics) Observe(method, route string, status int, elapsed time.Duration) {
+	m.requests.Inc(method, route, strconv.Itoa(status))
+	m.duration.Observe(elapsed, method, route)
+}
+
+// Collect retorna contagens e histogramas
+func (m *HTTPMetrics) Collect() []Family {
+	return append(m.requests.Collect(), m.duration.Collect()...)
+}
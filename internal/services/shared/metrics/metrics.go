@@ -0,0 +1,287 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trading/internal/services/shared/stats"
+)
+
+// ContentType é o formato de exposição de texto do Prometheus
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Tipos de métrica do formato de texto
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// Label é um par nome/valor de uma amostra
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample é um valor de uma família. Name é o nome completo da série (com
+// sufixos como _bucket, _sum e _count nos histogramas).
+type Sample struct {
+	Name   string
+	Labels []Label
+	Value  float64
+}
+
+// Family agrupa as amostras de uma métrica
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Collector produz as famílias de métricas no momento da coleta
+type Collector interface {
+	Collect() []Family
+}
+
+// CollectorFunc adapta uma função a Collector
+type CollectorFunc func() []Family
+
+// Collect chama a função
+func (f CollectorFunc) Collect() []Family {
+	return f()
+}
+
+// Registry reúne os coletores expostos em /metrics
+type Registry struct {
+	collectors []Collector
+	mutex      sync.Mutex
+}
+
+// NewRegistry cria um registro vazio
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adiciona coletores ao registro
+func (r *Registry) Register(collectors ...Collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.collectors = append(r.collectors, collectors...)
+}
+
+// Gather coleta todas as famílias, ordenadas por nome; famílias de mesmo nome
+// vindas de coletores diferentes são unidas
+func (r *Registry) Gather() []Family {
+	r.mutex.Lock()
+	collectors := append([]Collector{}, r.collectors...)
+	r.mutex.Unlock()
+
+	byName := map[string]*Family{}
+	var names []string
+	for _, collector := range collectors {
+		for _, family := range collector.Collect() {
+			if existing, ok := byName[family.Name]; ok {
+				existing.Samples = append(existing.Samples, family.Samples...)
+				continue
+			}
+			family := family
+			byName[family.Name] = &family
+			names = append(names, family.Name)
+		}
+	}
+
+	sort.Strings(names)
+	families := make([]Family, 0, len(names))
+	for _, name := range names {
+		families = append(families, *byName[name])
+	}
+	return families
+}
+
+// Write escreve as métricas no formato de texto do Prometheus
+func (r *Registry) Write(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	for _, family := range r.Gather() {
+		fmt.Fprintf(out, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		fmt.Fprintf(out, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			out.WriteString(sample.Name)
+			if len(sample.Labels) > 0 {
+				out.WriteByte('{')
+				for i, label := range sample.Labels {
+					if i > 0 {
+						out.WriteByte(',')
+					}
+					fmt.Fprintf(out, "%s=\"%s\"", label.Name, escapeLabel(label.Value))
+				}
+				out.WriteByte('}')
+			}
+			out.WriteByte(' ')
+			out.WriteString(formatValue(sample.Value))
+			out.WriteByte('\n')
+		}
+	}
+	return out.Flush()
+}
+
+// ServeHTTP responde GET /metrics
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	_ = r.Write(w)
+}
+
+// CounterVec conta ocorrências por combinação de labels. Combinações já
+// vistas são incrementadas sem locks.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+	values sync.Map // valores dos labels unidos por \xff -> *atomic.Uint64
+}
+
+// NewCounterVec cria um contador com os labels informados
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{name: name, help: help, labels: labels}
+}
+
+// Inc soma um à série dos valores de label informados, na ordem dos labels
+func (c *CounterVec) Inc(values ...string) {
+	key := strings.Join(values, "\xff")
+	counter, ok := c.values.Load(key)
+	if !ok {
+		counter, _ = c.values.LoadOrStore(key, new(atomic.Uint64))
+	}
+	counter.(*atomic.Uint64).Add(1)
+}
+
+// Collect retorna as séries, ordenadas pelos valores dos labels
+func (c *CounterVec) Collect() []Family {
+	family := Family{Name: c.name, Help: c.help, Type: TypeCounter}
+	for _, key := range sortedKeys(&c.values) {
+		counter, _ := c.values.Load(key)
+		family.Samples = append(family.Samples, Sample{
+			Name:   c.name,
+			Labels: pairs(c.labels, key),
+			Value:  float64(counter.(*atomic.Uint64).Load()),
+		})
+	}
+	return []Family{family}
+}
+
+// HistogramVec distribui durações por combinação de labels, em segundos
+type HistogramVec struct {
+	name   string
+	help   string
+	labels []string
+	bounds []time.Duration
+	values sync.Map // valores dos labels unidos por \xff -> *stats.Histogram
+}
+
+// NewHistogramVec cria um histograma com os limites e labels informados
+func NewHistogramVec(name, help string, bounds []time.Duration, labels ...string) *HistogramVec {
+	return &HistogramVec{name: name, help: help, labels: labels, bounds: bounds}
+}
+
+// Observe registra a duração na série dos valores de label informados
+func (h *HistogramVec) Observe(d time.Duration, values ...string) {
+	key := strings.Join(values, "\xff")
+	histogram, ok := h.values.Load(key)
+	if !ok {
+		histogram, _ = h.values.LoadOrStore(key, stats.NewHistogram(h.bounds))
+	}
+	histogram.(*stats.Histogram).Observe(d)
+}
+
+// Collect retorna as séries, ordenadas pelos valores dos labels
+func (h *HistogramVec) Collect() []Family {
+	family := Family{Name: h.name, Help: h.help, Type: TypeHistogram}
+	for _, key := range sortedKeys(&h.values) {
+		histogram, _ := h.values.Load(key)
+		family.Samples = append(family.Samples, HistogramSamples(h.name, pairs(h.labels, key), histogram.(*stats.Histogram).Snapshot())...)
+	}
+	return []Family{family}
+}
+
+// HistogramSamples converte a leitura de um histograma nas séries _bucket
+// (cumulativas, com le em segundos), _sum e _count
+func HistogramSamples(name string, labels []Label, snapshot stats.HistogramSnapshot) []Sample {
+	samples := make([]Sample, 0, len(snapshot.Counts)+2)
+	var cumulative uint64
+	for i, count := range snapshot.Counts {
+		cumulative += count
+		le := "+Inf"
+		if i < len(snapshot.Bounds) {
+			le = formatValue(snapshot.Bounds[i].Seconds())
+		}
+		samples = append(samples, Sample{
+			Name:   name + "_bucket",
+			Labels: append(append([]Label{}, labels...), Label{"le", le}),
+			Value:  float64(cumulative),
+		})
+	}
+	return append(samples,
+		Sample{Name: name + "_sum", Labels: labels, Value: snapshot.Sum.Seconds()},
+		Sample{Name: name + "_count", Labels: labels, Value: float64(snapshot.Count)},
+	)
+}
+
+// sortedKeys retorna as chaves de um sync.Map em ordem
+func sortedKeys(values *sync.Map) []string {
+	var keys []string
+	values.Range(func(key, _ interface{}) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// pairs associa os valores unidos em key aos nomes dos labels
+func pairs(names []string, key string) []Label {
+	if len(names) == 0 {
+		return nil
+	}
+	values := strings.Split(key, "\xff")
+	labels := make([]Label, len(names))
+	for i, name := range names {
+		labels[i] = Label{Name: name}
+		if i < len(values) {
+			labels[i].Value = values[i]
+		}
+	}
+	return labels
+}
+
+// formatValue formata um valor como o Prometheus espera
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeHelp escapa barras e quebras de linha do texto de ajuda
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+// escapeLabel escapa barras, aspas e quebras de linha do valor de um label
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// RuntimeCollector expõe goroutines, memória e coletas de lixo do processo
+func RuntimeCollector() Collector {
+	started := time.Now()
+	return CollectorFunc(func() []Family {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		gauge := func(name, help string, value float64) Family {
+			return Family{Name: name, Help: help, Type: TypeGauge, Samples: []Sample{{Name: name, Value: value}}}
+		}
+		counter := func(name, help string, value float64) Family {
+			return Family{Name: name, Help: help, Type: TypeCounter, Samples: []Sample{{Name: name, Value: value}}}
+		}
+
+		return []Family{
+			{Name: "go_info", Help: "Versão do Go do processo.", Type: TypeGauge,
+				Samples: []Sample{{Name: "go_info", Labels: []Label{{"version", runtime.Version()}}, Value: 1}}},
+			gauge("go_goroutines", "Goroutines em execução.", float64(runtime.NumGoroutine())),
+			gauge("go_memstats_alloc_bytes", "Bytes alocados no heap e ainda em uso.", float64(mem.HeapAlloc)),
+			gauge("go_memstats_heap_inuse_bytes", "Bytes em spans do heap em uso.", float64(mem.HeapInuse)),
+			gauge("go_memstats_sys_bytes", "Bytes obtidos do sistema operacional.", float64(mem.Sys)),
+			counter("go_memstats_mallocs_total", "Alocações de objetos no heap.", float64(mem.Mallocs)),
+			counter("go_memstats_frees_total", "Objetos do heap liberados.", float64(mem.Frees)),
+			counter("go_gc_cycles_total", "Ciclos de coleta de lixo concluídos.", float64(mem.NumGC)),
+			counter("go_gc_pause_seconds_total", "Tempo total de pausa das coletas de lixo.", time.Duration(mem.PauseTotalNs).Seconds()),
+			gauge("process_start_time_seconds", "Início do processo em segundos desde a época Unix.", float64(started.Unix())),
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrOther agrupa, em um ErrorCounter, os erros fora da lista conhecida
+var ErrOther = errors.New("outro motivo")
+
+// ErrorCounter conta ocorrências por erro. A lista de erros é fixada na
+// criação, então Inc só faz operações atômicas.
+type ErrorCounter struct {
+	errs   []error
+	counts []atomic.Uint64
+}
+
+// NewErrorCounter cria o contador para os erros informados (mais ErrOther)
+func NewErrorCounter(known ...error) *ErrorCounter {
+	errs := append(append([]error{}, known...), ErrOther)
+	return &ErrorCounter{errs: errs, counts: make([]atomic.Uint64, len(errs))}
+}
+
+// Inc conta o erro no primeiro erro conhecido que casar via errors.Is
+func (c *ErrorCounter) Inc(err error) {
+	for i, known := range c.errs[:len(c.errs)-1] {
+		if errors.Is(err, known) {
+			c.counts[i].Add(1)
+			return
+		}
+	}
+	c.counts[len(c.counts)-1].Add(1)
+}
+
+// Snapshot retorna as contagens diferentes de zero
+func (c *ErrorCounter) Snapshot() map[error]uint64 {
+	counts := make(map[error]uint64)
+	for i, err := range c.errs {
+		if n := c.counts[i].Load(); n > 0 {
+			counts[err] = n
+		}
+	}
+	return counts
+}
+
+// Float é um float64 atualizado atomicamente
+type Float struct {
+	bits atomic.Uint64
+}
+
+// Add soma delta ao valor
+func (f *Float) Add(delta float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// Load retorna o valor atual
+func (f *Float) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+// Total é a quantidade de ocorrências e a soma dos valores de uma chave
+type Total struct {
+	Count uint64  `json:"count"`
+	Sum   float64 `json:"sum"`
+}
+
+// totalEntry guarda os contadores atômicos de uma chave
+type totalEntry struct {
+	count atomic.Uint64
+	sum   Float
+}
+
+// KeyedTotals acumula Total por chave. Chaves já vistas são atualizadas sem
+// locks; só a primeira ocorrência de uma chave passa pelo sync.Map.
+type KeyedTotals struct {
+	entries sync.Map // string -> *totalEntry
+}
+
+// Add registra uma ocorrência de value na chave
+func (t *KeyedTotals) Add(key string, value float64) {
+	entry, ok := t.entries.Load(key)
+	if !ok {
+		entry, _ = t.entries.LoadOrStore(key, &totalEntry{})
+	}
+	e := entry.(*totalEntry)
+	e.count.Add(1)
+	e.sum.Add(value)
+}
+
+// Snapshot retorna os totais por chave
+func (t *KeyedTotals) Snapshot() map[string]Total {
+	totals := make(map[string]Total)
+	t.entries.Range(func(key, value interface{}) bool {
+		e := value.(*totalEntry)
+		totals[key.(string)] = Total{Count: e.count.Load(), Sum: e.sum.Load()}
+		return true
+	})
+	return totals
+}
+
+// Set conta chaves distintas
+type Set struct {
+	keys sync.Map
+	size atomic.Int64
+}
+
+// Add inclui a chave no conjunto
+func (s *Set) Add(key string) {
+	if _, loaded := s.keys.Load(key); loaded {
+		return
+	}
+	if _, loaded := s.keys.LoadOrStore(key, struct{}{}); !loaded {
+		s.size.Add(1)
+	}
+}
+
+// Len retorna a quantidade de chaves distintas
+func (s *Set) Len() int {
+	return int(s.size.Load())
+}
+
+// LatencyBuckets são os limites superiores padrão dos histogramas de latência
+var LatencyBuckets = []time.Duration{
+	5 * time.Microsecond, 10 * time.Microsecond, 25 * time.Microsecond, 50 * time.Microsecond,
+	100 * time.Microsecond, 250 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond, 10 * time.Millisecond,
+	25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond,
+	500 * time.Millisecond, time.Second, 2500 * time.Millisecond, 5 * time.Second,
+}
+
+// Histogram distribui durações em faixas de limites fixos
+type Histogram struct {
+	bounds []time.Duration
+	counts []atomic.Uint64 // uma faixa por limite, mais a faixa acima do último
+	sum    atomic.Int64
+}
+
+// NewHistogram cria o histograma com os limites superiores informados, em ordem crescente
+func NewHistogram(bounds []time.Duration) *Histogram {
+	return &Histogram{bounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+// Observe registra uma duração
+func (h *Histogram) Observe(d time.Duration) {
+	i := 0
+	for i < len(h.bounds) && d > h.bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.sum.Add(int64(d))
+}
+
+// Snapshot retorna as contagens atuais
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	snapshot := HistogramSnapshot{Bounds: h.bounds, Counts: make([]uint64, len(h.counts)), Sum: time.Duration(h.sum.Load())}
+	for i := range h.counts {
+		snapshot.Counts[i] = h.counts[i].Load()
+		snapshot.Count += snapshot.Counts[i]
+	}
+	return snapshot
+}
+
+// HistogramSnapshot é uma leitura de um Histogram. Counts[i] conta as
+// durações até Bounds[i] (e acima da anterior); a última posição conta as
+// acima do último limite.
+type HistogramSnapshot struct {
+	Bounds []time.Duration `json:"bounds"`
+	Counts []uint64        `json:"counts"`
+	Count  uint64          `json:"count"`
+	Sum    time.Duration   `json:"sum"`
+}
+
+// Mean retorna a duração média (zero sem observações)
+func (s HistogramSnapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
+
+// Quantile estima o quantil q (0 a 1) interpolando dentro da faixa; acima do
+// último limite retorna o próprio limite
+func (s HistogramSnapshot) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := q * float64(s.Count)
+	var seen uint64
+	for i, n := range s.Counts {
+		if n == 0 || float64(seen+n) < rank {
+			seen += n
+			continue
+		}
+		if i == len(s.Bounds) {
+			return s.Bounds[len(s.Bounds)-1]
+		}
+		lower := time.Duration(0)
+		if i > 0 {
+			lower = s.Bounds[i-1]
+		}
+		fraction := (rank - float64(seen)) / float64(n)
+		return lower + time.Duration(fraction*float64(s.Bounds[i]-lower))
+	}
+	return s.Bounds[len(s.Bounds)-1]
+}
@@ -0,0 +1,323 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Padrões do exportador OTLP
+const (
+	DefaultBatchSize     = 512
+	DefaultFlushInterval = time.Second
+	defaultQueueSize     = 4096
+	defaultExportTimeout = 5 * time.Second
+)
+
+// FileExporter grava cada span encerrado como uma linha JSON (usado em
+// testes e depuração local)
+type FileExporter struct {
+	file    *os.File
+	encoder *json.Encoder
+	mutex   sync.Mutex
+}
+
+// NewFileExporter abre (ou cria) o arquivo em modo append
+func NewFileExporter(path string) (*FileExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Export grava o span imediatamente
+func (e *FileExporter) Export(span SpanData) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if err := e.encoder.Encode(span); err != nil {
+		slog.Error("erro ao gravar span", "span", span.Name, "error", err)
+	}
+}
+
+// Shutdown fecha o arquivo
+func (e *FileExporter) Shutdown() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.file.Close()
+}
+
+// OTLPExporter envia os spans em lotes para um coletor OTLP/HTTP (JSON).
+// Com a fila cheia, novos spans são descartados em vez de atrasar ordens.
+type OTLPExporter struct {
+	url       string
+	client    *http.Client
+	batchSize int
+	interval  time.Duration
+
+	queue chan SpanData
+	flush chan chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewOTLPExporter cria o exportador para a URL completa do coletor
+// (ex.: http://localhost:4318/v1/traces)
+func NewOTLPExporter(url string) *OTLPExporter {
+	e := &OTLPExporter{
+		url:       url,
+		client:    &http.Client{Timeout: defaultExportTimeout},
+		batchSize: DefaultBatchSize,
+		interval:  DefaultFlushInterval,
+		queue:     make(chan SpanData, defaultQueueSize),
+		flush:     make(chan chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Export enfileira o span para o próximo lote
+func (e *OTLPExporter) Export(span SpanData) {
+	select {
+	case e.queue <- span:
+	default:
+	}
+}
+
+// Flush envia imediatamente os spans enfileirados
+func (e *OTLPExporter) Flush() {
+	ack := make(chan struct{})
+	select {
+	case e.flush <- ack:
+		<-ack
+	case <-e.done:
+	}
+}
+
+// Shutdown envia os spans pendentes e encerra o envio
+func (e *OTLPExporter) Shutdown() error {
+	e.once.Do(func() {
+		e.Flush()
+		close(e.done)
+	})
+	return nil
+}
+
+// run acumula spans e envia um lote ao atingir o tamanho ou o intervalo
+func (e *OTLPExporter) run() {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, e.batchSize)
+	send := func() {
+		if len(batch) > 0 {
+			if err := e.send(batch); err != nil {
+				slog.Warn("erro ao exportar spans", "spans", len(batch), "error", err)
+			}
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case span := <-e.queue:
+			if batch = append(batch, span); len(batch) >= e.batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-e.flush:
+			for drained := false; !drained; {
+				select {
+				case span := <-e.queue:
+					batch = append(batch, span)
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(ack)
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// send faz o POST do lote no formato JSON do OTLP
+func (e *OTLPExporter) send(spans []SpanData) error {
+	body, err := json.Marshal(otlpRequest(spans))
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("coletor respondeu %s", resp.Status)
+	}
+	return nil
+}
+
+// Estruturas do ExportTraceServiceRequest em JSON (OTLP/HTTP)
+type (
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+	}
+)
+
+// Valores de Span.Kind e Status.Code do OTLP
+const (
+	otlpKindInternal = 1
+	otlpStatusOK     = 1
+	otlpStatusError  = 2
+)
+
+// otlpRequest agrupa os spans por serviço (resource)
+func otlpRequest(spans []SpanData) otlpTraces {
+	var request otlpTraces
+	index := make(map[string]int)
+	for _, span := range spans {
+		i, exists := index[span.Service]
+		if !exists {
+			i = len(request.ResourceSpans)
+			index[span.Service] = i
+			request.ResourceSpans = append(request.ResourceSpans, otlpResourceSpans{
+				Resource:   otlpResource{Attributes: []otlpAttribute{otlpAttr("service.name", span.Service)}},
+				ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "trading"}}},
+			})
+		}
+		scope := &request.ResourceSpans[i].ScopeSpans[0]
+		scope.Spans = append(scope.Spans, otlpSpanFrom(span))
+	}
+	return request
+}
+
+// otlpSpanFrom converte um span encerrado
+func otlpSpanFrom(span SpanData) otlpSpan {
+	converted := otlpSpan{
+		TraceID:           span.TraceID,
+		SpanID:            span.SpanID,
+		ParentSpanID:      span.ParentID,
+		Name:              span.Name,
+		Kind:              otlpKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+		Status:            otlpStatus{Code: otlpStatusOK},
+	}
+	for key, value := range span.Attributes {
+		converted.Attributes = append(converted.Attributes, otlpAttr(key, value))
+	}
+	if span.Error != "" {
+		converted.Status = otlpStatus{Code: otlpStatusError, Message: span.Error}
+	}
+	return converted
+}
+
+// otlpAttr converte um atributo para o AnyValue do OTLP
+func otlpAttr(key string, value interface{}) otlpAttribute {
+	var v otlpValue
+	switch x := value.(type) {
+	case string:
+		v.StringValue = &x
+	case bool:
+		v.BoolValue = &x
+	case int64:
+		s := strconv.FormatInt(x, 10)
+		v.IntValue = &s
+	case uint64:
+		s := strconv.FormatUint(x, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &x
+	default:
+		s := fmt.Sprint(x)
+		v.StringValue = &s
+	}
+	return otlpAttribute{Key: key, Value: v}
+}
+
+// Setup instala o tracer padrão conforme o ambiente e retorna a função que
+// o encerra. OTEL_EXPORTER_OTLP_TRACES_ENDPOINT (URL completa) ou
+// OTEL_EXPORTER_OTLP_ENDPOINT (base, acrescida de /v1/traces) exportam via
+// OTLP/HTTP; TRACE_FILE grava os spans em JSON lines. Sem nenhum deles o
+// tracing fica desligado. OTEL_SERVICE_NAME substitui o nome do serviço.
+func Setup(service string) (func(), error) {
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		service = name
+	}
+
+	var exporter Exporter
+	switch {
+	case os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "":
+		exporter = NewOTLPExporter(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"))
+	case os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "":
+		exporter = NewOTLPExporter(strings.TrimSuffix(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "/") + "/v1/traces")
+	case os.Getenv("TRACE_FILE") != "":
+		file, err := NewFileExporter(os.Getenv("TRACE_FILE"))
+		if err != nil {
+			return nil, err
+		}
+		exporter = file
+	default:
+		return func() {}, nil
+	}
+
+	tracer := NewTracer(service, exporter)
+	SetDefault(tracer)
+	return func() {
+		SetDefault(nil)
+		if err := tracer.Shutdown(); err != nil {
+			slog.Error("erro ao encerrar tracing", "error", err)
+		}
+	}, nil
+}
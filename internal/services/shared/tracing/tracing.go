@@ -0,0 +1,239 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trading/internal/domain"
+)
+
+// HeaderTraceParent é o header W3C Trace Context que liga os spans do web
+// service aos do engine separado
+const HeaderTraceParent = "traceparent"
+
+// TraceID identifica um trace (todos os spans de uma requisição)
+type TraceID [16]byte
+
+// String retorna o ID em hexadecimal
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifica um span dentro do trace
+type SpanID [8]byte
+
+// String retorna o ID em hexadecimal
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// spanContext é o par de IDs propagado pelo contexto e entre processos
+type spanContext struct {
+	traceID TraceID
+	spanID  SpanID
+}
+
+// spanContextKey guarda o span corrente no contexto
+type spanContextKey struct{}
+
+// SpanData é um span encerrado, entregue ao exportador
+type SpanData struct {
+	Service    string                 `json:"service"`
+	Name       string                 `json:"name"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Duration retorna a duração do span
+func (d SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// Exporter recebe os spans encerrados
+type Exporter interface {
+	Export(span SpanData)
+	Shutdown() error
+}
+
+// Tracer cria spans e os entrega ao exportador ao serem encerrados
+type Tracer struct {
+	service  string
+	exporter Exporter
+}
+
+// NewTracer cria um tracer para o serviço informado
+func NewTracer(service string, exporter Exporter) *Tracer {
+	return &Tracer{service: service, exporter: exporter}
+}
+
+// Start inicia um span filho do span corrente do contexto (ou de um pai
+// remoto) e retorna o contexto com o novo span
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, *Span) {
+	span := &Span{tracer: t, name: name, start: time.Now()}
+	if parent, ok := ctx.Value(spanContextKey{}).(spanContext); ok {
+		span.context.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		rand.Read(span.context.traceID[:])
+	}
+	rand.Read(span.context.spanID[:])
+	span.SetAttributes(attrs...)
+	return context.WithValue(ctx, spanContextKey{}, span.context), span
+}
+
+// Shutdown envia os spans pendentes e encerra o exportador
+func (t *Tracer) Shutdown() error {
+	return t.exporter.Shutdown()
+}
+
+// Span mede uma etapa. Um span nil (tracing desligado) ignora as chamadas.
+type Span struct {
+	tracer   *Tracer
+	name     string
+	context  spanContext
+	parentID SpanID
+	start    time.Time
+
+	attrs []slog.Attr
+	err   error
+	ended bool
+	mutex sync.Mutex
+}
+
+// SetAttributes acrescenta atributos ao span
+func (s *Span) SetAttributes(attrs ...slog.Attr) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mutex.Unlock()
+}
+
+// RecordError marca o span com erro (nil é ignorado)
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mutex.Lock()
+	s.err = err
+	s.mutex.Unlock()
+}
+
+// End encerra o span e o exporta; chamadas repetidas são ignoradas
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	data := SpanData{
+		Service: s.tracer.service,
+		Name:    s.name,
+		TraceID: s.context.traceID.String(),
+		SpanID:  s.context.spanID.String(),
+		Start:   s.start,
+		End:     end,
+	}
+	if s.parentID != (SpanID{}) {
+		data.ParentID = s.parentID.String()
+	}
+	if len(s.attrs) > 0 {
+		data.Attributes = make(map[string]interface{}, len(s.attrs))
+		for _, attr := range s.attrs {
+			data.Attributes[attr.Key] = attr.Value.Resolve().Any()
+		}
+	}
+	if s.err != nil {
+		data.Error = s.err.Error()
+	}
+	s.mutex.Unlock()
+
+	s.tracer.exporter.Export(data)
+}
+
+// defaultTracer é o tracer usado por Start; nil desliga o tracing
+var defaultTracer atomic.Pointer[Tracer]
+
+// SetDefault instala o tracer usado pelo pacote (nil desliga o tracing)
+func SetDefault(t *Tracer) {
+	defaultTracer.Store(t)
+}
+
+// Default retorna o tracer instalado (nil se desligado)
+func Default() *Tracer {
+	return defaultTracer.Load()
+}
+
+// Start inicia um span no tracer padrão. Sem tracer, retorna o próprio
+// contexto e um span nil, sem custo além da chamada.
+func Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, *Span) {
+	t := defaultTracer.Load()
+	if t == nil {
+		return ctx, nil
+	}
+	return t.Start(ctx, name, attrs...)
+}
+
+// TraceParent retorna o header traceparent do span corrente (vazio se não houver)
+func TraceParent(ctx context.Context) string {
+	sc, ok := ctx.Value(spanContextKey{}).(spanContext)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-01", sc.traceID, sc.spanID)
+}
+
+// WithTraceParent usa o header traceparent recebido como pai remoto dos
+// próximos spans; valores inválidos são ignorados
+func WithTraceParent(ctx context.Context, header string) context.Context {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return ctx
+	}
+
+	var sc spanContext
+	if len(parts[1]) != 2*len(sc.traceID) || len(parts[2]) != 2*len(sc.spanID) {
+		return ctx
+	}
+	if _, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil {
+		return ctx
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil {
+		return ctx
+	}
+	if sc.traceID == (TraceID{}) || sc.spanID == (SpanID{}) {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// OrderAttrs são os atributos que identificam uma ordem nos spans
+func OrderAttrs(order *domain.Order) []slog.Attr {
+	return []slog.Attr{
+		slog.String("order.id", order.ID),
+		slog.String("order.symbol", order.Symbol),
+		slog.String("order.user_id", order.UserID),
+		slog.String("order.side", string(order.Side)),
+		slog.Int("order.quantity", order.Quantity),
+		slog.Float64("order.price", order.Price),
+	}
+}
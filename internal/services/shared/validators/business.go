@@ -1,61 +1,279 @@
 package validators
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+	_ "time/tzdata" // garante America/New_York mesmo sem zoneinfo no sistema
+
 	"trading/internal/domain"
+	"trading/internal/services/shared/stats"
 )
 
+// Stock representa uma ação disponível para negociação
+type Stock struct {
+	Symbol      string  `json:"symbol"`
+	Company     string  `json:"company"`
+	Sector      string  `json:"sector"`
+	MinPrice    float64 `json:"min_price"`
+	MarketCap   string  `json:"market_cap"`
+	Description string  `json:"description"`
+}
+
+// MarketHours representa o horário de funcionamento do mercado
+type MarketHours struct {
+	Timezone     string `json:"timezone"`
+	RegularHours struct {
+		Open  string   `json:"open"`
+		Close string   `json:"close"`
+		Days  []string `json:"days"`
+	} `json:"regular_hours"`
+	ClosedDays []string `json:"closed_days"`
+	Holidays   []string `json:"holidays"`
+}
+
+// TradingRules representa regras gerais de negociação
+type TradingRules struct {
+	MinOrderValue float64 `json:"min_order_value"`
+	TickSize      float64 `json:"tick_size"`
+	LotSize       int     `json:"lot_size"`
+}
+
+// MarketStatus representa o estado atual do mercado
+type MarketStatus struct {
+	Open      bool      `json:"open"`
+	Reason    string    `json:"reason,omitempty"`
+	Timezone  string    `json:"timezone"`
+	LocalTime time.Time `json:"local_time"`
+	OpensAt   string    `json:"opens_at"`
+	ClosesAt  string    `json:"closes_at"`
+}
+
+// stocksFile representa o formato de data/stocks.json
+type stocksFile struct {
+	Stocks       map[string]Stock `json:"stocks"`
+	MarketHours  MarketHours      `json:"market_hours"`
+	TradingRules TradingRules     `json:"trading_rules"`
+}
+
 // BusinessValidator implementa validações de regras de negócio
 type BusinessValidator struct {
-	// TODO: Implementar campos necessários (ex: stocks map)
+	stocks   map[string]Stock
+	hours    MarketHours
+	rules    TradingRules
+	location *time.Location
+	openMin  int
+	closeMin int
+	days     map[time.Weekday]bool
+	holidays map[string]bool
+	now      func() time.Time
+
+	rejections *stats.ErrorCounter
 }
 
-// NewBusinessValidator cria um novo validador de negócio
-func NewBusinessValidator() *BusinessValidator {
+// NewBusinessValidator cria um novo validador de negócio a partir do arquivo de ações
+func NewBusinessValidator(stocksPath string) (*BusinessValidator, error) {
+	data, err := os.ReadFile(stocksPath)
+	if err != nil {
+		return nil, fmt.Errorf("lendo %s: %w", stocksPath, err)
+	}
+
+	var file stocksFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decodificando %s: %w", stocksPath, err)
+	}
+
+	location, err := time.LoadLocation(file.MarketHours.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("fuso horário do mercado: %w", err)
+	}
+
+	openMin, err := parseClock(file.MarketHours.RegularHours.Open)
+	if err != nil {
+		return nil, err
+	}
+	closeMin, err := parseClock(file.MarketHours.RegularHours.Close)
+	if err != nil {
+		return nil, err
+	}
+
 	validator := &BusinessValidator{
-		// TODO: Inicializar campos
+		stocks:   make(map[string]Stock, len(file.Stocks)),
+		hours:    file.MarketHours,
+		rules:    file.TradingRules,
+		location: location,
+		openMin:  openMin,
+		closeMin: closeMin,
+		days:     make(map[time.Weekday]bool),
+		holidays: make(map[string]bool),
+		now:      time.Now,
+		rejections: stats.NewErrorCounter(domain.ErrInvalidOrder, domain.ErrInvalidOrderSide, domain.ErrInvalidQuantity,
+			domain.ErrInvalidPrice, domain.ErrInvalidSymbol, domain.ErrInvalidUser, domain.ErrPriceTooLow, domain.ErrMarketClosed),
 	}
 
-	// TODO: Carregar dados de ações do arquivo JSON
-	return validator
+	for symbol, stock := range file.Stocks {
+		stock.Symbol = symbol
+		validator.stocks[symbol] = stock
+	}
+
+	for _, day := range file.MarketHours.RegularHours.Days {
+		weekday, err := parseWeekday(day)
+		if err != nil {
+			return nil, err
+		}
+		validator.days[weekday] = true
+	}
+
+	for _, holiday := range file.MarketHours.Holidays {
+		validator.holidays[holiday] = true
+	}
+
+	return validator, nil
+}
+
+// SetClock substitui o relógio usado nas validações de horário (útil em testes)
+func (v *BusinessValidator) SetClock(now func() time.Time) {
+	v.now = now
 }
 
-// ValidateOrder valida uma ordem completa
+// ValidateOrder valida uma ordem completa, contando as rejeições por motivo
 func (v *BusinessValidator) ValidateOrder(order *domain.Order) error {
-	// TODO: Implementar validações completas
-	// 1. Validar símbolo existe
-	// 2. Validar preço mínimo
-	// 3. Validar horário de mercado
-	// 4. Validar campos básicos
+	err := v.validateOrder(order)
+	if err != nil {
+		v.rejections.Inc(err)
+	}
+	return err
+}
 
-	return nil
+// Rejections retorna quantas ordens ValidateOrder rejeitou, por motivo
+func (v *BusinessValidator) Rejections() map[error]uint64 {
+	return v.rejections.Snapshot()
+}
+
+// validateOrder aplica as regras de ValidateOrder
+func (v *BusinessValidator) validateOrder(order *domain.Order) error {
+	if err := order.Validate(); err != nil {
+		return err
+	}
+	if err := v.ValidateSymbol(order.Symbol); err != nil {
+		return err
+	}
+	if err := v.ValidateMinPrice(order.Symbol, order.Price); err != nil {
+		return err
+	}
+	return v.ValidateMarketHours()
 }
 
 // ValidateSymbol valida se o símbolo existe
 func (v *BusinessValidator) ValidateSymbol(symbol string) error {
-	// TODO: Implementar validação de símbolo
-	// 1. Verificar se símbolo está na lista de 20 ações
-	// 2. Retornar erro se não encontrado
-
+	if _, exists := v.stocks[symbol]; !exists {
+		return domain.ErrInvalidSymbol
+	}
 	return nil
 }
 
 // ValidateMinPrice valida se o preço está acima do mínimo
 func (v *BusinessValidator) ValidateMinPrice(symbol string, price float64) error {
-	// TODO: Implementar validação de preço mínimo
-	// 1. Obter preço mínimo do símbolo
-	// 2. Comparar com preço fornecido
-	// 3. Retornar erro se abaixo do mínimo
-
+	stock, exists := v.stocks[symbol]
+	if !exists {
+		return domain.ErrInvalidSymbol
+	}
+	if price < stock.MinPrice {
+		return domain.ErrPriceTooLow
+	}
 	return nil
 }
 
 // ValidateMarketHours valida se o mercado está aberto
 func (v *BusinessValidator) ValidateMarketHours() error {
-	// TODO: Implementar validação de horário
-	// 1. Obter horário atual em EST
-	// 2. Verificar se é dia útil (segunda a sábado para o evento)
-	// 3. Verificar se está no horário 9:30-16:00 EST
-	// 4. Verificar feriados da NYSE
-
+	if !v.GetMarketStatus().Open {
+		return domain.ErrMarketClosed
+	}
 	return nil
 }
+
+// GetMarketStatus retorna o estado do mercado no horário atual
+func (v *BusinessValidator) GetMarketStatus() MarketStatus {
+	local := v.now().In(v.location)
+	status := MarketStatus{
+		Timezone:  v.hours.Timezone,
+		LocalTime: local,
+		OpensAt:   v.hours.RegularHours.Open,
+		ClosesAt:  v.hours.RegularHours.Close,
+	}
+
+	minutes := local.Hour()*60 + local.Minute()
+	switch {
+	case !v.days[local.Weekday()]:
+		status.Reason = "dia sem pregão"
+	case v.holidays[local.Format("2006-01-02")]:
+		status.Reason = "feriado"
+	case minutes < v.openMin:
+		status.Reason = "antes da abertura"
+	case minutes >= v.closeMin:
+		status.Reason = "após o fechamento"
+	default:
+		status.Open = true
+	}
+
+	return status
+}
+
+// Session retorna abertura e fechamento do pregão regular que contém t
+// (ok = false se t estiver fora do pregão)
+func (v *BusinessValidator) Session(t time.Time) (open, close time.Time, ok bool) {
+	local := t.In(v.location)
+	if !v.days[local.Weekday()] || v.holidays[local.Format("2006-01-02")] {
+		return time.Time{}, time.Time{}, false
+	}
+
+	// time.Date mantém o horário de parede mesmo em dias de troca de horário de verão
+	open = time.Date(local.Year(), local.Month(), local.Day(), v.openMin/60, v.openMin%60, 0, 0, v.location)
+	close = time.Date(local.Year(), local.Month(), local.Day(), v.closeMin/60, v.closeMin%60, 0, 0, v.location)
+	if local.Before(open) || !local.Before(close) {
+		return time.Time{}, time.Time{}, false
+	}
+	return open, close, true
+}
+
+// GetStock retorna os dados de uma ação
+func (v *BusinessValidator) GetStock(symbol string) (Stock, error) {
+	stock, exists := v.stocks[symbol]
+	if !exists {
+		return Stock{}, domain.ErrInvalidSymbol
+	}
+	return stock, nil
+}
+
+// GetStocks retorna as ações disponíveis ordenadas por símbolo
+func (v *BusinessValidator) GetStocks() []Stock {
+	stocks := make([]Stock, 0, len(v.stocks))
+	for _, stock := range v.stocks {
+		stocks = append(stocks, stock)
+	}
+	sort.Slice(stocks, func(i, j int) bool {
+		return stocks[i].Symbol < stocks[j].Symbol
+	})
+	return stocks
+}
+
+// parseClock converte "HH:MM" em minutos desde a meia-noite
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("horário de mercado inválido %q: %w", value, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseWeekday converte o nome do dia em time.Weekday
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if day.String() == name {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("dia da semana inválido %q", name)
+}
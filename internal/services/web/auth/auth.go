@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Erros de autenticação (respondidos com 401)
+var (
+	ErrMissingCredentials = errors.New("credenciais ausentes")
+	ErrInvalidCredentials = errors.New("credenciais inválidas")
+	ErrExpiredCredentials = errors.New("credenciais expiradas")
+)
+
+// ErrBodyTooLarge indica um corpo assinado maior que o limite lido para
+// conferir a assinatura (respondido com 413)
+var ErrBodyTooLarge = errors.New("corpo da requisição excede o limite")
+
+// Métodos de autenticação
+const (
+	MethodAPIKey     = "api_key"
+	MethodJWT        = "jwt"
+	MethodAdminToken = "admin_token"
+)
+
+// HeaderAdminToken leva o ADMIN_TOKEN, que autentica o principal admin
+const HeaderAdminToken = "X-Admin-Token"
+
+// AdminSubject identifica nos logs o principal autenticado pelo ADMIN_TOKEN
+const AdminSubject = "admin-token"
+
+// RoleAdmin concede todas as permissões, inclusive agir por outros usuários
+const RoleAdmin = "admin"
+
+// Principal é a identidade autenticada da requisição, vinculada a um usuário.
+// Sem papéis declarados na credencial, o principal é viewer.
+type Principal struct {
+	Subject string   `json:"subject"`
+	UserID  string   `json:"user_id"`
+	Roles   []string `json:"roles"`
+	Method  string   `json:"method"`
+}
+
+// HasRole informa se o principal tem o papel
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && slices.Contains(p.Roles, role)
+}
+
+// newPrincipal aplica o papel padrão às credenciais sem papéis
+func newPrincipal(subject, userID string, roles []string, method string) *Principal {
+	if len(roles) == 0 {
+		roles = []string{RoleViewer}
+	}
+	return &Principal{Subject: subject, UserID: userID, Roles: roles, Method: method}
+}
+
+// Config reúne as credenciais aceitas
+type Config struct {
+	// APIKeys são as chaves com segredo HMAC, cada uma ligada a um usuário
+	APIKeys []APIKey
+
+	// JWTSecret é o segredo HS256 dos tokens; vazio desabilita JWT
+	JWTSecret []byte
+
+	// Issuer e Audience, se definidos, são exigidos nas claims iss e aud
+	Issuer   string
+	Audience string
+
+	// AdminToken, se definido, autentica pelo X-Admin-Token um principal com
+	// o papel admin, sem usuário vinculado
+	AdminToken string
+}
+
+// Enabled informa se há credenciais de usuário configuradas (chaves de API
+// ou JWT); o AdminToken sozinho não exige autenticação nas demais rotas
+func (c Config) Enabled() bool {
+	return len(c.APIKeys) > 0 || len(c.JWTSecret) > 0
+}
+
+// ConfigFromEnv lê AUTH_API_KEYS_FILE (JSON com as chaves), JWT_SECRET,
+// JWT_ISSUER, JWT_AUDIENCE e ADMIN_TOKEN
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		JWTSecret:  []byte(os.Getenv("JWT_SECRET")),
+		Issuer:     os.Getenv("JWT_ISSUER"),
+		Audience:   os.Getenv("JWT_AUDIENCE"),
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
+	}
+	if path := os.Getenv("AUTH_API_KEYS_FILE"); path != "" {
+		keys, err := LoadAPIKeys(path)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.APIKeys = keys
+	}
+	return cfg, nil
+}
+
+// LoadAPIKeys carrega as chaves de um arquivo JSON
+func LoadAPIKeys(path string) ([]APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("chaves de API inválidas em %s: %w", path, err)
+	}
+	for _, key := range keys {
+		if key.ID == "" || key.Secret == "" || key.UserID == "" {
+			return nil, fmt.Errorf("chave de API incompleta em %s: id, secret e user_id são obrigatórios", path)
+		}
+	}
+	return keys, nil
+}
+
+// Authenticator valida as credenciais do header Authorization ou o
+// ADMIN_TOKEN:
+//
+//	Authorization: HMAC <key_id>:<assinatura>   (com X-Auth-Timestamp)
+//	Authorization: Bearer <jwt>
+//	X-Admin-Token: <ADMIN_TOKEN>
+type Authenticator struct {
+	keys       map[string]APIKey
+	secret     []byte
+	issuer     string
+	audience   string
+	adminToken string
+	skew       time.Duration
+	now        func() time.Time
+}
+
+// NewAuthenticator cria o autenticador com as credenciais configuradas
+func NewAuthenticator(cfg Config) *Authenticator {
+	keys := make(map[string]APIKey, len(cfg.APIKeys))
+	for _, key := range cfg.APIKeys {
+		keys[key.ID] = key
+	}
+	return &Authenticator{
+		keys:       keys,
+		secret:     cfg.JWTSecret,
+		issuer:     cfg.Issuer,
+		audience:   cfg.Audience,
+		adminToken: cfg.AdminToken,
+		skew:       DefaultMaxSkew,
+		now:        time.Now,
+	}
+}
+
+// SetClock substitui o relógio usado em timestamps e expiração (útil em testes)
+func (a *Authenticator) SetClock(now func() time.Time) {
+	a.now = now
+}
+
+// Authenticate identifica o principal da requisição. O X-Admin-Token, quando
+// presente, prevalece sobre o header Authorization.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if token := r.Header.Get(HeaderAdminToken); token != "" {
+		return a.authenticateAdmin(token)
+	}
+
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+	scheme, credentials, _ := strings.Cut(header, " ")
+	credentials = strings.TrimSpace(credentials)
+
+	switch {
+	case header == "":
+		return nil, ErrMissingCredentials
+	case strings.EqualFold(scheme, SchemeHMAC) && len(a.keys) > 0:
+		return a.authenticateKey(r, credentials)
+	case strings.EqualFold(scheme, "Bearer") && len(a.secret) > 0:
+		return a.authenticateToken(credentials)
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// authenticateAdmin emite o principal admin para o ADMIN_TOKEN configurado
+func (a *Authenticator) authenticateAdmin(token string) (*Principal, error) {
+	if a.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(a.adminToken)) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	return newPrincipal(AdminSubject, "", []string{RoleAdmin}, MethodAdminToken), nil
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
+// jwtLeeway tolera pequenas diferenças de relógio em exp e nbf
+const jwtLeeway = 30 * time.Second
+
+// Claims são as claims aceitas nos tokens. sub é o user_id; roles lista os
+// papéis (role, com um único papel, também é aceito).
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Roles     []string `json:"roles,omitempty"`
+	Role      string   `json:"role,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+// audience aceita aud como string ou lista
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// jwtHeader é o cabeçalho do token; só HS256 é aceito
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+var encoding = base64.RawURLEncoding
+
+// NewToken emite um token HS256 com as claims (usado por ferramentas e testes)
+func NewToken(claims Claims, secret []byte) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signing := encoding.EncodeToString(header) + "." + encoding.EncodeToString(payload)
+	return signing + "." + encoding.EncodeToString(signHS256(signing, secret)), nil
+}
+
+// authenticateToken valida assinatura, algoritmo, validade, emissor e audiência
+func (a *Authenticator) authenticateToken(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCredentials
+	}
+
+	var header jwtHeader
+	if !decodeSegment(parts[0], &header) || header.Alg != "HS256" {
+		return nil, ErrInvalidCredentials
+	}
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, signHS256(parts[0]+"."+parts[1], a.secret)) {
+		return nil, ErrInvalidCredentials
+	}
+
+	var claims Claims
+	if !decodeSegment(parts[1], &claims) || claims.Subject == "" || claims.ExpiresAt == 0 {
+		return nil, ErrInvalidCredentials
+	}
+	now := a.now()
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(jwtLeeway)) {
+		return nil, ErrExpiredCredentials
+	}
+	if claims.NotBefore != 0 && now.Add(jwtLeeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, ErrInvalidCredentials
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return nil, ErrInvalidCredentials
+	}
+	if a.audience != "" && !slices.Contains(claims.Audience, a.audience) {
+		return nil, ErrInvalidCredentials
+	}
+
+	roles := claims.Roles
+	if claims.Role != "" {
+		roles = append(roles, claims.Role)
+	}
+	return newPrincipal(claims.Subject, claims.Subject, roles, MethodJWT), nil
+}
+
+// decodeSegment decodifica um trecho base64url do token em JSON
+func decodeSegment(segment string, out interface{}) bool {
+	data, err := encoding.DecodeString(segment)
+	return err == nil && json.Unmarshal(data, out) == nil
+}
+
+// signHS256 assina o trecho header.payload
+func signHS256(signing string, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signing))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,64 @@
+package auth
+
+import "slices"
+
+// Papéis atribuídos aos principais
+const (
+	RoleViewer = "viewer"
+	RoleTrader = "trader"
+	RoleRisk   = "risk"
+)
+
+// Permission é uma ação protegida, declarada por rota
+type Permission string
+
+// Permissões da API
+const (
+	PermMarketRead    Permission = "market:read"
+	PermOrdersRead    Permission = "orders:read"
+	PermOrdersWrite   Permission = "orders:write"
+	PermPortfolioRead Permission = "portfolio:read"
+	PermStatsRead     Permission = "stats:read"
+	PermBookL3        Permission = "book:l3"
+	PermUsersRead     Permission = "users:read"
+	PermUsersWrite    Permission = "users:write"
+	PermAdmin         Permission = "admin"
+)
+
+// Administrative informa se a permissão é reservada a administradores. Sem
+// autenticação configurada, só o principal admin do X-Admin-Token as tem.
+func (p Permission) Administrative() bool {
+	switch p {
+	case PermBookL3, PermAdmin:
+		return true
+	}
+	return false
+}
+
+// viewerPermissions são as leituras do próprio usuário e do mercado
+var viewerPermissions = []Permission{PermMarketRead, PermOrdersRead, PermPortfolioRead}
+
+// RolePermissions associa cada papel às permissões concedidas. users:read e
+// users:write permitem ler e agir sobre dados de outros usuários.
+var RolePermissions = map[string][]Permission{
+	RoleViewer: viewerPermissions,
+	RoleTrader: append(slices.Clone(viewerPermissions), PermOrdersWrite),
+	RoleRisk:   append(slices.Clone(viewerPermissions), PermStatsRead, PermBookL3, PermUsersRead),
+	RoleAdmin: {
+		PermMarketRead, PermOrdersRead, PermOrdersWrite, PermPortfolioRead,
+		PermStatsRead, PermBookL3, PermUsersRead, PermUsersWrite, PermAdmin,
+	},
+}
+
+// Can informa se algum papel do principal concede a permissão
+func (p *Principal) Can(permission Permission) bool {
+	if p == nil {
+		return false
+	}
+	for _, role := range p.Roles {
+		if slices.Contains(RolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,39 +1,231 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"runtime"
+	"time"
 
 	restful "github.com/emicklei/go-restful/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
+	"trading/internal/services/engine"
+	"trading/internal/services/engine/api"
+	"trading/internal/services/engine/repository"
+	"trading/internal/services/fix"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/shared/marketdata"
+	"trading/internal/services/shared/metrics"
+	"trading/internal/services/shared/tracing"
+	"trading/internal/services/shared/validators"
+	"trading/internal/services/web/auth"
 	"trading/internal/services/web/handlers"
+	"trading/internal/services/web/ratelimit"
+	"trading/internal/services/web/stream"
 )
 
+// marketDataBackfill é o período de negociações agregado em barras e tickers ao iniciar
+const marketDataBackfill = 7 * 24 * time.Hour
+
 func main() {
-	log.Println("🚀 Iniciando Sistema de Trading Web Service...")
+	logging.Setup()
+	slog.Info("iniciando web service")
+
+	// Spans via OTLP ou arquivo, se configurados
+	shutdownTracing, err := tracing.Setup("trading-web")
+	if err != nil {
+		logging.Fatal("erro ao configurar tracing", err)
+	}
+	defer shutdownTracing()
+
+	dataDir := getEnv("DATA_DIR", "data")
+
+	validator, err := validators.NewBusinessValidator(filepath.Join(dataDir, "stocks.json"))
+	if err != nil {
+		logging.Fatal("erro ao carregar ações", err)
+	}
+
+	// Barramento de eventos entre engine e camada web
+	bus := events.NewBus()
+	defer bus.Close()
+
+	// Métricas Prometheus em /metrics; com engine embutido incluem as do engine
+	registry := metrics.NewRegistry()
+	httpMetrics := metrics.NewHTTPMetrics()
+	registry.Register(httpMetrics, metrics.RuntimeCollector(),
+		metrics.RejectionCollector("trading_validation_rejections_total", "Ordens rejeitadas pelas regras de ações e mercado antes do engine.", validator.Rejections, handlers.ErrorCode))
+
+	var (
+		matcher    handlers.OrderProcessor
+		books      handlers.OrderBookProvider
+		portfolios handlers.PortfolioProvider
+		snapshots  handlers.SnapshotService
+		startCore  func()
+	)
+
+	if engineAddr := os.Getenv("ENGINE_ADDR"); engineAddr != "" {
+		// Engine em processo separado: chamadas e eventos pela rede
+		timeout, err := time.ParseDuration(getEnv("ENGINE_TIMEOUT", api.DefaultTimeout.String()))
+		if err != nil {
+			logging.Fatal("ENGINE_TIMEOUT inválido", err)
+		}
+		client := api.NewClient(engineAddr, timeout)
+		client.SetToken(os.Getenv("ENGINE_TOKEN"))
+		client.SetAdminToken(os.Getenv("ADMIN_TOKEN"))
+		matcher, books, portfolios, snapshots = client, client, client, client
+		startCore = func() { go client.Subscribe(context.Background(), bus) }
+		slog.Info("usando engine remoto", "addr", engineAddr)
+
+		// Ordens, livro e portfolio pelo canal gRPC, se configurado
+		if grpcAddr := os.Getenv("ENGINE_GRPC_ADDR"); grpcAddr != "" {
+			conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+				api.TokenCredentials(os.Getenv("ENGINE_TOKEN")))
+			if err != nil {
+				logging.Fatal("erro ao conectar ao engine via gRPC", err)
+			}
+			defer conn.Close()
+
+			grpcClient := api.NewGRPCClient(conn, client, timeout)
+			matcher, books, portfolios = grpcClient, grpcClient, grpcClient
+			slog.Info("usando gRPC do engine", "addr", grpcAddr)
+		}
+	} else {
+		// Engine embutido: recupera o estado de banco, journal e snapshots
+		cfg, err := engine.ConfigFromEnv()
+		if err != nil {
+			logging.Fatal("configuração inválida", err)
+		}
+		core, err := engine.Open(cfg)
+		if err != nil {
+			logging.Fatal("erro ao iniciar engine", err)
+		}
+		defer core.Close()
+
+		core.SetPublisher(bus)
+		matcher, books, portfolios = core.Matcher, core.Books, core.Portfolios
+		registry.Register(metrics.EngineCollector(core.Matcher, core.Books, handlers.ErrorCode))
+		if core.Snapshots != nil {
+			snapshots = core.Snapshots
+		}
+		startCore = func() { go core.Run(context.Background()) }
+
+		// Gateway FIX junto ao engine embutido, se FIX_PORT e FIX_CLIENTS
+		// estiverem definidos; escuta em ENGINE_BIND (padrão: interface local)
+		if fixPort := os.Getenv("FIX_PORT"); fixPort != "" {
+			fixConfig, err := fix.ConfigFromEnv()
+			if err != nil {
+				logging.Fatal("FIX_CLIENTS inválido", err)
+			}
+			if len(fixConfig.Clients) == 0 {
+				logging.Fatal("gateway FIX sem clientes", errors.New("defina FIX_CLIENTS junto com FIX_PORT"))
+			}
+			gateway := fix.NewAcceptor(fixConfig, core.Matcher, core.Portfolios, validator)
+			bus.Subscribe("fix", gateway.HandleEvent)
+			defer gateway.Close()
+
+			fixAddr := net.JoinHostPort(getEnv("ENGINE_BIND", "127.0.0.1"), fixPort)
+			go func() {
+				if err := gateway.ListenAndServe(fixAddr); err != nil {
+					logging.Fatal("erro no gateway FIX", err)
+				}
+			}()
+			slog.Info("gateway FIX 4.4 ativo", "addr", fixAddr)
+		}
+	}
+
+	// Market data em tempo real via WebSocket. O hub guarda estado por
+	// símbolo, então os símbolos são distribuídos entre partições.
+	marketData := stream.NewMarketDataHub(books, validator)
+	bus.Subscribe("marketdata", marketData.HandleEvent, events.WithPartitions(runtime.GOMAXPROCS(0)))
+
+	// Barras OHLCV e tickers, retomados do histórico recente de negociações
+	var symbols []string
+	for _, stock := range validator.GetStocks() {
+		symbols = append(symbols, stock.Symbol)
+	}
+	candles := marketdata.NewCandleStore(validator, 0)
+	tickers := marketdata.NewTickerService(symbols, validator, books)
+	recent, err := matcher.ListTrades(repository.TradeFilter{From: time.Now().Add(-marketDataBackfill)})
+	if err != nil {
+		slog.Warn("erro ao carregar negociações para market data", "error", err)
+	}
+	for _, trade := range recent {
+		candles.Add(trade)
+		tickers.Add(trade)
+	}
+	bus.Subscribe("candles", candles.HandleEvent)
+	bus.Subscribe("tickers", tickers.HandleEvent)
+
+	// Eventos por usuário via Server-Sent Events
+	userEvents := stream.NewUserEventHub(0)
+	bus.Subscribe("user-events", userEvents.HandleEvent)
+
+	startCore()
 
 	// Cria container RESTful
-	ws := handlers.NewInternalWebRestfulContainer()
+	tradingHandler := handlers.NewTradingHandler(matcher, books, portfolios, validator)
+	authConfig, err := auth.ConfigFromEnv()
+	if err != nil {
+		logging.Fatal("configuração de autenticação inválida", err)
+	}
+	if authConfig.Enabled() {
+		tradingHandler.SetAuthenticator(auth.NewAuthenticator(authConfig))
+		slog.Info("autenticação habilitada", "api_keys", len(authConfig.APIKeys), "jwt", len(authConfig.JWTSecret) > 0)
+	} else {
+		if authConfig.AdminToken != "" {
+			tradingHandler.SetAdminAuthenticator(auth.NewAuthenticator(authConfig))
+		}
+		slog.Warn("autenticação desabilitada: defina AUTH_API_KEYS_FILE ou JWT_SECRET")
+	}
+	rateLimits, err := ratelimit.ConfigFromEnv(filepath.Join(dataDir, "users.json"))
+	if err != nil {
+		logging.Fatal("configuração de limite de requisições inválida", err)
+	}
+	tradingHandler.SetRateLimiter(ratelimit.NewLimiter(), rateLimits)
+	tradingHandler.SetUserEvents(userEvents)
+	tradingHandler.SetEventBus(bus)
+	tradingHandler.SetCandles(candles)
+	tradingHandler.SetTickers(tickers)
+	tradingHandler.SetHTTPMetrics(httpMetrics)
+	tradingHandler.SetMetrics(registry)
+	tradingHandler.SetMarketData(marketData)
+	origins := handlers.ParseOrigins(os.Getenv("CORS_ORIGINS"))
+	tradingHandler.SetAllowedOrigins(origins)
+	marketData.SetAllowedOrigins(origins)
+	if snapshots != nil {
+		tradingHandler.SetSnapshots(snapshots)
+	}
+	ws := handlers.NewInternalWebRestfulContainer(tradingHandler)
 
 	// Configura router
 	restful.DefaultContainer.Router(restful.CurlyRouter{})
-	restful.Add(ws.GetWS())
+	for _, service := range ws.GetWebServices() {
+		restful.Add(service)
+	}
 
 	// Configurações globais
 	restful.DefaultContainer.EnableContentEncoding(true)
+	restful.DefaultContainer.ServiceErrorHandler(handlers.ServiceErrorHandler)
 
 	// Porta do servidor
 	port := getEnv("PORT", "8080")
-	log.Printf("📊 Web Service rodando na porta %s", port)
-	log.Printf("🌐 Health Check: http://localhost:%s/api/health", port)
-	log.Printf("📈 API: http://localhost:%s/api/orders", port)
-	log.Printf("📊 Order Book: http://localhost:%s/api/orderbook/AAPL", port)
-	log.Printf("👤 Portfolio: http://localhost:%s/api/portfolio/ana-silva", port)
+	slog.Info("web service rodando",
+		"port", port,
+		"health", "http://localhost:"+port+"/api/health",
+		"api", "http://localhost:"+port+"/api/orders",
+		"marketdata", "ws://localhost:"+port+"/api/ws/marketdata",
+		"metrics", "http://localhost:"+port+"/metrics")
 
 	// Inicia servidor
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatal("❌ Erro ao iniciar web service:", err)
+		logging.Fatal("erro ao iniciar web service", err)
 	}
 }
 
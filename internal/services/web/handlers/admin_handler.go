@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"trading/internal/services/engine/snapshot"
+	"trading/internal/services/web/auth"
+)
+
+// SnapshotService grava e restaura snapshots do engine
+type SnapshotService interface {
+	Take() (snapshot.Info, error)
+	List() ([]snapshot.Info, error)
+	Restore(seq uint64) (snapshot.Info, int, error)
+}
+
+// RestoreResponse é a resposta de POST /admin/snapshots/{seq}/restore
+type RestoreResponse struct {
+	Snapshot snapshot.Info `json:"snapshot"`
+	Replayed int           `json:"replayed_commands"`
+}
+
+// SetSnapshots habilita os endpoints administrativos de snapshot
+func (h *TradingHandler) SetSnapshots(snapshots SnapshotService) {
+	h.snapshots = snapshots
+}
+
+// ListSnapshots lista os snapshots disponíveis
+func (h *TradingHandler) ListSnapshots(req *restful.Request, resp *restful.Response) {
+	if !h.can(req, auth.PermAdmin) {
+		h.forbid(req, resp, auth.PermAdmin, "requer a permissão admin")
+		return
+	}
+
+	infos, err := h.snapshots.List()
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	writeJSON(resp, http.StatusOK, infos)
+}
+
+// TakeSnapshot grava um snapshot do estado atual do engine
+func (h *TradingHandler) TakeSnapshot(req *restful.Request, resp *restful.Response) {
+	if !h.can(req, auth.PermAdmin) {
+		h.forbid(req, resp, auth.PermAdmin, "requer a permissão admin")
+		return
+	}
+
+	info, err := h.snapshots.Take()
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	writeJSON(resp, http.StatusCreated, info)
+}
+
+// RestoreSnapshot recarrega o engine a partir de um snapshot e do journal
+func (h *TradingHandler) RestoreSnapshot(req *restful.Request, resp *restful.Response) {
+	if !h.can(req, auth.PermAdmin) {
+		h.forbid(req, resp, auth.PermAdmin, "requer a permissão admin")
+		return
+	}
+
+	seq, err := strconv.ParseUint(req.PathParameter("seq"), 10, 64)
+	if err != nil {
+		writeError(req, resp, errInvalidParameter, "seq deve ser numérico")
+		return
+	}
+
+	info, replayed, err := h.snapshots.Restore(seq)
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	writeJSON(resp, http.StatusOK, RestoreResponse{Snapshot: info, Replayed: replayed})
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"trading/internal/services/web/auth"
+)
+
+// Authenticator identifica o principal de uma requisição
+type Authenticator interface {
+	Authenticate(r *http.Request) (*auth.Principal, error)
+}
+
+// attrPrincipal guarda o principal autenticado nos atributos da requisição
+const attrPrincipal = "principal"
+
+// metaPublic marca as rotas acessíveis sem autenticação
+const metaPublic = "public"
+
+// metaPermission declara a permissão exigida pela rota. Com autenticação,
+// rotas não públicas sem permissão declarada são negadas.
+const metaPermission = "permission"
+
+// Detalhes das respostas 403
+const (
+	detailUserMismatch = "user_id não corresponde ao usuário autenticado"
+	detailOrderOwner   = "a ordem pertence a outro usuário"
+	detailUndeclared   = "rota sem permissão declarada"
+)
+
+// SetAuthenticator passa a exigir credenciais (chave de API ou JWT) em todas
+// as rotas não públicas, com as permissões dos papéis do principal
+func (h *TradingHandler) SetAuthenticator(authenticator Authenticator) {
+	h.auth = authenticator
+}
+
+// SetAdminAuthenticator autentica, sem exigir credenciais nas demais
+// requisições, as que apresentam X-Admin-Token: elas recebem o principal
+// admin emitido pelo autenticador. Só é usado sem SetAuthenticator, cujo
+// autenticador já aceita o ADMIN_TOKEN.
+func (h *TradingHandler) SetAdminAuthenticator(authenticator Authenticator) {
+	h.admin = authenticator
+}
+
+// SetAuditLogger define onde são registrados os acessos negados (padrão: o
+// logger global, com log=audit)
+func (h *TradingHandler) SetAuditLogger(logger *slog.Logger) {
+	h.audit = logger
+}
+
+// principal retorna o principal autenticado (nil sem autenticação)
+func principal(req *restful.Request) *auth.Principal {
+	p, _ := req.Attribute(attrPrincipal).(*auth.Principal)
+	return p
+}
+
+// can informa se a requisição tem a permissão. Com principal, valem os seus
+// papéis; sem autenticação configurada, o anônimo tem todas menos as
+// administrativas.
+func (h *TradingHandler) can(req *restful.Request, permission auth.Permission) bool {
+	if p := principal(req); p != nil {
+		return p.Can(permission)
+	}
+	return h.auth == nil && !permission.Administrative()
+}
+
+// owns informa se o principal pode agir em nome do usuário: o próprio
+// usuário ou um papel com users:read (leituras) ou users:write (alterações).
+// Sem autenticação configurada, tudo é permitido.
+func (h *TradingHandler) owns(req *restful.Request, userID string) bool {
+	if h.auth == nil {
+		return true
+	}
+	p := principal(req)
+	if p != nil && p.UserID == userID {
+		return true
+	}
+	if req.Request.Method == http.MethodGet {
+		return p.Can(auth.PermUsersRead)
+	}
+	return p.Can(auth.PermUsersWrite)
+}
+
+// auditLogger retorna o logger de auditoria
+func (h *TradingHandler) auditLogger() *slog.Logger {
+	if h.audit == nil {
+		return slog.Default().With("log", "audit")
+	}
+	return h.audit
+}
+
+// forbid responde 403 e registra a tentativa no log de auditoria
+func (h *TradingHandler) forbid(req *restful.Request, resp *restful.Response, permission auth.Permission, detail string) {
+	attrs := []any{
+		"method", req.Request.Method,
+		"path", req.Request.URL.Path,
+		"route", req.SelectedRoutePath(),
+		"permission", string(permission),
+		"reason", detail,
+		"remote_addr", req.Request.RemoteAddr,
+	}
+	if p := principal(req); p != nil {
+		attrs = append(attrs, "subject", p.Subject, "user_id", p.UserID, "roles", p.Roles, "auth_method", p.Method)
+	}
+	h.auditLogger().WarnContext(req.Request.Context(), "acesso negado", attrs...)
+
+	writeError(req, resp, errForbidden, detail)
+}
+
+// authFilter autentica a requisição, exige a permissão declarada na rota e
+// confere o {user_id} do caminho e o ?user_id da query contra o principal.
+// Sem autenticação configurada, só as requisições com X-Admin-Token são
+// autenticadas; as demais seguem anônimas.
+func (c *InternalWebRestfulContainer) authFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	h := c.tradingHandler
+	if req.SelectedRoute() == nil || req.SelectedRoute().Metadata()[metaPublic] == true {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+
+	authenticator := h.auth
+	if authenticator == nil {
+		if h.admin == nil || req.HeaderParameter(auth.HeaderAdminToken) == "" {
+			chain.ProcessFilter(req, resp)
+			return
+		}
+		authenticator = h.admin
+	}
+
+	p, err := authenticator.Authenticate(req.Request)
+	if err != nil {
+		if !errors.Is(err, auth.ErrBodyTooLarge) {
+			resp.Header().Set("WWW-Authenticate", `Bearer, HMAC`)
+		}
+		writeError(req, resp, err, nil)
+		return
+	}
+	req.SetAttribute(attrPrincipal, p)
+	if p.Method == auth.MethodAdminToken {
+		h.auditLogger().InfoContext(req.Request.Context(), "acesso administrativo",
+			"method", req.Request.Method, "path", req.Request.URL.Path, "route", req.SelectedRoutePath(),
+			"subject", p.Subject, "remote_addr", req.Request.RemoteAddr)
+	}
+
+	permission, declared := req.SelectedRoute().Metadata()[metaPermission].(auth.Permission)
+	switch {
+	case !declared:
+		h.forbid(req, resp, "", detailUndeclared)
+		return
+	case !p.Can(permission):
+		h.forbid(req, resp, permission, "requer a permissão "+string(permission))
+		return
+	}
+
+	for _, userID := range []string{req.PathParameter("user_id"), req.QueryParameter("user_id")} {
+		if userID != "" && !h.owns(req, userID) {
+			h.forbid(req, resp, permission, detailUserMismatch)
+			return
+		}
+	}
+	chain.ProcessFilter(req, resp)
+}
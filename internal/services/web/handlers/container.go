@@ -1,26 +1,34 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
 	restful "github.com/emicklei/go-restful/v3"
+
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/shared/tracing"
+	"trading/internal/services/web/auth"
 )
 
 // InternalWebRestfulContainer gerencia o container RESTful
 type InternalWebRestfulContainer struct {
 	webService     *restful.WebService
+	metricsService *restful.WebService
 	tradingHandler *TradingHandler
 }
 
 // NewInternalWebRestfulContainer cria um novo container RESTful
-func NewInternalWebRestfulContainer() *InternalWebRestfulContainer {
+func NewInternalWebRestfulContainer(tradingHandler *TradingHandler) *InternalWebRestfulContainer {
 	container := &InternalWebRestfulContainer{
-		tradingHandler: &TradingHandler{},
+		tradingHandler: tradingHandler,
 	}
 
 	// Configura web service
 	container.setupWebService()
+	container.setupMetricsService()
 
 	return container
 }
@@ -30,6 +38,23 @@ func (c *InternalWebRestfulContainer) GetWS() *restful.WebService {
 	return c.webService
 }
 
+// GetWebServices retorna a API e, se habilitado, o serviço de /metrics
+func (c *InternalWebRestfulContainer) GetWebServices() []*restful.WebService {
+	if c.metricsService == nil {
+		return []*restful.WebService{c.webService}
+	}
+	return []*restful.WebService{c.webService, c.metricsService}
+}
+
+// filters aplica os filtros comuns a todos os web services: CORS, logging,
+// autenticação com a permissão declarada na rota e limite de requisições
+func (c *InternalWebRestfulContainer) filters(ws *restful.WebService) {
+	ws.Filter(c.corsFilter)
+	ws.Filter(c.loggingFilter)
+	ws.Filter(c.authFilter)
+	ws.Filter(c.rateLimitFilter)
+}
+
 // setupWebService configura rotas e middleware
 func (c *InternalWebRestfulContainer) setupWebService() {
 	ws := new(restful.WebService)
@@ -40,32 +65,67 @@ func (c *InternalWebRestfulContainer) setupWebService() {
 		Produces(restful.MIME_JSON).
 		Doc("Trading System API")
 
-	// Middleware de CORS
-	ws.Filter(c.corsFilter)
-
-	// Middleware de logging
-	ws.Filter(c.loggingFilter)
+	// CORS, logging, autenticação por chave de API ou JWT (se configurada)
+	// com a permissão declarada em cada rota e limite de requisições por IP
+	// e por usuário (se configurado)
+	c.filters(ws)
 
 	// Health check
 	ws.Route(ws.GET("/health").To(c.tradingHandler.HealthCheck).
 		Doc("Health check endpoint").
+		Metadata(metaPublic, true).
 		Returns(200, "OK", nil))
 
 	// Rotas de ordens
 	ws.Route(ws.POST("/orders").To(c.tradingHandler.CreateOrder).
 		Doc("Create a new order").
+		Metadata(metaPermission, auth.PermOrdersWrite).
+		Metadata(metaRateLimited, true).
+		Param(ws.HeaderParameter(HeaderIdempotencyKey, "client_order_id, if not given in the body").DataType("string")).
 		Returns(201, "Order created", nil).
-		Returns(400, "Bad request", nil))
+		Returns(400, "Bad request", nil).
+		Returns(409, "client_order_id reused with different parameters", nil).
+		Returns(429, "Rate limit exceeded", nil))
+
+	ws.Route(ws.GET("/orders/{order_id}").To(c.tradingHandler.GetOrder).
+		Doc("Get the last known state of an order").
+		Metadata(metaPermission, auth.PermOrdersRead).
+		Param(ws.PathParameter("order_id", "Order ID").DataType("string")).
+		Returns(200, "OK", nil).
+		Returns(404, "Order not found", nil))
+
+	ws.Route(ws.PATCH("/orders/{order_id}").To(c.tradingHandler.AmendOrder).
+		Doc("Amend quantity and price of an open order").
+		Metadata(metaPermission, auth.PermOrdersWrite).
+		Metadata(metaRateLimited, true).
+		Param(ws.PathParameter("order_id", "Order ID").DataType("string")).
+		Returns(200, "Order amended", nil).
+		Returns(400, "Bad request", nil).
+		Returns(404, "Order not found", nil))
+
+	ws.Route(ws.DELETE("/orders/{order_id}").To(c.tradingHandler.CancelOrder).
+		Doc("Cancel an open order").
+		Metadata(metaPermission, auth.PermOrdersWrite).
+		Metadata(metaRateLimited, true).
+		Param(ws.PathParameter("order_id", "Order ID").DataType("string")).
+		Returns(200, "Order cancelled", nil).
+		Returns(404, "Order not found", nil))
 
 	// Rotas de order book
 	ws.Route(ws.GET("/orderbook/{symbol}").To(c.tradingHandler.GetOrderBook).
 		Doc("Get order book for symbol").
+		Metadata(metaPermission, auth.PermMarketRead).
 		Param(ws.PathParameter("symbol", "Stock symbol").DataType("string")).
-		Returns(200, "OK", nil))
+		Param(ws.QueryParameter("depth", "Number of price levels per side (1-100, default 10)").DataType("integer")).
+		Param(ws.QueryParameter("level", "2 for aggregated levels (default), 3 for individual orders (admin only)").DataType("integer")).
+		Returns(200, "OK", nil).
+		Returns(400, "Invalid parameter", nil).
+		Returns(403, "Forbidden", nil))
 
 	// Rotas de portfolio
 	ws.Route(ws.GET("/portfolio/{user_id}").To(c.tradingHandler.GetPortfolio).
 		Doc("Get user portfolio").
+		Metadata(metaPermission, auth.PermPortfolioRead).
 		Param(ws.PathParameter("user_id", "User ID").DataType("string")).
 		Returns(200, "OK", nil).
 		Returns(404, "User not found", nil))
@@ -73,37 +133,185 @@ func (c *InternalWebRestfulContainer) setupWebService() {
 	// Rotas de usuário
 	ws.Route(ws.GET("/users/{user_id}").To(c.tradingHandler.GetUserProfile).
 		Doc("Get user profile").
+		Metadata(metaPermission, auth.PermPortfolioRead).
 		Param(ws.PathParameter("user_id", "User ID").DataType("string")).
 		Returns(200, "OK", nil))
 
+	ws.Route(ws.GET("/users/{user_id}/orders").To(c.tradingHandler.ListUserOrders).
+		Doc("Get user order history").
+		Metadata(metaPermission, auth.PermOrdersRead).
+		Param(ws.PathParameter("user_id", "User ID").DataType("string")).
+		Param(ws.QueryParameter("status", "Filter by order status").DataType("string")).
+		Param(ws.QueryParameter("symbol", "Filter by stock symbol").DataType("string")).
+		Param(ws.QueryParameter("limit", "Maximum number of orders (1-1000, default 100)").DataType("integer")).
+		Returns(200, "OK", nil).
+		Returns(400, "Invalid parameter", nil).
+		Returns(404, "User not found", nil))
+
+	// Ordens pelo client_order_id
+	ws.Route(ws.GET("/users/{user_id}/client-orders/{client_order_id}").To(c.tradingHandler.GetClientOrder).
+		Doc("Get the last known state of a user's order by client_order_id").
+		Metadata(metaPermission, auth.PermOrdersRead).
+		Param(ws.PathParameter("user_id", "User ID").DataType("string")).
+		Param(ws.PathParameter("client_order_id", "Client order ID").DataType("string")).
+		Returns(200, "OK", nil).
+		Returns(404, "Order not found", nil))
+
+	ws.Route(ws.DELETE("/users/{user_id}/client-orders/{client_order_id}").To(c.tradingHandler.CancelClientOrder).
+		Doc("Cancel a user's open order by client_order_id").
+		Metadata(metaPermission, auth.PermOrdersWrite).
+		Metadata(metaRateLimited, true).
+		Param(ws.PathParameter("user_id", "User ID").DataType("string")).
+		Param(ws.PathParameter("client_order_id", "Client order ID").DataType("string")).
+		Returns(200, "Order cancelled", nil).
+		Returns(404, "Order not found", nil).
+		Returns(409, "Order is no longer open", nil))
+
+	// Stream de eventos do usuário (SSE)
+	if c.tradingHandler.events != nil {
+		ws.Route(ws.GET("/users/{user_id}/events").To(c.tradingHandler.StreamUserEvents).
+			Doc("Stream user order, trade and portfolio events (Server-Sent Events)").
+			Metadata(metaPermission, auth.PermPortfolioRead).
+			Param(ws.PathParameter("user_id", "User ID").DataType("string")).
+			Param(ws.HeaderParameter("Last-Event-ID", "Resume after this event ID").DataType("integer")).
+			Produces("text/event-stream", restful.MIME_JSON).
+			ContentEncodingEnabled(false).
+			Returns(200, "OK", nil).
+			Returns(404, "User not found", nil))
+	}
+
 	// Rotas de mercado
 	ws.Route(ws.GET("/market/status").To(c.tradingHandler.GetMarketStatus).
 		Doc("Get market status").
+		Metadata(metaPermission, auth.PermMarketRead).
 		Returns(200, "OK", nil))
 
+	// Barras OHLCV
+	if c.tradingHandler.candles != nil {
+		ws.Route(ws.GET("/market/{symbol}/candles").To(c.tradingHandler.GetCandles).
+			Doc("Get OHLCV candles with VWAP and trade count").
+			Metadata(metaPermission, auth.PermMarketRead).
+			Param(ws.PathParameter("symbol", "Stock symbol").DataType("string")).
+			Param(ws.QueryParameter("interval", "1s, 1m (default), 5m, 1h or 1d").DataType("string")).
+			Param(ws.QueryParameter("from", "Candles starting at or after (RFC 3339 or YYYY-MM-DD)").DataType("string")).
+			Param(ws.QueryParameter("to", "Candles starting before (RFC 3339 or YYYY-MM-DD)").DataType("string")).
+			Returns(200, "OK", nil).
+			Returns(400, "Invalid parameter", nil))
+	}
+
+	// Ticker por símbolo
+	if c.tradingHandler.tickers != nil {
+		ws.Route(ws.GET("/market/{symbol}/ticker").To(c.tradingHandler.GetTicker).
+			Doc("Get last price, change, session statistics and best bid/ask").
+			Metadata(metaPermission, auth.PermMarketRead).
+			Param(ws.PathParameter("symbol", "Stock symbol").DataType("string")).
+			Returns(200, "OK", nil).
+			Returns(400, "Invalid symbol", nil))
+	}
+
+	// Market data em tempo real via WebSocket; cada conexão consome do
+	// limite de requisições
+	if c.tradingHandler.marketData != nil {
+		ws.Route(ws.GET("/ws/marketdata").To(c.tradingHandler.StreamMarketData).
+			Doc("Real-time depth, top of book and trades (WebSocket)").
+			Metadata(metaPermission, auth.PermMarketRead).
+			Metadata(metaRateLimited, true).
+			ContentEncodingEnabled(false).
+			Returns(101, "Switching Protocols", nil).
+			Returns(403, "Origin not allowed", nil).
+			Returns(429, "Rate limit exceeded", nil))
+	}
+
 	// Rotas de ações
 	ws.Route(ws.GET("/stocks").To(c.tradingHandler.GetStocks).
 		Doc("Get available stocks").
+		Metadata(metaPermission, auth.PermMarketRead).
 		Returns(200, "OK", nil))
 
 	// Rotas de trades
 	ws.Route(ws.GET("/trades").To(c.tradingHandler.GetTrades).
-		Doc("Get trades history").
-		Returns(200, "OK", nil))
+		Doc("Get trades history (JSON or CSV blotter)").
+		Metadata(metaPermission, auth.PermMarketRead).
+		Param(ws.QueryParameter("symbol", "Filter by stock symbol").DataType("string")).
+		Param(ws.QueryParameter("user_id", "Filter by buyer or seller").DataType("string")).
+		Param(ws.QueryParameter("from", "Executed at or after (RFC 3339 or YYYY-MM-DD)").DataType("string")).
+		Param(ws.QueryParameter("to", "Executed before (RFC 3339 or YYYY-MM-DD)").DataType("string")).
+		Param(ws.QueryParameter("min_value", "Minimum trade value").DataType("number")).
+		Param(ws.QueryParameter("sort", "asc (default) or desc by execution").DataType("string")).
+		Param(ws.QueryParameter("limit", "Maximum number of trades (1-1000, default 100)").DataType("integer")).
+		Param(ws.QueryParameter("cursor", "Cursor from X-Next-Cursor of the previous page").DataType("string")).
+		Produces(restful.MIME_JSON, mimeCSV).
+		Returns(200, "OK", nil).
+		Returns(400, "Invalid parameter", nil).
+		Returns(404, "User not found", nil))
+
+	// Snapshots do engine (admin)
+	if c.tradingHandler.snapshots != nil {
+		ws.Route(ws.GET("/admin/snapshots").To(c.tradingHandler.ListSnapshots).
+			Doc("List engine snapshots (admin only)").
+			Metadata(metaPermission, auth.PermAdmin).
+			Returns(200, "OK", nil).
+			Returns(403, "Forbidden", nil))
+
+		ws.Route(ws.POST("/admin/snapshots").To(c.tradingHandler.TakeSnapshot).
+			Doc("Write a snapshot of the engine state (admin only)").
+			Metadata(metaPermission, auth.PermAdmin).
+			Returns(201, "Snapshot written", nil).
+			Returns(403, "Forbidden", nil))
+
+		ws.Route(ws.POST("/admin/snapshots/{seq}/restore").To(c.tradingHandler.RestoreSnapshot).
+			Doc("Reload the engine from a snapshot and the journal tail (admin only)").
+			Metadata(metaPermission, auth.PermAdmin).
+			Param(ws.PathParameter("seq", "Journal sequence of the snapshot").DataType("integer")).
+			Returns(200, "Restored", nil).
+			Returns(403, "Forbidden", nil).
+			Returns(404, "Snapshot not found", nil))
+	}
 
 	// Rotas de estatísticas
 	ws.Route(ws.GET("/stats").To(c.tradingHandler.GetStats).
 		Doc("Get system statistics").
+		Metadata(metaPermission, auth.PermStatsRead).
 		Returns(200, "OK", nil))
 
 	c.webService = ws
 }
 
-// corsFilter implementa CORS middleware
+// setupMetricsService configura GET /metrics, fora de /api, com os mesmos
+// filtros da API
+func (c *InternalWebRestfulContainer) setupMetricsService() {
+	if c.tradingHandler.metrics == nil {
+		return
+	}
+
+	ws := new(restful.WebService)
+	ws.Path("/metrics").Doc("Prometheus metrics")
+	c.filters(ws)
+
+	ws.Route(ws.GET("").To(c.tradingHandler.ServeMetrics).
+		Doc("Metrics in the Prometheus text format").
+		Metadata(metaPermission, auth.PermStatsRead).
+		Metadata(metaRateLimited, true).
+		Produces("text/plain", "*/*").
+		Returns(200, "OK", nil).
+		Returns(403, "Forbidden", nil))
+
+	c.metricsService = ws
+}
+
+// corsFilter implementa CORS middleware. Com origens configuradas, só elas
+// recebem Access-Control-Allow-Origin; sem configuração, qualquer origem.
 func (c *InternalWebRestfulContainer) corsFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-	resp.Header().Set("Access-Control-Allow-Origin", "*")
-	resp.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	resp.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	if origins := c.tradingHandler.origins; len(origins) == 0 {
+		resp.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		resp.Header().Add("Vary", "Origin")
+		if origin := req.HeaderParameter("Origin"); slices.Contains(origins, origin) {
+			resp.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+	}
+	resp.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+	resp.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Admin-Token, X-Auth-Timestamp, Idempotency-Key, Last-Event-ID, X-Request-ID, traceparent")
 
 	if req.Request.Method == "OPTIONS" {
 		resp.WriteHeader(200)
@@ -113,16 +321,47 @@ func (c *InternalWebRestfulContainer) corsFilter(req *restful.Request, resp *res
 	chain.ProcessFilter(req, resp)
 }
 
-// loggingFilter implementa logging middleware
+// SetAllowedOrigins restringe o CORS às origens informadas
+func (h *TradingHandler) SetAllowedOrigins(origins []string) {
+	h.origins = origins
+}
+
+// ParseOrigins lê a lista de origens separadas por vírgula de CORS_ORIGINS
+func ParseOrigins(value string) []string {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// loggingFilter implementa logging middleware e alimenta as métricas HTTP
+// por rota. O X-Request-ID recebido (ou gerado) é devolvido na resposta e
+// segue no contexto da requisição até o matching e o portfolio, assim como
+// o traceparent do cliente, pai dos spans da requisição.
 func (c *InternalWebRestfulContainer) loggingFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
 	start := time.Now()
+	id := requestID(req)
+	resp.Header().Set(HeaderRequestID, id)
+	ctx := logging.WithRequestID(req.Request.Context(), id)
+	if parent := req.HeaderParameter(tracing.HeaderTraceParent); parent != "" {
+		ctx = tracing.WithTraceParent(ctx, parent)
+	}
+	req.Request = req.Request.WithContext(ctx)
+
 	chain.ProcessFilter(req, resp)
 	duration := time.Since(start)
 
-	// Log da requisição
-	log.Printf("📡 %s %s - %d - %v",
-		req.Request.Method,
-		req.Request.URL.Path,
-		resp.StatusCode(),
-		duration)
+	if c.tradingHandler.http != nil {
+		c.tradingHandler.http.Observe(req.Request.Method, req.SelectedRoutePath(), resp.StatusCode(), duration)
+	}
+
+	slog.InfoContext(req.Request.Context(), "requisição HTTP",
+		"method", req.Request.Method,
+		"path", req.Request.URL.Path,
+		"route", req.SelectedRoutePath(),
+		"status", resp.StatusCode(),
+		"duration_ms", float64(duration.Microseconds())/1000)
 }
@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/api"
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/web/auth"
+)
+
+// Idiomas suportados nas mensagens de erro
+const (
+	LangPT = "pt"
+	LangEN = "en"
+
+	defaultLang = LangPT
+)
+
+// HeaderRequestID é o header usado para correlacionar requisições
+const HeaderRequestID = logging.HeaderRequestID
+
+// ErrorResponse é o envelope padrão de erro da API
+type ErrorResponse struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id"`
+}
+
+// Erros da camada web (não pertencem ao domínio)
+var (
+	errInvalidParameter = errors.New("parâmetro inválido")
+	errForbidden        = errors.New("acesso negado")
+)
+
+// webError associa um erro da camada web ao seu código estável
+type webError struct {
+	err  error
+	code string
+}
+
+// webErrors são os erros que não pertencem ao domínio; os demais códigos vêm
+// de domain.ErrorCode
+var webErrors = []webError{
+	{errInvalidParameter, "INVALID_PARAMETER"},
+	{auth.ErrMissingCredentials, "UNAUTHORIZED"},
+	{auth.ErrInvalidCredentials, "INVALID_CREDENTIALS"},
+	{auth.ErrExpiredCredentials, "CREDENTIALS_EXPIRED"},
+	{auth.ErrBodyTooLarge, "PAYLOAD_TOO_LARGE"},
+	{errForbidden, "FORBIDDEN"},
+	{errRateLimited, "RATE_LIMITED"},
+	{api.ErrUnavailable, api.CodeUnavailable},
+}
+
+// errorSpec descreve como um código estável é exposto pela API
+type errorSpec struct {
+	code     string
+	status   int
+	messages map[string]string
+}
+
+// apiErrors associa cada código estável ao status HTTP e às mensagens
+var apiErrors = []errorSpec{
+	// 400 - dados de entrada inválidos ou ordem rejeitada pelas regras
+	{"INVALID_ORDER", http.StatusBadRequest, map[string]string{
+		LangPT: "Ordem inválida",
+		LangEN: "Invalid order",
+	}},
+	{"INVALID_ORDER_SIDE", http.StatusBadRequest, map[string]string{
+		LangPT: "Lado da ordem inválido, use BUY ou SELL",
+		LangEN: "Invalid order side, use BUY or SELL",
+	}},
+	{"INVALID_QUANTITY", http.StatusBadRequest, map[string]string{
+		LangPT: "Quantidade inválida",
+		LangEN: "Invalid quantity",
+	}},
+	{"INVALID_PRICE", http.StatusBadRequest, map[string]string{
+		LangPT: "Preço inválido",
+		LangEN: "Invalid price",
+	}},
+	{"INVALID_SYMBOL", http.StatusBadRequest, map[string]string{
+		LangPT: "Símbolo inválido",
+		LangEN: "Invalid symbol",
+	}},
+	{"INVALID_USER", http.StatusBadRequest, map[string]string{
+		LangPT: "Usuário inválido",
+		LangEN: "Invalid user",
+	}},
+	{"INVALID_CLIENT_ORDER_ID", http.StatusBadRequest, map[string]string{
+		LangPT: "client_order_id inválido: até 64 caracteres ASCII visíveis",
+		LangEN: "Invalid client_order_id: up to 64 visible ASCII characters",
+	}},
+
+	{"INVALID_PARAMETER", http.StatusBadRequest, map[string]string{
+		LangPT: "Parâmetro inválido",
+		LangEN: "Invalid parameter",
+	}},
+
+	// 401 - credenciais ausentes ou inválidas
+	{"UNAUTHORIZED", http.StatusUnauthorized, map[string]string{
+		LangPT: "Autenticação necessária",
+		LangEN: "Authentication required",
+	}},
+	{"INVALID_CREDENTIALS", http.StatusUnauthorized, map[string]string{
+		LangPT: "Credenciais inválidas",
+		LangEN: "Invalid credentials",
+	}},
+	{"CREDENTIALS_EXPIRED", http.StatusUnauthorized, map[string]string{
+		LangPT: "Credenciais expiradas",
+		LangEN: "Credentials expired",
+	}},
+
+	// 403 - operação restrita
+	{"FORBIDDEN", http.StatusForbidden, map[string]string{
+		LangPT: "Acesso negado",
+		LangEN: "Access denied",
+	}},
+
+	// 404 - recurso inexistente
+	{"USER_NOT_FOUND", http.StatusNotFound, map[string]string{
+		LangPT: "Usuário não encontrado",
+		LangEN: "User not found",
+	}},
+	{"ORDER_NOT_FOUND", http.StatusNotFound, map[string]string{
+		LangPT: "Ordem não encontrada",
+		LangEN: "Order not found",
+	}},
+	{"SNAPSHOT_NOT_FOUND", http.StatusNotFound, map[string]string{
+		LangPT: "Snapshot não encontrado",
+		LangEN: "Snapshot not found",
+	}},
+
+	// 409 - conflito com o estado atual da ordem
+	{"ORDER_NOT_OPEN", http.StatusConflict, map[string]string{
+		LangPT: "A ordem não está mais aberta",
+		LangEN: "Order is no longer open",
+	}},
+	{"CLIENT_ORDER_ID_CONFLICT", http.StatusConflict, map[string]string{
+		LangPT: "client_order_id já usado com outros parâmetros",
+		LangEN: "client_order_id already used with different parameters",
+	}},
+
+	// 413 - corpo maior que o aceito
+	{"PAYLOAD_TOO_LARGE", http.StatusRequestEntityTooLarge, map[string]string{
+		LangPT: "Corpo da requisição excede o limite",
+		LangEN: "Request body too large",
+	}},
+
+	// 429 - limite de requisições excedido
+	{"RATE_LIMITED", http.StatusTooManyRequests, map[string]string{
+		LangPT: "Limite de requisições excedido",
+		LangEN: "Rate limit exceeded",
+	}},
+
+	// 422 - requisição bem formada, mas violando regras de negócio
+	{"PRICE_TOO_LOW", http.StatusUnprocessableEntity, map[string]string{
+		LangPT: "Preço abaixo do mínimo permitido para o símbolo",
+		LangEN: "Price is below the minimum allowed for the symbol",
+	}},
+	{"INSUFFICIENT_BALANCE", http.StatusUnprocessableEntity, map[string]string{
+		LangPT: "Saldo insuficiente",
+		LangEN: "Insufficient balance",
+	}},
+	{"INSUFFICIENT_POSITION", http.StatusUnprocessableEntity, map[string]string{
+		LangPT: "Posição insuficiente",
+		LangEN: "Insufficient position",
+	}},
+	{"EXCEEDS_PROFILE_LIMIT", http.StatusUnprocessableEntity, map[string]string{
+		LangPT: "Ordem excede o limite do perfil",
+		LangEN: "Order exceeds the profile limit",
+	}},
+	{"NO_MATCH", http.StatusUnprocessableEntity, map[string]string{
+		LangPT: "Nenhuma correspondência encontrada",
+		LangEN: "No match found",
+	}},
+
+	// 503 - mercado ou engine indisponível
+	{"MARKET_CLOSED", http.StatusServiceUnavailable, map[string]string{
+		LangPT: "Mercado fechado",
+		LangEN: "Market is closed",
+	}},
+	{"ENGINE_UNAVAILABLE", http.StatusServiceUnavailable, map[string]string{
+		LangPT: "Engine de negociação indisponível, tente novamente",
+		LangEN: "Trading engine unavailable, please retry",
+	}},
+}
+
+// internalError é usado para qualquer erro não mapeado
+var internalError = errorSpec{domain.CodeInternal, http.StatusInternalServerError, map[string]string{
+	LangPT: "Erro interno do servidor",
+	LangEN: "Internal server error",
+}}
+
+// routeErrors mapeia erros de roteamento do go-restful
+var routeErrors = map[int]errorSpec{
+	http.StatusBadRequest: {"BAD_REQUEST", http.StatusBadRequest, map[string]string{
+		LangPT: "Requisição inválida",
+		LangEN: "Bad request",
+	}},
+	http.StatusNotFound: {"NOT_FOUND", http.StatusNotFound, map[string]string{
+		LangPT: "Recurso não encontrado",
+		LangEN: "Resource not found",
+	}},
+	http.StatusMethodNotAllowed: {"METHOD_NOT_ALLOWED", http.StatusMethodNotAllowed, map[string]string{
+		LangPT: "Método não permitido",
+		LangEN: "Method not allowed",
+	}},
+	http.StatusNotAcceptable: {"NOT_ACCEPTABLE", http.StatusNotAcceptable, map[string]string{
+		LangPT: "Formato de resposta não suportado",
+		LangEN: "Response format not acceptable",
+	}},
+	http.StatusUnsupportedMediaType: {"UNSUPPORTED_MEDIA_TYPE", http.StatusUnsupportedMediaType, map[string]string{
+		LangPT: "Tipo de conteúdo não suportado",
+		LangEN: "Unsupported media type",
+	}},
+}
+
+// lookupError encontra a especificação de um erro
+func lookupError(err error) errorSpec {
+	code := ErrorCode(err)
+	for _, spec := range apiErrors {
+		if spec.code == code {
+			return spec
+		}
+	}
+	return internalError
+}
+
+// ErrorCode retorna o código estável de um erro de domínio ou da camada web
+func ErrorCode(err error) string {
+	for _, w := range webErrors {
+		if errors.Is(err, w.err) {
+			return w.code
+		}
+	}
+	return domain.ErrorCode(err)
+}
+
+// ErrorStatus retorna o status HTTP associado a um erro de domínio
+func ErrorStatus(err error) int {
+	return lookupError(err).status
+}
+
+// NewErrorResponse monta o envelope de erro no idioma informado
+func NewErrorResponse(err error, lang, requestID string) (int, *ErrorResponse) {
+	spec := lookupError(err)
+	return spec.status, spec.response(lang, requestID, nil)
+}
+
+// response monta o envelope a partir da especificação
+func (s errorSpec) response(lang, requestID string, details interface{}) *ErrorResponse {
+	message, ok := s.messages[lang]
+	if !ok {
+		message = s.messages[defaultLang]
+	}
+	return &ErrorResponse{
+		Code:      s.code,
+		Message:   message,
+		Details:   details,
+		RequestID: requestID,
+	}
+}
+
+// writeError escreve o envelope de erro correspondente a err
+func writeError(req *restful.Request, resp *restful.Response, err error, details interface{}) {
+	writeErrorSpec(req, resp, lookupError(err), details)
+}
+
+// writeErrorSpec escreve o envelope com idioma e request ID resolvidos
+func writeErrorSpec(req *restful.Request, resp *restful.Response, spec errorSpec, details interface{}) {
+	lang := negotiateLanguage(req.HeaderParameter("Accept-Language"))
+	requestID := requestID(req)
+
+	resp.Header().Set("Content-Language", lang)
+	resp.Header().Set(HeaderRequestID, requestID)
+	_ = resp.WriteHeaderAndJson(spec.status, spec.response(lang, requestID, details), restful.MIME_JSON)
+}
+
+// ServiceErrorHandler converte erros de roteamento no envelope padrão
+func ServiceErrorHandler(serviceErr restful.ServiceError, req *restful.Request, resp *restful.Response) {
+	spec, ok := routeErrors[serviceErr.Code]
+	if !ok {
+		spec = internalError
+		spec.status = serviceErr.Code
+	}
+	writeErrorSpec(req, resp, spec, nil)
+}
+
+// requestID retorna o ID da requisição, gerando um novo se ausente
+func requestID(req *restful.Request) string {
+	if id, ok := req.Attribute(HeaderRequestID).(string); ok && id != "" {
+		return id
+	}
+	id := req.HeaderParameter(HeaderRequestID)
+	if id == "" {
+		id = logging.NewRequestID()
+	}
+	req.SetAttribute(HeaderRequestID, id)
+	return id
+}
+
+// negotiateLanguage escolhe o idioma a partir do header Accept-Language
+func negotiateLanguage(header string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = value
+				}
+			}
+		}
+
+		primary := strings.SplitN(tag, "-", 2)[0]
+		if (primary == LangPT || primary == LangEN) && q > 0 {
+			candidates = append(candidates, candidate{primary, q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return defaultLang
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].lang
+}
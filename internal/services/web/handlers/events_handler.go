@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"trading/internal/services/web/stream"
+)
+
+// sseHeartbeat é o intervalo dos comentários que mantêm a conexão SSE viva
+const sseHeartbeat = 15 * time.Second
+
+// UserEventSource fornece o stream de eventos de um usuário
+type UserEventSource interface {
+	Subscribe(userID string, lastEventID uint64, resume bool) *stream.Subscription
+}
+
+// SetUserEvents habilita o stream SSE de eventos por usuário
+func (h *TradingHandler) SetUserEvents(events UserEventSource) {
+	h.events = events
+}
+
+// StreamUserEvents envia via Server-Sent Events as ordens, negociações e
+// alterações de portfolio do usuário, retomando a partir do Last-Event-ID
+func (h *TradingHandler) StreamUserEvents(req *restful.Request, resp *restful.Response) {
+	userID := req.PathParameter("user_id")
+	if _, err := h.portfolios.GetUser(userID); err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+
+	lastEventID, resume := uint64(0), false
+	if value := req.HeaderParameter("Last-Event-ID"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			writeError(req, resp, errInvalidParameter, "Last-Event-ID deve ser numérico")
+			return
+		}
+		lastEventID, resume = parsed, true
+	}
+
+	sub := h.events.Subscribe(userID, lastEventID, resume)
+	defer sub.Close()
+
+	header := resp.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(http.StatusOK)
+
+	if sub.Reset {
+		fmt.Fprint(resp, "event: reset\ndata: {}\n\n")
+	}
+	for _, event := range sub.Replay {
+		if err := writeSSE(resp, event); err != nil {
+			return
+		}
+	}
+	resp.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Inscrito ficou para trás; o cliente reconecta com Last-Event-ID
+				return
+			}
+			if err := writeSSE(resp, event); err != nil {
+				return
+			}
+			resp.Flush()
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(resp, ": ping\n\n"); err != nil {
+				return
+			}
+			resp.Flush()
+
+		case <-req.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// writeSSE escreve um evento no formato text/event-stream
+func writeSSE(resp *restful.Response, event stream.UserEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(resp, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
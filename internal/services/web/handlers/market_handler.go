@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"trading/internal/domain"
+	"trading/internal/services/shared/marketdata"
+)
+
+// CandleSource fornece as barras OHLCV agregadas das negociações
+type CandleSource interface {
+	Candles(symbol string, interval marketdata.Interval, from, to time.Time) []marketdata.Candle
+}
+
+// TickerSource fornece o ticker de cada símbolo e os últimos preços usados
+// na avaliação dos portfolios
+type TickerSource interface {
+	Ticker(symbol string) (marketdata.Ticker, bool)
+	Prices() map[string]float64
+}
+
+// defaultCandleInterval é o intervalo usado sem ?interval=
+const defaultCandleInterval = "1m"
+
+// SetCandles habilita o endpoint de barras OHLCV
+func (h *TradingHandler) SetCandles(candles CandleSource) {
+	h.candles = candles
+}
+
+// SetTickers habilita o endpoint de ticker e a avaliação dos portfolios pelo
+// último preço negociado
+func (h *TradingHandler) SetTickers(tickers TickerSource) {
+	h.tickers = tickers
+}
+
+// SetMarketData habilita o WebSocket de market data em /api/ws/marketdata
+func (h *TradingHandler) SetMarketData(hub http.Handler) {
+	h.marketData = hub
+}
+
+// StreamMarketData faz o upgrade da conexão para o WebSocket de market data
+func (h *TradingHandler) StreamMarketData(req *restful.Request, resp *restful.Response) {
+	h.marketData.ServeHTTP(resp.ResponseWriter, req.Request)
+}
+
+// GetTicker retorna último preço, variação, estatísticas do pregão e melhor
+// bid/ask de um símbolo
+func (h *TradingHandler) GetTicker(req *restful.Request, resp *restful.Response) {
+	ticker, ok := h.tickers.Ticker(strings.ToUpper(req.PathParameter("symbol")))
+	if !ok {
+		writeError(req, resp, domain.ErrInvalidSymbol, nil)
+		return
+	}
+	writeJSON(resp, http.StatusOK, ticker)
+}
+
+// GetCandles retorna as barras OHLCV de um símbolo no ?interval= pedido
+// (1s, 1m, 5m, 1h ou 1d), com início entre ?from= e ?to=
+func (h *TradingHandler) GetCandles(req *restful.Request, resp *restful.Response) {
+	symbol := strings.ToUpper(req.PathParameter("symbol"))
+	if err := h.validator.ValidateSymbol(symbol); err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+
+	name := req.QueryParameter("interval")
+	if name == "" {
+		name = defaultCandleInterval
+	}
+	interval, err := marketdata.ParseInterval(name)
+	if err != nil {
+		writeError(req, resp, errInvalidParameter, "interval deve ser 1s, 1m, 5m, 1h ou 1d")
+		return
+	}
+
+	from, err := parseTimeParameter(req.QueryParameter("from"))
+	if err != nil {
+		writeError(req, resp, errInvalidParameter, "from deve estar no formato RFC 3339 ou AAAA-MM-DD")
+		return
+	}
+	to, err := parseTimeParameter(req.QueryParameter("to"))
+	if err != nil {
+		writeError(req, resp, errInvalidParameter, "to deve estar no formato RFC 3339 ou AAAA-MM-DD")
+		return
+	}
+
+	writeJSON(resp, http.StatusOK, h.candles.Candles(symbol, interval, from, to))
+}
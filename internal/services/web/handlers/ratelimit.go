@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"trading/internal/services/web/ratelimit"
+)
+
+// Headers de limite de requisições, presentes em toda resposta das rotas
+// limitadas
+const (
+	HeaderRateLimit     = "X-RateLimit-Limit"
+	HeaderRateRemaining = "X-RateLimit-Remaining"
+	HeaderRateReset     = "X-RateLimit-Reset"
+)
+
+// metaRateLimited marca as rotas sujeitas ao limite: entrada de ordens,
+// conexões de market data e /metrics
+const metaRateLimited = "rate_limited"
+
+// errRateLimited indica que o balde do usuário ou do IP está vazio
+var errRateLimited = errors.New("limite de requisições excedido")
+
+// RateLimiter consome tokens de vários baldes por chave, todos ou nenhum
+type RateLimiter interface {
+	AllowAll(requests ...ratelimit.Request) []ratelimit.Decision
+}
+
+// rateLimits guarda o limitador, os limites e o perfil já resolvido de cada
+// usuário (perfis não mudam com o processo em execução)
+type rateLimits struct {
+	limiter  RateLimiter
+	config   ratelimit.Config
+	profiles sync.Map // userID -> perfil
+}
+
+// SetRateLimiter limita a entrada de ordens por IP e, com autenticação, pelo
+// usuário autenticado conforme o perfil
+func (h *TradingHandler) SetRateLimiter(limiter RateLimiter, cfg ratelimit.Config) {
+	h.limits = &rateLimits{limiter: limiter, config: cfg}
+}
+
+// profile retorna o perfil do usuário (vazio se desconhecido)
+func (h *TradingHandler) profile(userID string) string {
+	if profile, cached := h.limits.profiles.Load(userID); cached {
+		return profile.(string)
+	}
+	user, err := h.portfolios.GetUser(userID)
+	if err != nil {
+		return ""
+	}
+	h.limits.profiles.Store(userID, user.Profile)
+	return user.Profile
+}
+
+// clientIP extrai o endereço do cliente da conexão
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimitFilter consome um token do balde do IP e do usuário autenticado,
+// só se ambos tiverem token. Para usuários autenticados o IP vale o maior
+// entre o seu limite e o do perfil. Os headers refletem o balde mais
+// restrito; com um balde vazio a resposta é 429 com Retry-After.
+func (c *InternalWebRestfulContainer) rateLimitFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	h := c.tradingHandler
+	if h.limits == nil || req.SelectedRoute() == nil || req.SelectedRoute().Metadata()[metaRateLimited] != true {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+
+	scopes := []string{"IP"}
+	requests := []ratelimit.Request{{Key: "ip:" + clientIP(req.Request), Limit: h.limits.config.IP}}
+	if p := principal(req); p != nil {
+		user := h.limits.config.ForProfile(h.profile(p.UserID))
+		requests[0].Limit = h.limits.config.ForIP(user)
+		scopes = append(scopes, "usuário")
+		requests = append(requests, ratelimit.Request{Key: "user:" + p.UserID, Limit: user})
+	}
+
+	var tightest *ratelimit.Decision
+	for i, decision := range h.limits.limiter.AllowAll(requests...) {
+		limit := requests[i].Limit
+		if limit.Unlimited() {
+			continue
+		}
+		if !decision.Allowed {
+			setRateHeaders(resp, decision)
+			resp.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+			writeError(req, resp, errRateLimited, fmt.Sprintf("limite por %s excedido (%g/s, rajada %d)", scopes[i], limit.Rate, limit.Burst))
+			return
+		}
+		if tightest == nil || decision.Remaining < tightest.Remaining {
+			decision := decision
+			tightest = &decision
+		}
+	}
+	if tightest != nil {
+		setRateHeaders(resp, *tightest)
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// setRateHeaders escreve rajada, tokens restantes e segundos até o balde encher
+func setRateHeaders(resp *restful.Response, decision ratelimit.Decision) {
+	resp.Header().Set(HeaderRateLimit, strconv.Itoa(decision.Limit))
+	resp.Header().Set(HeaderRateRemaining, strconv.Itoa(decision.Remaining))
+	resp.Header().Set(HeaderRateReset, strconv.Itoa(ceilSeconds(decision.Reset)))
+}
+
+// ceilSeconds arredonda a duração para cima em segundos inteiros
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -1,52 +1,807 @@
 package handlers
 
-import "github.com/emicklei/go-restful/v3"
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/api"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/engine/portfolio"
+	"trading/internal/services/engine/repository"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/stats"
+	"trading/internal/services/shared/tracing"
+	"trading/internal/services/shared/validators"
+	"trading/internal/services/web/auth"
+)
+
+// OrderProcessor envia ordens ao matching engine
+type OrderProcessor interface {
+	ProcessOrder(ctx context.Context, order *domain.Order) *matching.MatchResult
+	Recall(ctx context.Context, order *domain.Order) (*matching.MatchResult, bool)
+	CancelOrder(ctx context.Context, orderID string) (*domain.Order, error)
+	AmendOrder(ctx context.Context, orderID string, quantity int, price float64) (*matching.MatchResult, error)
+	GetTrades() []*domain.Trade
+	ListTrades(filter repository.TradeFilter) ([]*domain.Trade, error)
+	GetOrder(orderID string) (*domain.Order, error)
+	ListOrders(filter repository.OrderFilter) ([]*domain.Order, error)
+	Stats() matching.Stats
+}
+
+// OrderBookProvider fornece livros de ofertas
+type OrderBookProvider interface {
+	GetOrderBook(symbol string) *orderbook.OrderBook
+	GetDepth(symbol string, depth int) *orderbook.Depth
+	FindOrder(orderID string) *domain.Order
+}
+
+// PortfolioProvider fornece usuários e portfolios
+type PortfolioProvider interface {
+	GetPortfolio(userID string) (*domain.Portfolio, error)
+	GetUser(userID string) (portfolio.User, error)
+	ValidateOrder(ctx context.Context, order *domain.Order) error
+}
+
+// OrderValidator aplica as regras de negócio de ações e mercado
+type OrderValidator interface {
+	ValidateOrder(order *domain.Order) error
+	ValidateSymbol(symbol string) error
+	GetStocks() []validators.Stock
+	GetMarketStatus() validators.MarketStatus
+}
+
+// EventBusStats fornece as métricas de backpressure do barramento de eventos
+type EventBusStats interface {
+	Stats() events.Stats
+}
+
+// HTTPObserver registra a duração e o status de cada requisição por rota
+type HTTPObserver interface {
+	Observe(method, route string, status int, elapsed time.Duration)
+}
 
 // TradingHandler gerencia endpoints do sistema de trading
 type TradingHandler struct {
+	matcher    OrderProcessor
+	books      OrderBookProvider
+	portfolios PortfolioProvider
+	validator  OrderValidator
+	events     UserEventSource
+	bus        EventBusStats
+	snapshots  SnapshotService
+	candles    CandleSource
+	tickers    TickerSource
+	marketData http.Handler
+	metrics    http.Handler
+	http       HTTPObserver
+	auth       Authenticator
+	admin      Authenticator
+	audit      *slog.Logger
+	limits     *rateLimits
+	origins    []string
+	startedAt  time.Time
+
+	// rejections conta as ordens novas rejeitadas antes do engine
+	rejections *stats.ErrorCounter
+}
+
+// entryRejections são os motivos de rejeição contados na entrada de ordens
+var entryRejections = []error{
+	domain.ErrInvalidOrder, domain.ErrInvalidOrderSide, domain.ErrInvalidQuantity, domain.ErrInvalidPrice,
+	domain.ErrInvalidSymbol, domain.ErrInvalidUser, domain.ErrInvalidClientOrderID, domain.ErrUserNotFound,
+	domain.ErrPriceTooLow, domain.ErrMarketClosed,
+}
+
+// Limites de profundidade do livro agregado
+const (
+	defaultBookDepth = 10
+	maxBookDepth     = 100
+)
+
+// Limites do histórico de ordens
+const (
+	defaultOrderHistory = 100
+	maxOrderHistory     = 1000
+)
+
+// Limites do histórico de negociações
+const (
+	defaultTradeHistory = 100
+	maxTradeHistory     = 1000
+)
+
+// HeaderNextCursor traz o cursor da próxima página de GET /trades (ausente na
+// última página)
+const HeaderNextCursor = "X-Next-Cursor"
+
+// mimeCSV é o formato do blotter de negociações
+const mimeCSV = "text/csv"
+
+// HeaderIdempotencyKey é a alternativa ao client_order_id do corpo
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderIdempotentReplayed marca a resposta com o resultado original de um
+// client_order_id repetido
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+// CreateOrderRequest representa o corpo de POST /orders
+type CreateOrderRequest struct {
+	UserID        string           `json:"user_id"`
+	Symbol        string           `json:"symbol"`
+	Side          domain.OrderSide `json:"side"`
+	Quantity      int              `json:"quantity"`
+	Price         float64          `json:"price"`
+	ClientOrderID string           `json:"client_order_id,omitempty"`
+}
+
+// PortfolioValuation é o portfolio avaliado pelos últimos preços negociados.
+// Posições de símbolos ainda sem negócios ficam fora de Prices e do valor.
+type PortfolioValuation struct {
+	UserID      string             `json:"user_id"`
+	Cash        float64            `json:"cash"`
+	Positions   map[string]int     `json:"positions"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Prices      map[string]float64 `json:"prices"`
+	MarketValue float64            `json:"market_value"`
+	TotalValue  float64            `json:"total_value"`
+}
+
+// Stats é a resposta de GET /stats. Rejeições são indexadas pelo código
+// estável do erro e somam as validações da web e do engine.
+type Stats struct {
+	StartedAt     time.Time      `json:"started_at"`
+	UptimeSeconds int64          `json:"uptime_seconds"`
+	Orders        OrderStats     `json:"orders"`
+	Trades        TradeStats     `json:"trades"`
+	MatchLatency  LatencyStats   `json:"match_latency"`
+	RestingOrders map[string]int `json:"resting_orders"`
+	ActiveUsers   int            `json:"active_users"`
+	// PersistenceErrors conta as gravações que falharam no repositório do engine
+	PersistenceErrors uint64        `json:"persistence_errors"`
+	EventBus          *events.Stats `json:"event_bus,omitempty"`
+}
+
+// OrderStats conta as ordens novas recebidas, aceitas e rejeitadas
+type OrderStats struct {
+	Received         uint64            `json:"received"`
+	Accepted         uint64            `json:"accepted"`
+	Rejected         uint64            `json:"rejected"`
+	RejectedByReason map[string]uint64 `json:"rejected_by_reason"`
+}
+
+// TradeStats soma as negociações e o notional, no total e por símbolo
+type TradeStats struct {
+	Total    uint64                 `json:"total"`
+	Notional float64                `json:"notional"`
+	BySymbol map[string]stats.Total `json:"by_symbol"`
+}
+
+// LatencyStats resume o tempo de processamento de ordens novas no engine
+type LatencyStats struct {
+	Count uint64  `json:"count"`
+	AvgMs float64 `json:"avg_ms"`
+	P99Ms float64 `json:"p99_ms"`
+}
+
+// AmendOrderRequest representa o corpo de PATCH /orders/{order_id}
+type AmendOrderRequest struct {
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+// NewTradingHandler cria um handler com as dependências do engine
+func NewTradingHandler(matcher OrderProcessor, books OrderBookProvider, portfolios PortfolioProvider, validator OrderValidator) *TradingHandler {
+	return &TradingHandler{
+		matcher:    matcher,
+		books:      books,
+		portfolios: portfolios,
+		validator:  validator,
+		startedAt:  time.Now().UTC(),
+		rejections: stats.NewErrorCounter(entryRejections...),
+	}
 }
 
 // CreateOrder cria uma nova ordem de compra ou venda
 func (h *TradingHandler) CreateOrder(req *restful.Request, resp *restful.Response) {
-	_, _ = resp.Write([]byte("OK - CreateOrder"))
+	ctx, span := tracing.Start(req.Request.Context(), "TradingHandler.CreateOrder")
+	defer span.End()
+
+	var body CreateOrderRequest
+	if err := req.ReadEntity(&body); err != nil {
+		writeError(req, resp, domain.ErrInvalidOrder, err.Error())
+		return
+	}
+	if !h.owns(req, body.UserID) {
+		h.forbid(req, resp, auth.PermUsersWrite, detailUserMismatch)
+		return
+	}
+	if key := req.HeaderParameter(HeaderIdempotencyKey); key != "" {
+		if body.ClientOrderID != "" && body.ClientOrderID != key {
+			writeError(req, resp, errInvalidParameter, "Idempotency-Key difere de client_order_id")
+			return
+		}
+		body.ClientOrderID = key
+	}
+
+	side := domain.OrderSide(strings.ToUpper(string(body.Side)))
+	order := domain.NewOrder(body.UserID, strings.ToUpper(body.Symbol), side, body.Quantity, body.Price)
+	order.ClientOrderID = body.ClientOrderID
+	span.SetAttributes(tracing.OrderAttrs(order)...)
+
+	// Dados de entrada inválidos: 400 com o envelope de erro
+	if err := order.Validate(); err != nil {
+		span.RecordError(err)
+		h.rejectEntry(err)
+		writeError(req, resp, err, nil)
+		return
+	}
+
+	// Reenvio: o resultado original, antes das regras (a reserva da própria
+	// ordem original poderia reprovar a cópia)
+	if previous, ok := h.matcher.Recall(ctx, order); ok {
+		h.writeMatchResult(req, resp, previous)
+		return
+	}
+
+	// Regras de negócio: 400 com a ordem rejeitada
+	if err := h.validateOrder(ctx, order); err != nil {
+		span.RecordError(err)
+		h.writeMatchResult(req, resp, matching.Reject(order, err))
+		return
+	}
+
+	result := h.matcher.ProcessOrder(ctx, order)
+	span.RecordError(result.Err)
+	h.writeMatchResult(req, resp, result)
+}
+
+// CancelOrder cancela uma ordem aberta
+func (h *TradingHandler) CancelOrder(req *restful.Request, resp *restful.Response) {
+	if h.auth != nil {
+		if current, err := h.matcher.GetOrder(req.PathParameter("order_id")); err == nil && !h.owns(req, current.UserID) {
+			h.forbid(req, resp, auth.PermUsersWrite, detailOrderOwner)
+			return
+		}
+	}
+
+	order, err := h.matcher.CancelOrder(req.Request.Context(), req.PathParameter("order_id"))
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+
+	writeJSON(resp, http.StatusOK, order)
+}
+
+// GetOrder retorna o último estado conhecido de uma ordem
+func (h *TradingHandler) GetOrder(req *restful.Request, resp *restful.Response) {
+	order, err := h.matcher.GetOrder(req.PathParameter("order_id"))
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	if !h.owns(req, order.UserID) {
+		h.forbid(req, resp, auth.PermUsersRead, detailOrderOwner)
+		return
+	}
+	writeJSON(resp, http.StatusOK, order)
+}
+
+// GetClientOrder retorna o último estado da ordem do usuário com o
+// client_order_id
+func (h *TradingHandler) GetClientOrder(req *restful.Request, resp *restful.Response) {
+	order, err := h.findClientOrder(req)
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	writeJSON(resp, http.StatusOK, order)
+}
+
+// CancelClientOrder cancela a ordem aberta do usuário com o client_order_id
+func (h *TradingHandler) CancelClientOrder(req *restful.Request, resp *restful.Response) {
+	current, err := h.findClientOrder(req)
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+
+	order, err := h.matcher.CancelOrder(req.Request.Context(), current.ID)
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	writeJSON(resp, http.StatusOK, order)
+}
+
+// findClientOrder busca a ordem mais recente do {user_id} com o
+// {client_order_id} (um mesmo valor pode voltar a ser usado fora da janela)
+func (h *TradingHandler) findClientOrder(req *restful.Request) (*domain.Order, error) {
+	orders, err := h.matcher.ListOrders(repository.OrderFilter{
+		UserID:        req.PathParameter("user_id"),
+		ClientOrderID: req.PathParameter("client_order_id"),
+		Limit:         1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, domain.ErrOrderNotFound
+	}
+	return orders[0], nil
+}
+
+// ListUserOrders retorna o histórico de ordens de um usuário, filtrado
+// opcionalmente por ?status= e ?symbol=
+func (h *TradingHandler) ListUserOrders(req *restful.Request, resp *restful.Response) {
+	userID := req.PathParameter("user_id")
+	if _, err := h.portfolios.GetUser(userID); err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+
+	filter := repository.OrderFilter{
+		UserID: userID,
+		Symbol: strings.ToUpper(req.QueryParameter("symbol")),
+		Status: domain.OrderStatus(strings.ToUpper(req.QueryParameter("status"))),
+		Limit:  defaultOrderHistory,
+	}
+	if value := req.QueryParameter("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxOrderHistory {
+			writeError(req, resp, errInvalidParameter, fmt.Sprintf("limit deve estar entre 1 e %d", maxOrderHistory))
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	orders, err := h.matcher.ListOrders(filter)
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	writeJSON(resp, http.StatusOK, orders)
 }
 
-// GetOrderBook retorna o livro de ofertas de um símbolo
+// AmendOrder altera quantidade e preço de uma ordem aberta
+func (h *TradingHandler) AmendOrder(req *restful.Request, resp *restful.Response) {
+	var body AmendOrderRequest
+	if err := req.ReadEntity(&body); err != nil {
+		writeError(req, resp, domain.ErrInvalidOrder, err.Error())
+		return
+	}
+
+	current := h.books.FindOrder(req.PathParameter("order_id"))
+	if current == nil {
+		writeError(req, resp, domain.ErrOrderNotFound, nil)
+		return
+	}
+	if !h.owns(req, current.UserID) {
+		h.forbid(req, resp, auth.PermUsersWrite, detailOrderOwner)
+		return
+	}
+
+	// Os novos parâmetros passam pelas mesmas regras de símbolo e mercado;
+	// saldo e posição são verificados pelo engine ao trocar a reserva
+	amended := current.Clone()
+	amended.Quantity = body.Quantity
+	amended.Price = body.Price
+	if err := amended.Validate(); err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	if err := validateRules(req.Request.Context(), h.validator, amended); err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+
+	result, err := h.matcher.AmendOrder(req.Request.Context(), current.ID, body.Quantity, body.Price)
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+
+	writeJSON(resp, http.StatusOK, result)
+}
+
+// validateOrder aplica o pipeline de validações do README antes do matching
+func (h *TradingHandler) validateOrder(ctx context.Context, order *domain.Order) error {
+	// 1. Usuário existe
+	if _, err := h.portfolios.GetUser(order.UserID); err != nil {
+		h.rejectEntry(err)
+		return err
+	}
+
+	// 2. Símbolo, preço mínimo e horário de mercado
+	if err := validateRules(ctx, h.validator, order); err != nil {
+		h.rejectEntry(err)
+		return err
+	}
+
+	// 3. Saldo/posição e limites do perfil; rejeições contadas pelo engine
+	return h.portfolios.ValidateOrder(ctx, order)
+}
+
+// rejectEntry conta uma ordem nova rejeitada na entrada, antes de chegar ao
+// engine; sem resposta do engine a ordem não foi avaliada e não é contada
+func (h *TradingHandler) rejectEntry(err error) {
+	if !errors.Is(err, api.ErrUnavailable) {
+		h.rejections.Inc(err)
+	}
+}
+
+// validateRules aplica as regras de ações e mercado medindo-as em um span
+func validateRules(ctx context.Context, validator OrderValidator, order *domain.Order) error {
+	_, span := tracing.Start(ctx, "BusinessValidator.ValidateOrder", tracing.OrderAttrs(order)...)
+	defer span.End()
+
+	err := validator.ValidateOrder(order)
+	span.RecordError(err)
+	return err
+}
+
+// writeMatchResult responde 201 para ordens aceitas e 400 para rejeitadas;
+// o resultado original de um client_order_id repetido leva o header
+// Idempotent-Replayed
+func (h *TradingHandler) writeMatchResult(req *restful.Request, resp *restful.Response, result *matching.MatchResult) {
+	if result.Replayed {
+		resp.Header().Set(HeaderIdempotentReplayed, "true")
+	}
+	if !result.Rejected {
+		writeJSON(resp, http.StatusCreated, result)
+		return
+	}
+
+	// Sem resposta do engine ou com client_order_id em conflito a ordem não
+	// foi rejeitada pelas regras: 503 ou 409
+	if errors.Is(result.Err, api.ErrUnavailable) || errors.Is(result.Err, domain.ErrClientOrderIDConflict) {
+		writeError(req, resp, result.Err, nil)
+		return
+	}
+
+	// Troca a mensagem interna pelo código estável e mensagem localizada
+	lang := negotiateLanguage(req.HeaderParameter("Accept-Language"))
+	spec := lookupError(result.Err)
+	result.Reason = spec.code
+	result.Message = spec.response(lang, "", nil).Message
+
+	resp.Header().Set("Content-Language", lang)
+	resp.Header().Set(HeaderRequestID, requestID(req))
+	writeJSON(resp, http.StatusBadRequest, result)
+}
+
+// SetHTTPMetrics passa a medir as requisições no filtro de logging
+func (h *TradingHandler) SetHTTPMetrics(observer HTTPObserver) {
+	h.http = observer
+}
+
+// SetMetrics habilita GET /metrics, com a permissão stats:read
+func (h *TradingHandler) SetMetrics(metrics http.Handler) {
+	h.metrics = metrics
+}
+
+// ServeMetrics expõe as métricas no formato de texto do Prometheus
+func (h *TradingHandler) ServeMetrics(req *restful.Request, resp *restful.Response) {
+	h.metrics.ServeHTTP(resp.ResponseWriter, req.Request)
+}
+
+// SetEventBus expõe as métricas do barramento em /stats
+func (h *TradingHandler) SetEventBus(bus EventBusStats) {
+	h.bus = bus
+}
+
+// GetOrderBook retorna o livro de ofertas de um símbolo. Por padrão responde
+// a visão agregada por nível (L2); ?level=3 retorna as ordens individuais e
+// exige a permissão book:l3 (papéis risk e admin).
 func (h *TradingHandler) GetOrderBook(req *restful.Request, resp *restful.Response) {
-	_, _ = resp.Write([]byte("OK - GetOrderBook"))
+	symbol := strings.ToUpper(req.PathParameter("symbol"))
+
+	switch req.QueryParameter("level") {
+	case "", "2":
+	case "3":
+		if !h.can(req, auth.PermBookL3) {
+			h.forbid(req, resp, auth.PermBookL3, "level=3 requer a permissão book:l3")
+			return
+		}
+		writeJSON(resp, http.StatusOK, h.books.GetOrderBook(symbol))
+		return
+	default:
+		writeError(req, resp, errInvalidParameter, "level deve ser 2 ou 3")
+		return
+	}
+
+	depth := defaultBookDepth
+	if value := req.QueryParameter("depth"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxBookDepth {
+			writeError(req, resp, errInvalidParameter, fmt.Sprintf("depth deve estar entre 1 e %d", maxBookDepth))
+			return
+		}
+		depth = parsed
+	}
+
+	writeJSON(resp, http.StatusOK, h.books.GetDepth(symbol, depth))
 }
 
-// GetPortfolio retorna o portfolio de um usuário
+// GetPortfolio retorna o portfolio de um usuário, avaliado pelos últimos
+// preços negociados quando há tickers
 func (h *TradingHandler) GetPortfolio(req *restful.Request, resp *restful.Response) {
-	_, _ = resp.Write([]byte("OK - GetPortfolio"))
+	portfolio, err := h.portfolios.GetPortfolio(req.PathParameter("user_id"))
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	snapshot := portfolio.Clone()
+	if h.tickers == nil {
+		writeJSON(resp, http.StatusOK, snapshot)
+		return
+	}
+
+	prices := h.tickers.Prices()
+	valuation := PortfolioValuation{
+		UserID:     snapshot.UserID,
+		Cash:       snapshot.Cash,
+		Positions:  snapshot.Positions,
+		UpdatedAt:  snapshot.UpdatedAt,
+		Prices:     make(map[string]float64, len(snapshot.Positions)),
+		TotalValue: snapshot.GetTotalValue(prices),
+	}
+	for symbol := range snapshot.Positions {
+		if price, exists := prices[symbol]; exists {
+			valuation.Prices[symbol] = price
+		}
+	}
+	valuation.MarketValue = valuation.TotalValue - valuation.Cash
+	writeJSON(resp, http.StatusOK, valuation)
 }
 
 // GetUserProfile retorna perfil e dados de um usuário
 func (h *TradingHandler) GetUserProfile(req *restful.Request, resp *restful.Response) {
-	_, _ = resp.Write([]byte("OK - GetUserProfile"))
+	user, err := h.portfolios.GetUser(req.PathParameter("user_id"))
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	writeJSON(resp, http.StatusOK, user)
 }
 
 // GetMarketStatus retorna status do mercado (aberto/fechado)
 func (h *TradingHandler) GetMarketStatus(req *restful.Request, resp *restful.Response) {
-	_, _ = resp.Write([]byte("OK - GetMarketStatus"))
+	writeJSON(resp, http.StatusOK, h.validator.GetMarketStatus())
 }
 
 // GetStocks retorna lista de ações disponíveis
 func (h *TradingHandler) GetStocks(req *restful.Request, resp *restful.Response) {
-	_, _ = resp.Write([]byte("OK - GetStocks"))
+	writeJSON(resp, http.StatusOK, h.validator.GetStocks())
 }
 
-// GetTrades retorna histórico de negociações
+// GetTrades retorna o histórico de negociações filtrado por ?symbol=,
+// ?user_id= (comprador ou vendedor), ?from=/?to= e ?min_value=, em ordem de
+// execução (?sort=desc para a inversa). A página tem até ?limit= negociações
+// e o cursor da seguinte vai em X-Next-Cursor; com Accept: text/csv a
+// resposta é o blotter em CSV.
 func (h *TradingHandler) GetTrades(req *restful.Request, resp *restful.Response) {
-	_, _ = resp.Write([]byte("OK - GetTrades"))
+	filter, ok := h.tradeFilter(req, resp)
+	if !ok {
+		return
+	}
+
+	// Compradores, vendedores e ordens de outros usuários exigem users:read;
+	// sem ?user_id=, os demais principais veem só as próprias negociações
+	if p := principal(req); filter.UserID == "" && p != nil && !h.can(req, auth.PermUsersRead) {
+		filter.UserID = p.UserID
+	}
+
+	// Uma negociação a mais indica se há próxima página
+	limit := filter.Limit
+	filter.Limit++
+	trades, err := h.matcher.ListTrades(filter)
+	if err != nil {
+		writeError(req, resp, err, nil)
+		return
+	}
+	if len(trades) > limit {
+		trades = trades[:limit]
+		resp.Header().Set(HeaderNextCursor, encodeCursor(trades[limit-1].ID))
+	}
+
+	if strings.Contains(req.HeaderParameter("Accept"), mimeCSV) {
+		writeTradesCSV(resp, trades)
+		return
+	}
+	writeJSON(resp, http.StatusOK, trades)
+}
+
+// tradeFilter lê e valida os parâmetros de GET /trades; em caso de erro
+// responde e retorna false
+func (h *TradingHandler) tradeFilter(req *restful.Request, resp *restful.Response) (repository.TradeFilter, bool) {
+	filter := repository.TradeFilter{
+		Symbol: strings.ToUpper(req.QueryParameter("symbol")),
+		UserID: req.QueryParameter("user_id"),
+		Limit:  defaultTradeHistory,
+	}
+	invalid := func(details string) (repository.TradeFilter, bool) {
+		writeError(req, resp, errInvalidParameter, details)
+		return filter, false
+	}
+
+	if filter.UserID != "" {
+		if _, err := h.portfolios.GetUser(filter.UserID); err != nil {
+			writeError(req, resp, err, nil)
+			return filter, false
+		}
+	}
+
+	var err error
+	if filter.From, err = parseTimeParameter(req.QueryParameter("from")); err != nil {
+		return invalid("from deve estar no formato RFC 3339 ou AAAA-MM-DD")
+	}
+	if filter.To, err = parseTimeParameter(req.QueryParameter("to")); err != nil {
+		return invalid("to deve estar no formato RFC 3339 ou AAAA-MM-DD")
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return invalid("from deve ser anterior a to")
+	}
+
+	if value := req.QueryParameter("min_value"); value != "" {
+		if filter.MinValue, err = strconv.ParseFloat(value, 64); err != nil || filter.MinValue < 0 {
+			return invalid("min_value deve ser um número não negativo")
+		}
+	}
+
+	switch req.QueryParameter("sort") {
+	case "", "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		return invalid("sort deve ser asc ou desc")
+	}
+
+	if value := req.QueryParameter("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxTradeHistory {
+			return invalid(fmt.Sprintf("limit deve estar entre 1 e %d", maxTradeHistory))
+		}
+		filter.Limit = parsed
+	}
+
+	if value := req.QueryParameter("cursor"); value != "" {
+		if filter.After, err = decodeCursor(value); err != nil {
+			return invalid("cursor inválido")
+		}
+	}
+	return filter, true
+}
+
+// parseTimeParameter aceita um instante RFC 3339 ou uma data (meia-noite UTC)
+func parseTimeParameter(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// encodeCursor torna opaco o ID da última negociação da página
+func encodeCursor(tradeID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(tradeID))
+}
+
+// decodeCursor recupera o ID da negociação a partir do cursor
+func decodeCursor(cursor string) (string, error) {
+	id, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(id) == 0 {
+		return "", errInvalidParameter
+	}
+	return string(id), nil
+}
+
+// tradesCSVHeader são as colunas do blotter em CSV
+var tradesCSVHeader = []string{
+	"id", "executed_at", "symbol", "buyer_id", "seller_id", "quantity", "price", "value", "buy_order_id", "sell_order_id",
+}
+
+// writeTradesCSV escreve as negociações como CSV, uma por linha
+func writeTradesCSV(resp *restful.Response, trades []*domain.Trade) {
+	resp.Header().Set("Content-Type", mimeCSV+"; charset=utf-8")
+	resp.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(resp)
+	_ = writer.Write(tradesCSVHeader)
+	for _, trade := range trades {
+		_ = writer.Write([]string{
+			trade.ID,
+			trade.ExecutedAt.UTC().Format(time.RFC3339Nano),
+			trade.Symbol,
+			trade.BuyerID,
+			trade.SellerID,
+			strconv.Itoa(trade.Quantity),
+			strconv.FormatFloat(trade.Price, 'f', -1, 64),
+			strconv.FormatFloat(trade.Value, 'f', -1, 64),
+			trade.BuyOrderID,
+			trade.SellOrderID,
+		})
+	}
+	writer.Flush()
 }
 
 // HealthCheck verifica saúde do sistema
 func (h *TradingHandler) HealthCheck(req *restful.Request, resp *restful.Response) {
-	_, _ = resp.Write([]byte("OK - HealthCheck"))
+	writeJSON(resp, http.StatusOK, map[string]interface{}{
+		"status":    "ok",
+		"timestamp": time.Now().UTC(),
+	})
 }
 
-// GetStats retorna estatísticas do sistema
+// GetStats retorna as estatísticas do engine desde a partida. Ordens novas
+// rejeitadas na entrada de POST /orders, antes do engine, entram em recebidas
+// e rejeitadas; alterações recusadas não contam como ordens recebidas.
 func (h *TradingHandler) GetStats(req *restful.Request, resp *restful.Response) {
-	_, _ = resp.Write([]byte("OK - GetStats"))
+	engine := h.matcher.Stats()
+	result := Stats{
+		StartedAt:     h.startedAt,
+		UptimeSeconds: int64(time.Since(h.startedAt).Seconds()),
+		Orders: OrderStats{
+			Received:         engine.Received,
+			Accepted:         engine.Accepted,
+			RejectedByReason: map[string]uint64{},
+		},
+		Trades:        TradeStats{BySymbol: engine.Trades},
+		RestingOrders: engine.Resting,
+		ActiveUsers:   engine.ActiveUsers,
+
+		PersistenceErrors: engine.PersistenceErrors,
+	}
+
+	rejections := h.rejections.Snapshot()
+	for _, count := range rejections {
+		result.Orders.Received += count
+	}
+	for _, counts := range []map[error]uint64{rejections, engine.Rejected} {
+		for reason, count := range counts {
+			result.Orders.Rejected += count
+			result.Orders.RejectedByReason[ErrorCode(reason)] += count
+		}
+	}
+
+	for _, total := range engine.Trades {
+		result.Trades.Total += total.Count
+		result.Trades.Notional += total.Sum
+	}
+
+	result.MatchLatency = LatencyStats{
+		Count: engine.Latency.Count,
+		AvgMs: milliseconds(engine.Latency.Mean()),
+		P99Ms: milliseconds(engine.Latency.Quantile(0.99)),
+	}
+
+	if h.bus != nil {
+		bus := h.bus.Stats()
+		result.EventBus = &bus
+	}
+	writeJSON(resp, http.StatusOK, result)
+}
+
+// milliseconds converte uma duração em milissegundos fracionários
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// writeJSON escreve uma resposta JSON com o status informado
+func writeJSON(resp *restful.Response, status int, value interface{}) {
+	_ = resp.WriteHeaderAndJson(status, value, restful.MIME_JSON)
 }
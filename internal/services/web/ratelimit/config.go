@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Limites padrão de entrada de ordens
+var (
+	// DefaultIPLimit vale por endereço IP; para usuários autenticados, vale
+	// o maior entre ele e o limite do perfil
+	DefaultIPLimit = Limit{Rate: 20, Burst: 40}
+
+	// DefaultUserLimit vale por usuário autenticado cujo perfil não define
+	// rate_limit
+	DefaultUserLimit = Limit{Rate: 5, Burst: 10}
+)
+
+// Config reúne os limites por IP, por usuário e por perfil
+type Config struct {
+	IP       Limit
+	User     Limit
+	Profiles map[string]Limit
+}
+
+// ForProfile retorna o limite do perfil, ou o limite padrão por usuário
+func (c Config) ForProfile(profile string) Limit {
+	if limit, exists := c.Profiles[profile]; exists {
+		return limit
+	}
+	return c.User
+}
+
+// ForIP retorna o limite do IP para um usuário autenticado com o limite
+// user: o maior dos dois, para que o limite do perfil seja alcançável
+func (c Config) ForIP(user Limit) Limit {
+	if c.IP.Unlimited() || user.Unlimited() {
+		return Limit{}
+	}
+	return Limit{Rate: math.Max(c.IP.Rate, user.Rate), Burst: max(c.IP.Burst, user.Burst)}
+}
+
+// usersFile é o trecho de data/users.json com os limites dos perfis
+type usersFile struct {
+	Profiles map[string]struct {
+		RateLimit *Limit `json:"rate_limit"`
+	} `json:"profiles"`
+}
+
+// LoadProfiles lê o rate_limit de cada perfil de data/users.json
+func LoadProfiles(usersPath string) (map[string]Limit, error) {
+	data, err := os.ReadFile(usersPath)
+	if err != nil {
+		return nil, fmt.Errorf("lendo %s: %w", usersPath, err)
+	}
+
+	var file usersFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decodificando %s: %w", usersPath, err)
+	}
+
+	profiles := make(map[string]Limit, len(file.Profiles))
+	for name, profile := range file.Profiles {
+		if profile.RateLimit != nil {
+			profiles[name] = *profile.RateLimit
+		}
+	}
+	return profiles, nil
+}
+
+// ParseLimit interpreta "<por segundo>:<rajada>" (ex.: "5:10"); "0"
+// desativa o limite
+func ParseLimit(value string) (Limit, error) {
+	if strings.TrimSpace(value) == "0" {
+		return Limit{}, nil
+	}
+	rate, burst, ok := strings.Cut(value, ":")
+	perSecond, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+	if !ok || err != nil || perSecond <= 0 {
+		return Limit{}, fmt.Errorf("limite inválido %q: use <por segundo>:<rajada>", value)
+	}
+	size, err := strconv.Atoi(strings.TrimSpace(burst))
+	if err != nil || size < 1 {
+		return Limit{}, fmt.Errorf("limite inválido %q: rajada deve ser positiva", value)
+	}
+	return Limit{Rate: perSecond, Burst: size}, nil
+}
+
+// ConfigFromEnv monta a configuração com os perfis de usersPath e os limites
+// de RATE_LIMIT_IP e RATE_LIMIT_USER, se definidos
+func ConfigFromEnv(usersPath string) (Config, error) {
+	profiles, err := LoadProfiles(usersPath)
+	if err != nil {
+		return Config{}, err
+	}
+
+	cfg := Config{IP: DefaultIPLimit, User: DefaultUserLimit, Profiles: profiles}
+	for env, limit := range map[string]*Limit{"RATE_LIMIT_IP": &cfg.IP, "RATE_LIMIT_USER": &cfg.User} {
+		if value := os.Getenv(env); value != "" {
+			parsed, err := ParseLimit(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("%s: %w", env, err)
+			}
+			*limit = parsed
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,173 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval é o intervalo mínimo entre as limpezas de baldes cheios
+const sweepInterval = time.Minute
+
+// Limit é a vazão sustentada (requisições por segundo) e a rajada de um balde
+type Limit struct {
+	Rate  float64 `json:"per_second"`
+	Burst int     `json:"burst"`
+}
+
+// Unlimited informa se o limite está desativado
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Decision é o resultado de uma tentativa de consumir um token
+type Decision struct {
+	Allowed bool
+
+	// Limit é a rajada do balde e Remaining os tokens inteiros restantes
+	Limit     int
+	Remaining int
+
+	// RetryAfter é a espera até haver um token (zero quando permitido)
+	RetryAfter time.Duration
+
+	// Reset é o tempo até o balde voltar a ficar cheio
+	Reset time.Duration
+}
+
+// bucket é o estado de um balde: tokens disponíveis na última atualização
+type bucket struct {
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+// refill repõe os tokens acumulados desde a última atualização
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+	}
+	b.updated = now
+}
+
+// Limiter mantém um balde de tokens por chave (usuário ou IP). Baldes que
+// voltaram a ficar cheios são descartados periodicamente.
+type Limiter struct {
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+	mutex     sync.Mutex
+}
+
+// NewLimiter cria um limitador vazio
+func NewLimiter() *Limiter {
+	return &Limiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// SetClock substitui o relógio usado na reposição (útil em testes)
+func (l *Limiter) SetClock(now func() time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.now = now
+}
+
+// Request é um balde a consultar em AllowAll
+type Request struct {
+	Key   string
+	Limit Limit
+}
+
+// Allow consome um token do balde da chave, criado cheio no primeiro uso.
+// Se o limite da chave mudar (outro perfil), o balde passa a usar o novo
+// limite sem ganhar tokens.
+func (l *Limiter) Allow(key string, limit Limit) Decision {
+	return l.AllowAll(Request{Key: key, Limit: limit})[0]
+}
+
+// AllowAll consulta todos os baldes e só consome um token de cada se todos
+// tiverem token; uma recusa em qualquer balde não gasta os demais. As
+// decisões seguem a ordem dos pedidos.
+func (l *Limiter) AllowAll(requests ...Request) []Decision {
+	decisions := make([]Decision, len(requests))
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	buckets := make([]*bucket, len(requests))
+	allowed := true
+	for i, request := range requests {
+		if request.Limit.Unlimited() {
+			continue
+		}
+		buckets[i] = l.bucket(request.Key, request.Limit, now)
+		allowed = allowed && buckets[i].tokens >= 1
+	}
+
+	for i, b := range buckets {
+		if b == nil {
+			decisions[i] = Decision{Allowed: true}
+			continue
+		}
+		decision := Decision{Limit: b.limit.Burst}
+		switch {
+		case allowed:
+			b.tokens--
+			decision.Allowed = true
+		case b.tokens >= 1:
+			decision.Allowed = true
+		default:
+			decision.RetryAfter = seconds((1 - b.tokens) / b.limit.Rate)
+		}
+		decision.Remaining = int(b.tokens)
+		decision.Reset = seconds((float64(b.limit.Burst) - b.tokens) / b.limit.Rate)
+		decisions[i] = decision
+	}
+	return decisions
+}
+
+// bucket retorna o balde da chave reposto até now; o chamador deve ter o lock
+func (l *Limiter) bucket(key string, limit Limit, now time.Time) *bucket {
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{limit: limit, tokens: float64(limit.Burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+	if b.limit != limit {
+		b.limit = limit
+		b.tokens = math.Min(b.tokens, float64(limit.Burst))
+	}
+	return b
+}
+
+// sweep descarta os baldes já cheios; o chamador deve ter o lock
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Len retorna o número de baldes mantidos
+func (l *Limiter) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.buckets)
+}
+
+// seconds converte segundos fracionários em duração
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,200 @@
+package stream
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Parâmetros da conexão WebSocket
+const (
+	sendBufferSize = 256
+	writeTimeout   = 10 * time.Second
+	pongTimeout    = 60 * time.Second
+	pingInterval   = 30 * time.Second
+	maxCommandSize = 1024
+)
+
+// client representa uma conexão WebSocket inscrita no hub
+type client struct {
+	hub  *MarketDataHub
+	conn *websocket.Conn
+
+	send   chan []byte
+	resync chan struct{}
+	done   chan struct{}
+
+	// lagging indica que o buffer encheu; mensagens são descartadas até o resync
+	lagging atomic.Bool
+	dropped atomic.Uint64
+
+	subs  map[string]map[string]bool // símbolo -> canais
+	mutex sync.Mutex
+}
+
+// newClient cria um cliente para a conexão
+func newClient(hub *MarketDataHub, conn *websocket.Conn) *client {
+	return &client{
+		hub:    hub,
+		conn:   conn,
+		send:   make(chan []byte, sendBufferSize),
+		resync: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		subs:   make(map[string]map[string]bool),
+	}
+}
+
+// enqueue serializa e enfileira uma mensagem
+func (c *client) enqueue(msg Message) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	c.enqueueRaw(payload)
+}
+
+// enqueueRaw enfileira sem bloquear; se o buffer estiver cheio o cliente é
+// marcado como lento e receberá snapshots novos em vez das mensagens perdidas
+func (c *client) enqueueRaw(payload []byte) {
+	if c.lagging.Load() {
+		c.dropped.Add(1)
+		return
+	}
+
+	select {
+	case c.send <- payload:
+	default:
+		c.lagging.Store(true)
+		c.dropped.Add(1)
+		select {
+		case c.resync <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// readLoop processa comandos do cliente até a conexão fechar
+func (c *client) readLoop() {
+	defer func() {
+		close(c.done)
+		c.hub.remove(c)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxCommandSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	})
+
+	for {
+		var cmd Command
+		if err := c.conn.ReadJSON(&cmd); err != nil {
+			return
+		}
+
+		symbol := normalizeSymbol(cmd.Symbol)
+		switch {
+		case symbol == "":
+			c.enqueue(Message{Type: MessageError, Error: "symbol é obrigatório"})
+		case c.hub.symbols.ValidateSymbol(symbol) != nil:
+			c.enqueue(Message{Type: MessageError, Symbol: symbol, Error: "símbolo inválido"})
+		case cmd.Channel != ChannelTrades && cmd.Channel != ChannelTop && cmd.Channel != ChannelDepth:
+			c.enqueue(Message{Type: MessageError, Symbol: symbol, Error: "canal inválido: " + cmd.Channel})
+		case cmd.Action == "subscribe":
+			c.hub.subscribe(c, cmd.Channel, symbol)
+		case cmd.Action == "unsubscribe":
+			c.hub.unsubscribe(c, cmd.Channel, symbol)
+			c.enqueue(Message{Type: MessageUnsubscribed, Channel: cmd.Channel, Symbol: symbol})
+		default:
+			c.enqueue(Message{Type: MessageError, Error: "ação inválida: " + cmd.Action})
+		}
+	}
+}
+
+// writeLoop envia mensagens enfileiradas, pings e resyncs
+func (c *client) writeLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case payload := <-c.send:
+			if err := c.write(websocket.TextMessage, payload); err != nil {
+				return
+			}
+
+		case <-c.resync:
+			c.drain()
+			payload, _ := json.Marshal(Message{Type: MessageResync})
+			if err := c.write(websocket.TextMessage, payload); err != nil {
+				return
+			}
+			c.hub.resync(c)
+
+		case <-ticker.C:
+			if err := c.write(websocket.PingMessage, nil); err != nil {
+				return
+			}
+
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// write escreve um frame com timeout
+func (c *client) write(messageType int, payload []byte) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.conn.WriteMessage(messageType, payload)
+}
+
+// drain descarta as mensagens pendentes
+func (c *client) drain() {
+	for {
+		select {
+		case <-c.send:
+		default:
+			return
+		}
+	}
+}
+
+// track registra ou remove uma inscrição do cliente
+func (c *client) track(symbol, channel string, subscribed bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if subscribed {
+		if c.subs[symbol] == nil {
+			c.subs[symbol] = make(map[string]bool)
+		}
+		c.subs[symbol][channel] = true
+		return
+	}
+
+	delete(c.subs[symbol], channel)
+	if len(c.subs[symbol]) == 0 {
+		delete(c.subs, symbol)
+	}
+}
+
+// subscriptions retorna uma cópia das inscrições do cliente
+func (c *client) subscriptions() map[string][]string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	subs := make(map[string][]string, len(c.subs))
+	for symbol, channels := range c.subs {
+		for channel := range channels {
+			subs[symbol] = append(subs[symbol], channel)
+		}
+	}
+	return subs
+}
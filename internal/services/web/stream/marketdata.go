@@ -0,0 +1,356 @@
+package stream
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/shared/events"
+)
+
+// Canais disponíveis por símbolo
+const (
+	ChannelTrades = "trades"
+	ChannelTop    = "top"
+	ChannelDepth  = "depth"
+)
+
+// Tipos de mensagem enviadas ao cliente
+const (
+	MessageSnapshot     = "snapshot"
+	MessageDelta        = "delta"
+	MessageTrade        = "trade"
+	MessageTop          = "top"
+	MessageSubscribed   = "subscribed"
+	MessageUnsubscribed = "unsubscribed"
+	MessageResync       = "resync"
+	MessageError        = "error"
+)
+
+// trackedLevels é a quantidade de níveis por lado mantida para os deltas
+const trackedLevels = 50
+
+// DepthSource fornece o livro agregado de um símbolo
+type DepthSource interface {
+	GetDepth(symbol string, depth int) *orderbook.Depth
+}
+
+// SymbolValidator verifica se um símbolo é negociável
+type SymbolValidator interface {
+	ValidateSymbol(symbol string) error
+}
+
+// Message é o envelope de toda mensagem enviada pelo WebSocket
+type Message struct {
+	Type    string      `json:"type"`
+	Channel string      `json:"channel,omitempty"`
+	Symbol  string      `json:"symbol,omitempty"`
+	Seq     uint64      `json:"seq,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// Command é a mensagem enviada pelo cliente para (des)inscrição
+type Command struct {
+	Action  string `json:"action"` // subscribe | unsubscribe
+	Channel string `json:"channel"`
+	Symbol  string `json:"symbol"`
+}
+
+// PublicTrade é a visão pública de uma negociação (sem identificar usuários)
+type PublicTrade struct {
+	ID         string    `json:"id"`
+	Symbol     string    `json:"symbol"`
+	Price      float64   `json:"price"`
+	Quantity   int       `json:"quantity"`
+	ExecutedAt time.Time `json:"executed_at"`
+}
+
+// TopOfBook representa o melhor bid e ask de um símbolo (preço 0 = lado vazio)
+type TopOfBook struct {
+	BidPrice float64 `json:"bid_price"`
+	BidSize  int     `json:"bid_size"`
+	AskPrice float64 `json:"ask_price"`
+	AskSize  int     `json:"ask_size"`
+}
+
+// DepthDelta traz apenas os níveis alterados; quantidade 0 remove o nível
+type DepthDelta struct {
+	Bids []orderbook.PriceLevel `json:"bids"`
+	Asks []orderbook.PriceLevel `json:"asks"`
+}
+
+// MarketDataHub distribui eventos do matching engine para clientes WebSocket
+type MarketDataHub struct {
+	books    DepthSource
+	symbols  SymbolValidator
+	upgrader websocket.Upgrader
+
+	states map[string]*symbolState
+	mutex  sync.Mutex
+}
+
+// symbolState guarda o último estado publicado e os inscritos de um símbolo.
+// O mutex serializa publicação e snapshots, garantindo a sequência dos deltas.
+type symbolState struct {
+	symbol      string
+	seq         uint64
+	depth       *orderbook.Depth
+	top         TopOfBook
+	subscribers map[string]map[*client]bool // canal -> clientes
+	mutex       sync.Mutex
+}
+
+// NewMarketDataHub cria o hub de market data
+func NewMarketDataHub(books DepthSource, symbols SymbolValidator) *MarketDataHub {
+	return &MarketDataHub{
+		books:   books,
+		symbols: symbols,
+		// Sem origens configuradas, só conexões da mesma origem
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+		},
+		states: make(map[string]*symbolState),
+	}
+}
+
+// SetAllowedOrigins aceita conexões de navegador apenas das origens
+// informadas (as mesmas do CORS da API); lista vazia volta à mesma origem
+func (h *MarketDataHub) SetAllowedOrigins(origins []string) {
+	if len(origins) == 0 {
+		h.upgrader.CheckOrigin = nil
+		return
+	}
+	h.upgrader.CheckOrigin = func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || slices.Contains(origins, origin)
+	}
+}
+
+// ServeHTTP faz o upgrade da conexão para WebSocket
+func (h *MarketDataHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := newClient(h, conn)
+	go c.writeLoop()
+	c.readLoop()
+}
+
+// HandleEvent consome os eventos do barramento; ordens individuais e
+// portfolios não fazem parte do market data público
+func (h *MarketDataHub) HandleEvent(event events.Event) {
+	switch e := event.(type) {
+	case events.TradeExecuted:
+		h.onTrade(e.Trade)
+	case events.BookChanged:
+		h.onBookChanged(e.Symbol)
+	}
+}
+
+// onTrade publica uma negociação no canal de trades do símbolo
+func (h *MarketDataHub) onTrade(trade *domain.Trade) {
+	state := h.state(trade.Symbol)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	state.broadcast(ChannelTrades, Message{
+		Type:    MessageTrade,
+		Channel: ChannelTrades,
+		Symbol:  trade.Symbol,
+		Data: PublicTrade{
+			ID:         trade.ID,
+			Symbol:     trade.Symbol,
+			Price:      trade.Price,
+			Quantity:   trade.Quantity,
+			ExecutedAt: trade.ExecutedAt,
+		},
+	})
+}
+
+// onBookChanged calcula e publica o delta do livro e o novo topo
+func (h *MarketDataHub) onBookChanged(symbol string) {
+	state := h.state(symbol)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	current := h.books.GetDepth(symbol, trackedLevels)
+	delta := DepthDelta{
+		Bids: diffLevels(state.depth.Bids, current.Bids),
+		Asks: diffLevels(state.depth.Asks, current.Asks),
+	}
+	state.depth = current
+
+	if len(delta.Bids) > 0 || len(delta.Asks) > 0 {
+		state.seq++
+		state.broadcast(ChannelDepth, Message{
+			Type:    MessageDelta,
+			Channel: ChannelDepth,
+			Symbol:  symbol,
+			Seq:     state.seq,
+			Data:    delta,
+		})
+	}
+
+	if top := topOf(current); top != state.top {
+		state.top = top
+		state.broadcast(ChannelTop, Message{
+			Type:    MessageTop,
+			Channel: ChannelTop,
+			Symbol:  symbol,
+			Seq:     state.seq,
+			Data:    top,
+		})
+	}
+}
+
+// subscribe inscreve o cliente e envia o snapshot inicial do canal
+func (h *MarketDataHub) subscribe(c *client, channel, symbol string) {
+	state := h.state(symbol)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	if state.subscribers[channel] == nil {
+		state.subscribers[channel] = make(map[*client]bool)
+	}
+	state.subscribers[channel][c] = true
+	c.track(symbol, channel, true)
+
+	c.enqueue(Message{Type: MessageSubscribed, Channel: channel, Symbol: symbol, Seq: state.seq})
+	state.sendSnapshot(c, channel)
+}
+
+// unsubscribe remove a inscrição do cliente
+func (h *MarketDataHub) unsubscribe(c *client, channel, symbol string) {
+	state := h.state(symbol)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	delete(state.subscribers[channel], c)
+	c.track(symbol, channel, false)
+}
+
+// resync reenvia snapshots a um cliente que ficou para trás
+func (h *MarketDataHub) resync(c *client) {
+	for symbol, channels := range c.subscriptions() {
+		state := h.state(symbol)
+		state.mutex.Lock()
+		// Volta a aceitar mensagens dentro do lock do símbolo para que nenhum
+		// delta seja enfileirado antes do snapshot correspondente
+		c.lagging.Store(false)
+		for _, channel := range channels {
+			state.sendSnapshot(c, channel)
+		}
+		state.mutex.Unlock()
+	}
+	c.lagging.Store(false)
+}
+
+// remove desinscreve o cliente de todos os canais
+func (h *MarketDataHub) remove(c *client) {
+	for symbol, channels := range c.subscriptions() {
+		state := h.state(symbol)
+		state.mutex.Lock()
+		for _, channel := range channels {
+			delete(state.subscribers[channel], c)
+		}
+		state.mutex.Unlock()
+	}
+}
+
+// state retorna (ou cria) o estado de um símbolo
+func (h *MarketDataHub) state(symbol string) *symbolState {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	state, exists := h.states[symbol]
+	if !exists {
+		depth := h.books.GetDepth(symbol, trackedLevels)
+		state = &symbolState{
+			symbol:      symbol,
+			depth:       depth,
+			top:         topOf(depth),
+			subscribers: make(map[string]map[*client]bool),
+		}
+		h.states[symbol] = state
+	}
+	return state
+}
+
+// broadcast envia a mensagem a todos os inscritos do canal; o chamador deve ter o lock
+func (s *symbolState) broadcast(channel string, msg Message) {
+	if len(s.subscribers[channel]) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	for c := range s.subscribers[channel] {
+		c.enqueueRaw(payload)
+	}
+}
+
+// sendSnapshot envia o estado atual do canal; o chamador deve ter o lock
+func (s *symbolState) sendSnapshot(c *client, channel string) {
+	switch channel {
+	case ChannelDepth:
+		c.enqueue(Message{Type: MessageSnapshot, Channel: channel, Symbol: s.symbol, Seq: s.seq, Data: s.depth})
+	case ChannelTop:
+		c.enqueue(Message{Type: MessageTop, Channel: channel, Symbol: s.symbol, Seq: s.seq, Data: s.top})
+	}
+}
+
+// topOf extrai o topo do livro agregado
+func topOf(depth *orderbook.Depth) TopOfBook {
+	top := TopOfBook{}
+	if len(depth.Bids) > 0 {
+		top.BidPrice = depth.Bids[0].Price
+		top.BidSize = depth.Bids[0].Quantity
+	}
+	if len(depth.Asks) > 0 {
+		top.AskPrice = depth.Asks[0].Price
+		top.AskSize = depth.Asks[0].Quantity
+	}
+	return top
+}
+
+// diffLevels retorna os níveis novos ou alterados e os removidos (quantidade 0)
+func diffLevels(previous, current []orderbook.PriceLevel) []orderbook.PriceLevel {
+	before := make(map[float64]orderbook.PriceLevel, len(previous))
+	for _, level := range previous {
+		before[level.Price] = level
+	}
+
+	changes := []orderbook.PriceLevel{}
+	for _, level := range current {
+		if old, exists := before[level.Price]; !exists || old != level {
+			changes = append(changes, level)
+		}
+		delete(before, level.Price)
+	}
+	for _, level := range previous {
+		if _, removed := before[level.Price]; removed {
+			changes = append(changes, orderbook.PriceLevel{Price: level.Price})
+		}
+	}
+
+	return changes
+}
+
+// normalizeSymbol padroniza o símbolo recebido do cliente
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,186 @@
+package stream
+
+import (
+	"sync"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/shared/events"
+)
+
+// Tipos de evento enviados ao usuário
+const (
+	EventOrder     = "order"
+	EventTrade     = "trade"
+	EventPortfolio = "portfolio"
+)
+
+// Parâmetros padrão do stream de eventos do usuário
+const (
+	defaultReplaySize    = 256
+	subscriberBufferSize = 64
+)
+
+// UserEvent é um evento destinado a um único usuário
+type UserEvent struct {
+	ID        uint64      `json:"id"`
+	Type      string      `json:"type"`
+	UserID    string      `json:"user_id"`
+	Data      interface{} `json:"data"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// UserTrade é a negociação do ponto de vista do usuário
+type UserTrade struct {
+	*domain.Trade
+	Side domain.OrderSide `json:"side"`
+}
+
+// Subscription é uma inscrição nos eventos de um usuário
+type Subscription struct {
+	// Replay contém os eventos posteriores ao Last-Event-ID informado
+	Replay []UserEvent
+	// Reset indica que o Last-Event-ID não está mais no buffer e o cliente
+	// deve recarregar o estado completo
+	Reset bool
+	// Events é fechado quando o inscrito fica para trás ou é encerrado
+	Events <-chan UserEvent
+
+	stream *userStream
+	events chan UserEvent
+}
+
+// UserEventHub mantém, por usuário, um buffer de replay e os inscritos SSE
+type UserEventHub struct {
+	replaySize int
+	users      map[string]*userStream
+	mutex      sync.Mutex
+}
+
+// userStream guarda a sequência, o buffer circular e os inscritos de um usuário
+type userStream struct {
+	seq         uint64
+	buffer      []UserEvent
+	subscribers map[*Subscription]bool
+	mutex       sync.Mutex
+}
+
+// NewUserEventHub cria o hub com buffer de replay de replaySize eventos por
+// usuário (replaySize <= 0 usa o padrão)
+func NewUserEventHub(replaySize int) *UserEventHub {
+	if replaySize <= 0 {
+		replaySize = defaultReplaySize
+	}
+	return &UserEventHub{
+		replaySize: replaySize,
+		users:      make(map[string]*userStream),
+	}
+}
+
+// Subscribe inscreve nos eventos do usuário. Com resume, os eventos com ID
+// maior que lastEventID ainda presentes no buffer são devolvidos em Replay.
+func (h *UserEventHub) Subscribe(userID string, lastEventID uint64, resume bool) *Subscription {
+	stream := h.stream(userID)
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	events := make(chan UserEvent, subscriberBufferSize)
+	sub := &Subscription{Events: events, stream: stream, events: events}
+	stream.subscribers[sub] = true
+
+	if !resume {
+		return sub
+	}
+
+	oldest := stream.seq - uint64(len(stream.buffer)) + 1
+	switch {
+	case lastEventID > stream.seq:
+		// ID desconhecido (ex.: servidor reiniciado)
+		sub.Reset = true
+	case lastEventID+1 < oldest:
+		// Parte dos eventos já saiu do buffer
+		sub.Reset = true
+	default:
+		for _, event := range stream.buffer {
+			if event.ID > lastEventID {
+				sub.Replay = append(sub.Replay, event)
+			}
+		}
+	}
+
+	return sub
+}
+
+// Close encerra a inscrição
+func (s *Subscription) Close() {
+	s.stream.mutex.Lock()
+	defer s.stream.mutex.Unlock()
+
+	if s.stream.subscribers[s] {
+		delete(s.stream.subscribers, s)
+		close(s.events)
+	}
+}
+
+// HandleEvent converte os eventos do barramento em eventos de cada usuário
+func (h *UserEventHub) HandleEvent(event events.Event) {
+	switch e := event.(type) {
+	case events.OrderAccepted:
+		h.publish(e.Order.UserID, EventOrder, e.Order)
+	case events.OrderRejected:
+		h.publish(e.Order.UserID, EventOrder, e.Order)
+	case events.OrderCancelled:
+		h.publish(e.Order.UserID, EventOrder, e.Order)
+	case events.TradeExecuted:
+		h.publish(e.BuyOrder.UserID, EventOrder, e.BuyOrder)
+		h.publish(e.SellOrder.UserID, EventOrder, e.SellOrder)
+		h.publish(e.Trade.BuyerID, EventTrade, UserTrade{Trade: e.Trade, Side: domain.BUY})
+		h.publish(e.Trade.SellerID, EventTrade, UserTrade{Trade: e.Trade, Side: domain.SELL})
+	case events.PortfolioUpdated:
+		h.publish(e.UserID, EventPortfolio, e)
+	}
+}
+
+// publish adiciona o evento ao buffer e entrega aos inscritos sem bloquear;
+// inscritos lentos são desconectados e retomam via Last-Event-ID
+func (h *UserEventHub) publish(userID, eventType string, data interface{}) {
+	stream := h.stream(userID)
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	stream.seq++
+	event := UserEvent{
+		ID:        stream.seq,
+		Type:      eventType,
+		UserID:    userID,
+		Data:      data,
+		Timestamp: time.Now().UTC(),
+	}
+
+	stream.buffer = append(stream.buffer, event)
+	if len(stream.buffer) > h.replaySize {
+		stream.buffer = stream.buffer[len(stream.buffer)-h.replaySize:]
+	}
+
+	for sub := range stream.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			delete(stream.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// stream retorna (ou cria) o stream de um usuário
+func (h *UserEventHub) stream(userID string) *userStream {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	stream, exists := h.users[userID]
+	if !exists {
+		stream = &userStream{subscribers: make(map[*Subscription]bool)}
+		h.users[userID] = stream
+	}
+	return stream
+}
@@ -0,0 +1,230 @@
+package integration
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"trading/internal/services/engine/snapshot"
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/web/auth"
+	"trading/internal/services/web/handlers"
+)
+
+const testJWTSecret = "segredo-jwt"
+
+// testAPIKey é a chave de API de carlos-santos nos testes
+var testAPIKey = auth.APIKey{ID: "key-carlos", Secret: "segredo-carlos", UserID: "carlos-santos", Roles: []string{"trader"}}
+
+// newAuthEnv monta o ambiente com autenticação por JWT e chave de API
+func newAuthEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	env := newTestEnv(t, marketOpen)
+	env.handler.SetAuthenticator(auth.NewAuthenticator(auth.Config{
+		APIKeys:    []auth.APIKey{testAPIKey},
+		JWTSecret:  []byte(testJWTSecret),
+		AdminToken: testAdminToken,
+	}))
+	return env
+}
+
+// bearerFor emite um JWT para o usuário com os papéis informados
+func bearerFor(t *testing.T, userID string, roles ...string) map[string]string {
+	t.Helper()
+
+	token, err := auth.NewToken(auth.Claims{Subject: userID, Roles: roles, ExpiresAt: time.Now().Add(time.Hour).Unix()}, []byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("Erro ao emitir token: %v", err)
+	}
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// TestAuthentication verifica credenciais exigidas, vínculo do principal ao
+// user_id do corpo, do caminho e das ordens, e o acesso do administrador
+func TestAuthentication(t *testing.T) {
+	env := newAuthEnv(t)
+	order := map[string]interface{}{"user_id": "ana-silva", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 210.00}
+
+	expectError := func(resp *httptest.ResponseRecorder, status int, code string) {
+		t.Helper()
+		var body handlers.ErrorResponse
+		decode(t, resp, &body)
+		if resp.Code != status || body.Code != code {
+			t.Errorf("Esperado %d %s, obtido %d %+v", status, code, resp.Code, body)
+		}
+	}
+
+	// Health check é público; o resto exige credenciais
+	if resp := doRequest(env.container, "GET", "/api/health", nil, nil); resp.Code != 200 {
+		t.Errorf("Health check deveria ser público, obtido %d", resp.Code)
+	}
+	resp := doRequest(env.container, "POST", "/api/orders", order, nil)
+	expectError(resp, 401, "UNAUTHORIZED")
+	if resp.Header().Get("WWW-Authenticate") == "" {
+		t.Error("Esperado header WWW-Authenticate no 401")
+	}
+	expectError(doRequest(env.container, "GET", "/api/stocks", nil, map[string]string{"Authorization": "Bearer abc.def.ghi"}), 401, "INVALID_CREDENTIALS")
+
+	// Cada principal só opera o próprio user_id
+	ana := bearerFor(t, "ana-silva", "trader")
+	created := doRequest(env.container, "POST", "/api/orders", order, ana)
+	if created.Code != 201 {
+		t.Fatalf("Esperado 201 para a própria ordem, obtido %d: %s", created.Code, created.Body.String())
+	}
+	var result struct {
+		Order struct {
+			ID string `json:"id"`
+		} `json:"order"`
+	}
+	decode(t, created, &result)
+
+	carlos := bearerFor(t, "carlos-santos", "trader")
+	expectError(doRequest(env.container, "POST", "/api/orders", order, carlos), 403, "FORBIDDEN")
+	expectError(doRequest(env.container, "GET", "/api/portfolio/ana-silva", nil, carlos), 403, "FORBIDDEN")
+	expectError(doRequest(env.container, "GET", "/api/trades?user_id=ana-silva", nil, carlos), 403, "FORBIDDEN")
+	expectError(doRequest(env.container, "GET", "/api/orders/"+result.Order.ID, nil, carlos), 403, "FORBIDDEN")
+	expectError(doRequest(env.container, "DELETE", "/api/orders/"+result.Order.ID, nil, carlos), 403, "FORBIDDEN")
+	if resp := doRequest(env.container, "GET", "/api/portfolio/carlos-santos", nil, carlos); resp.Code != 200 {
+		t.Errorf("Esperado 200 no próprio portfolio, obtido %d", resp.Code)
+	}
+
+	// Administrador age em nome de qualquer usuário
+	admin := bearerFor(t, "operador", auth.RoleAdmin)
+	if resp := doRequest(env.container, "GET", "/api/portfolio/ana-silva", nil, admin); resp.Code != 200 {
+		t.Errorf("Esperado 200 para o admin, obtido %d", resp.Code)
+	}
+	if resp := doRequest(env.container, "DELETE", "/api/orders/"+result.Order.ID, nil, admin); resp.Code != 200 {
+		t.Errorf("Esperado cancelamento pelo admin, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := doRequest(env.container, "GET", "/api/orderbook/AAPL?level=3", nil, admin); resp.Code != 200 {
+		t.Errorf("Esperado L3 para o papel admin, obtido %d", resp.Code)
+	}
+
+	// Chave de API: a assinatura cobre o corpo enviado
+	signed := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/orders", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if err := auth.SignRequest(req, testAPIKey, time.Now()); err != nil {
+			t.Fatalf("Erro ao assinar: %v", err)
+		}
+		resp := httptest.NewRecorder()
+		env.container.ServeHTTP(resp, req)
+		return resp
+	}
+	if resp := signed(`{"user_id":"carlos-santos","symbol":"AAPL","side":"SELL","quantity":1,"price":210}`); resp.Code != 201 {
+		t.Errorf("Esperado 201 com chave de API, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+	expectError(signed(`{"user_id":"ana-silva","symbol":"AAPL","side":"BUY","quantity":1,"price":210}`), 403, "FORBIDDEN")
+
+	// Corpo acima do limite assinado é recusado inteiro, não conferido pelo início
+	oversized, _ := http.NewRequest("POST", "/api/orders", strings.NewReader(strings.Repeat(" ", auth.MaxSignedBody)))
+	oversized.Header.Set("Content-Type", "application/json")
+	if err := auth.SignRequest(oversized, testAPIKey, time.Now()); err != nil {
+		t.Fatalf("Erro ao assinar: %v", err)
+	}
+	oversized.Body = io.NopCloser(strings.NewReader(strings.Repeat(" ", auth.MaxSignedBody+1)))
+	rejected := httptest.NewRecorder()
+	env.container.ServeHTTP(rejected, oversized)
+	expectError(rejected, 413, "PAYLOAD_TOO_LARGE")
+}
+
+// TestRoleAuthorization verifica as permissões declaradas por rota para cada
+// papel e o registro de auditoria dos acessos negados
+func TestRoleAuthorization(t *testing.T) {
+	env := newAuthEnv(t)
+	store, err := snapshot.NewStore(filepath.Join(t.TempDir(), "snapshots"), 0)
+	if err != nil {
+		t.Fatalf("Erro criando store: %v", err)
+	}
+	env.handler.SetSnapshots(snapshot.NewManager(env.matcher, store, nil))
+	audit := &logBuffer{}
+	env.handler.SetAuditLogger(logging.New(audit, slog.LevelInfo))
+	container := newContainer(env.handler)
+
+	expectStatus := func(method, path string, body interface{}, headers map[string]string, status int) {
+		t.Helper()
+		if resp := doRequest(container, method, path, body, headers); resp.Code != status {
+			t.Errorf("%s %s: esperado %d, obtido %d: %s", method, path, status, resp.Code, resp.Body.String())
+		}
+	}
+	order := map[string]interface{}{"user_id": "ana-silva", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 210.00}
+
+	// viewer lê, mas não envia ordens
+	viewer := bearerFor(t, "ana-silva", auth.RoleViewer)
+	expectStatus("GET", "/api/portfolio/ana-silva", nil, viewer, 200)
+	expectStatus("POST", "/api/orders", order, viewer, 403)
+
+	// trader opera a própria conta, sem L3, estatísticas nem snapshots
+	trader := bearerFor(t, "ana-silva", auth.RoleTrader)
+	expectStatus("POST", "/api/orders", order, trader, 201)
+	expectStatus("GET", "/api/orderbook/AAPL?level=3", nil, trader, 403)
+	expectStatus("GET", "/api/stats", nil, trader, 403)
+	expectStatus("POST", "/api/admin/snapshots", nil, trader, 403)
+	expectStatus("POST", "/api/admin/snapshots/1/restore", nil, trader, 403)
+
+	// risk lê L3, estatísticas e dados de outros usuários, mas não age por eles
+	risk := bearerFor(t, "analista", auth.RoleRisk)
+	expectStatus("GET", "/api/orderbook/AAPL?level=3", nil, risk, 200)
+	expectStatus("GET", "/api/stats", nil, risk, 200)
+	expectStatus("GET", "/api/portfolio/ana-silva", nil, risk, 200)
+	expectStatus("GET", "/api/users/ana-silva/orders", nil, risk, 200)
+	expectStatus("POST", "/api/orders", order, risk, 403)
+	expectStatus("GET", "/api/admin/snapshots", nil, risk, 403)
+
+	// admin recarrega o engine
+	admin := bearerFor(t, "operador", auth.RoleAdmin)
+	expectStatus("POST", "/api/admin/snapshots", nil, admin, 201)
+	expectStatus("GET", "/api/admin/snapshots", nil, admin, 200)
+
+	// Cada negação gera uma entrada de auditoria com principal, rota e motivo
+	entries := audit.entries(t, "acesso negado")
+	if len(entries) != 7 {
+		t.Fatalf("Esperadas 7 entradas de auditoria, obtidas %d", len(entries))
+	}
+	first := entries[0]
+	if first["subject"] != "ana-silva" || first["method"] != "POST" || first["route"] != "/api/orders" ||
+		first["permission"] != string(auth.PermOrdersWrite) || first["level"] != "WARN" || first["request_id"] == nil {
+		t.Errorf("Entrada de auditoria inesperada: %v", first)
+	}
+	if roles, _ := first["roles"].([]interface{}); len(roles) != 1 || roles[0] != auth.RoleViewer {
+		t.Errorf("Esperados os papéis do principal na auditoria, obtido %v", first["roles"])
+	}
+	if restore := entries[4]; restore["permission"] != string(auth.PermAdmin) || restore["path"] != "/api/admin/snapshots/1/restore" {
+		t.Errorf("Entrada de auditoria inesperada para restore: %v", restore)
+	}
+}
+
+// TestAdminTokenPrincipal verifica que o X-Admin-Token autentica o principal
+// admin, com ou sem autenticação configurada, e fica no log de auditoria
+func TestAdminTokenPrincipal(t *testing.T) {
+	for name, env := range map[string]*testEnv{"sem autenticação": newTestEnv(t, marketOpen), "com autenticação": newAuthEnv(t)} {
+		audit := &logBuffer{}
+		env.handler.SetAuditLogger(logging.New(audit, slog.LevelInfo))
+
+		admin := map[string]string{"X-Admin-Token": testAdminToken}
+		if resp := doRequest(env.container, "GET", "/api/orderbook/AAPL?level=3", nil, admin); resp.Code != 200 {
+			t.Errorf("%s: esperado 200 com o ADMIN_TOKEN, obtido %d", name, resp.Code)
+		}
+		wrong := map[string]string{"X-Admin-Token": "outro-token"}
+		if resp := doRequest(env.container, "GET", "/api/orderbook/AAPL?level=3", nil, wrong); resp.Code != 401 {
+			t.Errorf("%s: esperado 401 com token errado, obtido %d", name, resp.Code)
+		}
+
+		entries := audit.entries(t, "acesso administrativo")
+		if len(entries) != 1 || entries[0]["subject"] != auth.AdminSubject || entries[0]["route"] != "/api/orderbook/{symbol}" {
+			t.Errorf("%s: esperado o acesso admin na auditoria, obtido %v", name, entries)
+		}
+	}
+
+	// Sem autenticação e sem token, as rotas administrativas continuam negadas
+	env := newTestEnv(t, marketOpen)
+	if resp := doRequest(env.container, "GET", "/api/orderbook/AAPL?level=3", nil, nil); resp.Code != 403 {
+		t.Errorf("Esperado 403 sem ADMIN_TOKEN, obtido %d", resp.Code)
+	}
+}
@@ -0,0 +1,191 @@
+package integration
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/api"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/engine/portfolio"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/validators"
+	"trading/internal/services/web/handlers"
+)
+
+// engineProcess são os serviços hospedados pelo processo do engine
+type engineProcess struct {
+	matcher    *matching.Service
+	books      *orderbook.Manager
+	portfolios *portfolio.Service
+	bus        *events.Bus
+}
+
+// newEngineProcess monta os serviços do engine com seu próprio barramento
+func newEngineProcess(t *testing.T) *engineProcess {
+	t.Helper()
+
+	portfolios, err := portfolio.NewService(dataDir + "/users.json")
+	if err != nil {
+		t.Fatalf("Erro ao carregar usuários: %v", err)
+	}
+	books := orderbook.NewManager()
+	matcher := matching.NewService(books, portfolios)
+
+	bus := events.NewBus()
+	t.Cleanup(bus.Close)
+	matcher.SetPublisher(bus)
+	portfolios.SetPublisher(bus)
+
+	return &engineProcess{matcher: matcher, books: books, portfolios: portfolios, bus: bus}
+}
+
+// testEngineToken é o token de serviço entre web e engine nos testes
+const testEngineToken = "token-engine"
+
+// newEngineServer cria a API HTTP do engine exigindo o token de teste
+func (e *engineProcess) newEngineServer() *api.Server {
+	server := api.NewServer(e.matcher, e.books, e.portfolios, nil)
+	server.SetToken(testEngineToken)
+	return server
+}
+
+// newEngineClient cria o cliente do engine em url com o token de teste
+func newEngineClient(url string) *api.Client {
+	client := api.NewClient(url, time.Second)
+	client.SetToken(testEngineToken)
+	return client
+}
+
+// newWebValidator carrega as regras de ações com o mercado aberto
+func newWebValidator(t *testing.T) *validators.BusinessValidator {
+	t.Helper()
+
+	validator, err := validators.NewBusinessValidator(dataDir + "/stocks.json")
+	if err != nil {
+		t.Fatalf("Erro ao carregar ações: %v", err)
+	}
+	validator.SetClock(func() time.Time { return marketOpen })
+	return validator
+}
+
+// TestRemoteEngine verifica a camada web falando com o engine pela rede:
+// ordens, consultas, erros de domínio e eventos chegam como no modo embutido
+func TestRemoteEngine(t *testing.T) {
+	engine := newEngineProcess(t)
+	server := engine.newEngineServer()
+	engine.bus.Subscribe("engine-api", server.HandleEvent)
+
+	engineServer := httptest.NewServer(server.Container())
+	defer engineServer.Close()
+
+	// Processo web
+	validator := newWebValidator(t)
+	client := newEngineClient(engineServer.URL)
+	webBus := events.NewBus()
+	t.Cleanup(webBus.Close)
+	trades := make(chan events.TradeExecuted, 4)
+	events.On(webBus, "test", func(event events.TradeExecuted) { trades <- event })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Subscribe(ctx, webBus)
+	for deadline := time.Now().Add(2 * time.Second); server.Subscribers() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("Web service não conectou ao stream de eventos")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	container := newContainer(handlers.NewTradingHandler(client, client, client, validator))
+
+	sell := postOrder(t, container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+	}, 201)
+	buy := postOrder(t, container, map[string]interface{}{
+		"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 5, "price": 210.00,
+	}, 201)
+	if buy.Status != matching.StatusFilled || len(buy.Trades) != 1 || buy.Trades[0].SellOrderID != sell.Order.ID {
+		t.Fatalf("Esperada execução contra a venda, obtido %+v", buy)
+	}
+
+	select {
+	case event := <-trades:
+		if event.Trade.ID != buy.Trades[0].ID {
+			t.Errorf("Evento de outra negociação: %+v", event.Trade)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Evento TradeExecuted não chegou ao web service")
+	}
+
+	var portfolio struct {
+		Positions map[string]int `json:"positions"`
+	}
+	decode(t, doRequest(container, "GET", "/api/portfolio/beatriz-costa", nil, nil), &portfolio)
+	if portfolio.Positions["AAPL"] == 0 {
+		t.Errorf("Posição de AAPL não atualizada: %+v", portfolio)
+	}
+
+	// Erros de domínio atravessam a rede com o mesmo código
+	rejected := postOrder(t, container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 10000, "price": 210.00,
+	}, 400)
+	if rejected.Reason != "INSUFFICIENT_POSITION" {
+		t.Errorf("Esperado INSUFFICIENT_POSITION, obtido %s", rejected.Reason)
+	}
+
+	// Filtros do histórico de negociações chegam ao repositório do engine
+	var history []domain.Trade
+	decode(t, doRequest(container, "GET", "/api/trades?user_id=beatriz-costa&min_value=1000&sort=desc", nil, nil), &history)
+	if len(history) != 1 || history[0].ID != buy.Trades[0].ID {
+		t.Errorf("Esperada a negociação de beatriz-costa, obtido %+v", history)
+	}
+
+	// Contadores do engine, com as rejeições decodificadas pelo código
+	var stats handlers.Stats
+	decode(t, doRequest(container, "GET", "/api/stats", nil, nil), &stats)
+	if stats.Orders.Received != 3 || stats.Orders.RejectedByReason["INSUFFICIENT_POSITION"] != 1 || stats.Trades.Total != 1 {
+		t.Errorf("Estatísticas remotas inesperadas: %+v", stats)
+	}
+
+	var body handlers.ErrorResponse
+	resp := doRequest(container, "DELETE", "/api/orders/"+sell.Order.ID, nil, nil)
+	decode(t, resp, &body)
+	if resp.Code != 409 || body.Code != "ORDER_NOT_OPEN" {
+		t.Errorf("Esperado 409 ORDER_NOT_OPEN, obtido %d %s", resp.Code, body.Code)
+	}
+
+	// client_order_id atravessa a rede: reenvio, conflito e consulta
+	keyed := map[string]interface{}{
+		"user_id": "carlos-santos", "client_order_id": "remota-1", "symbol": "AAPL", "side": "SELL", "quantity": 2, "price": 212.00,
+	}
+	original := postOrder(t, container, keyed, 201)
+	if again := postOrder(t, container, keyed, 201); !again.Replayed || again.Order.ID != original.Order.ID {
+		t.Errorf("Esperado o resultado original, obtido %+v", again)
+	}
+	keyed["quantity"] = 3
+	resp = doRequest(container, "POST", "/api/orders", keyed, nil)
+	decode(t, resp, &body)
+	if resp.Code != 409 || body.Code != "CLIENT_ORDER_ID_CONFLICT" {
+		t.Errorf("Esperado 409 CLIENT_ORDER_ID_CONFLICT, obtido %d %s", resp.Code, body.Code)
+	}
+	var keyedOrder domain.Order
+	decode(t, doRequest(container, "GET", "/api/users/carlos-santos/client-orders/remota-1", nil, nil), &keyedOrder)
+	if keyedOrder.ID != original.Order.ID {
+		t.Errorf("Esperada a ordem remota-1, obtido %+v", keyedOrder)
+	}
+
+	// Engine fora do ar: 503 em vez de rejeição
+	cancel()
+	engineServer.Close()
+	resp = doRequest(container, "POST", "/api/orders", map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 1, "price": 210.00,
+	}, nil)
+	decode(t, resp, &body)
+	if resp.Code != 503 || body.Code != "ENGINE_UNAVAILABLE" {
+		t.Errorf("Esperado 503 ENGINE_UNAVAILABLE, obtido %d %s", resp.Code, body.Code)
+	}
+}
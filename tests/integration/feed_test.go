@@ -0,0 +1,162 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/feed"
+	"trading/internal/services/engine/orderbook"
+)
+
+// feedLevels converte os níveis do livro do engine para os do feed
+func feedLevels(levels []orderbook.PriceLevel) []feed.Level {
+	result := make([]feed.Level, 0, len(levels))
+	for _, level := range levels {
+		result = append(result, feed.Level{Price: level.Price, Quantity: level.Quantity, Orders: level.Orders})
+	}
+	return result
+}
+
+// TestBinaryFeed verifica o feed UDP: reconstrução do livro, negociações,
+// recuperação de lacunas por retransmissão e entrada tardia por snapshot
+func TestBinaryFeed(t *testing.T) {
+	engine := newEngineProcess(t)
+
+	consumer, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Erro abrindo socket UDP: %v", err)
+	}
+	defer consumer.Close()
+
+	publisher, err := feed.NewPublisher(feed.Config{
+		Addr:              consumer.LocalAddr().String(),
+		Depth:             5,
+		Window:            8,
+		HeartbeatInterval: 20 * time.Millisecond,
+	}, engine.books)
+	if err != nil {
+		t.Fatalf("Erro criando publicador: %v", err)
+	}
+	defer publisher.Close()
+	engine.bus.Subscribe("feed", publisher.HandleEvent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go publisher.Run(ctx)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Erro abrindo porta: %v", err)
+	}
+	server := feed.NewServer(publisher)
+	go server.Serve(listener)
+	defer server.Close()
+
+	recovery, err := feed.Dial(listener.Addr().String(), 2*time.Second)
+	if err != nil {
+		t.Fatalf("Erro conectando ao serviço de snapshot: %v", err)
+	}
+	defer recovery.Close()
+
+	// Consumidor que perde a terceira mensagem e se recupera por retransmissão
+	book := feed.NewBook("AAPL")
+	var trades []*feed.Message
+	gaps := 0
+	apply := func(msg *feed.Message) {
+		t.Helper()
+		if err := book.Apply(msg); err != nil {
+			t.Fatalf("Erro aplicando mensagem %c %d: %v", msg.Type, msg.Seq, err)
+		}
+		if msg.Type == feed.TypeTrade {
+			trades = append(trades, msg)
+		}
+	}
+
+	// consume lê o feed até o livro do consumidor refletir o do engine
+	buf := make([]byte, 64*1024)
+	consume := func() {
+		t.Helper()
+		depth := engine.books.GetDepth("AAPL", 5)
+		consumer.SetReadDeadline(time.Now().Add(3 * time.Second))
+		for !reflect.DeepEqual(book.Bids(), feedLevels(depth.Bids)) || !reflect.DeepEqual(book.Asks(), feedLevels(depth.Asks)) {
+			n, _, err := consumer.ReadFrom(buf)
+			if err != nil {
+				t.Fatalf("Feed não convergiu (seq %d): %v", book.Seq, err)
+			}
+			msg, err := feed.Decode(append([]byte(nil), buf[:n]...))
+			if err != nil {
+				t.Fatalf("Datagrama inválido: %v", err)
+			}
+			if msg.Type == feed.TypeBookUpdate && msg.Seq == 3 {
+				continue
+			}
+
+			if err := book.Apply(msg); errors.Is(err, feed.ErrGap) {
+				gaps++
+				missing, err := recovery.Retransmit("AAPL", book.Seq+1, msg.Seq)
+				if err != nil {
+					t.Fatalf("Erro na retransmissão: %v", err)
+				}
+				for _, m := range missing {
+					apply(m)
+				}
+			} else if err != nil {
+				t.Fatalf("Erro aplicando mensagem: %v", err)
+			} else if msg.Type == feed.TypeTrade {
+				trades = append(trades, msg)
+			}
+		}
+	}
+
+	// 9 mensagens de AAPL: 5 níveis, 1 negociação, 1 alteração e mais 2 níveis
+	// (o sexto bid fica fora da profundidade publicada)
+	for _, order := range []*domain.Order{
+		domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 5, 210),
+		domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 3, 211),
+		domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 2, 209),
+		domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 1, 208),
+		domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 1, 207),
+		domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 4, 210),
+		domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 1, 206),
+		domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 1, 205),
+		domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 1, 204),
+	} {
+		if result := engine.matcher.ProcessOrder(context.Background(), order); result.Rejected {
+			t.Fatalf("Ordem rejeitada: %v", result.Err)
+		}
+		consume()
+	}
+
+	if book.Seq != 9 {
+		t.Errorf("Esperada sequência 9, obtido %d", book.Seq)
+	}
+	if gaps == 0 {
+		t.Error("Lacuna não foi detectada")
+	}
+	if len(trades) != 1 || trades[0].Level.Price != 210 || trades[0].Level.Quantity != 4 {
+		t.Errorf("Esperada negociação de 4 a 210, obtido %+v", trades)
+	}
+
+	// Consumidor tardio: snapshot com a sequência a partir da qual aplicar o feed
+	snapshot, err := recovery.Snapshot("AAPL")
+	if err != nil {
+		t.Fatalf("Erro pedindo snapshot: %v", err)
+	}
+	late := feed.NewBook("AAPL")
+	if err := late.Apply(snapshot); err != nil {
+		t.Fatalf("Erro aplicando snapshot: %v", err)
+	}
+	if late.Seq != 9 || !reflect.DeepEqual(late.Bids(), book.Bids()) || !reflect.DeepEqual(late.Asks(), book.Asks()) {
+		t.Errorf("Snapshot divergente do feed: seq %d bids %+v asks %+v", late.Seq, late.Bids(), late.Asks())
+	}
+
+	// A janela guarda 8 mensagens: a primeira já não pode ser retransmitida
+	if _, err := recovery.Retransmit("AAPL", 1, 2); !errors.Is(err, feed.ErrOutOfWindow) {
+		t.Errorf("Esperado ErrOutOfWindow, obtido %v", err)
+	}
+}
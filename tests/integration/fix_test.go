@@ -0,0 +1,376 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/repository"
+	"trading/internal/services/fix"
+)
+
+// fixClient é um iniciador FIX mínimo para os testes
+type fixClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+	compID string
+	seq    int
+}
+
+// testFIXPassword é a senha (554) de todos os clientes FIX dos testes
+const testFIXPassword = "senha-fix"
+
+// fixClients monta a configuração de clientes com as contas de cada CompID
+func fixClients(accounts map[string][]string) map[string]fix.Client {
+	clients := make(map[string]fix.Client, len(accounts))
+	for compID, list := range accounts {
+		clients[compID] = fix.Client{Password: testFIXPassword, Accounts: list}
+	}
+	return clients
+}
+
+// newFIXGateway sobe o acceptor sobre um engine novo e retorna o endereço
+func newFIXGateway(t *testing.T, accounts map[string][]string) (*engineProcess, string) {
+	t.Helper()
+
+	engine := newEngineProcess(t)
+	gateway := fix.NewAcceptor(fix.Config{CompID: "TRADING", Clients: fixClients(accounts)}, engine.matcher, engine.portfolios, newWebValidator(t))
+	engine.bus.Subscribe("fix", gateway.HandleEvent)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Erro abrindo porta: %v", err)
+	}
+	go gateway.Serve(listener)
+	t.Cleanup(func() { gateway.Close() })
+
+	return engine, listener.Addr().String()
+}
+
+// dialFIX conecta como compID, continuando a sequência a partir de seq
+func dialFIX(t *testing.T, addr, compID string, seq int) *fixClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Erro conectando ao gateway FIX: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &fixClient{t: t, conn: conn, reader: bufio.NewReader(conn), compID: compID, seq: seq}
+}
+
+// send envia a mensagem com o próximo MsgSeqNum; tags e valores alternados
+func (c *fixClient) send(msgType string, fields ...string) {
+	c.t.Helper()
+	c.seq++
+	c.sendSeq(c.seq, msgType, fields...)
+}
+
+// sendSeq envia a mensagem com o MsgSeqNum informado
+func (c *fixClient) sendSeq(seq int, msgType string, fields ...string) {
+	c.t.Helper()
+
+	msg := fix.NewMessage(msgType).
+		Set(fix.TagSenderCompID, c.compID).
+		Set(fix.TagTargetCompID, "TRADING").
+		SetInt(fix.TagMsgSeqNum, seq).
+		SetTime(fix.TagSendingTime, time.Now())
+	for i := 0; i+1 < len(fields); i += 2 {
+		tag, _ := strconv.Atoi(fields[i])
+		msg.Set(tag, fields[i+1])
+	}
+	if _, err := c.conn.Write(msg.Bytes()); err != nil {
+		c.t.Fatalf("Erro enviando %s: %v", msgType, err)
+	}
+}
+
+// expect lê mensagens, ignorando heartbeats, até receber o tipo esperado
+func (c *fixClient) expect(msgType string) *fix.Message {
+	c.t.Helper()
+
+	c.conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	for {
+		msg, err := fix.ReadMessage(c.reader)
+		if err != nil {
+			c.t.Fatalf("Esperada mensagem %s, erro: %v", msgType, err)
+		}
+		if msg.Type() == msgType {
+			return msg
+		}
+		if msg.Type() != fix.MsgHeartbeat {
+			c.t.Fatalf("Esperada mensagem %s, recebido %s", msgType, msg)
+		}
+	}
+}
+
+// expectReport lê um ExecutionReport e confere ClOrdID, ExecType e OrdStatus
+func (c *fixClient) expectReport(clOrdID, execType, ordStatus string) *fix.Message {
+	c.t.Helper()
+
+	report := c.expect(fix.MsgExecutionReport)
+	if report.Get(fix.TagClOrdID) != clOrdID || report.Get(fix.TagExecType) != execType || report.Get(fix.TagOrdStatus) != ordStatus {
+		c.t.Fatalf("Esperado relatório %s 150=%s 39=%s, recebido %s", clOrdID, execType, ordStatus, report)
+	}
+	return report
+}
+
+// newOrder envia um NewOrderSingle limitado
+func (c *fixClient) newOrder(clOrdID, account, side string, quantity int, price string) {
+	c.t.Helper()
+	c.send(fix.MsgNewOrderSingle, "11", clOrdID, "1", account, "55", "AAPL", "54", side,
+		"38", strconv.Itoa(quantity), "40", "2", "44", price, "60", time.Now().UTC().Format("20060102-15:04:05"))
+}
+
+// TestFIXGateway verifica a sessão FIX e o ciclo de vida das ordens:
+// aceite, execução agressora e passiva, alteração, cancelamento e rejeições
+func TestFIXGateway(t *testing.T) {
+	engine, addr := newFIXGateway(t, map[string][]string{
+		"INST1": {"carlos-santos", "beatriz-costa"},
+	})
+
+	client := dialFIX(t, addr, "INST1", 0)
+	client.send(fix.MsgLogon, "98", "0", "108", "30", "141", "Y", "554", testFIXPassword)
+	if logon := client.expect(fix.MsgLogon); logon.Get(fix.TagHeartBtInt) != "30" || logon.SeqNum() != 1 {
+		t.Fatalf("Logon inesperado: %s", logon)
+	}
+
+	// Aceite e execução contra a ordem em repouso
+	client.newOrder("S1", "carlos-santos", "2", 5, "210")
+	sellAck := client.expectReport("S1", "0", "0")
+
+	client.newOrder("B1", "beatriz-costa", "1", 3, "210")
+	client.expectReport("B1", "0", "0")
+	fill := client.expectReport("B1", "F", "2")
+	if fill.Get(fix.TagLastQty) != "3" || fill.Get(fix.TagLastPx) != "210" || fill.Get(fix.TagAvgPx) != "210" || fill.Get(fix.TagLeavesQty) != "0" {
+		t.Errorf("Execução agressora inesperada: %s", fill)
+	}
+	passive := client.expectReport("S1", "F", "1")
+	if passive.Get(fix.TagCumQty) != "3" || passive.Get(fix.TagLeavesQty) != "2" || passive.Get(fix.TagOrderID) != sellAck.Get(fix.TagOrderID) {
+		t.Errorf("Execução passiva inesperada: %s", passive)
+	}
+
+	// Alteração: novo ClOrdID, mesma ordem, quantidade executada preservada
+	client.send(fix.MsgOrderCancelReplace, "11", "S2", "41", "S1", "1", "carlos-santos", "55", "AAPL", "54", "2", "38", "4", "40", "2", "44", "211")
+	replaced := client.expectReport("S2", "5", "1")
+	if replaced.Get(fix.TagOrigClOrdID) != "S1" || replaced.Get(fix.TagOrderQty) != "4" || replaced.Get(fix.TagLeavesQty) != "1" {
+		t.Errorf("Alteração inesperada: %s", replaced)
+	}
+
+	// Cancelamento e cancelamento tardio
+	client.send(fix.MsgOrderCancelRequest, "11", "C1", "41", "S2", "55", "AAPL", "54", "2")
+	if cancelled := client.expectReport("C1", "4", "4"); cancelled.Get(fix.TagOrigClOrdID) != "S2" || cancelled.Get(fix.TagLeavesQty) != "0" {
+		t.Errorf("Cancelamento inesperado: %s", cancelled)
+	}
+	client.send(fix.MsgOrderCancelRequest, "11", "C2", "41", "S2", "55", "AAPL", "54", "2")
+	if reject := client.expect(fix.MsgOrderCancelReject); reject.Get(fix.TagCxlRejReason) != "0" || reject.Get(fix.TagOrdStatus) != "4" || reject.Get(fix.TagCxlRejResponseTo) != "1" {
+		t.Errorf("Esperado cancelamento tardio (102=0), recebido %s", reject)
+	}
+	client.send(fix.MsgOrderCancelRequest, "11", "C3", "41", "NAO-EXISTE", "55", "AAPL", "54", "2")
+	if reject := client.expect(fix.MsgOrderCancelReject); reject.Get(fix.TagCxlRejReason) != "1" || reject.Get(fix.TagOrderID) != "NONE" {
+		t.Errorf("Esperada ordem desconhecida (102=1), recebido %s", reject)
+	}
+
+	// Rejeições mapeadas dos erros de domínio
+	rejections := []struct {
+		clOrdID, account string
+		quantity         int
+		ordRejReason     string
+		code             string
+	}{
+		{"R1", "carlos-santos", 100000, "99", "INSUFFICIENT_POSITION"},
+		{"R2", "ana-silva", 1, "15", "ACCOUNT_NOT_ALLOWED"},
+		{"B1", "beatriz-costa", 1, "6", "DUPLICATE_CL_ORD_ID"},
+	}
+	for _, tc := range rejections {
+		client.newOrder(tc.clOrdID, tc.account, "2", tc.quantity, "210")
+		report := client.expectReport(tc.clOrdID, "8", "8")
+		if report.Get(fix.TagOrdRejReason) != tc.ordRejReason || !strings.HasPrefix(report.Get(fix.TagText), tc.code) {
+			t.Errorf("%s: esperado 103=%s %s, recebido %s", tc.clOrdID, tc.ordRejReason, tc.code, report)
+		}
+	}
+
+	client.send(fix.MsgNewOrderSingle, "11", "M1", "1", "beatriz-costa", "55", "AAPL", "54", "1", "38", "1", "40", "1")
+	if report := client.expectReport("M1", "8", "8"); report.Get(fix.TagOrdRejReason) != "11" {
+		t.Errorf("Esperada ordem a mercado rejeitada (103=11), recebido %s", report)
+	}
+
+	// Tag obrigatória ausente: Reject de sessão
+	client.send(fix.MsgNewOrderSingle, "11", "X1", "1", "beatriz-costa", "54", "1", "38", "1", "40", "2", "44", "210")
+	if reject := client.expect(fix.MsgReject); reject.Get(fix.TagRefTagID) != "55" || reject.Get(fix.TagSessionRejectReason) != "1" {
+		t.Errorf("Esperado Reject por tag 55 ausente, recebido %s", reject)
+	}
+
+	// TestRequest
+	client.send(fix.MsgTestRequest, "112", "PING")
+	if heartbeat := client.expect(fix.MsgHeartbeat); heartbeat.Get(fix.TagTestReqID) != "PING" {
+		t.Errorf("Heartbeat sem TestReqID: %s", heartbeat)
+	}
+
+	// Lacuna na sequência do cliente: ResendRequest e preenchimento com GapFill
+	expected := client.seq + 1
+	client.sendSeq(expected+3, fix.MsgHeartbeat)
+	if resend := client.expect(fix.MsgResendRequest); resend.Get(fix.TagBeginSeqNo) != strconv.Itoa(expected) || resend.Get(fix.TagEndSeqNo) != "0" {
+		t.Errorf("ResendRequest inesperado: %s", resend)
+	}
+	client.sendSeq(expected, fix.MsgSequenceReset, "43", "Y", "123", "Y", "36", strconv.Itoa(expected+4))
+	client.seq = expected + 3
+	client.send(fix.MsgTestRequest, "112", "AFTER-GAP")
+	if heartbeat := client.expect(fix.MsgHeartbeat); heartbeat.Get(fix.TagTestReqID) != "AFTER-GAP" {
+		t.Errorf("Sessão não retomou após GapFill: %s", heartbeat)
+	}
+
+	// Reenvio a pedido do cliente: Logon vira GapFill, relatórios vão com PossDupFlag
+	client.send(fix.MsgResendRequest, "7", "1", "16", "3")
+	if gap := client.expect(fix.MsgSequenceReset); gap.SeqNum() != 1 || gap.Get(fix.TagNewSeqNo) != "2" || !gap.Flag(fix.TagGapFillFlag) {
+		t.Errorf("GapFill inesperado: %s", gap)
+	}
+	for seq := 2; seq <= 3; seq++ {
+		dup := client.expect(fix.MsgExecutionReport)
+		if dup.SeqNum() != seq || !dup.Flag(fix.TagPossDupFlag) || dup.Get(fix.TagOrigSendingTime) == "" {
+			t.Errorf("Reenvio %d inesperado: %s", seq, dup)
+		}
+	}
+
+	// Ordem em repouso executada com o cliente desconectado
+	client.newOrder("S9", "carlos-santos", "2", 1, "212")
+	client.expectReport("S9", "0", "0")
+	client.send(fix.MsgLogout)
+	client.expect(fix.MsgLogout)
+	client.conn.Close()
+
+	engine.matcher.ProcessOrder(context.Background(), domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 1, 212))
+
+	// Logon com sequência antiga é recusado
+	stale := dialFIX(t, addr, "INST1", 0)
+	stale.send(fix.MsgLogon, "98", "0", "108", "30", "554", testFIXPassword)
+	if logout := stale.expect(fix.MsgLogout); !strings.Contains(logout.Get(fix.TagText), "MsgSeqNum too low") {
+		t.Errorf("Esperado Logout por sequência baixa, recebido %s", logout)
+	}
+	stale.conn.Close()
+
+	// Reconexão: a execução gerada offline chega pelo reenvio
+	client = dialFIX(t, addr, "INST1", client.seq)
+	client.send(fix.MsgLogon, "98", "0", "108", "30", "554", testFIXPassword)
+	logon := client.expect(fix.MsgLogon)
+	client.send(fix.MsgResendRequest, "7", strconv.Itoa(passive.SeqNum()+1), "16", "0")
+	for deadline := time.Now().Add(3 * time.Second); ; {
+		if time.Now().After(deadline) {
+			t.Fatal("Execução offline não foi reenviada")
+		}
+		msg, err := fix.ReadMessage(client.reader)
+		if err != nil {
+			t.Fatalf("Erro lendo reenvio: %v", err)
+		}
+		if msg.Type() == fix.MsgExecutionReport && msg.Get(fix.TagClOrdID) == "S9" && msg.Get(fix.TagExecType) == "F" {
+			if msg.SeqNum() >= logon.SeqNum() || !msg.Flag(fix.TagPossDupFlag) {
+				t.Errorf("Execução offline deveria vir como reenvio anterior ao Logon: %s", msg)
+			}
+			break
+		}
+	}
+}
+
+// TestFIXLogonAuthentication verifica que só clientes configurados, com a
+// senha correta, abrem sessão
+func TestFIXLogonAuthentication(t *testing.T) {
+	_, addr := newFIXGateway(t, map[string][]string{"INST1": {"carlos-santos"}})
+
+	attempts := map[string][]string{
+		"senha errada":         {"INST1", "554", "outra-senha"},
+		"sem senha":            {"INST1"},
+		"cliente desconhecido": {"INST9", "554", testFIXPassword},
+	}
+	for name, attempt := range attempts {
+		client := dialFIX(t, addr, attempt[0], 0)
+		client.send(fix.MsgLogon, append([]string{"98", "0", "108", "30"}, attempt[1:]...)...)
+		if logout := client.expect(fix.MsgLogout); !strings.Contains(logout.Get(fix.TagText), "não autorizado") {
+			t.Errorf("%s: esperado Logout de cliente não autorizado, recebido %s", name, logout)
+		}
+		client.conn.Close()
+	}
+}
+
+// TestFIXHeartbeatTimeout verifica o TestRequest e a desconexão de um
+// cliente que para de responder
+func TestFIXHeartbeatTimeout(t *testing.T) {
+	_, addr := newFIXGateway(t, map[string][]string{"SILENT": {"carlos-santos"}})
+
+	client := dialFIX(t, addr, "SILENT", 0)
+	client.send(fix.MsgLogon, "98", "0", "108", "1", "141", "Y", "554", testFIXPassword)
+	client.expect(fix.MsgLogon)
+
+	if testRequest := client.expect(fix.MsgTestRequest); testRequest.Get(fix.TagTestReqID) == "" {
+		t.Errorf("TestRequest sem TestReqID: %s", testRequest)
+	}
+
+	client.conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	for {
+		msg, err := fix.ReadMessage(client.reader)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				t.Fatal("Gateway não desconectou o cliente silencioso")
+			}
+			return
+		}
+		if msg.Type() != fix.MsgHeartbeat {
+			t.Fatalf("Mensagem inesperada: %s", msg)
+		}
+	}
+}
+
+// TestFIXClOrdIDReachesEngine verifica que o ClOrdID vira o client_order_id
+// da ordem: consultável no engine e deduplicado mesmo após reinício do gateway
+func TestFIXClOrdIDReachesEngine(t *testing.T) {
+	engine, addr := newFIXGateway(t, map[string][]string{"INST1": {"carlos-santos"}})
+
+	client := dialFIX(t, addr, "INST1", 0)
+	client.send(fix.MsgLogon, "98", "0", "108", "30", "141", "Y", "554", testFIXPassword)
+	client.expect(fix.MsgLogon)
+
+	client.newOrder("K1", "carlos-santos", "2", 5, "212")
+	ack := client.expectReport("K1", "0", "0")
+	orders, err := engine.matcher.ListOrders(repository.OrderFilter{UserID: "carlos-santos", ClientOrderID: "K1"})
+	if err != nil || len(orders) != 1 || orders[0].ID != ack.Get(fix.TagOrderID) {
+		t.Fatalf("Esperada a ordem K1 no engine, obtido %+v (%v)", orders, err)
+	}
+
+	client.newOrder("com espaço", "carlos-santos", "2", 1, "212")
+	if report := client.expectReport("com espaço", "8", "8"); !strings.HasPrefix(report.Get(fix.TagText), "INVALID_CLIENT_ORDER_ID") {
+		t.Errorf("Esperado INVALID_CLIENT_ORDER_ID, recebido %s", report)
+	}
+
+	// Gateway reiniciado sobre o mesmo engine: a sessão nova não conhece K1,
+	// mas o engine devolve a ordem original em vez de criar outra
+	restarted := fix.NewAcceptor(fix.Config{CompID: "TRADING", Clients: fixClients(map[string][]string{"INST1": {"carlos-santos"}})}, engine.matcher, engine.portfolios, newWebValidator(t))
+	engine.bus.Subscribe("fix-restarted", restarted.HandleEvent)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Erro abrindo porta: %v", err)
+	}
+	go restarted.Serve(listener)
+	t.Cleanup(func() { restarted.Close() })
+
+	resent := dialFIX(t, listener.Addr().String(), "INST1", 0)
+	resent.send(fix.MsgLogon, "98", "0", "108", "30", "141", "Y", "554", testFIXPassword)
+	resent.expect(fix.MsgLogon)
+
+	resent.newOrder("K1", "carlos-santos", "2", 5, "212")
+	if report := resent.expectReport("K1", "8", "8"); report.Get(fix.TagOrdRejReason) != "6" || !strings.HasPrefix(report.Get(fix.TagText), "DUPLICATE_CL_ORD_ID") {
+		t.Errorf("Esperado ClOrdID duplicado (103=6), recebido %s", report)
+	}
+	if book := engine.books.GetOrderBook("AAPL"); len(book.Asks) != 1 {
+		t.Errorf("Reenvio não deveria entrar no livro: %+v", book.Asks)
+	}
+
+	// A sessão nova passa a acompanhar a ordem original
+	resent.send(fix.MsgOrderCancelRequest, "11", "K1-C", "41", "K1", "55", "AAPL", "54", "2")
+	if cancelled := resent.expectReport("K1-C", "4", "4"); cancelled.Get(fix.TagOrderID) != ack.Get(fix.TagOrderID) {
+		t.Errorf("Cancelamento deveria atingir a ordem original: %s", cancelled)
+	}
+}
@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/api"
+	"trading/internal/services/engine/api/enginepb"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/web/handlers"
+)
+
+// TestGRPCEngine verifica ordens, erros de domínio, prazos e market data pelo
+// canal gRPC entre web service e engine
+func TestGRPCEngine(t *testing.T) {
+	engine := newEngineProcess(t)
+
+	service := api.NewGRPCServer(engine.matcher, engine.books, engine.portfolios)
+	engine.bus.Subscribe("engine-grpc", service.HandleEvent)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Erro abrindo porta: %v", err)
+	}
+	grpcServer := grpc.NewServer(api.TokenInterceptors(testEngineToken)...)
+	service.Register(grpcServer)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	httpServer := httptest.NewServer(engine.newEngineServer().Container())
+	defer httpServer.Close()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()),
+		api.TokenCredentials(testEngineToken))
+	if err != nil {
+		t.Fatalf("Erro conectando: %v", err)
+	}
+	defer conn.Close()
+
+	// Sem o token de serviço, chamadas unárias e streams são recusados
+	for _, token := range []string{"", "outro-token"} {
+		intruder, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()),
+			api.TokenCredentials(token))
+		if err != nil {
+			t.Fatalf("Erro conectando: %v", err)
+		}
+		rpc := enginepb.NewEngineClient(intruder)
+		_, err = rpc.SubmitOrder(context.Background(), &enginepb.SubmitOrderRequest{UserId: "ana-silva", Symbol: "AAPL", Side: enginepb.Side_SIDE_BUY, Quantity: 1, Price: 210})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Token %q: esperado Unauthenticated em SubmitOrder, obtido %v", token, err)
+		}
+		if stream, err := rpc.SubscribeMarketData(context.Background(), &enginepb.SubscribeMarketDataRequest{Symbols: []string{"AAPL"}}); err == nil {
+			if _, err = stream.Recv(); status.Code(err) != codes.Unauthenticated {
+				t.Errorf("Token %q: esperado Unauthenticated no stream, obtido %v", token, err)
+			}
+		}
+		intruder.Close()
+	}
+
+	client := api.NewGRPCClient(conn, newEngineClient(httpServer.URL), time.Second)
+	container := newContainer(handlers.NewTradingHandler(client, client, client, newWebValidator(t)))
+
+	// Market data: livro atual primeiro, depois alterações e negociações em sequência
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := make(chan *enginepb.MarketDataUpdate, 16)
+	go client.SubscribeMarketData(ctx, []string{"aapl"}, 5, func(update *enginepb.MarketDataUpdate) { updates <- update })
+
+	nextUpdate := func() *enginepb.MarketDataUpdate {
+		t.Helper()
+		select {
+		case update := <-updates:
+			return update
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout aguardando market data")
+		}
+		return nil
+	}
+	if first := nextUpdate(); first.Seq != 1 || first.GetBook() == nil || first.Symbol != "AAPL" {
+		t.Fatalf("Esperado snapshot do livro com seq 1, obtido %v", first)
+	}
+
+	sell := postOrder(t, container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+	}, 201)
+	if book := nextUpdate(); book.Seq != 2 || len(book.GetBook().GetAsks()) != 1 {
+		t.Errorf("Esperado livro com a venda (seq 2), obtido %v", book)
+	}
+
+	buy := postOrder(t, container, map[string]interface{}{
+		"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 5, "price": 210.00,
+	}, 201)
+	if buy.Status != matching.StatusFilled || len(buy.Trades) != 1 {
+		t.Fatalf("Esperada execução total, obtido %+v", buy)
+	}
+	if trade := nextUpdate(); trade.Seq != 3 || trade.GetTrade().GetId() != buy.Trades[0].ID {
+		t.Errorf("Esperada negociação com seq 3, obtido %v", trade)
+	}
+
+	var portfolio struct {
+		Positions map[string]int `json:"positions"`
+	}
+	decode(t, doRequest(container, "GET", "/api/portfolio/beatriz-costa", nil, nil), &portfolio)
+	if portfolio.Positions["AAPL"] == 0 {
+		t.Errorf("Posição de AAPL não atualizada: %+v", portfolio.Positions)
+	}
+
+	// Rejeição de negócio volta no corpo, com o erro de domínio original
+	result := client.ProcessOrder(context.Background(), domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 10000, 210))
+	if !result.Rejected || result.Err != domain.ErrInsufficientPosition {
+		t.Errorf("Esperada rejeição por posição insuficiente, obtido %+v", result)
+	}
+
+	// Erros de domínio viram status gRPC e voltam a ser o mesmo erro no cliente
+	if _, err := client.CancelOrder(context.Background(), sell.Order.ID); err != domain.ErrOrderNotOpen {
+		t.Errorf("Esperado ErrOrderNotOpen, obtido %v", err)
+	}
+	_, err = enginepb.NewEngineClient(conn).CancelOrder(context.Background(), &enginepb.CancelOrderRequest{OrderId: "ORD-inexistente"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Esperado NotFound, obtido %v", err)
+	}
+	_, err = enginepb.NewEngineClient(conn).SubmitOrder(context.Background(), &enginepb.SubmitOrderRequest{UserId: "carlos-santos", Symbol: "AAPL", Quantity: 1, Price: 210})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Esperado InvalidArgument para lado ausente, obtido %v", err)
+	}
+
+	// Reenvio com o mesmo client_order_id devolve o resultado original
+	keyed := &enginepb.SubmitOrderRequest{UserId: "carlos-santos", Symbol: "AAPL", Side: enginepb.Side_SIDE_SELL, Quantity: 10000, Price: 210, ClientOrderId: "grpc-1"}
+	first, err := enginepb.NewEngineClient(conn).SubmitOrder(context.Background(), keyed)
+	if err != nil || first.Replayed {
+		t.Fatalf("Esperada a primeira submissão, obtido %v (%v)", first, err)
+	}
+	again, err := enginepb.NewEngineClient(conn).SubmitOrder(context.Background(), keyed)
+	if err != nil || !again.Replayed || again.GetOrder().GetId() != first.GetOrder().GetId() || again.GetOrder().GetClientOrderId() != "grpc-1" {
+		t.Errorf("Esperado o resultado original, obtido %v (%v)", again, err)
+	}
+	keyed.Price = 211
+	if conflict, err := enginepb.NewEngineClient(conn).SubmitOrder(context.Background(), keyed); err != nil || conflict.ReasonCode != "CLIENT_ORDER_ID_CONFLICT" {
+		t.Errorf("Esperado CLIENT_ORDER_ID_CONFLICT, obtido %v (%v)", conflict, err)
+	}
+
+	// Prazo estourado: a ordem não é executada e a API responde 503
+	impatient := api.NewGRPCClient(conn, newEngineClient(httpServer.URL), time.Nanosecond)
+	if result := impatient.ProcessOrder(context.Background(), domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 1, 210)); !errors.Is(result.Err, api.ErrUnavailable) {
+		t.Errorf("Esperado ErrUnavailable com prazo estourado, obtido %+v", result)
+	}
+	if book := engine.books.GetOrderBook("AAPL"); len(book.Asks) != 0 {
+		t.Errorf("Ordem com prazo estourado não deveria entrar no livro: %+v", book.Asks)
+	}
+}
@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/shared/logging"
+	"trading/internal/services/web/handlers"
+)
+
+// logBuffer acumula as linhas de log escritas por várias goroutines
+type logBuffer struct {
+	buf   bytes.Buffer
+	mutex sync.Mutex
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+// entries retorna as linhas JSON com a mensagem informada
+func (b *logBuffer) entries(t *testing.T, msg string) []map[string]interface{} {
+	t.Helper()
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var found []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Linha de log não é JSON: %q", line)
+		}
+		if entry["msg"] == msg {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// captureLogs instala um logger JSON em debug como padrão durante o teste
+func captureLogs(t *testing.T) *logBuffer {
+	t.Helper()
+
+	out := &logBuffer{}
+	previous := slog.Default()
+	slog.SetDefault(logging.New(out, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return out
+}
+
+// TestRequestIDLogging verifica que o X-Request-ID recebido é devolvido na
+// resposta e aparece nos logs da requisição, do portfolio e do matching,
+// também quando o engine roda em outro processo
+func TestRequestIDLogging(t *testing.T) {
+	logs := captureLogs(t)
+	env := newTestEnv(t, marketOpen)
+
+	body := map[string]interface{}{"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00}
+	resp := doRequest(env.container, "POST", "/api/orders", body, map[string]string{"X-Request-ID": "req-teste"})
+	if resp.Code != 201 || resp.Header().Get("X-Request-ID") != "req-teste" {
+		t.Fatalf("Esperado 201 com X-Request-ID, obtido %d %q", resp.Code, resp.Header().Get("X-Request-ID"))
+	}
+
+	for _, msg := range []string{"requisição HTTP", "reserva criada", "ordem processada"} {
+		entries := logs.entries(t, msg)
+		if len(entries) != 1 || entries[0]["request_id"] != "req-teste" {
+			t.Errorf("%s: esperada uma linha com request_id req-teste, obtido %+v", msg, entries)
+		}
+	}
+	if entry := logs.entries(t, "ordem processada")[0]; entry["level"] != "INFO" || entry["symbol"] != "AAPL" || entry["status"] != string(matching.StatusPending) {
+		t.Errorf("Campos inesperados no log do matching: %+v", entry)
+	}
+
+	// Sem header, um ID é gerado e devolvido
+	resp = doRequest(env.container, "GET", "/api/health", nil, nil)
+	if id := resp.Header().Get("X-Request-ID"); !strings.HasPrefix(id, "req-") {
+		t.Errorf("Esperado request ID gerado, obtido %q", id)
+	}
+
+	// Engine remoto: o ID atravessa a chamada HTTP
+	engine := newEngineProcess(t)
+	engineServer := httptest.NewServer(engine.newEngineServer().Container())
+	defer engineServer.Close()
+
+	client := newEngineClient(engineServer.URL)
+	container := newContainer(handlers.NewTradingHandler(client, client, client, newWebValidator(t)))
+	resp = doRequest(container, "POST", "/api/orders", body, map[string]string{"X-Request-ID": "req-remoto"})
+	if resp.Code != 201 {
+		t.Fatalf("Esperado 201, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+
+	var remote []map[string]interface{}
+	for _, entry := range logs.entries(t, "ordem processada") {
+		if entry["request_id"] == "req-remoto" {
+			remote = append(remote, entry)
+		}
+	}
+	if len(remote) != 1 {
+		t.Errorf("Esperado log do engine remoto com req-remoto, obtido %+v", remote)
+	}
+}
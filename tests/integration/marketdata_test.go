@@ -0,0 +1,231 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"trading/internal/services/shared/marketdata"
+	"trading/internal/services/web/handlers"
+	"trading/internal/services/web/stream"
+)
+
+// readMessage lê a próxima mensagem do WebSocket
+func readMessage(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg map[string]interface{}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Erro lendo mensagem: %v", err)
+	}
+	return msg
+}
+
+// TestMarketDataWebSocket verifica snapshot, deltas sequenciais e trades
+func TestMarketDataWebSocket(t *testing.T) {
+	env := newTestEnv(t, marketOpen)
+	hub := stream.NewMarketDataHub(env.books, env.validator)
+	env.bus.Subscribe("marketdata", hub.HandleEvent)
+	env.handler.SetMarketData(hub)
+	env.container = newContainer(env.handler)
+
+	server := httptest.NewServer(env.container)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws/marketdata"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Erro conectando: %v", err)
+	}
+	defer conn.Close()
+
+	subscribe := func(channel string) {
+		_ = conn.WriteJSON(stream.Command{Action: "subscribe", Channel: channel, Symbol: "aapl"})
+		if msg := readMessage(t, conn); msg["type"] != stream.MessageSubscribed {
+			t.Fatalf("Esperado 'subscribed', obtido %v", msg)
+		}
+	}
+
+	subscribe(stream.ChannelDepth)
+	snapshot := readMessage(t, conn)
+	if snapshot["type"] != stream.MessageSnapshot || snapshot["symbol"] != "AAPL" {
+		t.Fatalf("Esperado snapshot de AAPL, obtido %v", snapshot)
+	}
+	subscribe(stream.ChannelTrades)
+
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+	}, 201)
+
+	delta := readMessage(t, conn)
+	if delta["type"] != stream.MessageDelta || delta["seq"] != float64(1) {
+		t.Fatalf("Esperado delta com seq 1, obtido %v", delta)
+	}
+
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 5, "price": 210.00,
+	}, 201)
+
+	trade := readMessage(t, conn)
+	if trade["type"] != stream.MessageTrade {
+		t.Fatalf("Esperado trade, obtido %v", trade)
+	}
+	raw, _ := json.Marshal(trade["data"])
+	if strings.Contains(string(raw), "carlos-santos") {
+		t.Errorf("Trade público não deveria expor usuários: %s", raw)
+	}
+
+	removal := readMessage(t, conn)
+	if removal["type"] != stream.MessageDelta || removal["seq"] != float64(2) {
+		t.Fatalf("Esperado delta com seq 2, obtido %v", removal)
+	}
+	asks := removal["data"].(map[string]interface{})["asks"].([]interface{})
+	if len(asks) != 1 || asks[0].(map[string]interface{})["quantity"] != float64(0) {
+		t.Errorf("Esperada remoção do nível 210, obtido %v", asks)
+	}
+}
+
+// TestMarketDataRequiresAuth verifica que o WebSocket passa pela autenticação,
+// pela permissão market:read e pelas origens configuradas, e que /metrics
+// exige stats:read
+func TestMarketDataRequiresAuth(t *testing.T) {
+	env := newAuthEnv(t)
+	hub := stream.NewMarketDataHub(env.books, env.validator)
+	origins := []string{"https://app.example.com"}
+	hub.SetAllowedOrigins(origins)
+	env.handler.SetAllowedOrigins(origins)
+	env.handler.SetMarketData(hub)
+	env.handler.SetMetrics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	env.container = newContainer(env.handler)
+
+	server := httptest.NewServer(env.container)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws/marketdata"
+
+	dial := func(headers map[string]string) int {
+		t.Helper()
+		header := http.Header{}
+		for key, value := range headers {
+			header.Set(key, value)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+		if err == nil {
+			conn.Close()
+			return resp.StatusCode
+		}
+		if resp == nil {
+			t.Fatalf("Erro conectando: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := dial(nil); status != http.StatusUnauthorized {
+		t.Errorf("Esperado 401 sem credenciais, obtido %d", status)
+	}
+	viewer := bearerFor(t, "ana-silva")
+	if status := dial(viewer); status != http.StatusSwitchingProtocols {
+		t.Errorf("Esperado upgrade com market:read, obtido %d", status)
+	}
+
+	viewer["Origin"] = "https://app.example.com"
+	if status := dial(viewer); status != http.StatusSwitchingProtocols {
+		t.Errorf("Esperado upgrade da origem configurada, obtido %d", status)
+	}
+	viewer["Origin"] = "https://evil.example.com"
+	if status := dial(viewer); status != http.StatusForbidden {
+		t.Errorf("Esperado 403 para origem não configurada, obtido %d", status)
+	}
+
+	// O CORS da API segue as mesmas origens
+	for origin, want := range map[string]string{"https://app.example.com": "https://app.example.com", "https://evil.example.com": ""} {
+		resp := doRequest(env.container, "GET", "/api/health", nil, map[string]string{"Origin": origin})
+		if got := resp.Header().Get("Access-Control-Allow-Origin"); got != want {
+			t.Errorf("CORS para %s: esperado %q, obtido %q", origin, want, got)
+		}
+	}
+
+	for _, tc := range []struct {
+		headers map[string]string
+		status  int
+	}{
+		{nil, http.StatusUnauthorized},
+		{bearerFor(t, "ana-silva"), http.StatusForbidden},
+		{bearerFor(t, "ana-silva", "risk"), http.StatusOK},
+	} {
+		if resp := doRequest(env.container, "GET", "/metrics", nil, tc.headers); resp.Code != tc.status {
+			t.Errorf("GET /metrics: esperado %d, obtido %d", tc.status, resp.Code)
+		}
+	}
+}
+
+// TestCandlesAndTicker verifica barras, ticker e avaliação de portfolio
+// alimentados pelas negociações do barramento
+func TestCandlesAndTicker(t *testing.T) {
+	env := newTestEnv(t, marketOpen)
+
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+	}, 201)
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 3, "price": 210.00,
+	}, 201)
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 2, "price": 210.00,
+	}, 201)
+
+	// As barras são agregadas de forma assíncrona a partir do barramento
+	var candles []marketdata.Candle
+	for deadline := time.Now().Add(2 * time.Second); ; {
+		decode(t, doRequest(env.container, "GET", "/api/market/aapl/candles?interval=1d", nil, nil), &candles)
+		if len(candles) == 1 && candles[0].Trades == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Esperada 1 barra diária com 2 negociações, obtido %+v", candles)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if bar := candles[0]; bar.Volume != 5 || bar.Open != 210 || bar.VWAP != 210 {
+		t.Errorf("Barra inesperada: %+v", bar)
+	}
+
+	// Ticker: último preço e melhor oferta restante no livro
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 4, "price": 212.50,
+	}, 201)
+	var ticker marketdata.Ticker
+	decode(t, doRequest(env.container, "GET", "/api/market/aapl/ticker", nil, nil), &ticker)
+	if ticker.LastPrice == nil || *ticker.LastPrice != 210 || ticker.BestAsk == nil || *ticker.BestAsk != 212.5 || ticker.AskSize != 4 || ticker.BestBid != nil {
+		t.Errorf("Ticker inesperado: %+v", ticker)
+	}
+
+	// Portfolio avaliado pelo último preço
+	var valuation handlers.PortfolioValuation
+	decode(t, doRequest(env.container, "GET", "/api/portfolio/beatriz-costa", nil, nil), &valuation)
+	if valuation.Prices["AAPL"] != 210 || valuation.TotalValue != valuation.Cash+valuation.MarketValue ||
+		valuation.MarketValue < float64(valuation.Positions["AAPL"])*210 {
+		t.Errorf("Avaliação inesperada: %+v", valuation)
+	}
+
+	decode(t, doRequest(env.container, "GET", "/api/market/AAPL/candles?from=2000-01-01&to=2000-01-02", nil, nil), &candles)
+	if len(candles) != 0 {
+		t.Errorf("Intervalo de 2000 deveria ser vazio, obtido %+v", candles)
+	}
+
+	for path, want := range map[string]int{
+		"/api/market/AAPL/candles?interval=2m": 400,
+		"/api/market/XYZ/candles":              400,
+		"/api/market/AAPL/candles?from=ontem":  400,
+		"/api/market/XYZ/ticker":               400,
+	} {
+		if resp := doRequest(env.container, "GET", path, nil, nil); resp.Code != want {
+			t.Errorf("%s: esperado status %d, obtido %d", path, want, resp.Code)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"trading/internal/services/shared/metrics"
+	"trading/internal/services/web/handlers"
+)
+
+// TestMetricsEndpoint verifica GET /metrics no formato de texto do Prometheus:
+// requisições por rota, métricas do engine e do runtime
+func TestMetricsEndpoint(t *testing.T) {
+	env := newTestEnv(t, marketOpen)
+
+	registry := metrics.NewRegistry()
+	httpMetrics := metrics.NewHTTPMetrics()
+	env.handler.SetHTTPMetrics(httpMetrics)
+	registry.Register(httpMetrics, metrics.RuntimeCollector(),
+		metrics.EngineCollector(env.matcher, env.books, handlers.ErrorCode),
+		metrics.RejectionCollector("trading_validation_rejections_total", "Rejeições do validador.", env.validator.Rejections, handlers.ErrorCode))
+	env.handler.SetMetrics(registry)
+	container := newContainer(env.handler)
+
+	postOrder(t, container, map[string]interface{}{"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00}, 201)
+	postOrder(t, container, map[string]interface{}{"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 2, "price": 210.00}, 201)
+	postOrder(t, container, map[string]interface{}{"user_id": "ana-silva", "symbol": "AAPL", "side": "BUY", "quantity": 10, "price": 210.00}, 400)
+	postOrder(t, container, map[string]interface{}{"user_id": "ana-silva", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 1.00}, 400)
+	doRequest(container, "GET", "/api/orders/ORD-INEXISTENTE", nil, nil)
+
+	resp := doRequest(container, "GET", "/metrics", nil, nil)
+	if resp.Code != 200 || resp.Header().Get("Content-Type") != metrics.ContentType {
+		t.Fatalf("Esperado 200 em texto do Prometheus, obtido %d %s", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	body := resp.Body.String()
+
+	for _, line := range []string{
+		"# TYPE http_requests_total counter",
+		`http_requests_total{method="POST",route="/api/orders",code="201"} 2`,
+		`http_requests_total{method="POST",route="/api/orders",code="400"} 2`,
+		`http_requests_total{method="GET",route="/api/orders/{order_id}",code="404"} 1`,
+		`http_request_duration_seconds_count{method="POST",route="/api/orders"} 4`,
+		`http_request_duration_seconds_bucket{method="POST",route="/api/orders",le="+Inf"} 4`,
+		"trading_orders_received_total 3",
+		"trading_orders_accepted_total 2",
+		`trading_orders_rejected_total{reason="EXCEEDS_PROFILE_LIMIT"} 1`,
+		`trading_validation_rejections_total{reason="PRICE_TOO_LOW"} 1`,
+		`trading_trades_total{symbol="AAPL"} 1`,
+		`trading_trade_notional_total{symbol="AAPL"} 420`,
+		"trading_match_latency_seconds_count 2",
+		`trading_book_orders{symbol="AAPL",side="ask"} 1`,
+		`trading_book_quantity{symbol="AAPL",side="ask"} 3`,
+		`trading_book_levels{symbol="AAPL",side="bid"} 0`,
+		"# TYPE go_goroutines gauge",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Linha ausente: %s", line)
+		}
+	}
+	if t.Failed() {
+		t.Logf("Métricas:\n%s", body)
+	}
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"testing"
+
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/web/handlers"
+)
+
+// TestOrderBookDepth verifica a visão agregada e o acesso L3
+func TestOrderBookDepth(t *testing.T) {
+	container := newTestContainer(t)
+
+	orders := []map[string]interface{}{
+		{"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 5, "price": 205.00},
+		{"user_id": "larissa-campos", "symbol": "AAPL", "side": "BUY", "quantity": 7, "price": 205.00},
+		{"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 2, "price": 201.00},
+		{"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 4, "price": 210.00},
+	}
+	for _, order := range orders {
+		postOrder(t, container, order, 201)
+	}
+
+	t.Run("Aggregated", func(t *testing.T) {
+		var depth orderbook.Depth
+		decode(t, doRequest(container, "GET", "/api/orderbook/AAPL", nil, nil), &depth)
+
+		if len(depth.Bids) != 2 || depth.Bids[0].Quantity != 12 || depth.Bids[0].Orders != 2 {
+			t.Errorf("Níveis de bid inesperados: %+v", depth.Bids)
+		}
+		if depth.BestBid == nil || *depth.BestBid != 205 || depth.BestAsk == nil || *depth.BestAsk != 210 {
+			t.Fatalf("Melhores preços inesperados: bid=%v ask=%v", depth.BestBid, depth.BestAsk)
+		}
+		if *depth.Spread != 5 || *depth.MidPrice != 207.5 {
+			t.Errorf("Spread/mid inesperados: %v / %v", *depth.Spread, *depth.MidPrice)
+		}
+	})
+
+	t.Run("DepthLimit", func(t *testing.T) {
+		var depth orderbook.Depth
+		decode(t, doRequest(container, "GET", "/api/orderbook/AAPL?depth=1", nil, nil), &depth)
+		if len(depth.Bids) != 1 || len(depth.Asks) != 1 {
+			t.Errorf("Esperado 1 nível por lado, obtido %d bids e %d asks", len(depth.Bids), len(depth.Asks))
+		}
+
+		resp := doRequest(container, "GET", "/api/orderbook/AAPL?depth=0", nil, nil)
+		if resp.Code != 400 {
+			t.Errorf("Esperado status 400 para depth=0, obtido %d", resp.Code)
+		}
+	})
+
+	t.Run("L3RequiresAdmin", func(t *testing.T) {
+		resp := doRequest(container, "GET", "/api/orderbook/AAPL?level=3", nil, nil)
+		if resp.Code != 403 {
+			t.Fatalf("Esperado status 403, obtido %d", resp.Code)
+		}
+
+		var body handlers.ErrorResponse
+		decode(t, resp, &body)
+		if body.Code != "FORBIDDEN" {
+			t.Errorf("Esperado código 'FORBIDDEN', obtido '%s'", body.Code)
+		}
+
+		resp = doRequest(container, "GET", "/api/orderbook/AAPL?level=3", nil, map[string]string{
+			"X-Admin-Token": testAdminToken,
+		})
+		var book orderbook.OrderBook
+		decode(t, resp, &book)
+		if len(book.Bids) != 3 || book.Bids[0].UserID != "beatriz-costa" {
+			t.Errorf("Livro L3 inesperado: %+v", book.Bids)
+		}
+	})
+}
@@ -0,0 +1,313 @@
+package integration
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	restful "github.com/emicklei/go-restful/v3"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/web/handlers"
+)
+
+// postOrder envia uma ordem e decodifica o MatchResult
+func postOrder(t *testing.T, container *restful.Container, body map[string]interface{}, wantStatus int) matching.MatchResult {
+	t.Helper()
+
+	resp := doRequest(container, "POST", "/api/orders", body, nil)
+	if resp.Code != wantStatus {
+		t.Fatalf("Esperado status %d, obtido %d: %s", wantStatus, resp.Code, resp.Body.String())
+	}
+
+	var result matching.MatchResult
+	decode(t, resp, &result)
+	return result
+}
+
+// TestOrderScenarios cobre os 3 cenários essenciais do README
+func TestOrderScenarios(t *testing.T) {
+	// Cenário 1: ordem aceita e executada (matching funciona)
+	t.Run("AcceptedAndExecuted", func(t *testing.T) {
+		container := newTestContainer(t)
+
+		sell := postOrder(t, container, map[string]interface{}{
+			"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+		}, 201)
+		if sell.Status != matching.StatusPending {
+			t.Fatalf("Esperado venda pendente, obtido '%s'", sell.Status)
+		}
+
+		buy := postOrder(t, container, map[string]interface{}{
+			"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 5, "price": 215.00,
+		}, 201)
+		if buy.Status != matching.StatusFilled || len(buy.Trades) != 1 {
+			t.Fatalf("Esperado compra executada com 1 trade, obtido '%s' com %d", buy.Status, len(buy.Trades))
+		}
+
+		trade := buy.Trades[0]
+		if trade.Price != 210.00 || trade.Quantity != 5 || trade.SellerID != "carlos-santos" {
+			t.Errorf("Trade inesperado: %+v", trade)
+		}
+
+		var buyer domain.Portfolio
+		decode(t, doRequest(container, "GET", "/api/portfolio/beatriz-costa", nil, nil), &buyer)
+		if buyer.Cash != 100000-1050 || buyer.Positions["AAPL"] != 5 {
+			t.Errorf("Portfolio do comprador inesperado: cash=%.2f positions=%v", buyer.Cash, buyer.Positions)
+		}
+
+		var seller domain.Portfolio
+		decode(t, doRequest(container, "GET", "/api/portfolio/carlos-santos", nil, nil), &seller)
+		if seller.Cash != 25000+1050 || seller.Positions["AAPL"] != 95 {
+			t.Errorf("Portfolio do vendedor inesperado: cash=%.2f positions=%v", seller.Cash, seller.Positions)
+		}
+	})
+
+	// Cenário 2: ordem rejeitada (saldo insuficiente)
+	t.Run("RejectedInsufficientBalance", func(t *testing.T) {
+		container := newTestContainer(t)
+
+		result := postOrder(t, container, map[string]interface{}{
+			"user_id": "elena-rodriguez", "symbol": "AAPL", "side": "BUY", "quantity": 100000, "price": 200.00,
+		}, 400)
+		if !result.Rejected || result.Reason != "INSUFFICIENT_BALANCE" || result.Order.Status != domain.REJECTED {
+			t.Errorf("Esperada rejeição por saldo insuficiente, obtido %+v", result)
+		}
+	})
+
+	// Cenário 3: ordem aceita mas fica no book (sem match)
+	t.Run("AcceptedRestsInBook", func(t *testing.T) {
+		container := newTestContainer(t)
+
+		result := postOrder(t, container, map[string]interface{}{
+			"user_id": "beatriz-costa", "symbol": "MSFT", "side": "BUY", "quantity": 10, "price": 160.00,
+		}, 201)
+		if result.Status != matching.StatusPending || result.Message != "Ordem adicionada ao livro" {
+			t.Errorf("Esperado ordem pendente no livro, obtido %+v", result)
+		}
+
+		var book orderbook.Depth
+		decode(t, doRequest(container, "GET", "/api/orderbook/MSFT", nil, nil), &book)
+		if len(book.Bids) != 1 || book.Bids[0].Price != 160.00 || book.Bids[0].Quantity != 10 {
+			t.Errorf("Esperado 1 nível de bid com 10 @ 160, obtido %+v", book.Bids)
+		}
+	})
+}
+
+// TestOrderValidationPipeline verifica as demais regras do pipeline
+func TestOrderValidationPipeline(t *testing.T) {
+	container := newTestContainer(t)
+
+	cases := []struct {
+		name   string
+		body   map[string]interface{}
+		reason string
+	}{
+		{"UnknownUser", map[string]interface{}{"user_id": "ninguem", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 210.0}, "USER_NOT_FOUND"},
+		{"UnknownSymbol", map[string]interface{}{"user_id": "ana-silva", "symbol": "XXXX", "side": "BUY", "quantity": 1, "price": 210.0}, "INVALID_SYMBOL"},
+		{"PriceTooLow", map[string]interface{}{"user_id": "ana-silva", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 150.0}, "PRICE_TOO_LOW"},
+		{"InsufficientPosition", map[string]interface{}{"user_id": "ana-silva", "symbol": "AAPL", "side": "SELL", "quantity": 1, "price": 210.0}, "INSUFFICIENT_POSITION"},
+		{"ExceedsProfileLimit", map[string]interface{}{"user_id": "ana-silva", "symbol": "AAPL", "side": "BUY", "quantity": 3, "price": 210.0}, "EXCEEDS_PROFILE_LIMIT"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := postOrder(t, container, tc.body, 400)
+			if result.Reason != tc.reason {
+				t.Errorf("Esperado motivo '%s', obtido '%s'", tc.reason, result.Reason)
+			}
+		})
+	}
+
+	t.Run("MarketClosed", func(t *testing.T) {
+		sunday := time.Date(2025, 9, 21, 15, 0, 0, 0, time.UTC)
+		closed := newTestContainerAt(t, sunday)
+
+		result := postOrder(t, closed, map[string]interface{}{
+			"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 210.0,
+		}, 400)
+		if result.Reason != "MARKET_CLOSED" {
+			t.Errorf("Esperado motivo 'MARKET_CLOSED', obtido '%s'", result.Reason)
+		}
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		resp := doRequest(container, "POST", "/api/orders", map[string]interface{}{
+			"user_id": "ana-silva", "symbol": "AAPL", "side": "HOLD", "quantity": 1, "price": 210.0,
+		}, nil)
+		if resp.Code != 400 {
+			t.Fatalf("Esperado status 400, obtido %d", resp.Code)
+		}
+
+		var body handlers.ErrorResponse
+		decode(t, resp, &body)
+		if body.Code != "INVALID_ORDER_SIDE" {
+			t.Errorf("Esperado código 'INVALID_ORDER_SIDE', obtido '%s'", body.Code)
+		}
+	})
+}
+
+// TestAmendAndCancelOrder verifica PATCH e DELETE em /orders/{order_id}
+func TestAmendAndCancelOrder(t *testing.T) {
+	env := newTestEnv(t, marketOpen)
+
+	sell := postOrder(t, env.container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+	}, 201)
+	path := "/api/orders/" + sell.Order.ID
+
+	// Abaixo do preço mínimo: regra do símbolo se aplica à alteração
+	resp := doRequest(env.container, "PATCH", path, map[string]interface{}{"quantity": 5, "price": 1.00}, nil)
+	if resp.Code != 422 {
+		t.Errorf("Esperado status 422, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp = doRequest(env.container, "PATCH", path, map[string]interface{}{"quantity": 8, "price": 212.00}, nil)
+	if resp.Code != 200 {
+		t.Fatalf("Esperado status 200, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+	var amended matching.MatchResult
+	decode(t, resp, &amended)
+	if amended.Order.ID != sell.Order.ID || amended.Order.RemainingQuantity != 8 || amended.Order.Price != 212 {
+		t.Errorf("Ordem alterada inesperada: %+v", amended.Order)
+	}
+
+	resp = doRequest(env.container, "DELETE", path, nil, nil)
+	if resp.Code != 200 {
+		t.Fatalf("Esperado status 200, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+	var cancelled domain.Order
+	decode(t, resp, &cancelled)
+	if cancelled.Status != domain.CANCELLED {
+		t.Errorf("Esperado status CANCELLED, obtido %s", cancelled.Status)
+	}
+	if book := env.books.GetOrderBook("AAPL"); len(book.Asks) != 0 {
+		t.Errorf("Ordem cancelada ainda no livro: %+v", book.Asks)
+	}
+
+	resp = doRequest(env.container, "DELETE", path, nil, nil)
+	var body handlers.ErrorResponse
+	decode(t, resp, &body)
+	if resp.Code != 409 || body.Code != "ORDER_NOT_OPEN" {
+		t.Errorf("Esperado 409 ORDER_NOT_OPEN, obtido %d %s", resp.Code, body.Code)
+	}
+
+	resp = doRequest(env.container, "DELETE", "/api/orders/ORD-inexistente", nil, nil)
+	if resp.Code != 404 {
+		t.Errorf("Esperado status 404, obtido %d", resp.Code)
+	}
+}
+
+// TestOrderHistory verifica GET /orders/{order_id} e /users/{user_id}/orders
+func TestOrderHistory(t *testing.T) {
+	env := newTestEnv(t, marketOpen)
+
+	sell := postOrder(t, env.container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+	}, 201)
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 5, "price": 210.00,
+	}, 201)
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "GOOGL", "side": "SELL", "quantity": 2, "price": 150.00,
+	}, 201)
+
+	// A ordem executada saiu do livro mas continua consultável
+	resp := doRequest(env.container, "GET", "/api/orders/"+sell.Order.ID, nil, nil)
+	var order domain.Order
+	decode(t, resp, &order)
+	if resp.Code != 200 || order.Status != domain.FILLED {
+		t.Errorf("Esperada ordem FILLED, obtido %d %+v", resp.Code, order)
+	}
+
+	resp = doRequest(env.container, "GET", "/api/users/carlos-santos/orders?status=pending", nil, nil)
+	var orders []domain.Order
+	decode(t, resp, &orders)
+	if len(orders) != 1 || orders[0].Symbol != "GOOGL" {
+		t.Errorf("Esperada apenas a venda pendente de GOOGL, obtido %+v", orders)
+	}
+
+	// O histórico vem da mais recente para a mais antiga; limit traz as últimas
+	decode(t, doRequest(env.container, "GET", "/api/users/carlos-santos/orders?limit=1", nil, nil), &orders)
+	if len(orders) != 1 || orders[0].Symbol != "GOOGL" {
+		t.Errorf("Esperada a ordem mais recente (GOOGL), obtido %+v", orders)
+	}
+
+	for path, want := range map[string]int{
+		"/api/orders/ORD-inexistente":             404,
+		"/api/users/ninguem/orders":               404,
+		"/api/users/carlos-santos/orders?limit=0": 400,
+	} {
+		if resp := doRequest(env.container, "GET", path, nil, nil); resp.Code != want {
+			t.Errorf("%s: esperado status %d, obtido %d", path, want, resp.Code)
+		}
+	}
+}
+
+// TestIdempotentOrders verifica o reenvio com Idempotency-Key ou
+// client_order_id e a consulta e o cancelamento pelo client_order_id
+func TestIdempotentOrders(t *testing.T) {
+	env := newTestEnv(t, marketOpen)
+	sell := map[string]interface{}{"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00}
+	key := map[string]string{handlers.HeaderIdempotencyKey: "venda-aapl-1"}
+
+	resp := doRequest(env.container, "POST", "/api/orders", sell, key)
+	if resp.Code != 201 || resp.Header().Get(handlers.HeaderIdempotentReplayed) != "" {
+		t.Fatalf("Esperado 201 sem replay, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+	var first matching.MatchResult
+	decode(t, resp, &first)
+	if first.Order.ClientOrderID != "venda-aapl-1" {
+		t.Errorf("Esperado client_order_id na ordem, obtido %+v", first.Order)
+	}
+
+	// O mesmo client_order_id no corpo devolve a ordem original
+	retry := map[string]interface{}{"client_order_id": "venda-aapl-1"}
+	for k, v := range sell {
+		retry[k] = v
+	}
+	resp = doRequest(env.container, "POST", "/api/orders", retry, nil)
+	var replayed matching.MatchResult
+	decode(t, resp, &replayed)
+	if resp.Code != 201 || resp.Header().Get(handlers.HeaderIdempotentReplayed) != "true" ||
+		!replayed.Replayed || replayed.Order.ID != first.Order.ID {
+		t.Errorf("Esperado o resultado original, obtido %d %+v", resp.Code, replayed)
+	}
+	if book := env.books.GetOrderBook("AAPL"); len(book.Asks) != 1 {
+		t.Errorf("Reenvio não deveria entrar no livro: %+v", book.Asks)
+	}
+
+	expectError := func(resp *httptest.ResponseRecorder, status int, code string) {
+		t.Helper()
+		var body handlers.ErrorResponse
+		decode(t, resp, &body)
+		if resp.Code != status || body.Code != code {
+			t.Errorf("Esperado %d %s, obtido %d %+v", status, code, resp.Code, body)
+		}
+	}
+	retry["price"] = 211.00
+	expectError(doRequest(env.container, "POST", "/api/orders", retry, nil), 409, "CLIENT_ORDER_ID_CONFLICT")
+	expectError(doRequest(env.container, "POST", "/api/orders", retry, map[string]string{handlers.HeaderIdempotencyKey: "outra"}), 400, "INVALID_PARAMETER")
+	expectError(doRequest(env.container, "POST", "/api/orders", sell, map[string]string{handlers.HeaderIdempotencyKey: "com espaço"}), 400, "INVALID_CLIENT_ORDER_ID")
+
+	// Consulta e cancelamento pelo client_order_id
+	path := "/api/users/carlos-santos/client-orders/venda-aapl-1"
+	resp = doRequest(env.container, "GET", path, nil, nil)
+	var order domain.Order
+	decode(t, resp, &order)
+	if resp.Code != 200 || order.ID != first.Order.ID {
+		t.Errorf("Esperada a ordem original, obtido %d %+v", resp.Code, order)
+	}
+	resp = doRequest(env.container, "DELETE", path, nil, nil)
+	decode(t, resp, &order)
+	if resp.Code != 200 || order.Status != domain.CANCELLED {
+		t.Errorf("Esperado cancelamento, obtido %d %+v", resp.Code, order)
+	}
+	for _, missing := range []string{"/api/users/carlos-santos/client-orders/inexistente", "/api/users/beatriz-costa/client-orders/venda-aapl-1"} {
+		if resp := doRequest(env.container, "GET", missing, nil, nil); resp.Code != 404 {
+			t.Errorf("%s: esperado 404, obtido %d", missing, resp.Code)
+		}
+	}
+}
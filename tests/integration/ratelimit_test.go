@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+
+	"trading/internal/services/web/handlers"
+	"trading/internal/services/web/ratelimit"
+)
+
+// newRateLimitedContainer habilita o limite com relógio controlado pelo teste
+func newRateLimitedContainer(t *testing.T, env *testEnv, ip ratelimit.Limit, now *time.Time) *restful.Container {
+	t.Helper()
+
+	profiles, err := ratelimit.LoadProfiles(dataDir + "/users.json")
+	if err != nil {
+		t.Fatalf("Erro ao carregar perfis: %v", err)
+	}
+	limiter := ratelimit.NewLimiter()
+	limiter.SetClock(func() time.Time { return *now })
+	env.handler.SetRateLimiter(limiter, ratelimit.Config{IP: ip, User: ratelimit.DefaultUserLimit, Profiles: profiles})
+	return newContainer(env.handler)
+}
+
+// postFrom envia POST /orders a partir do endereço informado
+func postFrom(container *restful.Container, remoteAddr string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/api/orders", bytes.NewReader(payload))
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp := httptest.NewRecorder()
+	container.ServeHTTP(resp, req)
+	return resp
+}
+
+// TestRateLimitByUserProfile verifica o balde por usuário com o limite do
+// perfil, os headers em toda resposta e o 429 com Retry-After
+func TestRateLimitByUserProfile(t *testing.T) {
+	now := time.Now()
+	env := newAuthEnv(t)
+	container := newRateLimitedContainer(t, env, ratelimit.Limit{}, &now)
+	ana := bearerFor(t, "ana-silva", "trader")
+	order := map[string]interface{}{"user_id": "ana-silva", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 210.00}
+
+	// conservador: rajada 5 a 2 por segundo
+	for i := 0; i < 5; i++ {
+		resp := doRequest(container, "POST", "/api/orders", order, ana)
+		if resp.Code != 201 {
+			t.Fatalf("Ordem %d: esperado 201, obtido %d: %s", i, resp.Code, resp.Body.String())
+		}
+		if resp.Header().Get(handlers.HeaderRateLimit) != "5" || resp.Header().Get(handlers.HeaderRateRemaining) != strconv.Itoa(4-i) {
+			t.Errorf("Ordem %d: headers inesperados %v", i, resp.Header())
+		}
+	}
+
+	resp := doRequest(container, "POST", "/api/orders", order, ana)
+	var body handlers.ErrorResponse
+	decode(t, resp, &body)
+	if resp.Code != http.StatusTooManyRequests || body.Code != "RATE_LIMITED" {
+		t.Fatalf("Esperado 429 RATE_LIMITED, obtido %d %+v", resp.Code, body)
+	}
+	if resp.Header().Get("Retry-After") != "1" || resp.Header().Get(handlers.HeaderRateRemaining) != "0" || resp.Header().Get(handlers.HeaderRateReset) != "3" {
+		t.Errorf("Headers inesperados no 429: %v", resp.Header())
+	}
+
+	// O cancelamento usa o mesmo balde; outras rotas não são limitadas
+	if resp := doRequest(container, "DELETE", "/api/orders/inexistente", nil, ana); resp.Code != http.StatusTooManyRequests {
+		t.Errorf("Esperado 429 no cancelamento, obtido %d", resp.Code)
+	}
+	if resp := doRequest(container, "GET", "/api/portfolio/ana-silva", nil, ana); resp.Code != 200 || resp.Header().Get(handlers.HeaderRateLimit) != "" {
+		t.Errorf("Consulta não deveria ser limitada, obtido %d %v", resp.Code, resp.Header())
+	}
+
+	// premium tem o próprio balde, maior; erros de validação também consomem
+	elena := bearerFor(t, "elena-rodriguez", "trader")
+	invalid := map[string]interface{}{"user_id": "elena-rodriguez", "symbol": "AAPL", "side": "BUY", "quantity": 0, "price": 210.00}
+	resp = doRequest(container, "POST", "/api/orders", invalid, elena)
+	if resp.Code != 400 || resp.Header().Get(handlers.HeaderRateLimit) != "50" || resp.Header().Get(handlers.HeaderRateRemaining) != "49" {
+		t.Errorf("Esperado 400 com headers do perfil premium, obtido %d %v", resp.Code, resp.Header())
+	}
+
+	// Meio segundo repõe um token
+	now = now.Add(500 * time.Millisecond)
+	if resp := doRequest(container, "POST", "/api/orders", order, ana); resp.Code != 201 {
+		t.Errorf("Esperado 201 após reposição, obtido %d", resp.Code)
+	}
+}
+
+// TestRateLimitByIP verifica o balde por IP, aplicado também sem autenticação
+func TestRateLimitByIP(t *testing.T) {
+	now := time.Now()
+	env := newTestEnv(t, marketOpen)
+	container := newRateLimitedContainer(t, env, ratelimit.Limit{Rate: 1, Burst: 2}, &now)
+	order := map[string]interface{}{"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 1, "price": 210.00}
+
+	for i := 0; i < 2; i++ {
+		if resp := postFrom(container, "203.0.113.7:5000", order, nil); resp.Code != 201 {
+			t.Fatalf("Esperado 201, obtido %d: %s", resp.Code, resp.Body.String())
+		}
+	}
+	resp := postFrom(container, "203.0.113.7:5001", order, nil)
+	var body handlers.ErrorResponse
+	decode(t, resp, &body)
+	if resp.Code != http.StatusTooManyRequests || resp.Header().Get("Retry-After") != "1" || body.Details != "limite por IP excedido (1/s, rajada 2)" {
+		t.Errorf("Esperado 429 por IP, obtido %d %+v %v", resp.Code, body, resp.Header())
+	}
+
+	if resp := postFrom(container, "198.51.100.2:5000", order, nil); resp.Code != 201 {
+		t.Errorf("Outro IP deveria ter o próprio balde, obtido %d", resp.Code)
+	}
+}
+
+// TestRateLimitProfileAboveIP verifica que um usuário autenticado alcança o
+// limite do perfil mesmo acima do limite por IP
+func TestRateLimitProfileAboveIP(t *testing.T) {
+	now := time.Now()
+	env := newAuthEnv(t)
+	container := newRateLimitedContainer(t, env, ratelimit.Limit{Rate: 1, Burst: 2}, &now)
+	elena := bearerFor(t, "elena-rodriguez", "trader")
+	invalid := map[string]interface{}{"user_id": "elena-rodriguez", "symbol": "AAPL", "side": "BUY", "quantity": 0, "price": 210.00}
+
+	// premium: rajada 50, acima da rajada 2 do IP
+	for i := 0; i < 10; i++ {
+		resp := postFrom(container, "203.0.113.7:5000", invalid, elena)
+		if resp.Code != 400 || resp.Header().Get(handlers.HeaderRateLimit) != "50" || resp.Header().Get(handlers.HeaderRateRemaining) != strconv.Itoa(49-i) {
+			t.Fatalf("Ordem %d: esperado 400 com o limite premium, obtido %d %v", i, resp.Code, resp.Header())
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"trading/internal/services/engine/api"
+	"trading/internal/services/engine/snapshot"
+	"trading/internal/services/web/handlers"
+)
+
+// TestAdminSnapshots verifica gravação, listagem e restauração de snapshots
+func TestAdminSnapshots(t *testing.T) {
+	env := newTestEnv(t, marketOpen)
+
+	store, err := snapshot.NewStore(filepath.Join(t.TempDir(), "snapshots"), 0)
+	if err != nil {
+		t.Fatalf("Erro criando store: %v", err)
+	}
+	env.handler.SetSnapshots(snapshot.NewManager(env.matcher, store, nil))
+	container := newContainer(env.handler)
+	admin := map[string]string{"X-Admin-Token": testAdminToken}
+
+	if resp := doRequest(container, "POST", "/api/admin/snapshots", nil, nil); resp.Code != 403 {
+		t.Errorf("Esperado status 403 sem token, obtido %d", resp.Code)
+	}
+
+	resp := doRequest(container, "POST", "/api/admin/snapshots", nil, admin)
+	if resp.Code != 201 {
+		t.Fatalf("Esperado status 201, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+	var info snapshot.Info
+	decode(t, resp, &info)
+
+	postOrder(t, container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+	}, 201)
+
+	resp = doRequest(container, "GET", "/api/admin/snapshots", nil, admin)
+	var infos []snapshot.Info
+	decode(t, resp, &infos)
+	if len(infos) != 1 || infos[0].File != info.File {
+		t.Errorf("Listagem inesperada: %+v", infos)
+	}
+
+	// Sem journal, restaurar volta exatamente ao estado do snapshot
+	resp = doRequest(container, "POST", "/api/admin/snapshots/0/restore", nil, admin)
+	if resp.Code != 200 {
+		t.Fatalf("Esperado status 200, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+	if book := env.books.GetOrderBook("AAPL"); len(book.Asks) != 0 {
+		t.Errorf("Livro deveria voltar vazio: %+v", book.Asks)
+	}
+
+	resp = doRequest(container, "POST", "/api/admin/snapshots/99/restore", nil, admin)
+	var body handlers.ErrorResponse
+	decode(t, resp, &body)
+	if resp.Code != 404 || body.Code != "SNAPSHOT_NOT_FOUND" {
+		t.Errorf("Esperado 404 SNAPSHOT_NOT_FOUND, obtido %d %s", resp.Code, body.Code)
+	}
+}
+
+// TestEngineSnapshotsRequireAdmin verifica que, no engine separado, gravar e
+// restaurar snapshots exige o ADMIN_TOKEN além do token de serviço
+func TestEngineSnapshotsRequireAdmin(t *testing.T) {
+	engine := newEngineProcess(t)
+	store, err := snapshot.NewStore(filepath.Join(t.TempDir(), "snapshots"), 0)
+	if err != nil {
+		t.Fatalf("Erro criando store: %v", err)
+	}
+	server := api.NewServer(engine.matcher, engine.books, engine.portfolios, snapshot.NewManager(engine.matcher, store, nil))
+	server.SetToken(testEngineToken)
+	server.SetAdminToken(testAdminToken)
+	engineServer := httptest.NewServer(server.Container())
+	defer engineServer.Close()
+
+	// Só o token de serviço não basta
+	for _, path := range []string{"/engine/snapshots", "/engine/snapshots/0/restore"} {
+		req, _ := http.NewRequest("POST", engineServer.URL+path, nil)
+		req.Header.Set(api.HeaderServiceToken, testEngineToken)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Erro chamando o engine: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s: esperado 403 sem ADMIN_TOKEN, obtido %d", path, resp.StatusCode)
+		}
+	}
+	client := newEngineClient(engineServer.URL)
+	if _, err := client.Take(); err == nil {
+		t.Error("Snapshot sem ADMIN_TOKEN deveria falhar")
+	}
+
+	client.SetAdminToken(testAdminToken)
+	info, err := client.Take()
+	if err != nil {
+		t.Fatalf("Erro gravando snapshot: %v", err)
+	}
+	if _, _, err := client.Restore(info.Seq); err != nil {
+		t.Errorf("Erro restaurando snapshot: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"reflect"
+	"testing"
+
+	"trading/internal/services/web/handlers"
+)
+
+// TestStats verifica os contadores de GET /stats: ordens por desfecho e
+// motivo de rejeição, negociações por símbolo, latência e livros
+func TestStats(t *testing.T) {
+	env := newTestEnv(t, marketOpen)
+
+	for _, order := range []struct {
+		body   map[string]interface{}
+		status int
+	}{
+		{map[string]interface{}{"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00}, 201},
+		{map[string]interface{}{"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 5, "price": 210.00}, 201},
+		{map[string]interface{}{"user_id": "carlos-santos", "symbol": "MSFT", "side": "SELL", "quantity": 1, "price": 400.00}, 201},
+		// Limite do perfil (portfolio) e preço mínimo (validador de negócio)
+		{map[string]interface{}{"user_id": "ana-silva", "symbol": "AAPL", "side": "BUY", "quantity": 10, "price": 210.00}, 400},
+		{map[string]interface{}{"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 1.00}, 400},
+		// Dados inválidos e usuário inexistente, barrados na entrada
+		{map[string]interface{}{"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 0, "price": 210.00}, 400},
+		{map[string]interface{}{"user_id": "ninguem", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 210.00}, 400},
+	} {
+		postOrder(t, env.container, order.body, order.status)
+	}
+
+	// Alteração recusada pelas regras não é uma ordem recebida
+	resting := env.books.GetOrderBook("MSFT").Asks[0]
+	if resp := doRequest(env.container, "PATCH", "/api/orders/"+resting.ID, map[string]interface{}{"quantity": 1, "price": 1.00}, nil); resp.Code/100 != 4 {
+		t.Fatalf("Esperada alteração recusada, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+
+	resp := doRequest(env.container, "GET", "/api/stats", nil, nil)
+	if resp.Code != 200 {
+		t.Fatalf("Esperado status 200, obtido %d: %s", resp.Code, resp.Body.String())
+	}
+	var stats handlers.Stats
+	decode(t, resp, &stats)
+
+	orders := stats.Orders
+	wantReasons := map[string]uint64{"EXCEEDS_PROFILE_LIMIT": 1, "PRICE_TOO_LOW": 1, "INVALID_QUANTITY": 1, "USER_NOT_FOUND": 1}
+	if orders.Received != 7 || orders.Accepted != 3 || orders.Rejected != 4 || !reflect.DeepEqual(orders.RejectedByReason, wantReasons) {
+		t.Errorf("Contagem de ordens inesperada: %+v", orders)
+	}
+
+	trades := stats.Trades
+	if trades.Total != 1 || trades.Notional != 1050 || trades.BySymbol["AAPL"].Count != 1 || trades.BySymbol["AAPL"].Sum != 1050 {
+		t.Errorf("Negociações inesperadas: %+v", trades)
+	}
+
+	// Só as três ordens que chegaram ao matching têm latência medida
+	if stats.MatchLatency.Count != 3 || stats.MatchLatency.AvgMs <= 0 || stats.MatchLatency.P99Ms <= 0 {
+		t.Errorf("Latência inesperada: %+v", stats.MatchLatency)
+	}
+	if stats.RestingOrders["AAPL"] != 0 || stats.RestingOrders["MSFT"] != 1 {
+		t.Errorf("Ordens em repouso inesperadas: %+v", stats.RestingOrders)
+	}
+	if stats.ActiveUsers != 2 || stats.EventBus == nil {
+		t.Errorf("Esperados 2 usuários ativos e métricas do barramento: %+v", stats)
+	}
+}
@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"trading/internal/services/shared/tracing"
+	"trading/internal/services/web/handlers"
+)
+
+// traceFile instala um tracer que grava em arquivo durante o teste
+func traceFile(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	exporter, err := tracing.NewFileExporter(path)
+	if err != nil {
+		t.Fatalf("Erro ao criar exportador: %v", err)
+	}
+	tracing.SetDefault(tracing.NewTracer("trading-test", exporter))
+	t.Cleanup(func() {
+		tracing.SetDefault(nil)
+		exporter.Shutdown()
+	})
+	return path
+}
+
+// readSpans lê os spans gravados, indexados por trace
+func readSpans(t *testing.T, path string) map[string][]tracing.SpanData {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Erro ao abrir spans: %v", err)
+	}
+	defer file.Close()
+
+	traces := make(map[string][]tracing.SpanData)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var span tracing.SpanData
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("Span inválido: %v", err)
+		}
+		traces[span.TraceID] = append(traces[span.TraceID], span)
+	}
+	return traces
+}
+
+// spanNamed retorna o span do trace com o nome informado
+func spanNamed(t *testing.T, spans []tracing.SpanData, name string) tracing.SpanData {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("Span %s ausente em %+v", name, spans)
+	return tracing.SpanData{}
+}
+
+// TestOrderTracing verifica os spans de cada etapa de uma ordem executada,
+// com os IDs da ordem e do símbolo, e a continuação do trace no engine separado
+func TestOrderTracing(t *testing.T) {
+	path := traceFile(t)
+	env := newTestEnv(t, marketOpen)
+
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+	}, 201)
+	buy := postOrder(t, env.container, map[string]interface{}{
+		"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 5, "price": 210.00,
+	}, 201)
+
+	var spans []tracing.SpanData
+	for _, trace := range readSpans(t, path) {
+		for _, span := range trace {
+			if span.Name == "TradingHandler.CreateOrder" && span.Attributes["order.id"] == buy.Order.ID {
+				spans = trace
+			}
+		}
+	}
+	if len(spans) != 6 {
+		t.Fatalf("Esperados 6 spans no trace da compra, obtidos %+v", spans)
+	}
+
+	root := spanNamed(t, spans, "TradingHandler.CreateOrder")
+	process := spanNamed(t, spans, "matching.ProcessOrder")
+	parents := map[string]tracing.SpanData{
+		"BusinessValidator.ValidateOrder": root,
+		"portfolio.ValidateOrder":         root,
+		"matching.ProcessOrder":           root,
+		"portfolio.ReserveOrder":          process,
+		"portfolio.ExecuteTrade":          process,
+	}
+	for name, parent := range parents {
+		span := spanNamed(t, spans, name)
+		if span.ParentID != parent.SpanID || span.Attributes["order.symbol"] != "AAPL" {
+			t.Errorf("%s: esperado filho de %s com order.symbol, obtido %+v", name, parent.Name, span)
+		}
+		if span.Start.Before(root.Start) || span.End.After(root.End) {
+			t.Errorf("%s fora do intervalo da requisição", name)
+		}
+	}
+	if process.Attributes["order.id"] != buy.Order.ID || process.Attributes["order.status"] != "filled" {
+		t.Errorf("Atributos inesperados no matching: %+v", process.Attributes)
+	}
+	if trade := spanNamed(t, spans, "portfolio.ExecuteTrade"); trade.Attributes["trade.id"] != buy.Trades[0].ID {
+		t.Errorf("Atributos inesperados na liquidação: %+v", trade.Attributes)
+	}
+
+	// Rejeição pelo portfolio marca o span com o erro
+	rejected := postOrder(t, env.container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 10000, "price": 210.00,
+	}, 400)
+	found := false
+	for _, trace := range readSpans(t, path) {
+		if root := trace[len(trace)-1]; root.Attributes["order.id"] == rejected.Order.ID {
+			found = true
+			if validate := spanNamed(t, trace, "portfolio.ValidateOrder"); validate.Error == "" || root.Error == "" {
+				t.Errorf("Esperado erro nos spans da rejeição: %+v", trace)
+			}
+		}
+	}
+	if !found {
+		t.Error("Trace da ordem rejeitada não encontrado")
+	}
+
+	// Engine separado: os spans do engine continuam o trace do web service
+	engine := newEngineProcess(t)
+	engineServer := httptest.NewServer(engine.newEngineServer().Container())
+	defer engineServer.Close()
+
+	client := newEngineClient(engineServer.URL)
+	container := newContainer(handlers.NewTradingHandler(client, client, client, newWebValidator(t)))
+	before := readSpans(t, path)
+	postOrder(t, container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+	}, 201)
+
+	traces := readSpans(t, path)
+	if len(traces) != len(before)+1 {
+		t.Fatalf("Esperado um único trace novo, obtidos %d", len(traces)-len(before))
+	}
+	for id, trace := range traces {
+		if _, seen := before[id]; seen {
+			continue
+		}
+		root := spanNamed(t, trace, "TradingHandler.CreateOrder")
+		if span := spanNamed(t, trace, "matching.ProcessOrder"); span.ParentID != root.SpanID {
+			t.Errorf("matching.ProcessOrder remoto fora do trace: %+v", span)
+		}
+		spanNamed(t, trace, "portfolio.ValidateOrder")
+		spanNamed(t, trace, "portfolio.ReserveOrder")
+	}
+}
@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"trading/internal/domain"
+	"trading/internal/services/web/auth"
+	"trading/internal/services/web/handlers"
+)
+
+// TestTradeHistory verifica filtros, paginação por cursor, ordenação e o
+// blotter em CSV de GET /trades
+func TestTradeHistory(t *testing.T) {
+	env := newTestEnv(t, marketOpen)
+
+	// Três negociações: AAPL 5@210, GOOGL 2@150 e AAPL 1@211
+	for _, pair := range [][2]map[string]interface{}{
+		{{"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00},
+			{"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 5, "price": 210.00}},
+		{{"user_id": "carlos-santos", "symbol": "GOOGL", "side": "SELL", "quantity": 2, "price": 150.00},
+			{"user_id": "beatriz-costa", "symbol": "GOOGL", "side": "BUY", "quantity": 2, "price": 150.00}},
+		{{"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 1, "price": 211.00},
+			{"user_id": "ana-silva", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 211.00}},
+	} {
+		postOrder(t, env.container, pair[0], 201)
+		if result := postOrder(t, env.container, pair[1], 201); len(result.Trades) != 1 {
+			t.Fatalf("Esperada uma negociação, obtido %+v", result)
+		}
+	}
+	all := env.matcher.GetTrades()
+
+	list := func(path string) ([]domain.Trade, string) {
+		t.Helper()
+		resp := doRequest(env.container, "GET", path, nil, nil)
+		if resp.Code != 200 {
+			t.Fatalf("%s: esperado status 200, obtido %d: %s", path, resp.Code, resp.Body.String())
+		}
+		var trades []domain.Trade
+		decode(t, resp, &trades)
+		return trades, resp.Header().Get(handlers.HeaderNextCursor)
+	}
+
+	// Paginação: a última página não traz cursor
+	page, cursor := list("/api/trades?limit=2")
+	if len(page) != 2 || page[0].ID != all[0].ID || cursor == "" {
+		t.Fatalf("Primeira página inesperada: %+v (cursor %q)", page, cursor)
+	}
+	page, cursor = list("/api/trades?limit=2&cursor=" + cursor)
+	if len(page) != 1 || page[0].ID != all[2].ID || cursor != "" {
+		t.Errorf("Segunda página inesperada: %+v (cursor %q)", page, cursor)
+	}
+
+	if page, _ = list("/api/trades?sort=desc&limit=1"); len(page) != 1 || page[0].ID != all[2].ID {
+		t.Errorf("Esperada a negociação mais recente primeiro, obtido %+v", page)
+	}
+	if page, _ = list("/api/trades?symbol=aapl&min_value=500"); len(page) != 1 || page[0].ID != all[0].ID {
+		t.Errorf("Esperada apenas a negociação de 1050, obtido %+v", page)
+	}
+	if page, _ = list("/api/trades?user_id=ana-silva"); len(page) != 1 || page[0].BuyerID != "ana-silva" {
+		t.Errorf("Esperada apenas a compra de ana-silva, obtido %+v", page)
+	}
+	if page, _ = list("/api/trades?user_id=carlos-santos&from=2000-01-01&to=2000-01-02"); len(page) != 0 {
+		t.Errorf("Intervalo de 2000 deveria ser vazio, obtido %+v", page)
+	}
+
+	// Blotter em CSV
+	resp := doRequest(env.container, "GET", "/api/trades?symbol=AAPL", nil, map[string]string{"Accept": "text/csv"})
+	if resp.Code != 200 || !strings.HasPrefix(resp.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("Esperado CSV, obtido %d %s", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV inválido: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "id" || records[1][0] != all[0].ID || records[2][7] != "211" {
+		t.Errorf("Blotter inesperado: %v", records)
+	}
+
+	for path, want := range map[string]int{
+		"/api/trades?user_id=ninguem":               404,
+		"/api/trades?sort=price":                    400,
+		"/api/trades?limit=1001":                    400,
+		"/api/trades?min_value=-1":                  400,
+		"/api/trades?cursor=%21%21":                 400,
+		"/api/trades?from=ontem":                    400,
+		"/api/trades?from=2025-09-18&to=2025-09-17": 400,
+	} {
+		if resp := doRequest(env.container, "GET", path, nil, nil); resp.Code != want {
+			t.Errorf("%s: esperado status %d, obtido %d", path, want, resp.Code)
+		}
+	}
+}
+
+// TestTradeHistoryScopedToPrincipal verifica que, com autenticação, GET
+// /trades sem ?user_id= só lista as negociações do próprio principal, a menos
+// que ele tenha users:read
+func TestTradeHistoryScopedToPrincipal(t *testing.T) {
+	env := newAuthEnv(t)
+	admin := bearerFor(t, "operador", auth.RoleAdmin)
+
+	// Carlos vende para Beatriz e para Ana
+	for _, order := range []map[string]interface{}{
+		{"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 3, "price": 210.00},
+		{"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 2, "price": 210.00},
+		{"user_id": "ana-silva", "symbol": "AAPL", "side": "BUY", "quantity": 1, "price": 210.00},
+	} {
+		if resp := doRequest(env.container, "POST", "/api/orders", order, admin); resp.Code != 201 {
+			t.Fatalf("Esperado 201, obtido %d: %s", resp.Code, resp.Body.String())
+		}
+	}
+
+	list := func(headers map[string]string) []domain.Trade {
+		t.Helper()
+		resp := doRequest(env.container, "GET", "/api/trades", nil, headers)
+		if resp.Code != 200 {
+			t.Fatalf("Esperado 200, obtido %d: %s", resp.Code, resp.Body.String())
+		}
+		var trades []domain.Trade
+		decode(t, resp, &trades)
+		return trades
+	}
+
+	ana := bearerFor(t, "ana-silva", auth.RoleTrader)
+	if trades := list(ana); len(trades) != 1 || trades[0].BuyerID != "ana-silva" {
+		t.Errorf("Trader deveria ver só a própria negociação, obtido %+v", trades)
+	}
+	if trades := list(bearerFor(t, "analista", auth.RoleRisk)); len(trades) != 2 {
+		t.Errorf("Papel risk (users:read) deveria ver todas as negociações, obtido %+v", trades)
+	}
+
+	// O blotter em CSV segue o mesmo escopo
+	headers := map[string]string{"Accept": "text/csv"}
+	for key, value := range ana {
+		headers[key] = value
+	}
+	records, err := csv.NewReader(doRequest(env.container, "GET", "/api/trades", nil, headers).Body).ReadAll()
+	if err != nil || len(records) != 2 {
+		t.Errorf("Esperado cabeçalho e uma linha no CSV, obtido %v (%v)", records, err)
+	}
+}
@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseEvent é um evento lido do stream text/event-stream
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// openEvents abre o stream SSE de um usuário
+func openEvents(t *testing.T, server *httptest.Server, userID, lastEventID string) (*http.Response, chan sseEvent) {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", server.URL+"/api/users/"+userID+"/events", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Erro abrindo stream: %v", err)
+	}
+
+	events := make(chan sseEvent, 32)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var current sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if current.event != "" {
+					events <- current
+				}
+				current = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				current.id = line[4:]
+			case strings.HasPrefix(line, "event: "):
+				current.event = line[7:]
+			case strings.HasPrefix(line, "data: "):
+				current.data = line[6:]
+			}
+		}
+	}()
+
+	return resp, events
+}
+
+// nextEvent aguarda o próximo evento SSE
+func nextEvent(t *testing.T, events chan sseEvent) sseEvent {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("Stream encerrado inesperadamente")
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout aguardando evento")
+	}
+	return sseEvent{}
+}
+
+// TestUserEventsStream verifica eventos de ordem, trade e portfolio e o resume
+func TestUserEventsStream(t *testing.T) {
+	env := newTestEnv(t, marketOpen)
+	server := httptest.NewServer(env.container)
+	defer server.Close()
+
+	resp, events := openEvents(t, server, "carlos-santos", "")
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Content-Type inesperado: %s", resp.Header.Get("Content-Type"))
+	}
+
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "carlos-santos", "symbol": "AAPL", "side": "SELL", "quantity": 5, "price": 210.00,
+	}, 201)
+	postOrder(t, env.container, map[string]interface{}{
+		"user_id": "beatriz-costa", "symbol": "AAPL", "side": "BUY", "quantity": 5, "price": 210.00,
+	}, 201)
+
+	want := []string{"order", "portfolio", "order", "trade"}
+	var received []sseEvent
+	for _, eventType := range want {
+		event := nextEvent(t, events)
+		if event.event != eventType {
+			t.Fatalf("Esperado evento '%s', obtido '%s' (%s)", eventType, event.event, event.data)
+		}
+		received = append(received, event)
+	}
+	resp.Body.Close()
+
+	if !strings.Contains(received[1].data, `"position_delta":-5`) {
+		t.Errorf("Evento de portfolio sem delta de posição: %s", received[1].data)
+	}
+	if !strings.Contains(received[2].data, `"status":"FILLED"`) {
+		t.Errorf("Esperada ordem FILLED: %s", received[2].data)
+	}
+
+	t.Run("ResumeFromLastEventID", func(t *testing.T) {
+		resp, events := openEvents(t, server, "carlos-santos", received[1].id)
+		defer resp.Body.Close()
+
+		for _, expected := range received[2:] {
+			event := nextEvent(t, events)
+			if event.id != expected.id || event.event != expected.event {
+				t.Errorf("Replay inesperado: esperado %s/%s, obtido %s/%s", expected.id, expected.event, event.id, event.event)
+			}
+		}
+	})
+
+	t.Run("ResetWhenOutOfBuffer", func(t *testing.T) {
+		resp, events := openEvents(t, server, "carlos-santos", "999")
+		defer resp.Body.Close()
+
+		if event := nextEvent(t, events); event.event != "reset" {
+			t.Errorf("Esperado evento 'reset', obtido '%s'", event.event)
+		}
+	})
+
+	t.Run("UnknownUser", func(t *testing.T) {
+		resp := doRequest(env.container, "GET", "/api/users/ninguem/events", nil, nil)
+		if resp.Code != 404 {
+			t.Errorf("Esperado status 404, obtido %d", resp.Code)
+		}
+	})
+}
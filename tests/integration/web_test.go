@@ -1,85 +1,277 @@
 package integration
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	restful "github.com/emicklei/go-restful/v3"
 
+	"trading/internal/domain"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/engine/portfolio"
+	"trading/internal/services/shared/events"
+	"trading/internal/services/shared/marketdata"
+	"trading/internal/services/shared/validators"
+	"trading/internal/services/web/auth"
 	"trading/internal/services/web/handlers"
+	"trading/internal/services/web/stream"
 )
 
+const (
+	dataDir        = "../../data"
+	testAdminToken = "admin-secret"
+)
+
+// marketOpen é uma quarta-feira às 11h em Nova York (mercado aberto)
+var marketOpen = time.Date(2025, 9, 17, 15, 0, 0, 0, time.UTC)
+
+// testEnv agrupa o container e os serviços reais usados nos testes
+type testEnv struct {
+	container  *restful.Container
+	handler    *handlers.TradingHandler
+	books      *orderbook.Manager
+	portfolios *portfolio.Service
+	matcher    *matching.Service
+	validator  *validators.BusinessValidator
+	bus        *events.Bus
+	userEvents *stream.UserEventHub
+	candles    *marketdata.CandleStore
+	tickers    *marketdata.TickerService
+}
+
+// newTestContainer monta o container com os serviços reais do engine
+func newTestContainer(t *testing.T) *restful.Container {
+	return newTestEnv(t, marketOpen).container
+}
+
+// newTestContainerAt monta o container com o relógio de mercado fixo em now
+func newTestContainerAt(t *testing.T, now time.Time) *restful.Container {
+	return newTestEnv(t, now).container
+}
+
+// newTestEnv monta os serviços do engine com o relógio de mercado fixo em now
+func newTestEnv(t *testing.T, now time.Time) *testEnv {
+	t.Helper()
+
+	validator, err := validators.NewBusinessValidator(dataDir + "/stocks.json")
+	if err != nil {
+		t.Fatalf("Erro ao carregar ações: %v", err)
+	}
+	validator.SetClock(func() time.Time { return now })
+
+	portfolios, err := portfolio.NewService(dataDir + "/users.json")
+	if err != nil {
+		t.Fatalf("Erro ao carregar usuários: %v", err)
+	}
+
+	books := orderbook.NewManager()
+	matcher := matching.NewService(books, portfolios)
+
+	bus := events.NewBus()
+	t.Cleanup(bus.Close)
+	matcher.SetPublisher(bus)
+	portfolios.SetPublisher(bus)
+
+	userEvents := stream.NewUserEventHub(8)
+	bus.Subscribe("user-events", userEvents.HandleEvent)
+
+	candles := marketdata.NewCandleStore(validator, 0)
+	bus.Subscribe("candles", candles.HandleEvent)
+
+	var symbols []string
+	for _, stock := range validator.GetStocks() {
+		symbols = append(symbols, stock.Symbol)
+	}
+	tickers := marketdata.NewTickerService(symbols, validator, books)
+	bus.Subscribe("tickers", tickers.HandleEvent)
+
+	handler := handlers.NewTradingHandler(matcher, books, portfolios, validator)
+	handler.SetAdminAuthenticator(auth.NewAuthenticator(auth.Config{AdminToken: testAdminToken}))
+	handler.SetUserEvents(userEvents)
+	handler.SetEventBus(bus)
+	handler.SetCandles(candles)
+	handler.SetTickers(tickers)
+
+	return &testEnv{
+		container:  newContainer(handler),
+		handler:    handler,
+		books:      books,
+		portfolios: portfolios,
+		matcher:    matcher,
+		validator:  validator,
+		bus:        bus,
+		userEvents: userEvents,
+		candles:    candles,
+		tickers:    tickers,
+	}
+}
+
+// newContainer registra o handler em um container isolado
+func newContainer(handler *handlers.TradingHandler) *restful.Container {
+	container := restful.NewContainer()
+	container.Router(restful.CurlyRouter{})
+	container.ServiceErrorHandler(handlers.ServiceErrorHandler)
+	for _, service := range handlers.NewInternalWebRestfulContainer(handler).GetWebServices() {
+		container.Add(service)
+	}
+	return container
+}
+
+// doRequest executa uma requisição contra o container
+func doRequest(container *restful.Container, method, path string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&payload).Encode(body)
+	}
+
+	req, _ := http.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp := httptest.NewRecorder()
+	container.ServeHTTP(resp, req)
+	return resp
+}
+
+// decode decodifica o corpo JSON da resposta
+func decode(t *testing.T, resp *httptest.ResponseRecorder, target interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(resp.Body.Bytes(), target); err != nil {
+		t.Fatalf("Resposta não é JSON válido (%v): %s", err, resp.Body.String())
+	}
+}
+
 // TestWebServiceEndpoints testa se todos os endpoints estão funcionando
 func TestWebServiceEndpoints(t *testing.T) {
 	// Setup
-	container := handlers.NewInternalWebRestfulContainer()
-	restful.DefaultContainer.Router(restful.CurlyRouter{})
-	restful.Add(container.GetWS())
+	container := newTestContainer(t)
 
 	// Testa health check
 	t.Run("HealthCheck", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/api/health", nil)
-		resp := httptest.NewRecorder()
-		restful.DefaultContainer.ServeHTTP(resp, req)
+		resp := doRequest(container, "GET", "/api/health", nil, nil)
 
 		if resp.Code != 200 {
 			t.Errorf("Esperado status 200, obtido %d", resp.Code)
 		}
 
-		body := resp.Body.String()
-		if body != "OK - HealthCheck" {
-			t.Errorf("Esperado 'OK - HealthCheck', obtido '%s'", body)
+		var body map[string]interface{}
+		decode(t, resp, &body)
+		if body["status"] != "ok" {
+			t.Errorf("Esperado status 'ok', obtido '%v'", body["status"])
 		}
 	})
 
 	// Testa order book
 	t.Run("GetOrderBook", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/api/orderbook/AAPL", nil)
-		resp := httptest.NewRecorder()
-		restful.DefaultContainer.ServeHTTP(resp, req)
+		resp := doRequest(container, "GET", "/api/orderbook/AAPL", nil, nil)
 
 		if resp.Code != 200 {
 			t.Errorf("Esperado status 200, obtido %d", resp.Code)
 		}
 
-		body := resp.Body.String()
-		if body != "OK - GetOrderBook" {
-			t.Errorf("Esperado 'OK - GetOrderBook', obtido '%s'", body)
+		var book orderbook.Depth
+		decode(t, resp, &book)
+		if book.Symbol != "AAPL" {
+			t.Errorf("Esperado símbolo 'AAPL', obtido '%s'", book.Symbol)
 		}
 	})
 
 	// Testa portfolio
 	t.Run("GetPortfolio", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/api/portfolio/ana-silva", nil)
-		resp := httptest.NewRecorder()
-		restful.DefaultContainer.ServeHTTP(resp, req)
+		resp := doRequest(container, "GET", "/api/portfolio/carlos-santos", nil, nil)
 
 		if resp.Code != 200 {
 			t.Errorf("Esperado status 200, obtido %d", resp.Code)
 		}
 
-		body := resp.Body.String()
-		if body != "OK - GetPortfolio" {
-			t.Errorf("Esperado 'OK - GetPortfolio', obtido '%s'", body)
+		var body domain.Portfolio
+		decode(t, resp, &body)
+		if body.Cash != 25000 || body.Positions["AAPL"] != 100 {
+			t.Errorf("Portfolio inesperado: cash=%.2f positions=%v", body.Cash, body.Positions)
+		}
+	})
+
+	// Testa portfolio de usuário inexistente
+	t.Run("GetPortfolioNotFound", func(t *testing.T) {
+		resp := doRequest(container, "GET", "/api/portfolio/ninguem", nil, nil)
+
+		if resp.Code != 404 {
+			t.Errorf("Esperado status 404, obtido %d", resp.Code)
+		}
+
+		var body handlers.ErrorResponse
+		decode(t, resp, &body)
+		if body.Code != "USER_NOT_FOUND" {
+			t.Errorf("Esperado código 'USER_NOT_FOUND', obtido '%s'", body.Code)
 		}
 	})
 
 	// Testa stocks
 	t.Run("GetStocks", func(t *testing.T) {
-		req, _ := http.NewRequest("GET", "/api/stocks", nil)
-		resp := httptest.NewRecorder()
-		restful.DefaultContainer.ServeHTTP(resp, req)
+		resp := doRequest(container, "GET", "/api/stocks", nil, nil)
 
 		if resp.Code != 200 {
 			t.Errorf("Esperado status 200, obtido %d", resp.Code)
 		}
 
-		body := resp.Body.String()
-		if body != "OK - GetStocks" {
-			t.Errorf("Esperado 'OK - GetStocks', obtido '%s'", body)
+		var stocks []validators.Stock
+		decode(t, resp, &stocks)
+		if len(stocks) != 20 {
+			t.Errorf("Esperado 20 ações, obtido %d", len(stocks))
+		}
+	})
+
+	// Testa envelope de erro para rota inexistente
+	t.Run("NotFoundEnvelope", func(t *testing.T) {
+		resp := doRequest(container, "GET", "/api/inexistente", nil, map[string]string{
+			"Accept-Language":        "en-US,en;q=0.9,pt;q=0.5",
+			handlers.HeaderRequestID: "req-teste",
+		})
+
+		if resp.Code != 404 {
+			t.Errorf("Esperado status 404, obtido %d", resp.Code)
+		}
+
+		var body handlers.ErrorResponse
+		decode(t, resp, &body)
+		if body.Code != "NOT_FOUND" || body.Message != "Resource not found" || body.RequestID != "req-teste" {
+			t.Errorf("Envelope inesperado: %+v", body)
 		}
 	})
 
 	t.Logf("✅ Todos os endpoints estão respondendo corretamente!")
 }
+
+// fakePortfolios é um PortfolioProvider controlado pelo teste
+type fakePortfolios struct {
+	handlers.PortfolioProvider
+	err error
+}
+
+func (f *fakePortfolios) GetPortfolio(userID string) (*domain.Portfolio, error) {
+	return nil, f.err
+}
+
+// TestHandlerWithFakes verifica que o handler usa apenas as dependências injetadas
+func TestHandlerWithFakes(t *testing.T) {
+	handler := handlers.NewTradingHandler(nil, nil, &fakePortfolios{err: domain.ErrInvalidUser}, nil)
+	container := newContainer(handler)
+
+	resp := doRequest(container, "GET", "/api/portfolio/ana-silva", nil, nil)
+	if resp.Code != 400 {
+		t.Errorf("Esperado status 400, obtido %d", resp.Code)
+	}
+
+	var body handlers.ErrorResponse
+	decode(t, resp, &body)
+	if body.Code != "INVALID_USER" {
+		t.Errorf("Esperado código 'INVALID_USER', obtido '%s'", body.Code)
+	}
+}
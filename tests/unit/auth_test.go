@@ -0,0 +1,198 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"trading/internal/services/web/auth"
+)
+
+var (
+	authNow    = time.Date(2025, 9, 17, 15, 0, 0, 0, time.UTC)
+	authSecret = []byte("segredo-jwt")
+	authKey    = auth.APIKey{ID: "key-ana", Secret: "segredo-ana", UserID: "ana-silva", Roles: []string{"trader"}}
+)
+
+// newAuthenticator cria o autenticador com relógio fixo
+func newAuthenticator() *auth.Authenticator {
+	a := auth.NewAuthenticator(auth.Config{APIKeys: []auth.APIKey{authKey}, JWTSecret: authSecret, Issuer: "trading", Audience: "api"})
+	a.SetClock(func() time.Time { return authNow })
+	return a
+}
+
+// bearer monta uma requisição com o token
+func bearer(token string) *http.Request {
+	req, _ := http.NewRequest("GET", "/api/portfolio/ana-silva", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+// TestAuthJWT verifica assinatura, algoritmo, validade, emissor e audiência
+func TestAuthJWT(t *testing.T) {
+	a := newAuthenticator()
+	claims := auth.Claims{Subject: "ana-silva", Roles: []string{"trader"}, Issuer: "trading", Audience: []string{"api"}, ExpiresAt: authNow.Add(time.Hour).Unix()}
+
+	token, err := auth.NewToken(claims, authSecret)
+	if err != nil {
+		t.Fatalf("Erro ao emitir token: %v", err)
+	}
+	p, err := a.Authenticate(bearer(token))
+	if err != nil || p.UserID != "ana-silva" || p.Method != auth.MethodJWT || !p.HasRole("trader") || p.Can(auth.PermAdmin) {
+		t.Fatalf("Principal inesperado: %+v (%v)", p, err)
+	}
+
+	expired := claims
+	expired.ExpiresAt = authNow.Add(-time.Hour).Unix()
+	otherIssuer := claims
+	otherIssuer.Issuer = "outro"
+	noExpiry := claims
+	noExpiry.ExpiresAt = 0
+
+	cases := []struct {
+		name   string
+		claims auth.Claims
+		secret []byte
+		want   error
+	}{
+		{"expirado", expired, authSecret, auth.ErrExpiredCredentials},
+		{"emissor", otherIssuer, authSecret, auth.ErrInvalidCredentials},
+		{"sem exp", noExpiry, authSecret, auth.ErrInvalidCredentials},
+		{"segredo", claims, []byte("outro"), auth.ErrInvalidCredentials},
+	}
+	for _, tc := range cases {
+		token, _ := auth.NewToken(tc.claims, tc.secret)
+		if _, err := a.Authenticate(bearer(token)); !errors.Is(err, tc.want) {
+			t.Errorf("%s: esperado %v, obtido %v", tc.name, tc.want, err)
+		}
+	}
+
+	// alg=none com a assinatura removida não é aceito
+	parts := strings.Split(token, ".")
+	none := "eyJhbGciOiJub25lIn0." + parts[1] + "."
+	if _, err := a.Authenticate(bearer(none)); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("alg=none aceito: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/stats", nil)
+	if _, err := a.Authenticate(req); !errors.Is(err, auth.ErrMissingCredentials) {
+		t.Errorf("Esperado ErrMissingCredentials, obtido %v", err)
+	}
+}
+
+// TestAuthAPIKey verifica a assinatura HMAC sobre método, caminho, timestamp e corpo
+func TestAuthAPIKey(t *testing.T) {
+	a := newAuthenticator()
+	body := `{"user_id":"ana-silva","symbol":"AAPL","side":"BUY","quantity":1,"price":210}`
+
+	signed := func(at time.Time) *http.Request {
+		req, _ := http.NewRequest("POST", "/api/orders?x=1", strings.NewReader(body))
+		if err := auth.SignRequest(req, authKey, at); err != nil {
+			t.Fatalf("Erro ao assinar: %v", err)
+		}
+		return req
+	}
+
+	req := signed(authNow)
+	p, err := a.Authenticate(req)
+	if err != nil || p.UserID != "ana-silva" || p.Subject != "key-ana" || p.Method != auth.MethodAPIKey {
+		t.Fatalf("Principal inesperado: %+v (%v)", p, err)
+	}
+	if read, _ := io.ReadAll(req.Body); string(read) != body {
+		t.Errorf("Corpo não preservado para o handler: %q", read)
+	}
+
+	tampered := signed(authNow)
+	tampered.Body = io.NopCloser(bytes.NewReader([]byte(strings.Replace(body, "ana-silva", "carlos-santos", 1))))
+	if _, err := a.Authenticate(tampered); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("Corpo alterado aceito: %v", err)
+	}
+	if _, err := a.Authenticate(signed(authNow.Add(-10 * time.Minute))); !errors.Is(err, auth.ErrExpiredCredentials) {
+		t.Errorf("Timestamp antigo aceito: %v", err)
+	}
+
+	unknown := signed(authNow)
+	unknown.Header.Set("Authorization", strings.Replace(unknown.Header.Get("Authorization"), "key-ana", "key-x", 1))
+	if _, err := a.Authenticate(unknown); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("Chave desconhecida aceita: %v", err)
+	}
+
+	// Bytes além do limite não podem passar sem assinatura: a requisição é
+	// recusada em vez de conferida só pelo início do corpo
+	limit := bytes.Repeat([]byte(" "), auth.MaxSignedBody)
+	padded, _ := http.NewRequest("POST", "/api/orders", bytes.NewReader(limit))
+	if err := auth.SignRequest(padded, authKey, authNow); err != nil {
+		t.Fatalf("Corpo no limite deveria ser assinado: %v", err)
+	}
+	padded.Body = io.NopCloser(io.MultiReader(bytes.NewReader(limit), strings.NewReader(body)))
+	if _, err := a.Authenticate(padded); !errors.Is(err, auth.ErrBodyTooLarge) {
+		t.Errorf("Esperado ErrBodyTooLarge, obtido %v", err)
+	}
+}
+
+// TestAuthAdminToken verifica o principal admin emitido pelo ADMIN_TOKEN
+func TestAuthAdminToken(t *testing.T) {
+	a := auth.NewAuthenticator(auth.Config{AdminToken: "segredo-admin"})
+	request := func(token string) *http.Request {
+		req, _ := http.NewRequest("GET", "/api/admin/snapshots", nil)
+		req.Header.Set(auth.HeaderAdminToken, token)
+		return req
+	}
+
+	p, err := a.Authenticate(request("segredo-admin"))
+	if err != nil || p.Subject != auth.AdminSubject || p.Method != auth.MethodAdminToken || !p.HasRole(auth.RoleAdmin) || p.UserID != "" {
+		t.Fatalf("Esperado o principal admin, obtido %+v, %v", p, err)
+	}
+	if _, err := a.Authenticate(request("outro")); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("Token errado aceito: %v", err)
+	}
+	if _, err := newAuthenticator().Authenticate(request("segredo-admin")); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("Sem ADMIN_TOKEN configurado, o header não deveria autenticar: %v", err)
+	}
+}
+
+// TestRolePermissions verifica as permissões de cada papel e o papel padrão
+// das credenciais sem papéis
+func TestRolePermissions(t *testing.T) {
+	cases := []struct {
+		role    string
+		allowed []auth.Permission
+		denied  []auth.Permission
+	}{
+		{auth.RoleViewer, []auth.Permission{auth.PermMarketRead, auth.PermOrdersRead, auth.PermPortfolioRead}, []auth.Permission{auth.PermOrdersWrite, auth.PermBookL3, auth.PermUsersRead}},
+		{auth.RoleTrader, []auth.Permission{auth.PermOrdersWrite}, []auth.Permission{auth.PermStatsRead, auth.PermBookL3, auth.PermUsersRead, auth.PermAdmin}},
+		{auth.RoleRisk, []auth.Permission{auth.PermStatsRead, auth.PermBookL3, auth.PermUsersRead}, []auth.Permission{auth.PermOrdersWrite, auth.PermUsersWrite, auth.PermAdmin}},
+		{auth.RoleAdmin, []auth.Permission{auth.PermOrdersWrite, auth.PermUsersWrite, auth.PermAdmin}, nil},
+	}
+	for _, tc := range cases {
+		p := &auth.Principal{Roles: []string{tc.role}}
+		for _, perm := range tc.allowed {
+			if !p.Can(perm) {
+				t.Errorf("%s deveria ter %s", tc.role, perm)
+			}
+		}
+		for _, perm := range tc.denied {
+			if p.Can(perm) {
+				t.Errorf("%s não deveria ter %s", tc.role, perm)
+			}
+		}
+	}
+
+	// Papéis somam permissões; principal nil ou papel desconhecido não têm nenhuma
+	if p := (&auth.Principal{Roles: []string{auth.RoleTrader, auth.RoleRisk}}); !p.Can(auth.PermOrdersWrite) || !p.Can(auth.PermBookL3) {
+		t.Error("Esperada a união das permissões de trader e risk")
+	}
+	if (*auth.Principal)(nil).Can(auth.PermMarketRead) || (&auth.Principal{Roles: []string{"root"}}).Can(auth.PermMarketRead) {
+		t.Error("Principal sem papel conhecido não deveria ter permissões")
+	}
+
+	token, _ := auth.NewToken(auth.Claims{Subject: "ana-silva", Issuer: "trading", Audience: []string{"api"}, ExpiresAt: authNow.Add(time.Hour).Unix()}, authSecret)
+	p, err := newAuthenticator().Authenticate(bearer(token))
+	if err != nil || !p.HasRole(auth.RoleViewer) || p.Can(auth.PermOrdersWrite) {
+		t.Errorf("Token sem papéis deveria ser viewer, obtido %+v, %v", p, err)
+	}
+}
@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/api"
+	"trading/internal/services/engine/snapshot"
+	"trading/internal/services/web/handlers"
+)
+
+// TestErrorMapping verifica códigos e status HTTP dos erros de domínio
+func TestErrorMapping(t *testing.T) {
+	cases := []struct {
+		err    error
+		code   string
+		status int
+	}{
+		{domain.ErrInvalidQuantity, "INVALID_QUANTITY", http.StatusBadRequest},
+		{domain.ErrUserNotFound, "USER_NOT_FOUND", http.StatusNotFound},
+		{domain.ErrOrderNotOpen, "ORDER_NOT_OPEN", http.StatusConflict},
+		{domain.ErrInsufficientBalance, "INSUFFICIENT_BALANCE", http.StatusUnprocessableEntity},
+		{domain.ErrMarketClosed, "MARKET_CLOSED", http.StatusServiceUnavailable},
+		{fmt.Errorf("validando ordem: %w", domain.ErrPriceTooLow), "PRICE_TOO_LOW", http.StatusUnprocessableEntity},
+		{fmt.Errorf("falha inesperada"), "INTERNAL_ERROR", http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		if code := handlers.ErrorCode(tc.err); code != tc.code {
+			t.Errorf("%v: esperado código %s, obtido %s", tc.err, tc.code, code)
+		}
+		if status := handlers.ErrorStatus(tc.err); status != tc.status {
+			t.Errorf("%v: esperado status %d, obtido %d", tc.err, tc.status, status)
+		}
+	}
+}
+
+// TestErrorCodesShared verifica que REST e API do engine expõem todo erro de
+// domínio com o mesmo código da tabela única
+func TestErrorCodesShared(t *testing.T) {
+	for _, code := range domain.ErrorCodes() {
+		err, ok := domain.ErrorForCode(code)
+		if !ok || domain.ErrorCode(fmt.Errorf("contexto: %w", err)) != code {
+			t.Fatalf("%s: código sem erro de domínio correspondente", code)
+		}
+		if got := handlers.ErrorCode(err); got != code {
+			t.Errorf("%s: REST expõe código %s", code, got)
+		}
+		if status := handlers.ErrorStatus(err); status == http.StatusInternalServerError {
+			t.Errorf("%s: REST sem status próprio", code)
+		}
+		if got := api.ErrorCode(err); got != code {
+			t.Errorf("%s: API do engine expõe código %s", code, got)
+		}
+	}
+
+	if code := domain.ErrorCode(snapshot.ErrNotFound); code != "SNAPSHOT_NOT_FOUND" {
+		t.Errorf("Esperado SNAPSHOT_NOT_FOUND, obtido %s", code)
+	}
+	unknown := fmt.Errorf("falha inesperada")
+	for transport, code := range map[string]string{"REST": handlers.ErrorCode(unknown), "engine": api.ErrorCode(unknown)} {
+		if code != "INTERNAL_ERROR" {
+			t.Errorf("%s: erro desconhecido deveria usar INTERNAL_ERROR, obtido %s", transport, code)
+		}
+	}
+}
+
+// TestErrorResponseLocalized verifica as mensagens em português e inglês
+func TestErrorResponseLocalized(t *testing.T) {
+	_, pt := handlers.NewErrorResponse(domain.ErrMarketClosed, handlers.LangPT, "req-1")
+	if pt.Message != "Mercado fechado" || pt.RequestID != "req-1" {
+		t.Errorf("Mensagem em português inesperada: %+v", pt)
+	}
+
+	_, en := handlers.NewErrorResponse(domain.ErrMarketClosed, handlers.LangEN, "req-1")
+	if en.Message != "Market is closed" {
+		t.Errorf("Mensagem em inglês inesperada: %+v", en)
+	}
+
+	_, fallback := handlers.NewErrorResponse(domain.ErrMarketClosed, "fr", "req-1")
+	if fallback.Message != pt.Message {
+		t.Errorf("Idioma não suportado deveria cair para português, obtido %q", fallback.Message)
+	}
+}
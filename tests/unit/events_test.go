@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/shared/events"
+)
+
+// TestBusOrderedPerSymbol verifica a ordem de entrega por símbolo com várias partições
+func TestBusOrderedPerSymbol(t *testing.T) {
+	bus := events.NewBus()
+
+	var mutex sync.Mutex
+	received := map[string][]int64{}
+	bus.Subscribe("ordem", func(event events.Event) {
+		changed := event.(events.BookChanged)
+		mutex.Lock()
+		received[changed.Symbol] = append(received[changed.Symbol], changed.Timestamp.UnixNano())
+		mutex.Unlock()
+	}, events.WithPartitions(4), events.WithBuffer(8))
+
+	symbols := []string{"AAPL", "MSFT", "GOOGL", "TSLA"}
+	for i := 0; i < 200; i++ {
+		for _, symbol := range symbols {
+			bus.Publish(events.BookChanged{Symbol: symbol, Timestamp: time.Unix(0, int64(i))})
+		}
+	}
+	bus.Close()
+
+	for _, symbol := range symbols {
+		if len(received[symbol]) != 200 {
+			t.Fatalf("Esperados 200 eventos de %s, obtidos %d", symbol, len(received[symbol]))
+		}
+		for i, value := range received[symbol] {
+			if value != int64(i) {
+				t.Fatalf("Evento fora de ordem em %s: posição %d recebeu %d", symbol, i, value)
+			}
+		}
+	}
+
+	stats := bus.Stats()
+	if stats.Published != 800 || stats.Subscriptions[0].Delivered != 800 {
+		t.Errorf("Métricas inesperadas: %+v", stats)
+	}
+}
+
+// TestBusBackpressure verifica que a fila cheia bloqueia o publicador sem
+// perder eventos e que o bloqueio aparece nas métricas
+func TestBusBackpressure(t *testing.T) {
+	bus := events.NewBus()
+
+	release := make(chan struct{})
+	events.On(bus, "lento", func(event events.TradeExecuted) {
+		<-release
+	}, events.WithBuffer(1))
+
+	published := make(chan struct{})
+	go func() {
+		trade := &domain.Trade{Symbol: "AAPL"}
+		for i := 0; i < 5; i++ {
+			bus.Publish(events.TradeExecuted{Trade: trade})
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("Publicador deveria aguardar com a fila cheia")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-published
+	bus.Close()
+
+	stats := bus.Stats().Subscriptions[0]
+	if stats.Delivered != 5 || stats.Blocked == 0 || stats.MaxQueueDepth != 1 {
+		t.Errorf("Métricas de backpressure inesperadas: %+v", stats)
+	}
+}
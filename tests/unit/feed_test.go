@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"trading/internal/services/engine/feed"
+)
+
+// TestFeedCodec verifica o formato binário: tamanhos fixos, preços em ticks e
+// leitura de quadros TCP consecutivos
+func TestFeedCodec(t *testing.T) {
+	at := time.Date(2025, 9, 17, 15, 0, 0, 123, time.UTC)
+	messages := []*feed.Message{
+		{Type: feed.TypeBookUpdate, Seq: 1, Timestamp: at, Symbol: "AAPL", Side: feed.Bid, Level: feed.Level{Price: 210.1234, Quantity: 500, Orders: 3}},
+		{Type: feed.TypeTrade, Seq: 2, Timestamp: at, Symbol: "AAPL", Level: feed.Level{Price: 210.5, Quantity: 7}},
+		{Type: feed.TypeHeartbeat, Seq: 2, Timestamp: at, Symbol: "AAPL"},
+		{Type: feed.TypeSnapshot, Seq: 2, Timestamp: at, Symbol: "GOOGL",
+			Bids: []feed.Level{{Price: 150, Quantity: 10, Orders: 1}},
+			Asks: []feed.Level{{Price: 151, Quantity: 5, Orders: 2}, {Price: 152, Quantity: 1, Orders: 1}}},
+		{Type: feed.TypeReject, Seq: 40, Timestamp: at, Symbol: "AAPL", Reason: feed.RejectOutOfWindow},
+	}
+	sizes := []int{45, 40, 28, 80, 29}
+
+	var stream bytes.Buffer
+	for i, m := range messages {
+		packet, err := feed.Encode(m)
+		if err != nil {
+			t.Fatalf("Erro codificando %c: %v", m.Type, err)
+		}
+		if len(packet) != sizes[i] {
+			t.Errorf("%c: esperado %d bytes, obtido %d", m.Type, sizes[i], len(packet))
+		}
+		stream.Write(packet)
+	}
+
+	for _, want := range messages {
+		got, err := feed.ReadMessage(&stream)
+		if err != nil {
+			t.Fatalf("Erro decodificando %c: %v", want.Type, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Decodificado %+v, esperado %+v", got, want)
+		}
+	}
+
+	if _, err := feed.Encode(&feed.Message{Type: feed.TypeHeartbeat, Symbol: "LONGSYMBOL"}); !errors.Is(err, feed.ErrSymbolSize) {
+		t.Errorf("Esperado ErrSymbolSize, obtido %v", err)
+	}
+	packet, _ := feed.Encode(messages[0])
+	if _, err := feed.Decode(packet[:len(packet)-1]); !errors.Is(err, feed.ErrMalformed) {
+		t.Errorf("Esperado ErrMalformed para datagrama truncado, obtido %v", err)
+	}
+}
+
+// TestFeedBookGaps verifica que o livro do consumidor não aplica mensagens
+// fora de ordem e ignora duplicatas
+func TestFeedBookGaps(t *testing.T) {
+	book := feed.NewBook("AAPL")
+	update := func(seq uint64, side feed.Side, price float64, quantity int) *feed.Message {
+		return &feed.Message{Type: feed.TypeBookUpdate, Seq: seq, Symbol: "AAPL", Side: side, Level: feed.Level{Price: price, Quantity: quantity, Orders: 1}}
+	}
+
+	for _, m := range []*feed.Message{update(1, feed.Bid, 209, 10), update(2, feed.Ask, 211, 5), update(1, feed.Bid, 209, 99)} {
+		if err := book.Apply(m); err != nil {
+			t.Fatalf("Erro aplicando %d: %v", m.Seq, err)
+		}
+	}
+	if err := book.Apply(update(4, feed.Bid, 208, 1)); !errors.Is(err, feed.ErrGap) {
+		t.Errorf("Esperado ErrGap, obtido %v", err)
+	}
+	if err := book.Apply(&feed.Message{Type: feed.TypeHeartbeat, Seq: 3, Symbol: "AAPL"}); !errors.Is(err, feed.ErrGap) {
+		t.Errorf("Esperado ErrGap no heartbeat adiante do livro, obtido %v", err)
+	}
+
+	if err := book.Apply(update(3, feed.Bid, 209, 0)); err != nil {
+		t.Fatalf("Erro aplicando remoção: %v", err)
+	}
+	if book.Seq != 3 || len(book.Bids()) != 0 || !reflect.DeepEqual(book.Asks(), []feed.Level{{Price: 211, Quantity: 5, Orders: 1}}) {
+		t.Errorf("Livro inesperado: seq %d bids %+v asks %+v", book.Seq, book.Bids(), book.Asks())
+	}
+}
@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"trading/internal/services/fix"
+)
+
+// TestFIXMessageCodec verifica BodyLength, CheckSum, ordem do cabeçalho e a
+// rejeição de mensagens corrompidas
+func TestFIXMessageCodec(t *testing.T) {
+	msg := fix.NewMessage(fix.MsgNewOrderSingle).
+		Set(fix.TagClOrdID, "ORD-1").
+		Set(fix.TagSymbol, "AAPL").
+		SetInt(fix.TagOrderQty, 10).
+		SetFloat(fix.TagPrice, 150.25).
+		Set(fix.TagText, "").
+		Set(fix.TagSenderCompID, "INST1").
+		Set(fix.TagTargetCompID, "TRADING").
+		SetInt(fix.TagMsgSeqNum, 7)
+
+	raw := msg.Bytes()
+	want := "8=FIX.4.4|9=63|35=D|49=INST1|56=TRADING|34=7|11=ORD-1|55=AAPL|38=10|44=150.25|10="
+	if got := strings.ReplaceAll(string(raw), "\x01", "|"); !strings.HasPrefix(got, want) {
+		t.Fatalf("Codificação inesperada:\n%s\nesperado prefixo\n%s", got, want)
+	}
+
+	// Duas mensagens seguidas no mesmo fluxo
+	reader := bufio.NewReader(bytes.NewReader(append(append([]byte{}, raw...), raw...)))
+	for i := 0; i < 2; i++ {
+		decoded, err := fix.ReadMessage(reader)
+		if err != nil {
+			t.Fatalf("Erro decodificando: %v", err)
+		}
+		if decoded.Type() != fix.MsgNewOrderSingle || decoded.SeqNum() != 7 || decoded.Get(fix.TagPrice) != "150.25" || decoded.Has(fix.TagText) {
+			t.Errorf("Mensagem decodificada inesperada: %s", decoded)
+		}
+	}
+
+	// CheckSum errado
+	corrupted := append([]byte{}, raw...)
+	corrupted[len(corrupted)-2]++
+	if _, err := fix.ReadMessage(bufio.NewReader(bytes.NewReader(corrupted))); !errors.Is(err, fix.ErrGarbled) {
+		t.Errorf("Esperado ErrGarbled para CheckSum inválido, obtido %v", err)
+	}
+
+	// Versão diferente
+	other := bytes.Replace(raw, []byte("FIX.4.4"), []byte("FIX.4.2"), 1)
+	if _, err := fix.ReadMessage(bufio.NewReader(bytes.NewReader(other))); !errors.Is(err, fix.ErrGarbled) {
+		t.Errorf("Esperado ErrGarbled para BeginString FIX.4.2, obtido %v", err)
+	}
+}
+
+// TestFIXParseClients verifica o formato de FIX_CLIENTS
+func TestFIXParseClients(t *testing.T) {
+	clients, err := fix.ParseClients("INST1:s1=ana-silva|carlos-santos, INST2:s2=beatriz-costa")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+	want := map[string]fix.Client{
+		"INST1": {Password: "s1", Accounts: []string{"ana-silva", "carlos-santos"}},
+		"INST2": {Password: "s2", Accounts: []string{"beatriz-costa"}},
+	}
+	if !reflect.DeepEqual(clients, want) {
+		t.Errorf("Esperado %v, obtido %v", want, clients)
+	}
+
+	if clients, err := fix.ParseClients(""); err != nil || clients != nil {
+		t.Errorf("Lista vazia deveria desativar o gateway, obtido %v %v", clients, err)
+	}
+	for _, value := range []string{":s=conta", "INST1=conta", "INST1:=conta", "INST1:s1", "INST1:s1="} {
+		if _, err := fix.ParseClients(value); err == nil {
+			t.Errorf("Esperado erro para %q", value)
+		}
+	}
+}
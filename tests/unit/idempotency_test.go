@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/journal"
+	"trading/internal/services/engine/matching"
+)
+
+// clientOrder cria uma ordem com client_order_id
+func clientOrder(userID, clientOrderID string, side domain.OrderSide, quantity int, price float64) *domain.Order {
+	order := domain.NewOrder(userID, "GOOGL", side, quantity, price)
+	order.ClientOrderID = clientOrderID
+	return order
+}
+
+// TestClientOrderIDIdempotency verifica que o reenvio devolve o resultado
+// original sem nova reserva, e que parâmetros diferentes geram conflito
+func TestClientOrderIDIdempotency(t *testing.T) {
+	engine, books, _ := newEngine(t)
+
+	first := engine.ProcessOrder(context.Background(), clientOrder("carlos-santos", "venda-1", domain.SELL, 10, 160))
+	if first.Status != matching.StatusPending || first.Replayed {
+		t.Fatalf("Esperada venda pendente, obtido %+v", first)
+	}
+
+	// Os reenvios não reservam nem entram no livro de novo
+	for i := 0; i < 3; i++ {
+		retry := engine.ProcessOrder(context.Background(), clientOrder("carlos-santos", "venda-1", domain.SELL, 10, 160))
+		if retry.Rejected || !retry.Replayed || retry.Order.ID != first.Order.ID {
+			t.Fatalf("Esperado o resultado original, obtido %+v", retry)
+		}
+	}
+	if book := books.GetOrderBook("GOOGL"); len(book.Asks) != 1 {
+		t.Errorf("Reenvio não deveria entrar no livro: %+v", book.Asks)
+	}
+
+	recalled, ok := engine.Recall(context.Background(), clientOrder("carlos-santos", "venda-1", domain.SELL, 10, 160))
+	if !ok || recalled.Order.ID != first.Order.ID {
+		t.Errorf("Recall deveria encontrar a ordem original, obtido %+v", recalled)
+	}
+	if _, ok := engine.Recall(context.Background(), clientOrder("carlos-santos", "venda-2", domain.SELL, 10, 160)); ok {
+		t.Error("Recall não deveria encontrar um client_order_id novo")
+	}
+
+	conflict := engine.ProcessOrder(context.Background(), clientOrder("carlos-santos", "venda-1", domain.SELL, 10, 161))
+	if !conflict.Rejected || conflict.Err != domain.ErrClientOrderIDConflict {
+		t.Errorf("Esperado conflito de client_order_id, obtido %+v", conflict)
+	}
+
+	// O client_order_id é único por usuário
+	other := engine.ProcessOrder(context.Background(), clientOrder("beatriz-costa", "venda-1", domain.BUY, 5, 150))
+	if other.Rejected || other.Replayed || other.Order.ID == first.Order.ID {
+		t.Errorf("Outro usuário deveria criar uma ordem nova, obtido %+v", other)
+	}
+
+	// Fora da janela, o mesmo client_order_id volta a ser processado
+	engine.SetIdempotencyWindow(0)
+	expired := engine.ProcessOrder(context.Background(), clientOrder("carlos-santos", "venda-1", domain.SELL, 5, 160))
+	if expired.Replayed || expired.Order.ID == first.Order.ID {
+		t.Errorf("Esperada ordem nova fora da janela, obtido %+v", expired)
+	}
+
+	invalid := clientOrder("carlos-santos", "com espaço", domain.SELL, 1, 160)
+	if err := invalid.Validate(); err != domain.ErrInvalidClientOrderID {
+		t.Errorf("Esperado ErrInvalidClientOrderID, obtido %v", err)
+	}
+}
+
+// TestClientOrderIDSurvivesReplay verifica que o replay do journal reconstrói
+// os client_order_id já enviados
+func TestClientOrderIDSurvivesReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+	sequences := domain.Sequences()
+
+	commands, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Erro abrindo journal: %v", err)
+	}
+	engine, _, _ := newEngine(t)
+	engine.SetJournal(commands)
+	first := engine.ProcessOrder(context.Background(), clientOrder("carlos-santos", "venda-1", domain.SELL, 10, 160))
+	commands.Close()
+
+	domain.RestoreSequences(sequences)
+	replayed, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Erro reabrindo journal: %v", err)
+	}
+	defer replayed.Close()
+
+	engine, _, _ = newEngine(t)
+	if _, err := engine.Replay(replayed, 0); err != nil {
+		t.Fatalf("Replay falhou: %v", err)
+	}
+	retry := engine.ProcessOrder(context.Background(), clientOrder("carlos-santos", "venda-1", domain.SELL, 10, 160))
+	if !retry.Replayed || retry.Order.ID != first.Order.ID {
+		t.Errorf("Esperado o resultado original após o replay, obtido %+v", retry)
+	}
+}
@@ -0,0 +1,168 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/journal"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/engine/portfolio"
+)
+
+// engineState serializa livros, negociações e portfolios para comparação
+func engineState(t *testing.T, engine *matching.Service, books *orderbook.Manager, portfolios *portfolio.Service) []byte {
+	t.Helper()
+
+	state := map[string]interface{}{
+		"trades": engine.GetTrades(),
+	}
+	for _, symbol := range []string{"AAPL", "GOOGL"} {
+		state["book_"+symbol] = books.GetOrderBook(symbol)
+	}
+	for _, userID := range []string{"carlos-santos", "beatriz-costa"} {
+		p, err := portfolios.GetPortfolio(userID)
+		if err != nil {
+			t.Fatalf("Erro lendo portfolio: %v", err)
+		}
+		state["portfolio_"+userID] = p
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("Erro serializando estado: %v", err)
+	}
+	return data
+}
+
+// TestJournalReplayRebuildsState verifica que o replay reproduz o mesmo estado
+func TestJournalReplayRebuildsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+	sequences := domain.Sequences()
+
+	commands, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Erro abrindo journal: %v", err)
+	}
+	engine, books, portfolios := newEngine(t)
+	engine.SetJournal(commands)
+
+	sell := domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 10, 210)
+	engine.ProcessOrder(context.Background(), sell)
+	engine.ProcessOrder(context.Background(), domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 4, 210))
+	resting := domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 5, 150)
+	engine.ProcessOrder(context.Background(), resting)
+	if _, err := engine.AmendOrder(context.Background(), sell.ID, 8, 209); err != nil {
+		t.Fatalf("Erro alterando ordem: %v", err)
+	}
+	engine.ProcessOrder(context.Background(), domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 2, 215))
+	if _, err := engine.CancelOrder(context.Background(), resting.ID); err != nil {
+		t.Fatalf("Erro cancelando ordem: %v", err)
+	}
+	// Cancelamento inválido também é registrado e se repete no replay
+	if _, err := engine.CancelOrder(context.Background(), resting.ID); err != domain.ErrOrderNotOpen {
+		t.Fatalf("Esperado ErrOrderNotOpen, obtido %v", err)
+	}
+
+	want := engineState(t, engine, books, portfolios)
+	if commands.Seq() != 7 {
+		t.Errorf("Esperados 7 comandos no journal, obtido %d", commands.Seq())
+	}
+	commands.Close()
+
+	// Novo processo: mesmos geradores de ID, estado vazio
+	domain.RestoreSequences(sequences)
+	replayed, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Erro reabrindo journal: %v", err)
+	}
+	defer replayed.Close()
+
+	engine, books, portfolios = newEngine(t)
+	applied, err := engine.Replay(replayed, 0)
+	if err != nil || applied != 7 {
+		t.Fatalf("Replay falhou: %d comandos, %v", applied, err)
+	}
+
+	if got := engineState(t, engine, books, portfolios); !bytes.Equal(got, want) {
+		t.Errorf("Estado após replay difere:\nesperado %s\nobtido   %s", want, got)
+	}
+}
+
+// TestJournalIntegrity verifica checksum e descarte de registro incompleto
+func TestJournalIntegrity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+
+	commands, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Erro abrindo journal: %v", err)
+	}
+	for _, id := range []string{"order-1", "order-2", "order-3"} {
+		if _, err := commands.Append(journal.Command{Type: journal.CommandCancel, OrderID: id}); err != nil {
+			t.Fatalf("Erro gravando: %v", err)
+		}
+	}
+	commands.Close()
+
+	t.Run("TornTail", func(t *testing.T) {
+		file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		_, _ = file.WriteString(`{"crc32":1,"command":{"seq":4`)
+		file.Close()
+
+		reopened, err := journal.Open(path)
+		if err != nil {
+			t.Fatalf("Registro incompleto deveria ser descartado: %v", err)
+		}
+		defer reopened.Close()
+
+		if reopened.Seq() != 3 {
+			t.Errorf("Esperada sequência 3, obtido %d", reopened.Seq())
+		}
+		if cmd, err := reopened.Append(journal.Command{Type: journal.CommandCancel, OrderID: "order-4"}); err != nil || cmd.Seq != 4 {
+			t.Errorf("Esperado append com seq 4, obtido %d (%v)", cmd.Seq, err)
+		}
+	})
+
+	t.Run("FailedAppend", func(t *testing.T) {
+		failing, err := journal.Open(path)
+		if err != nil {
+			t.Fatalf("Erro abrindo journal: %v", err)
+		}
+		seq := failing.Seq()
+		failing.Close()
+
+		// Gravação falha sem avançar a sequência; sem conseguir voltar ao
+		// último registro válido, o journal recusa novos comandos
+		if cmd, err := failing.Append(journal.Command{Type: journal.CommandCancel, OrderID: "order-x"}); err == nil || failing.Seq() != seq {
+			t.Fatalf("Esperada falha sem avançar a sequência, obtido seq %d (%v)", cmd.Seq, err)
+		}
+		if _, err := failing.Append(journal.Command{Type: journal.CommandCancel, OrderID: "order-y"}); !errors.Is(err, journal.ErrFailed) {
+			t.Errorf("Esperado ErrFailed, obtido %v", err)
+		}
+
+		reopened, err := journal.Open(path)
+		if err != nil {
+			t.Fatalf("Journal deveria continuar legível: %v", err)
+		}
+		defer reopened.Close()
+		if cmd, err := reopened.Append(journal.Command{Type: journal.CommandCancel, OrderID: "order-z"}); err != nil || cmd.Seq != seq+1 {
+			t.Errorf("Esperado append com seq %d, obtido %d (%v)", seq+1, cmd.Seq, err)
+		}
+	})
+
+	t.Run("Checksum", func(t *testing.T) {
+		data, _ := os.ReadFile(path)
+		corrupted := bytes.Replace(data, []byte("order-2"), []byte("order-9"), 1)
+		_ = os.WriteFile(path, corrupted, 0o644)
+
+		if _, err := journal.Open(path); !errors.Is(err, journal.ErrCorrupt) {
+			t.Errorf("Esperado ErrCorrupt, obtido %v", err)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"trading/internal/services/shared/logging"
+)
+
+// TestLoggingRequestID verifica que o logger JSON inclui o request ID do
+// contexto, inclusive em loggers derivados, e respeita o nível configurado
+func TestLoggingRequestID(t *testing.T) {
+	var out bytes.Buffer
+	logger := logging.New(&out, slog.LevelInfo)
+
+	ctx := logging.WithRequestID(context.Background(), "req-teste")
+	logger.With("service", "engine").InfoContext(ctx, "ordem processada", "order_id", "ORD-1")
+	logger.DebugContext(ctx, "descartada pelo nível")
+	logger.Info("sem contexto")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Esperadas 2 linhas, obtidas %d: %s", len(lines), out.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Linha não é JSON: %v", err)
+	}
+	want := map[string]interface{}{"level": "INFO", "msg": "ordem processada", "request_id": "req-teste", "service": "engine", "order_id": "ORD-1"}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s: esperado %v, obtido %v", key, value, entry[key])
+		}
+	}
+	if strings.Contains(lines[1], logging.KeyRequestID) {
+		t.Errorf("Linha sem contexto não deveria ter request_id: %s", lines[1])
+	}
+
+	if level, err := logging.ParseLevel("debug"); err != nil || level != slog.LevelDebug {
+		t.Errorf("Esperado nível debug, obtido %v (%v)", level, err)
+	}
+	if _, err := logging.ParseLevel("verbose"); err == nil {
+		t.Error("Esperado erro para nível inválido")
+	}
+}
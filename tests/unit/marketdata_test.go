@@ -0,0 +1,151 @@
+package unit
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/shared/marketdata"
+	"trading/internal/services/shared/validators"
+)
+
+// tradeAt cria uma negociação de AAPL executada no instante informado
+func tradeAt(at time.Time, quantity int, price float64) *domain.Trade {
+	buy := domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, quantity, price)
+	sell := domain.NewOrder("carlos-santos", "AAPL", domain.SELL, quantity, price)
+	return domain.NewTradeAt(buy, sell, quantity, price, at)
+}
+
+// TestCandleAggregation verifica OHLCV, VWAP, alinhamento à abertura do
+// pregão e o fechamento das barras no fim do pregão
+func TestCandleAggregation(t *testing.T) {
+	calendar, err := validators.NewBusinessValidator(dataDir + "/stocks.json")
+	if err != nil {
+		t.Fatalf("Erro ao carregar ações: %v", err)
+	}
+
+	// Pregão de 17/09/2025: 13:30 às 20:00 UTC
+	day := func(d, h, m, s, ms int) time.Time {
+		return time.Date(2025, 9, d, h, m, s, ms*int(time.Millisecond), time.UTC)
+	}
+	store := marketdata.NewCandleStore(calendar, 4)
+	store.SetClock(func() time.Time { return day(18, 14, 0, 0, 0) })
+	for _, trade := range []*domain.Trade{
+		tradeAt(day(17, 13, 30, 0, 500), 10, 100),
+		tradeAt(day(17, 13, 30, 0, 700), 5, 102),
+		tradeAt(day(17, 13, 31, 10, 0), 5, 99),
+		tradeAt(day(17, 19, 45, 30, 0), 1, 101),
+		tradeAt(day(18, 13, 30, 5, 0), 2, 105),
+	} {
+		store.Add(trade)
+	}
+
+	interval := func(name string) marketdata.Interval {
+		i, err := marketdata.ParseInterval(name)
+		if err != nil {
+			t.Fatalf("Intervalo %s: %v", name, err)
+		}
+		return i
+	}
+
+	seconds := store.Candles("AAPL", interval("1s"), time.Time{}, time.Time{})
+	first := seconds[0]
+	if len(seconds) != 4 || first.Open != 100 || first.High != 102 || first.Low != 100 || first.Close != 102 ||
+		first.Volume != 15 || first.Trades != 2 || math.Abs(first.VWAP-1510.0/15) > 1e-9 {
+		t.Errorf("Barra de 1s inesperada: %+v", seconds)
+	}
+
+	// A última barra de 1h do pregão termina no fechamento (20:00 UTC)
+	hours := store.Candles("AAPL", interval("1h"), time.Time{}, day(18, 0, 0, 0, 0))
+	if len(hours) != 2 || !hours[0].Start.Equal(day(17, 13, 30, 0, 0)) || hours[0].Trades != 3 ||
+		!hours[1].Start.Equal(day(17, 19, 30, 0, 0)) || !hours[1].End.Equal(day(17, 20, 0, 0, 0)) {
+		t.Errorf("Barras de 1h inesperadas: %+v", hours)
+	}
+
+	days := store.Candles("AAPL", interval("1d"), time.Time{}, time.Time{})
+	if len(days) != 2 || !days[0].End.Equal(day(17, 20, 0, 0, 0)) || days[0].Volume != 21 ||
+		days[0].Low != 99 || days[0].Close != 101 || !days[0].Closed || days[1].Closed {
+		t.Errorf("Barras diárias inesperadas: %+v", days)
+	}
+
+	minutes := store.Candles("AAPL", interval("1m"), day(17, 13, 31, 0, 0), day(18, 0, 0, 0, 0))
+	if len(minutes) != 2 || !minutes[0].Start.Equal(day(17, 13, 31, 0, 0)) || !minutes[1].Start.Equal(day(17, 19, 45, 0, 0)) {
+		t.Errorf("Filtro de 1m inesperado: %+v", minutes)
+	}
+
+	// O anel guarda 4 barras: negociações de barras descartadas são ignoradas
+	store.Add(tradeAt(day(18, 13, 32, 0, 0), 1, 104))
+	store.Add(tradeAt(day(17, 13, 30, 0, 900), 100, 100))
+	if minutes := store.Candles("AAPL", interval("1m"), time.Time{}, time.Time{}); len(minutes) != 4 || minutes[0].Volume != 5 {
+		t.Errorf("Esperadas as 4 barras de 1m mais recentes, obtido %+v", minutes)
+	}
+
+	// Fora do pregão (domingo) as barras seguem o relógio UTC
+	store.Add(tradeAt(day(21, 15, 0, 0, 0), 1, 100))
+	if days := store.Candles("AAPL", interval("1d"), day(21, 0, 0, 0, 0), time.Time{}); len(days) != 1 || !days[0].Start.Equal(day(21, 0, 0, 0, 0)) {
+		t.Errorf("Barra diária fora do pregão inesperada: %+v", days)
+	}
+
+	if _, err := marketdata.ParseInterval("2m"); err == nil {
+		t.Error("Esperado erro para intervalo 2m")
+	}
+}
+
+// TestTicker verifica último preço, variação sobre o fechamento anterior,
+// virada de pregão e melhor bid/ask lido do livro
+func TestTicker(t *testing.T) {
+	calendar, err := validators.NewBusinessValidator(dataDir + "/stocks.json")
+	if err != nil {
+		t.Fatalf("Erro ao carregar ações: %v", err)
+	}
+	books := orderbook.NewManager()
+	books.AddOrder(domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 7, 104))
+
+	tickers := marketdata.NewTickerService([]string{"AAPL", "GOOGL"}, calendar, books)
+	now := time.Date(2025, 9, 18, 14, 0, 0, 0, time.UTC)
+	tickers.SetClock(func() time.Time { return now })
+
+	// Pregão de 17/09 fecha a 100; o de 18/09 negocia 105 e 110
+	for _, trade := range []*domain.Trade{
+		tradeAt(time.Date(2025, 9, 17, 14, 0, 0, 0, time.UTC), 10, 98),
+		tradeAt(time.Date(2025, 9, 17, 19, 0, 0, 0, time.UTC), 10, 100),
+		tradeAt(time.Date(2025, 9, 18, 13, 35, 0, 0, time.UTC), 1, 105),
+		tradeAt(time.Date(2025, 9, 18, 13, 40, 0, 0, time.UTC), 3, 110),
+	} {
+		tickers.Add(trade)
+	}
+
+	ticker, ok := tickers.Ticker("AAPL")
+	if !ok || ticker.LastPrice == nil || *ticker.LastPrice != 110 || *ticker.PreviousClose != 100 ||
+		*ticker.Change != 10 || *ticker.ChangePercent != 10 || *ticker.High != 110 || *ticker.Low != 105 ||
+		ticker.Volume != 4 || ticker.Trades != 2 || *ticker.VWAP != 108.75 {
+		t.Errorf("Ticker inesperado: %+v", ticker)
+	}
+	if ticker.BestBid == nil || *ticker.BestBid != 104 || ticker.BidSize != 7 || ticker.BestAsk != nil {
+		t.Errorf("Melhor bid/ask inesperado: %+v", ticker)
+	}
+
+	// No pregão seguinte, sem negócios, 110 vira o fechamento anterior
+	now = time.Date(2025, 9, 19, 14, 0, 0, 0, time.UTC)
+	if ticker, _ = tickers.Ticker("AAPL"); *ticker.PreviousClose != 110 || *ticker.Change != 0 || ticker.High != nil || ticker.Volume != 0 {
+		t.Errorf("Ticker após a virada inesperado: %+v", ticker)
+	}
+
+	if ticker, _ = tickers.Ticker("GOOGL"); ticker.LastPrice != nil || ticker.PreviousClose != nil {
+		t.Errorf("Símbolo sem negócios deveria ter preços nulos: %+v", ticker)
+	}
+	if _, ok := tickers.Ticker("XYZ"); ok {
+		t.Error("Símbolo desconhecido não deveria ter ticker")
+	}
+
+	// Preços para avaliação de portfolio
+	prices := tickers.Prices()
+	portfolio := domain.NewPortfolio("beatriz-costa", 1000)
+	portfolio.Positions["AAPL"] = 2
+	portfolio.Positions["GOOGL"] = 5
+	if len(prices) != 1 || portfolio.GetTotalValue(prices) != 1220 {
+		t.Errorf("Avaliação inesperada com preços %v: %.2f", prices, portfolio.GetTotalValue(prices))
+	}
+}
@@ -0,0 +1,127 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/orderbook"
+	"trading/internal/services/engine/portfolio"
+)
+
+const dataDir = "../../data"
+
+// newEngine cria um matching engine com os usuários do dataset
+func newEngine(t *testing.T) (*matching.Service, *orderbook.Manager, *portfolio.Service) {
+	t.Helper()
+
+	portfolios, err := portfolio.NewService(dataDir + "/users.json")
+	if err != nil {
+		t.Fatalf("Erro ao carregar usuários: %v", err)
+	}
+
+	books := orderbook.NewManager()
+	return matching.NewService(books, portfolios), books, portfolios
+}
+
+// TestPriceTimePriority verifica prioridade por preço e depois por chegada
+func TestPriceTimePriority(t *testing.T) {
+	engine, books, _ := newEngine(t)
+
+	first := domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 5, 210)
+	second := domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 5, 210)
+	best := domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 5, 205)
+	for _, order := range []*domain.Order{first, second, best} {
+		if result := engine.ProcessOrder(context.Background(), order); result.Status != matching.StatusPending {
+			t.Fatalf("Esperado venda pendente, obtido %+v", result)
+		}
+	}
+
+	result := engine.ProcessOrder(context.Background(), domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 8, 210))
+	if result.Status != matching.StatusFilled || len(result.Trades) != 2 {
+		t.Fatalf("Esperado 2 trades, obtido %+v", result)
+	}
+	if result.Trades[0].SellOrderID != best.ID || result.Trades[0].Price != 205 {
+		t.Errorf("Primeiro trade deveria usar o melhor preço: %+v", result.Trades[0])
+	}
+	if result.Trades[1].SellOrderID != first.ID || result.Trades[1].Quantity != 3 {
+		t.Errorf("Segundo trade deveria usar a ordem mais antiga: %+v", result.Trades[1])
+	}
+
+	book := books.GetOrderBook("AAPL")
+	if len(book.Asks) != 2 || book.Asks[0].ID != first.ID || book.Asks[0].RemainingQuantity != 2 {
+		t.Errorf("Livro inesperado após preenchimento parcial: %+v", book.Asks)
+	}
+	if book.Asks[0].Status != domain.PARTIAL {
+		t.Errorf("Esperado status PARTIAL, obtido %s", book.Asks[0].Status)
+	}
+}
+
+// TestReservationsPreventOverselling verifica que ordens abertas comprometem a posição
+func TestReservationsPreventOverselling(t *testing.T) {
+	engine, _, _ := newEngine(t)
+
+	if result := engine.ProcessOrder(context.Background(), domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 10, 160)); result.Rejected {
+		t.Fatalf("Primeira venda não deveria ser rejeitada: %+v", result)
+	}
+
+	// Carlos tem 30 GOOGL; 10 já estão comprometidos
+	result := engine.ProcessOrder(context.Background(), domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 25, 160))
+	if !result.Rejected || result.Err != domain.ErrInsufficientPosition {
+		t.Errorf("Esperada rejeição por posição insuficiente, obtido %+v", result)
+	}
+}
+
+// TestCancelReleasesReservation verifica que o cancelamento libera a posição reservada
+func TestCancelReleasesReservation(t *testing.T) {
+	engine, books, _ := newEngine(t)
+
+	order := domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 10, 160)
+	if result := engine.ProcessOrder(context.Background(), order); result.Status != matching.StatusPending {
+		t.Fatalf("Esperada venda pendente, obtido %+v", result)
+	}
+
+	cancelled, err := engine.CancelOrder(context.Background(), order.ID)
+	if err != nil || cancelled.Status != domain.CANCELLED {
+		t.Fatalf("Esperado cancelamento, obtido %+v (%v)", cancelled, err)
+	}
+	if book := books.GetOrderBook("GOOGL"); len(book.Asks) != 0 {
+		t.Errorf("Ordem cancelada ainda no livro: %+v", book.Asks)
+	}
+	if _, err := engine.CancelOrder(context.Background(), order.ID); err != domain.ErrOrderNotOpen {
+		t.Errorf("Esperado ErrOrderNotOpen, obtido %v", err)
+	}
+
+	// Sem a reserva, Carlos volta a ter as 30 GOOGL disponíveis
+	if result := engine.ProcessOrder(context.Background(), domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 25, 100)); result.Rejected {
+		t.Errorf("Posição deveria estar liberada: %+v", result)
+	}
+}
+
+// TestAmendReplacesReservation verifica que a alteração troca a reserva de uma
+// vez e, se recusada, mantém a reserva original
+func TestAmendReplacesReservation(t *testing.T) {
+	engine, _, _ := newEngine(t)
+
+	order := domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 10, 160)
+	if result := engine.ProcessOrder(context.Background(), order); result.Status != matching.StatusPending {
+		t.Fatalf("Esperada venda pendente, obtido %+v", result)
+	}
+
+	// Carlos tem 30 GOOGL: 40 não cabem e as 10 continuam reservadas
+	if _, err := engine.AmendOrder(context.Background(), order.ID, 40, 160); err != domain.ErrInsufficientPosition {
+		t.Fatalf("Esperado ErrInsufficientPosition, obtido %v", err)
+	}
+	if result := engine.ProcessOrder(context.Background(), domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 21, 100)); !result.Rejected {
+		t.Errorf("Reserva original deveria continuar valendo: %+v", result)
+	}
+
+	// Aceita, a nova quantidade substitui a anterior em vez de somar
+	if _, err := engine.AmendOrder(context.Background(), order.ID, 15, 160); err != nil {
+		t.Fatalf("Erro inesperado na alteração: %v", err)
+	}
+	if result := engine.ProcessOrder(context.Background(), domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 15, 100)); result.Rejected {
+		t.Errorf("Só 15 deveriam estar reservadas: %+v", result)
+	}
+}
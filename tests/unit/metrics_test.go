@@ -0,0 +1,53 @@
+package unit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"trading/internal/services/shared/metrics"
+)
+
+// TestMetricsTextFormat verifica o formato de texto: famílias em ordem,
+// escape de labels, buckets cumulativos em segundos e união de famílias
+func TestMetricsTextFormat(t *testing.T) {
+	requests := metrics.NewCounterVec("requests_total", "Requisições\npor rota.", "route")
+	requests.Inc(`/a"b`)
+	requests.Inc("/a")
+	requests.Inc("/a")
+
+	latency := metrics.NewHistogramVec("latency_seconds", "Latência.", []time.Duration{time.Millisecond, 10 * time.Millisecond}, "route")
+	latency.Observe(500*time.Microsecond, "/a")
+	latency.Observe(5*time.Millisecond, "/a")
+	latency.Observe(time.Second, "/a")
+
+	extra := metrics.CollectorFunc(func() []metrics.Family {
+		return []metrics.Family{{Name: "requests_total", Help: "ignorado", Type: metrics.TypeCounter,
+			Samples: []metrics.Sample{{Name: "requests_total", Labels: []metrics.Label{{Name: "route", Value: "/z"}}, Value: 7}}}}
+	})
+
+	registry := metrics.NewRegistry()
+	registry.Register(requests, latency, extra)
+
+	var out bytes.Buffer
+	if err := registry.Write(&out); err != nil {
+		t.Fatalf("Erro escrevendo métricas: %v", err)
+	}
+
+	want := `# HELP latency_seconds Latência.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/a",le="0.001"} 1
+latency_seconds_bucket{route="/a",le="0.01"} 2
+latency_seconds_bucket{route="/a",le="+Inf"} 3
+latency_seconds_sum{route="/a"} 1.0055
+latency_seconds_count{route="/a"} 3
+# HELP requests_total Requisições\npor rota.
+# TYPE requests_total counter
+requests_total{route="/a"} 2
+requests_total{route="/a\"b"} 1
+requests_total{route="/z"} 7
+`
+	if out.String() != want {
+		t.Errorf("Saída inesperada:\n%s\nesperado:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"trading/internal/services/web/ratelimit"
+)
+
+// TestRateLimiterTokenBucket verifica rajada, reposição, Retry-After e a
+// troca de limite de uma chave
+func TestRateLimiterTokenBucket(t *testing.T) {
+	now := time.Date(2025, 9, 17, 15, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewLimiter()
+	limiter.SetClock(func() time.Time { return now })
+	limit := ratelimit.Limit{Rate: 2, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if d := limiter.Allow("user:ana", limit); !d.Allowed || d.Limit != 3 || d.Remaining != 2-i {
+			t.Fatalf("Token %d deveria ser permitido com %d restantes, obtido %+v", i, 2-i, d)
+		}
+	}
+	d := limiter.Allow("user:ana", limit)
+	if d.Allowed || d.RetryAfter != 500*time.Millisecond || d.Reset != 1500*time.Millisecond {
+		t.Fatalf("Esperada recusa com Retry-After de 500ms e reset de 1.5s, obtido %+v", d)
+	}
+	if d := limiter.Allow("user:carlos", limit); !d.Allowed {
+		t.Error("Outra chave deveria ter o próprio balde")
+	}
+
+	// Meio segundo repõe um token
+	now = now.Add(500 * time.Millisecond)
+	if d := limiter.Allow("user:ana", limit); !d.Allowed || d.Remaining != 0 {
+		t.Errorf("Esperado um token reposto, obtido %+v", d)
+	}
+
+	// Limite menor não acumula tokens acima da nova rajada
+	now = now.Add(time.Hour)
+	if d := limiter.Allow("user:ana", ratelimit.Limit{Rate: 1, Burst: 1}); !d.Allowed || d.Remaining != 0 || d.Limit != 1 {
+		t.Errorf("Esperada a rajada do novo limite, obtido %+v", d)
+	}
+
+	// Limite desativado sempre permite; baldes cheios são descartados
+	if d := limiter.Allow("user:ana", ratelimit.Limit{}); !d.Allowed {
+		t.Error("Limite zero deveria permitir")
+	}
+	now = now.Add(2 * time.Minute)
+	limiter.Allow("user:beatriz", limit)
+	if n := limiter.Len(); n != 1 {
+		t.Errorf("Esperado apenas o balde recém-usado após a limpeza, obtidos %d", n)
+	}
+}
+
+// TestRateLimiterAllowAll verifica que uma recusa em um balde não consome
+// token dos demais
+func TestRateLimiterAllowAll(t *testing.T) {
+	now := time.Date(2025, 9, 17, 15, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewLimiter()
+	limiter.SetClock(func() time.Time { return now })
+	ip := ratelimit.Request{Key: "ip:203.0.113.7", Limit: ratelimit.Limit{Rate: 1, Burst: 3}}
+	user := ratelimit.Request{Key: "user:ana", Limit: ratelimit.Limit{Rate: 1, Burst: 1}}
+
+	if decisions := limiter.AllowAll(ip, user); !decisions[0].Allowed || !decisions[1].Allowed || decisions[0].Remaining != 2 {
+		t.Fatalf("Esperado token dos dois baldes, obtido %+v", decisions)
+	}
+	decisions := limiter.AllowAll(ip, user)
+	if decisions[1].Allowed || !decisions[0].Allowed || decisions[0].Remaining != 2 {
+		t.Fatalf("Esperada recusa do usuário sem consumir o IP, obtido %+v", decisions)
+	}
+	if d := limiter.Allow(ip.Key, ip.Limit); !d.Allowed || d.Remaining != 1 {
+		t.Errorf("O IP deveria manter os tokens após a recusa do usuário, obtido %+v", d)
+	}
+}
+
+// TestRateLimitConfig verifica os limites por perfil e o formato das variáveis
+func TestRateLimitConfig(t *testing.T) {
+	profiles, err := ratelimit.LoadProfiles(dataDir + "/users.json")
+	if err != nil {
+		t.Fatalf("Erro ao carregar perfis: %v", err)
+	}
+	cfg := ratelimit.Config{User: ratelimit.DefaultUserLimit, Profiles: profiles}
+	if conservador, premium := cfg.ForProfile("conservador"), cfg.ForProfile("premium"); conservador.Burst >= premium.Burst || conservador.Rate >= premium.Rate {
+		t.Errorf("Conservador deveria ter limite menor que premium: %+v, %+v", conservador, premium)
+	}
+	if limit := cfg.ForProfile("desconhecido"); limit != ratelimit.DefaultUserLimit {
+		t.Errorf("Perfil sem rate_limit deveria usar o padrão, obtido %+v", limit)
+	}
+
+	if limit, err := ratelimit.ParseLimit("2.5:10"); err != nil || limit != (ratelimit.Limit{Rate: 2.5, Burst: 10}) {
+		t.Errorf("Esperado 2.5/s com rajada 10, obtido %+v, %v", limit, err)
+	}
+	if limit, err := ratelimit.ParseLimit("0"); err != nil || !limit.Unlimited() {
+		t.Errorf("\"0\" deveria desativar o limite, obtido %+v, %v", limit, err)
+	}
+	for _, value := range []string{"10", "abc:5", "5:0", "-1:5"} {
+		if _, err := ratelimit.ParseLimit(value); err == nil {
+			t.Errorf("Esperado erro para %q", value)
+		}
+	}
+
+	// Usuário autenticado: o IP não impede alcançar o limite do perfil
+	cfg.IP = ratelimit.DefaultIPLimit
+	if limit := cfg.ForIP(cfg.ForProfile("institucional")); limit != (ratelimit.Limit{Rate: 50, Burst: 100}) {
+		t.Errorf("IP deveria valer o limite institucional, obtido %+v", limit)
+	}
+	if limit := cfg.ForIP(cfg.ForProfile("conservador")); limit != ratelimit.DefaultIPLimit {
+		t.Errorf("IP deveria manter o próprio limite, obtido %+v", limit)
+	}
+
+	t.Setenv("RATE_LIMIT_IP", "0")
+	t.Setenv("RATE_LIMIT_USER", "1:2")
+	cfg, err = ratelimit.ConfigFromEnv(dataDir + "/users.json")
+	if err != nil || !cfg.IP.Unlimited() || cfg.User != (ratelimit.Limit{Rate: 1, Burst: 2}) || len(cfg.Profiles) == 0 {
+		t.Errorf("Configuração inesperada: %+v, %v", cfg, err)
+	}
+}
@@ -0,0 +1,240 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine"
+	"trading/internal/services/engine/matching"
+	"trading/internal/services/engine/repository"
+)
+
+// TestSQLiteRepositorySurvivesRestart verifica que ordens, negociações e
+// portfolios gravados pelo engine sobrevivem à reabertura do banco
+func TestSQLiteRepositorySurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trading.db")
+
+	store, err := repository.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("Erro abrindo banco: %v", err)
+	}
+
+	engine, _, portfolios := newEngine(t)
+	engine.SetRepositories(store, store)
+	portfolios.SetRepository(store)
+
+	sell := domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 10, 210)
+	engine.ProcessOrder(context.Background(), sell)
+	buy := domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 4, 210)
+	engine.ProcessOrder(context.Background(), buy)
+
+	wantTrades, _ := json.Marshal(engine.GetTrades())
+	wantPortfolio, _ := portfolios.GetPortfolio("carlos-santos")
+	if err := store.Close(); err != nil {
+		t.Fatalf("Erro fechando banco: %v", err)
+	}
+
+	// Reabrir não reaplica migrações já registradas
+	store, err = repository.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("Erro reabrindo banco: %v", err)
+	}
+	defer store.Close()
+	if version, err := store.SchemaVersion(); err != nil || version != 2 {
+		t.Errorf("Esperada versão 2 do schema, obtido %d (%v)", version, err)
+	}
+
+	restarted, _, restartedPortfolios := newEngine(t)
+	restarted.SetRepositories(store, store)
+	restartedPortfolios.SetRepository(store)
+	if loaded, err := restartedPortfolios.Load(); err != nil || loaded != 2 {
+		t.Fatalf("Esperados 2 portfolios carregados, obtido %d (%v)", loaded, err)
+	}
+
+	if got, _ := json.Marshal(restarted.GetTrades()); !bytes.Equal(got, wantTrades) {
+		t.Errorf("Negociações divergentes após reinício:\n%s\n%s", wantTrades, got)
+	}
+
+	portfolio, _ := restartedPortfolios.GetPortfolio("carlos-santos")
+	if portfolio.GetCash() != wantPortfolio.GetCash() || portfolio.GetPosition("AAPL") != wantPortfolio.GetPosition("AAPL") {
+		t.Errorf("Portfolio divergente: esperado %+v, obtido %+v", wantPortfolio, portfolio)
+	}
+
+	order, err := restarted.GetOrder(sell.ID)
+	if err != nil || order.Status != domain.PARTIAL || order.RemainingQuantity != 6 {
+		t.Errorf("Esperada venda PARTIAL com 6 restantes, obtido %+v (%v)", order, err)
+	}
+
+	filled, err := restarted.ListOrders(repository.OrderFilter{UserID: "beatriz-costa", Status: domain.FILLED})
+	if err != nil || len(filled) != 1 || filled[0].ID != buy.ID {
+		t.Errorf("Esperada a compra de Beatriz como FILLED, obtido %+v (%v)", filled, err)
+	}
+
+	if _, err := restarted.GetOrder("ORD-inexistente"); err != domain.ErrOrderNotFound {
+		t.Errorf("Esperado ErrOrderNotFound, obtido %v", err)
+	}
+}
+
+// TestSQLiteRestoresOpenOrders verifica que, só com o banco (sem journal nem
+// snapshot), as ordens abertas voltam aos livros com suas reservas
+func TestSQLiteRestoresOpenOrders(t *testing.T) {
+	cfg := engine.Config{DataDir: dataDir, DatabasePath: filepath.Join(t.TempDir(), "trading.db")}
+
+	first, err := engine.Open(cfg)
+	if err != nil {
+		t.Fatalf("Erro abrindo engine: %v", err)
+	}
+	partial := domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 10, 210)
+	first.Matcher.ProcessOrder(context.Background(), partial)
+	first.Matcher.ProcessOrder(context.Background(), domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 4, 210))
+	resting := domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 30, 80)
+	first.Matcher.ProcessOrder(context.Background(), resting)
+	if err := first.Close(); err != nil {
+		t.Fatalf("Erro fechando engine: %v", err)
+	}
+
+	restarted, err := engine.Open(cfg)
+	if err != nil {
+		t.Fatalf("Erro reabrindo engine: %v", err)
+	}
+	defer restarted.Close()
+
+	if asks := restarted.Books.GetOrderBook("AAPL").Asks; len(asks) != 1 || asks[0].ID != partial.ID || asks[0].RemainingQuantity != 6 {
+		t.Errorf("Esperada a venda parcial no livro com 6 restantes, obtido %+v", asks)
+	}
+	if asks := restarted.Books.GetOrderBook("GOOGL").Asks; len(asks) != 1 || asks[0].ID != resting.ID {
+		t.Errorf("Esperada a venda de GOOGL no livro, obtido %+v", asks)
+	}
+
+	// As 30 GOOGL continuam reservadas pela ordem restaurada
+	result := restarted.Matcher.ProcessOrder(context.Background(), domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 1, 80))
+	if !result.Rejected || result.Err != domain.ErrInsufficientPosition {
+		t.Errorf("Esperada rejeição por posição reservada, obtido %+v", result)
+	}
+
+	// A ordem restaurada executa normalmente
+	result = restarted.Matcher.ProcessOrder(context.Background(), domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 6, 210))
+	if result.Status != matching.StatusFilled || len(result.Trades) != 1 || result.Trades[0].SellOrderID != partial.ID {
+		t.Errorf("Esperada execução contra a ordem restaurada, obtido %+v", result)
+	}
+}
+
+// TestPersistenceErrorsCounted verifica que gravações recusadas pelo banco
+// não desfazem a execução e aparecem em Stats.PersistenceErrors
+func TestPersistenceErrorsCounted(t *testing.T) {
+	store, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "trading.db"))
+	if err != nil {
+		t.Fatalf("Erro abrindo banco: %v", err)
+	}
+	engine, _, portfolios := newEngine(t)
+	engine.SetRepositories(store, store)
+	portfolios.SetRepository(store)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Erro fechando banco: %v", err)
+	}
+
+	engine.ProcessOrder(context.Background(), domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 10, 210))
+	result := engine.ProcessOrder(context.Background(), domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 4, 210))
+	if result.Rejected || len(result.Trades) != 1 {
+		t.Fatalf("Esperada execução apesar do banco fechado, obtido %+v", result)
+	}
+
+	stats := engine.Stats()
+	if portfolios.PersistenceErrors() == 0 || stats.PersistenceErrors <= portfolios.PersistenceErrors() {
+		t.Errorf("Esperadas falhas de ordens, negociações e portfolios, obtido %d (portfolios %d)",
+			stats.PersistenceErrors, portfolios.PersistenceErrors())
+	}
+}
+
+// TestRepositoryFilters verifica os filtros compartilhados pelas implementações
+func TestRepositoryFilters(t *testing.T) {
+	sqlite, err := repository.OpenSQLite(filepath.Join(t.TempDir(), "trading.db"))
+	if err != nil {
+		t.Fatalf("Erro abrindo banco: %v", err)
+	}
+	defer sqlite.Close()
+
+	stores := map[string]interface {
+		repository.OrderRepository
+		repository.TradeRepository
+	}{
+		"memory": repository.NewMemoryStore(),
+		"sqlite": sqlite,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			sell := domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 10, 210)
+			buy := domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 10, 210)
+			other := domain.NewOrder("beatriz-costa", "GOOGL", domain.BUY, 1, 150)
+			for _, order := range []*domain.Order{sell, buy, other} {
+				if err := store.SaveOrder(order); err != nil {
+					t.Fatalf("Erro gravando ordem: %v", err)
+				}
+			}
+
+			// Regravar atualiza o estado sem mudar a posição no histórico
+			sell.Fill(10)
+			if err := store.SaveOrder(sell); err != nil {
+				t.Fatalf("Erro regravando ordem: %v", err)
+			}
+			orders, _ := store.ListOrders(repository.OrderFilter{})
+			if len(orders) != 3 || orders[0].ID != other.ID || orders[2].ID != sell.ID || orders[2].Status != domain.FILLED {
+				t.Errorf("Histórico inesperado: %+v", orders)
+			}
+
+			// Limit traz as mais recentes
+			byUser, _ := store.ListOrders(repository.OrderFilter{UserID: "beatriz-costa", Limit: 1})
+			if len(byUser) != 1 || byUser[0].ID != other.ID {
+				t.Errorf("Esperada apenas a compra de GOOGL, a mais recente, obtido %+v", byUser)
+			}
+			if aapl, _ := store.ListOrders(repository.OrderFilter{UserID: "beatriz-costa", Symbol: "AAPL"}); len(aapl) != 1 || aapl[0].ID != buy.ID {
+				t.Errorf("Esperada apenas a compra de AAPL, obtido %+v", aapl)
+			}
+
+			trade := domain.NewTrade(buy, sell, 10, 210)
+			if err := store.SaveTrade(trade); err != nil {
+				t.Fatalf("Erro gravando negociação: %v", err)
+			}
+			for _, filter := range []repository.TradeFilter{{UserID: "carlos-santos"}, {UserID: "beatriz-costa"}, {Symbol: "AAPL"}} {
+				if trades, _ := store.ListTrades(filter); len(trades) != 1 || trades[0].ID != trade.ID {
+					t.Errorf("Filtro %+v: esperada 1 negociação, obtido %+v", filter, trades)
+				}
+			}
+			if trades, _ := store.ListTrades(repository.TradeFilter{Symbol: "GOOGL"}); len(trades) != 0 {
+				t.Errorf("Esperada nenhuma negociação de GOOGL, obtido %+v", trades)
+			}
+			if trades, _ := store.ListTrades(repository.TradeFilter{From: trade.ExecutedAt.Add(1)}); len(trades) != 0 {
+				t.Errorf("Filtro From deveria excluir a negociação, obtido %+v", trades)
+			}
+
+			// Valor mínimo, ordem inversa e cursor
+			small := domain.NewTrade(other, sell, 1, 150)
+			last := domain.NewTrade(buy, sell, 2, 211)
+			for _, tr := range []*domain.Trade{small, last} {
+				if err := store.SaveTrade(tr); err != nil {
+					t.Fatalf("Erro gravando negociação: %v", err)
+				}
+			}
+			if trades, _ := store.ListTrades(repository.TradeFilter{MinValue: 422}); len(trades) != 2 || trades[1].ID != last.ID {
+				t.Errorf("MinValue deveria excluir a negociação de 150, obtido %+v", trades)
+			}
+			if trades, _ := store.ListTrades(repository.TradeFilter{Descending: true, Limit: 2}); len(trades) != 2 || trades[0].ID != last.ID || trades[1].ID != small.ID {
+				t.Errorf("Ordem inversa inesperada: %+v", trades)
+			}
+			if trades, _ := store.ListTrades(repository.TradeFilter{After: trade.ID}); len(trades) != 2 || trades[0].ID != small.ID {
+				t.Errorf("Cursor crescente inesperado: %+v", trades)
+			}
+			if trades, _ := store.ListTrades(repository.TradeFilter{After: small.ID, Descending: true}); len(trades) != 1 || trades[0].ID != trade.ID {
+				t.Errorf("Cursor decrescente inesperado: %+v", trades)
+			}
+			if trades, _ := store.ListTrades(repository.TradeFilter{After: "TRD-inexistente"}); len(trades) != 0 {
+				t.Errorf("Cursor desconhecido deveria retornar vazio, obtido %+v", trades)
+			}
+		})
+	}
+}
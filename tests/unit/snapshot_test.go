@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"trading/internal/domain"
+	"trading/internal/services/engine/journal"
+	"trading/internal/services/engine/repository"
+	"trading/internal/services/engine/snapshot"
+)
+
+// TestSnapshotPlusJournalTail verifica que snapshot + final do journal
+// reproduzem o mesmo estado do engine original
+func TestSnapshotPlusJournalTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "journal.log")
+	sequences := domain.Sequences()
+
+	commands, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Erro abrindo journal: %v", err)
+	}
+	store, err := snapshot.NewStore(filepath.Join(dir, "snapshots"), 2)
+	if err != nil {
+		t.Fatalf("Erro criando store: %v", err)
+	}
+
+	// O histórico de negociações fica no repositório, compartilhado entre os
+	// dois processos como um banco de dados
+	repo := repository.NewMemoryStore()
+	engine, books, portfolios := newEngine(t)
+	engine.SetRepositories(repo, repo)
+	engine.SetJournal(commands)
+	snapshots := snapshot.NewManager(engine, store, commands)
+
+	sell := domain.NewOrder("carlos-santos", "AAPL", domain.SELL, 10, 210)
+	engine.ProcessOrder(context.Background(), sell)
+	engine.ProcessOrder(context.Background(), domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 4, 210))
+
+	info, err := snapshots.Take()
+	if err != nil || info.Seq != 2 {
+		t.Fatalf("Esperado snapshot na seq 2, obtido %+v (%v)", info, err)
+	}
+
+	engine.ProcessOrder(context.Background(), domain.NewOrder("beatriz-costa", "AAPL", domain.BUY, 3, 211))
+	engine.ProcessOrder(context.Background(), domain.NewOrder("carlos-santos", "GOOGL", domain.SELL, 5, 150))
+	if _, err := engine.AmendOrder(context.Background(), sell.ID, 9, 212); err != nil {
+		t.Fatalf("Erro alterando ordem: %v", err)
+	}
+
+	want := engineState(t, engine, books, portfolios)
+	commands.Close()
+
+	// Novo processo: carrega o snapshot e reaplica só os 3 comandos seguintes
+	domain.RestoreSequences(sequences)
+	reopened, err := journal.Open(path)
+	if err != nil {
+		t.Fatalf("Erro reabrindo journal: %v", err)
+	}
+	defer reopened.Close()
+
+	engine, books, portfolios = newEngine(t)
+	engine.SetRepositories(repo, repo)
+	recovered, applied, err := snapshot.NewManager(engine, store, reopened).Recover()
+	if err != nil || recovered.Seq != 2 || applied != 3 {
+		t.Fatalf("Recover inesperado: %+v, %d comandos, %v", recovered, applied, err)
+	}
+
+	if got := engineState(t, engine, books, portfolios); !bytes.Equal(got, want) {
+		t.Errorf("Estado restaurado difere:\nesperado %s\nobtido   %s", want, got)
+	}
+
+	file := filepath.Join(dir, "snapshots", recovered.File)
+	data, _ := os.ReadFile(file)
+	if bytes.Contains(data, []byte(`"trades"`)) {
+		t.Error("Snapshot não deveria copiar o histórico de negociações")
+	}
+
+	t.Run("Corrupted", func(t *testing.T) {
+		_ = os.WriteFile(file, bytes.Replace(data, []byte("carlos-santos"), []byte("carlos-santoz"), 1), 0o644)
+
+		if _, err := store.Load(2); !errors.Is(err, snapshot.ErrCorrupt) {
+			t.Errorf("Esperado ErrCorrupt, obtido %v", err)
+		}
+		if _, err := store.Load(99); !errors.Is(err, snapshot.ErrNotFound) {
+			t.Errorf("Esperado ErrNotFound, obtido %v", err)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"trading/internal/domain"
+	"trading/internal/services/shared/stats"
+)
+
+// TestStatsCounters verifica os contadores sob concorrência e o agrupamento
+// de erros desconhecidos
+func TestStatsCounters(t *testing.T) {
+	rejections := stats.NewErrorCounter(domain.ErrInsufficientBalance, domain.ErrMarketClosed)
+	var totals stats.KeyedTotals
+	var users stats.Set
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				rejections.Inc(fmt.Errorf("reserva: %w", domain.ErrInsufficientBalance))
+				totals.Add("AAPL", 0.5)
+				users.Add(fmt.Sprintf("user-%d", j%10))
+			}
+			rejections.Inc(errors.New("falha de disco"))
+		}(i)
+	}
+	wg.Wait()
+
+	counts := rejections.Snapshot()
+	if counts[domain.ErrInsufficientBalance] != 8000 || counts[stats.ErrOther] != 8 || len(counts) != 2 {
+		t.Errorf("Rejeições inesperadas: %v", counts)
+	}
+	if total := totals.Snapshot()["AAPL"]; total.Count != 8000 || total.Sum != 4000 {
+		t.Errorf("Totais inesperados: %+v", total)
+	}
+	if users.Len() != 10 {
+		t.Errorf("Esperados 10 usuários distintos, obtido %d", users.Len())
+	}
+}
+
+// TestStatsHistogram verifica média e quantis estimados pelas faixas
+func TestStatsHistogram(t *testing.T) {
+	h := stats.NewHistogram([]time.Duration{time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond})
+	if snapshot := h.Snapshot(); snapshot.Mean() != 0 || snapshot.Quantile(0.99) != 0 {
+		t.Errorf("Histograma vazio deveria ser zero: %+v", snapshot)
+	}
+
+	// 98 observações rápidas, uma lenta e uma acima do último limite
+	for i := 0; i < 98; i++ {
+		h.Observe(500 * time.Microsecond)
+	}
+	h.Observe(50 * time.Millisecond)
+	h.Observe(time.Second)
+
+	snapshot := h.Snapshot()
+	if snapshot.Count != 100 || snapshot.Mean() != (98*500*time.Microsecond+1050*time.Millisecond)/100 {
+		t.Errorf("Contagem ou média inesperada: %d %v", snapshot.Count, snapshot.Mean())
+	}
+	if q := snapshot.Quantile(0.5); q <= 0 || q > time.Millisecond {
+		t.Errorf("Mediana deveria estar na primeira faixa, obtido %v", q)
+	}
+	if q := snapshot.Quantile(0.99); q <= 10*time.Millisecond || q > 100*time.Millisecond {
+		t.Errorf("p99 deveria estar na faixa de 10ms a 100ms, obtido %v", q)
+	}
+	if q := snapshot.Quantile(1); q != 100*time.Millisecond {
+		t.Errorf("Acima do último limite o quantil é o próprio limite, obtido %v", q)
+	}
+}
@@ -0,0 +1,122 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"trading/internal/services/shared/tracing"
+)
+
+// memoryExporter guarda os spans exportados
+type memoryExporter struct {
+	spans []tracing.SpanData
+	mutex sync.Mutex
+}
+
+func (e *memoryExporter) Export(span tracing.SpanData) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func (e *memoryExporter) Shutdown() error { return nil }
+
+// TestTracingSpans verifica hierarquia, atributos, erros e a propagação via
+// traceparent
+func TestTracingSpans(t *testing.T) {
+	exporter := &memoryExporter{}
+	tracer := tracing.NewTracer("teste", exporter)
+
+	ctx, root := tracer.Start(context.Background(), "raiz", slog.String("order.id", "ORD-1"))
+	_, child := tracer.Start(ctx, "filho")
+	child.RecordError(errors.New("saldo insuficiente"))
+	child.End()
+	child.End()
+	root.End()
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("Esperados 2 spans, obtidos %d", len(exporter.spans))
+	}
+	c, r := exporter.spans[0], exporter.spans[1]
+	if c.TraceID != r.TraceID || c.ParentID != r.SpanID || r.ParentID != "" {
+		t.Errorf("Hierarquia inesperada: raiz %+v filho %+v", r, c)
+	}
+	if r.Attributes["order.id"] != "ORD-1" || c.Error != "saldo insuficiente" || r.Service != "teste" {
+		t.Errorf("Atributos inesperados: raiz %+v filho %+v", r, c)
+	}
+
+	// O pai remoto vem do header traceparent
+	header := tracing.TraceParent(ctx)
+	if header != "00-"+r.TraceID+"-"+r.SpanID+"-01" {
+		t.Errorf("traceparent inesperado: %s", header)
+	}
+	_, remote := tracer.Start(tracing.WithTraceParent(context.Background(), header), "remoto")
+	remote.End()
+	if got := exporter.spans[2]; got.TraceID != r.TraceID || got.ParentID != r.SpanID {
+		t.Errorf("Span remoto fora do trace: %+v", got)
+	}
+	for _, invalid := range []string{"", "00-123-456-01", "ff-" + r.TraceID + "-" + r.SpanID + "-01", "00-" + r.TraceID + "-zzzzzzzzzzzzzzzz-01"} {
+		if tracing.TraceParent(tracing.WithTraceParent(context.Background(), invalid)) != "" {
+			t.Errorf("traceparent inválido aceito: %q", invalid)
+		}
+	}
+
+	// Sem tracer padrão os spans são nil e ignorados
+	tracing.SetDefault(nil)
+	if _, span := tracing.Start(context.Background(), "desligado"); span != nil {
+		t.Error("Esperado span nil sem tracer")
+	}
+}
+
+// TestTracingOTLPExporter verifica o lote enviado ao coletor OTLP/HTTP
+func TestTracingOTLPExporter(t *testing.T) {
+	requests := make(chan map[string]interface{}, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Requisição inesperada: %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests <- body
+	}))
+	defer collector.Close()
+
+	exporter := tracing.NewOTLPExporter(collector.URL + "/v1/traces")
+	tracer := tracing.NewTracer("trading-engine", exporter)
+	_, span := tracer.Start(context.Background(), "matching.ProcessOrder", slog.String("order.symbol", "AAPL"), slog.Int("order.quantity", 5))
+	span.RecordError(errors.New("rejeitada"))
+	span.End()
+	if err := tracer.Shutdown(); err != nil {
+		t.Fatalf("Erro no shutdown: %v", err)
+	}
+
+	body := <-requests
+	resource := body["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	service := resource["resource"].(map[string]interface{})["attributes"].([]interface{})[0].(map[string]interface{})
+	if service["key"] != "service.name" || service["value"].(map[string]interface{})["stringValue"] != "trading-engine" {
+		t.Errorf("Resource inesperado: %+v", resource["resource"])
+	}
+
+	spans := resource["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	got := spans[0].(map[string]interface{})
+	if got["name"] != "matching.ProcessOrder" || len(got["traceId"].(string)) != 32 || len(got["spanId"].(string)) != 16 {
+		t.Errorf("Span inesperado: %+v", got)
+	}
+	if status := got["status"].(map[string]interface{}); status["code"] != float64(2) || status["message"] != "rejeitada" {
+		t.Errorf("Status inesperado: %+v", status)
+	}
+	values := map[string]interface{}{}
+	for _, attr := range got["attributes"].([]interface{}) {
+		kv := attr.(map[string]interface{})
+		values[kv["key"].(string)] = kv["value"]
+	}
+	if values["order.symbol"].(map[string]interface{})["stringValue"] != "AAPL" || values["order.quantity"].(map[string]interface{})["intValue"] != "5" {
+		t.Errorf("Atributos inesperados: %+v", values)
+	}
+}